@@ -1,4 +1,4 @@
-// Package circle is a simple circle component with customisable size, colour, location and radius.
+// Package circle is a simple circle component with customisable size, colour or gradient, location and radius, which can also be drawn as a pie slice or chord segment, optionally with a border.
 package circle
 
 import (
@@ -32,13 +32,38 @@ type Component struct {
 	Radius int
 	// Colour is the colour of the circle.
 	Colour color.NRGBA
+	/*
+		StartAngle and EndAngle limit the circle to an arc running clockwise between them, in
+		degrees clockwise from the positive X axis. The whole circle is drawn if they are equal.
+	*/
+	StartAngle float64
+	EndAngle   float64
+	// ArcStyle is the shape filled by an arc, defaulting to a pie slice.
+	ArcStyle render.ArcStyle
+	// BorderWidth is the width of the border drawn just inside the edge of the circle, or zero for no border.
+	BorderWidth float64
+	// BorderColour is the colour of the border.
+	BorderColour color.NRGBA
+	// Outline leaves the inside of the circle unfilled, so only the border is drawn.
+	Outline bool
+	// Gradient fills the circle instead of Colour if it has any colour stops.
+	Gradient render.Gradient
 }
 
 type circleFormat struct {
-	CentreX string       `json:"centreX"`
-	CentreY string       `json:"centreY"`
-	Radius  string       `json:"radius"`
-	Colour  colourFormat `json:"colour"`
+	CentreX    string       `json:"centreX"`
+	CentreY    string       `json:"centreY"`
+	Radius     string       `json:"radius"`
+	Colour     colourFormat `json:"colour"`
+	StartAngle string       `json:"startAngle"`
+	EndAngle   string       `json:"endAngle"`
+	ArcStyle   string       `json:"arcStyle"`
+	Border     struct {
+		Width  string       `json:"width"`
+		Colour colourFormat `json:"colour"`
+	} `json:"border"`
+	Outline  string                  `json:"outline"`
+	Gradient *cutils.GradientStrings `json:"gradient"`
 }
 
 type colourFormat struct {
@@ -53,7 +78,32 @@ func (component Component) Write(canvas render.Canvas) (render.Canvas, error) {
 	if len(component.NamedPropertiesMap) != 0 {
 		return canvas, fmt.Errorf("cannot draw circle, not all named properties are set: %v", component.NamedPropertiesMap)
 	}
-	return canvas.Circle(component.Centre, component.Radius, component.Colour)
+	style := component.ArcStyle
+	if style == "" {
+		style = render.ArcPie
+	}
+	fill := cutils.FillColour(component.Colour, component.Gradient)
+	if component.BorderWidth == 0 && !component.Outline {
+		if component.StartAngle == component.EndAngle {
+			return canvas.Circle(component.Centre, component.Radius, fill)
+		}
+		return canvas.Arc(component.Centre, component.Radius, component.Radius, component.StartAngle, component.EndAngle, style, fill)
+	}
+	if component.Radius <= 0 {
+		return canvas, fmt.Errorf("invalid radius")
+	}
+	path := render.NewEllipsePath(component.Centre, component.Radius, component.Radius)
+	if component.StartAngle != component.EndAngle {
+		var err error
+		path, err = render.NewArcPath(component.Centre, component.Radius, component.Radius, component.StartAngle, component.EndAngle, style)
+		if err != nil {
+			return canvas, err
+		}
+	}
+	if component.Outline {
+		fill = nil
+	}
+	return canvas.Path(path, fill, component.BorderColour, render.StrokeStyle{Width: component.BorderWidth, Inside: true})
 }
 
 // SetNamedProperties processes the named properties and sets them into the circle properties.
@@ -95,33 +145,92 @@ func (component Component) VerifyAndSetJSONData(data interface{}) (render.Compon
 	if err != nil {
 		return component, props, err
 	}
-	c.NamedPropertiesMap, newVal, err = render.ExtractSingleProp(stringStruct.Colour.Red, "R", render.Uint8Type, c.NamedPropertiesMap)
-	if err != nil {
-		return component, props, err
-	}
-	if newVal != nil {
-		c.Colour.R = newVal.(uint8)
-	}
-	c.NamedPropertiesMap, newVal, err = render.ExtractSingleProp(stringStruct.Colour.Green, "G", render.Uint8Type, c.NamedPropertiesMap)
-	if err != nil {
-		return component, props, err
-	}
-	if newVal != nil {
-		c.Colour.G = newVal.(uint8)
-	}
-	c.NamedPropertiesMap, newVal, err = render.ExtractSingleProp(stringStruct.Colour.Blue, "B", render.Uint8Type, c.NamedPropertiesMap)
-	if err != nil {
-		return component, props, err
-	}
-	if newVal != nil {
-		c.Colour.B = newVal.(uint8)
-	}
-	c.NamedPropertiesMap, newVal, err = render.ExtractSingleProp(stringStruct.Colour.Alpha, "A", render.Uint8Type, c.NamedPropertiesMap)
-	if err != nil {
-		return component, props, err
-	}
-	if newVal != nil {
-		c.Colour.A = newVal.(uint8)
+	if stringStruct.Outline != "" {
+		c.NamedPropertiesMap, newVal, err = render.ExtractSingleProp(stringStruct.Outline, "outline", render.BoolType, c.NamedPropertiesMap)
+		if err != nil {
+			return component, props, err
+		}
+		if newVal != nil {
+			c.Outline = newVal.(bool)
+		}
+	}
+	colourSet := stringStruct.Colour != (colourFormat{})
+	if stringStruct.Gradient != nil {
+		if colourSet {
+			return component, props, fmt.Errorf("a circle takes either a colour or a gradient, not both")
+		}
+		c.Gradient, c.NamedPropertiesMap, err = cutils.ParseGradient(*stringStruct.Gradient, "gradient", c.NamedPropertiesMap)
+		if err != nil {
+			return component, props, err
+		}
+	}
+	// An outline has no fill and a gradient replaces the colour, so neither needs a colour
+	if !(c.Outline || stringStruct.Gradient != nil) || colourSet {
+		c.NamedPropertiesMap, newVal, err = render.ExtractSingleProp(stringStruct.Colour.Red, "R", render.Uint8Type, c.NamedPropertiesMap)
+		if err != nil {
+			return component, props, err
+		}
+		if newVal != nil {
+			c.Colour.R = newVal.(uint8)
+		}
+		c.NamedPropertiesMap, newVal, err = render.ExtractSingleProp(stringStruct.Colour.Green, "G", render.Uint8Type, c.NamedPropertiesMap)
+		if err != nil {
+			return component, props, err
+		}
+		if newVal != nil {
+			c.Colour.G = newVal.(uint8)
+		}
+		c.NamedPropertiesMap, newVal, err = render.ExtractSingleProp(stringStruct.Colour.Blue, "B", render.Uint8Type, c.NamedPropertiesMap)
+		if err != nil {
+			return component, props, err
+		}
+		if newVal != nil {
+			c.Colour.B = newVal.(uint8)
+		}
+		c.NamedPropertiesMap, newVal, err = render.ExtractSingleProp(stringStruct.Colour.Alpha, "A", render.Uint8Type, c.NamedPropertiesMap)
+		if err != nil {
+			return component, props, err
+		}
+		if newVal != nil {
+			c.Colour.A = newVal.(uint8)
+		}
+	}
+	// The arc is optional, but needs both angles if either is given
+	if stringStruct.StartAngle != "" || stringStruct.EndAngle != "" {
+		c.StartAngle, c.NamedPropertiesMap, err = cutils.ExtractFloat(stringStruct.StartAngle, "startAngle", c.NamedPropertiesMap)
+		if err != nil {
+			return component, props, err
+		}
+		c.EndAngle, c.NamedPropertiesMap, err = cutils.ExtractFloat(stringStruct.EndAngle, "endAngle", c.NamedPropertiesMap)
+		if err != nil {
+			return component, props, err
+		}
+	}
+	border := stringStruct.Border
+	if border.Width != "" || border.Colour != (colourFormat{}) {
+		c.BorderWidth, c.NamedPropertiesMap, err = cutils.ExtractFloat(border.Width, "borderWidth", c.NamedPropertiesMap)
+		if err != nil {
+			return component, props, err
+		}
+		c.BorderColour, c.NamedPropertiesMap, err = cutils.ParseColourStrings(cutils.ColourStrings{R: border.Colour.Red, G: border.Colour.Green, B: border.Colour.Blue, A: border.Colour.Alpha}, "border", c.NamedPropertiesMap)
+		if err != nil {
+			return component, props, err
+		}
+	} else if c.Outline {
+		return component, props, fmt.Errorf("an outline circle needs a border")
+	}
+	if stringStruct.ArcStyle != "" {
+		var styleName string
+		styleName, c.NamedPropertiesMap, err = cutils.ExtractString(stringStruct.ArcStyle, "arcStyle", c.NamedPropertiesMap)
+		if err != nil {
+			return component, props, err
+		}
+		if styleName != "" {
+			c.ArcStyle, err = render.ToArcStyle(styleName)
+			if err != nil {
+				return component, props, err
+			}
+		}
 	}
 	for key := range c.NamedPropertiesMap {
 		props[key] = struct {
@@ -6,6 +6,7 @@ import (
 	"image/color"
 	"testing"
 
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
 	"github.com/LLKennedy/imagetemplate/v3/render"
 	"github.com/stretchr/testify/assert"
 )
@@ -37,6 +38,69 @@ func TestCircleWrite(t *testing.T) {
 		assert.NoError(t, err)
 		canvas.AssertExpectations(t)
 	})
+	t.Run("arc", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		canvas.On("Arc", image.Pt(5, 6), 4, 4, 90.0, 180.0, render.ArcPie, color.NRGBA{A: 255}).Return(canvas, nil)
+		c := Component{Centre: image.Pt(5, 6), Radius: 4, Colour: color.NRGBA{A: 255}, StartAngle: 90, EndAngle: 180}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.NoError(t, err)
+		canvas.AssertExpectations(t)
+	})
+	t.Run("chord", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		canvas.On("Arc", image.Pt(5, 6), 4, 4, -90.0, 0.0, render.ArcChord, color.NRGBA{A: 255}).Return(canvas, fmt.Errorf("some error"))
+		c := Component{Centre: image.Pt(5, 6), Radius: 4, Colour: color.NRGBA{A: 255}, StartAngle: -90, ArcStyle: render.ArcChord}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "some error")
+		canvas.AssertExpectations(t)
+	})
+	t.Run("gradient", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		gradient := render.Gradient{Type: render.GradientRadial, Stops: []render.GradientStop{{Offset: 0, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 1, Colour: color.NRGBA{B: 255, A: 255}}}}
+		canvas.On("Circle", image.Pt(5, 6), 4, gradient).Return(canvas, nil)
+		c := Component{Centre: image.Pt(5, 6), Radius: 4, Colour: color.NRGBA{A: 255}, Gradient: gradient}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.NoError(t, err)
+		canvas.AssertExpectations(t)
+	})
+	t.Run("border", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		canvas.On("Path", render.NewEllipsePath(image.Pt(5, 6), 4, 4), color.NRGBA{A: 255}, color.NRGBA{R: 255, A: 255}, render.StrokeStyle{Width: 2, Inside: true}).Return(canvas, nil)
+		c := Component{Centre: image.Pt(5, 6), Radius: 4, Colour: color.NRGBA{A: 255}, BorderWidth: 2, BorderColour: color.NRGBA{R: 255, A: 255}}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.NoError(t, err)
+		canvas.AssertExpectations(t)
+	})
+	t.Run("outline arc", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		path, _ := render.NewArcPath(image.Pt(5, 6), 4, 4, 0, 90, render.ArcPie)
+		canvas.On("Path", path, nil, color.NRGBA{R: 255, A: 255}, render.StrokeStyle{Width: 2, Inside: true}).Return(canvas, fmt.Errorf("some error"))
+		c := Component{Centre: image.Pt(5, 6), Radius: 4, EndAngle: 90, BorderWidth: 2, BorderColour: color.NRGBA{R: 255, A: 255}, Outline: true}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "some error")
+		canvas.AssertExpectations(t)
+	})
+	t.Run("border invalid radius", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		c := Component{Centre: image.Pt(5, 6), BorderWidth: 2}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid radius")
+		canvas.AssertExpectations(t)
+	})
+	t.Run("border invalid arc style", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		c := Component{Centre: image.Pt(5, 6), Radius: 4, EndAngle: 90, ArcStyle: "slice", BorderWidth: 2}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "unsupported arc style: slice")
+		canvas.AssertExpectations(t)
+	})
 }
 
 func TestCircleSetNamedProperties(t *testing.T) {
@@ -153,6 +217,67 @@ func TestCircleSetNamedProperties(t *testing.T) {
 			},
 			err: "",
 		},
+		{
+			name: "arc",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"from":  {"startAngle"},
+					"to":    {"endAngle"},
+					"shape": {"arcStyle"},
+				},
+			},
+			input: render.NamedProperties{
+				"from":  45.0,
+				"to":    135.0,
+				"shape": "chord",
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				StartAngle:         45,
+				EndAngle:           135,
+				ArcStyle:           render.ArcChord,
+			},
+			err: "",
+		},
+		{
+			name: "invalid arc style",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"shape": {"arcStyle"},
+				},
+			},
+			input: render.NamedProperties{
+				"shape": "slice",
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"shape": {"arcStyle"},
+				},
+			},
+			err: "arc style slice does not match defined constants",
+		},
+		{
+			name: "border",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"thick":  {"borderWidth"},
+					"frame":  {"borderR", "borderG", "borderB", "borderA"},
+					"hollow": {"outline"},
+				},
+			},
+			input: render.NamedProperties{
+				"thick":  2.5,
+				"frame":  uint8(30),
+				"hollow": true,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				BorderWidth:        2.5,
+				BorderColour:       color.NRGBA{R: 30, G: 30, B: 30, A: 30},
+				Outline:            true,
+			},
+			err: "",
+		},
 		{
 			name: "full prop set, multiple sources, unused props",
 			start: Component{
@@ -353,7 +478,122 @@ func TestCircleVerifyAndTestCircleJSONData(t *testing.T) {
 			res:   Component{NamedPropertiesMap: map[string][]string{"a": {"A"}}, Centre: image.Pt(6, 7), Radius: 10, Colour: color.NRGBA{R: 100, G: 10, B: 200}},
 			props: render.NamedProperties{"a": struct{ Message string }{Message: "Please replace me with real data"}},
 		},
+		{
+			name: "arc",
+			input: &circleFormat{
+				CentreX:    "6",
+				CentreY:    "7",
+				Radius:     "10",
+				Colour:     colourFormat{Red: "100", Green: "10", Blue: "200", Alpha: "80"},
+				StartAngle: "-45",
+				EndAngle:   "$end$",
+				ArcStyle:   "chord",
+			},
+			res:   Component{NamedPropertiesMap: map[string][]string{"end": {"endAngle"}}, Centre: image.Pt(6, 7), Radius: 10, Colour: color.NRGBA{R: 100, G: 10, B: 200, A: 80}, StartAngle: -45, ArcStyle: render.ArcChord},
+			props: render.NamedProperties{"end": struct{ Message string }{Message: "Please replace me with real data"}},
+		},
+		{
+			name: "arc missing end",
+			input: &circleFormat{
+				CentreX:    "6",
+				CentreY:    "7",
+				Radius:     "10",
+				Colour:     colourFormat{Red: "100", Green: "10", Blue: "200", Alpha: "80"},
+				StartAngle: "-45",
+			},
+			props: render.NamedProperties{},
+			err:   "error parsing data for property endAngle: could not parse empty property",
+		},
+		{
+			name: "invalid arc style",
+			input: &circleFormat{
+				CentreX:  "6",
+				CentreY:  "7",
+				Radius:   "10",
+				Colour:   colourFormat{Red: "100", Green: "10", Blue: "200", Alpha: "80"},
+				ArcStyle: "slice",
+			},
+			props: render.NamedProperties{},
+			err:   "arc style slice does not match defined constants",
+		},
 	}
+	bordered := &circleFormat{CentreX: "6", CentreY: "7", Radius: "10", Colour: colourFormat{Red: "100", Green: "10", Blue: "200", Alpha: "80"}}
+	bordered.Border.Width = "$thick$"
+	bordered.Border.Colour = colourFormat{Red: "255", Green: "0", Blue: "0", Alpha: "255"}
+	tests = append(tests, testSet{
+		name:  "border",
+		input: bordered,
+		res: Component{
+			NamedPropertiesMap: map[string][]string{"thick": {"borderWidth"}},
+			Centre:             image.Pt(6, 7),
+			Radius:             10,
+			Colour:             color.NRGBA{R: 100, G: 10, B: 200, A: 80},
+			BorderColour:       color.NRGBA{R: 255, A: 255},
+		},
+		props: render.NamedProperties{"thick": struct{ Message string }{Message: "Please replace me with real data"}},
+	})
+	invalidBorder := *bordered
+	invalidBorder.Border.Colour.Blue = "blue"
+	tests = append(tests, testSet{
+		name:  "invalid border colour",
+		input: &invalidBorder,
+		props: render.NamedProperties{},
+		err:   "failed to convert property borderB to uint8: strconv.ParseUint: parsing \"blue\": invalid syntax",
+	})
+	outline := &circleFormat{CentreX: "6", CentreY: "7", Radius: "10", Outline: "true"}
+	tests = append(tests, testSet{
+		name:  "outline without border",
+		input: outline,
+		props: render.NamedProperties{},
+		err:   "an outline circle needs a border",
+	})
+	outlineWithBorder := *outline
+	outlineWithBorder.Border.Width = "1"
+	outlineWithBorder.Border.Colour = colourFormat{Red: "0", Green: "0", Blue: "0", Alpha: "255"}
+	tests = append(tests, testSet{
+		name:  "outline",
+		input: &outlineWithBorder,
+		res: Component{
+			NamedPropertiesMap: map[string][]string{},
+			Centre:             image.Pt(6, 7),
+			Radius:             10,
+			BorderWidth:        1,
+			BorderColour:       color.NRGBA{A: 255},
+			Outline:            true,
+		},
+		props: render.NamedProperties{},
+	})
+	invalidOutline := *outline
+	invalidOutline.Outline = "maybe"
+	tests = append(tests, testSet{
+		name:  "invalid outline",
+		input: &invalidOutline,
+		props: render.NamedProperties{},
+		err:   "failed to convert property outline to bool: strconv.ParseBool: parsing \"maybe\": invalid syntax",
+	})
+	gradient := &circleFormat{CentreX: "6", CentreY: "7", Radius: "10", Gradient: &cutils.GradientStrings{
+		Angle: "$angle$",
+		Stops: []cutils.GradientStopStrings{{Colour: cutils.ColourStrings{R: "255", G: "0", B: "0", A: "255"}}, {Offset: "0.5", Colour: cutils.ColourStrings{R: "0", G: "0", B: "255", A: "255"}}},
+	}}
+	tests = append(tests, testSet{
+		name:  "gradient",
+		input: gradient,
+		res: Component{
+			NamedPropertiesMap: map[string][]string{"angle": {"gradientAngle"}},
+			Centre:             image.Pt(6, 7),
+			Radius:             10,
+			Gradient:           render.Gradient{Type: render.GradientLinear, Stops: []render.GradientStop{{Offset: 0, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 0.5, Colour: color.NRGBA{B: 255, A: 255}}}},
+		},
+		props: render.NamedProperties{"angle": struct{ Message string }{Message: "Please replace me with real data"}},
+	})
+	gradientAndColour := *gradient
+	gradientAndColour.Colour = colourFormat{Alpha: "255"}
+	tests = append(tests, testSet{
+		name:  "gradient and colour",
+		input: &gradientAndColour,
+		props: render.NamedProperties{},
+		err:   "a circle takes either a colour or a gradient, not both",
+	})
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			res, props, err := test.start.VerifyAndSetJSONData(test.input)
@@ -1,9 +1,8 @@
 package circle
 
 import (
-	"fmt"
-
 	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/LLKennedy/imagetemplate/v3/render"
 )
 
 func (component *Component) delegatedSetProperties(name string, value interface{}) (err error) {
@@ -22,8 +21,31 @@ func (component *Component) delegatedSetProperties(name string, value interface{
 		component.Centre.Y, err = cutils.SetInt(value)
 	case "radius":
 		component.Radius, err = cutils.SetInt(value)
+	case "startAngle":
+		component.StartAngle, err = cutils.SetFloat64(value)
+	case "endAngle":
+		component.EndAngle, err = cutils.SetFloat64(value)
+	case "borderWidth":
+		component.BorderWidth, err = cutils.SetFloat64(value)
+	case "borderR":
+		component.BorderColour.R, err = cutils.SetUint8(value)
+	case "borderG":
+		component.BorderColour.G, err = cutils.SetUint8(value)
+	case "borderB":
+		component.BorderColour.B, err = cutils.SetUint8(value)
+	case "borderA":
+		component.BorderColour.A, err = cutils.SetUint8(value)
+	case "outline":
+		component.Outline, err = cutils.SetBool(value)
+	case "arcStyle":
+		var styleName string
+		styleName, err = cutils.SetString(value)
+		if err == nil {
+			component.ArcStyle, err = render.ToArcStyle(styleName)
+		}
 	default:
-		err = fmt.Errorf("invalid component property in named property map: %v", name)
+		// Anything else is either part of the gradient or invalid
+		err = cutils.SetGradientProperty(&component.Gradient, "gradient", name, value)
 	}
 	return
 }
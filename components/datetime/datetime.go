@@ -38,10 +38,16 @@ type Component struct {
 	Size float64
 	// MaxWidth is the maximum number of horizontal pixels the dot can move before scaling text.
 	MaxWidth int
+	// Overflow is how text too wide for MaxWidth is fitted, where empty is the same as cutils.OverflowScale.
+	Overflow cutils.TextOverflow
+	// MinSize is the smallest size in points the text can be scaled down to, or zero for no limit.
+	MinSize float64
 	// cutils.TextAlignment aligns text to the left, right or centre.
 	TextAlignment cutils.TextAlignment
 	// Font is the typeface to use.
 	Font *truetype.Font
+	// Effects are the stroke, shadow and background drawn with the text.
+	Effects cutils.TextEffects
 	// Colour is the colour of the text.
 	Colour color.NRGBA
 	// fs is the file system.
@@ -51,15 +57,20 @@ type Component struct {
 }
 
 type datetimeFormat struct {
-	Time          string       `json:"time"`
-	TimeFormat    string       `json:"timeFormat"`
-	StartX        string       `json:"startX"`
-	StartY        string       `json:"startY"`
-	Size          string       `json:"size"`
-	MaxWidth      string       `json:"maxWidth"`
-	TextAlignment string       `json:"alignment"`
-	Font          fontFormat   `json:"font"`
-	Colour        colourFormat `json:"colour"`
+	Time          string                        `json:"time"`
+	TimeFormat    string                        `json:"timeFormat"`
+	StartX        string                        `json:"startX"`
+	StartY        string                        `json:"startY"`
+	Size          string                        `json:"size"`
+	MaxWidth      string                        `json:"maxWidth"`
+	Overflow      string                        `json:"overflow"`
+	MinSize       string                        `json:"minSize"`
+	TextAlignment string                        `json:"alignment"`
+	Font          fontFormat                    `json:"font"`
+	Colour        colourFormat                  `json:"colour"`
+	Stroke        *cutils.TextStrokeStrings     `json:"stroke"`
+	Shadow        *cutils.TextShadowStrings     `json:"shadow"`
+	Background    *cutils.TextBackgroundStrings `json:"background"`
 }
 
 type fontFormat struct {
@@ -86,22 +97,34 @@ func (component Component) Write(canvas render.Canvas) (c render.Canvas, err err
 	}()
 	fontSize := component.Size
 	formattedTime := component.Time.Format(component.TimeFormat)
+	if component.Overflow != "" && component.Overflow != cutils.OverflowScale {
+		face := render.NewFontFace(component.Font, &truetype.Options{Size: component.Size, Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: canvas.GetPPI()})
+		return cutils.WriteOverflowingText(canvas, formattedTime, component.Start, face, component.Colour, component.MaxWidth, component.TextAlignment, component.Overflow, component.Effects)
+	}
 	fits := false
 	tries := 0
 	var face font.Face
-	var alignmentOffset int
+	var alignmentOffset, realWidth int
 	for !fits && tries < 10 {
-		fmt.Printf("new fontsize: %f", fontSize)
 		tries++
-		face = truetype.NewFace(component.Font, &truetype.Options{Size: fontSize, Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: canvas.GetPPI()})
-		var realWidth int
+		face = render.NewFontFace(component.Font, &truetype.Options{Size: fontSize, Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: canvas.GetPPI()})
 		fits, realWidth = c.TryText(formattedTime, component.Start, face, component.Colour, component.MaxWidth)
+		previousSize := fontSize
 		fontSize, alignmentOffset = cutils.ScaleFontsToWidth(fontSize, realWidth, component.MaxWidth, component.TextAlignment)
+		if !fits && fontSize < component.MinSize {
+			if previousSize <= component.MinSize {
+				return canvas, fmt.Errorf("unable to fit datetime %s into maxWidth %d without going below the minimum size %v", formattedTime, component.MaxWidth, component.MinSize)
+			}
+			fontSize = component.MinSize
+		}
 	}
 	if !fits {
 		return canvas, fmt.Errorf("unable to fit datetime %s into maxWidth %d after %d tries", formattedTime, component.MaxWidth, tries)
 	}
-	c, err = c.Text(formattedTime, image.Pt(component.Start.X+alignmentOffset, component.Start.Y), face, component.Colour, component.MaxWidth)
+	start := image.Pt(component.Start.X+alignmentOffset, component.Start.Y)
+	c, err = component.Effects.Write(c, cutils.TextBounds(start, realWidth, face), func(c render.Canvas, outline bool) (render.Canvas, error) {
+		return component.Effects.Text(c, outline, formattedTime, start, face, component.Colour, component.MaxWidth)
+	})
 	if err != nil {
 		return canvas, err
 	}
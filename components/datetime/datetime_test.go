@@ -1,6 +1,7 @@
 package datetime
 
 import (
+	"bytes"
 	"fmt"
 	"image"
 	"image/color"
@@ -33,7 +34,7 @@ func TestDateTimeWrite(t *testing.T) {
 		canvas.AssertExpectations(t)
 	})
 	t.Run("datetime error", func(t *testing.T) {
-		expectedFont := truetype.NewFace(goreg, &truetype.Options{Size: 14, Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		expectedFont := render.NewFontFace(goreg, &truetype.Options{Size: 14, Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
 		canvas := new(render.MockCanvas)
 		canvas.On("GetPPI").Return(float64(72))
 		canvas.On("TryText", "", image.Point{}, expectedFont, color.NRGBA{}, 100).Return(true, 10)
@@ -46,9 +47,9 @@ func TestDateTimeWrite(t *testing.T) {
 		canvas.AssertExpectations(t)
 	})
 	t.Run("multiple passes required", func(t *testing.T) {
-		expectedFont := truetype.NewFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
-		expectedFont2 := truetype.NewFace(goreg, &truetype.Options{Size: float64(12), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
-		expectedFont3 := truetype.NewFace(goreg, &truetype.Options{Size: float64(8), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		expectedFont := render.NewFontFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		expectedFont2 := render.NewFontFace(goreg, &truetype.Options{Size: float64(12), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		expectedFont3 := render.NewFontFace(goreg, &truetype.Options{Size: float64(8), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
 		canvas := new(render.MockCanvas)
 		canvas.On("GetPPI").Return(float64(72))
 		canvas.On("TryText", "", image.Point{}, expectedFont, color.NRGBA{}, 100).Return(false, 200)
@@ -63,7 +64,7 @@ func TestDateTimeWrite(t *testing.T) {
 		canvas.AssertExpectations(t)
 	})
 	t.Run("can't ever fit", func(t *testing.T) {
-		expectedFont := truetype.NewFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		expectedFont := render.NewFontFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
 		canvas := new(render.MockCanvas)
 		canvas.On("GetPPI").Return(float64(72))
 		timeVal := time.Now()
@@ -74,8 +75,64 @@ func TestDateTimeWrite(t *testing.T) {
 		assert.EqualError(t, err, fmt.Sprintf("unable to fit datetime %s into maxWidth 100 after 10 tries", timeVal.Format(time.RFC822)))
 		canvas.AssertExpectations(t)
 	})
+	t.Run("minimum size", func(t *testing.T) {
+		expectedFont := render.NewFontFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		expectedFont2 := render.NewFontFace(goreg, &truetype.Options{Size: float64(16), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		timeVal := time.Now()
+		t.Run("reached", func(t *testing.T) {
+			canvas := new(render.MockCanvas)
+			canvas.On("GetPPI").Return(float64(72))
+			canvas.On("TryText", timeVal.Format(time.RFC822), image.Point{}, expectedFont, color.NRGBA{}, 100).Return(false, 200)
+			canvas.On("TryText", timeVal.Format(time.RFC822), image.Point{}, expectedFont2, color.NRGBA{}, 100).Return(true, 100)
+			canvas.On("Text", timeVal.Format(time.RFC822), image.Point{}, expectedFont2, color.NRGBA{}, 100).Return(canvas, nil)
+			c := Component{Font: goreg, Size: 24, MaxWidth: 100, Time: &timeVal, TimeFormat: time.RFC822, MinSize: 16}
+			modifiedCanvas, err := c.Write(canvas)
+			assert.Equal(t, canvas, modifiedCanvas)
+			assert.NoError(t, err)
+			canvas.AssertExpectations(t)
+		})
+		t.Run("too small", func(t *testing.T) {
+			canvas := new(render.MockCanvas)
+			canvas.On("GetPPI").Return(float64(72))
+			canvas.On("TryText", timeVal.Format(time.RFC822), image.Point{}, expectedFont, color.NRGBA{}, 100).Return(false, 200)
+			canvas.On("TryText", timeVal.Format(time.RFC822), image.Point{}, expectedFont2, color.NRGBA{}, 100).Return(false, 150)
+			c := Component{Font: goreg, Size: 24, MaxWidth: 100, Time: &timeVal, TimeFormat: time.RFC822, MinSize: 16}
+			modifiedCanvas, err := c.Write(canvas)
+			assert.Equal(t, canvas, modifiedCanvas)
+			assert.EqualError(t, err, fmt.Sprintf("unable to fit datetime %s into maxWidth 100 without going below the minimum size 16", timeVal.Format(time.RFC822)))
+			canvas.AssertExpectations(t)
+		})
+	})
+	t.Run("overflow", func(t *testing.T) {
+		expectedFont := render.NewFontFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		canvas := new(render.MockCanvas)
+		canvas.On("GetPPI").Return(float64(72))
+		timeVal := time.Now()
+		canvas.On("TryText", timeVal.Format(time.RFC822), image.Point{}, expectedFont, color.NRGBA{}, 100).Return(false, 200)
+		c := Component{Font: goreg, Size: 24, MaxWidth: 100, Time: &timeVal, TimeFormat: time.RFC822, Overflow: cutils.OverflowError}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, fmt.Sprintf("text %s is wider than maxWidth 100", timeVal.Format(time.RFC822)))
+		canvas.AssertExpectations(t)
+	})
+	t.Run("effects", func(t *testing.T) {
+		canvas, _ := render.NewSVGCanvas(200, 50)
+		timeVal := time.Date(2020, time.March, 4, 5, 6, 0, 0, time.UTC)
+		c := Component{Font: goreg, Size: 10, MaxWidth: 150, Start: image.Pt(5, 20), Time: &timeVal, TimeFormat: "15:04", Colour: color.NRGBA{A: 255}, Effects: cutils.TextEffects{
+			Stroke:     &cutils.TextStroke{Width: 1, Colour: color.NRGBA{R: 255, A: 255}},
+			Shadow:     &render.Shadow{Offset: image.Pt(1, 1), Colour: color.NRGBA{A: 128}},
+			Background: &cutils.TextBackground{Colour: color.NRGBA{B: 255, A: 255}, Padding: 2},
+		}}
+		modifiedCanvas, err := c.Write(canvas)
+		if !assert.NoError(t, err) {
+			return
+		}
+		var buf bytes.Buffer
+		modifiedCanvas.(render.SVGCanvas).WriteSVG(&buf)
+		assert.Regexp(t, `(?s)<rect x="2" y="7" width="\d+" height="19" fill="#0000ff"/>.*<g filter="url\(#filter1\)">\s*<path d="[^"]+" fill="none" stroke="#ff0000" stroke-width="2"[^>]*/>\s*<text x="5" y="20"[^>]*>05:06</text>\s*</g>`, buf.String())
+	})
 	t.Run("different alignments", func(t *testing.T) {
-		expectedFont := truetype.NewFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		expectedFont := render.NewFontFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
 		canvas := new(render.MockCanvas)
 		canvas.On("GetPPI").Return(float64(72))
 		canvas.On("TryText", "", image.Point{}, expectedFont, color.NRGBA{}, 100).Return(true, 50)
@@ -712,6 +769,42 @@ func TestDateTimeSetNamedProperties(t *testing.T) {
 			},
 			err: "error converting a to float64",
 		},
+		{
+			name: "effects",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"outline": {"strokeWidth"},
+					"shade":   {"shadowA"},
+				},
+				Effects: cutils.TextEffects{Stroke: &cutils.TextStroke{}, Shadow: &render.Shadow{}},
+			},
+			input: render.NamedProperties{
+				"outline": float64(2),
+				"shade":   uint8(64),
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Effects:            cutils.TextEffects{Stroke: &cutils.TextStroke{Width: 2}, Shadow: &render.Shadow{Colour: color.NRGBA{A: 64}}},
+			},
+			err: "",
+		},
+		{
+			name: "effect not set",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"backgroundPadding"},
+				},
+			},
+			input: render.NamedProperties{
+				"aProp": 4,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"backgroundPadding"},
+				},
+			},
+			err: "invalid component property in named property map: backgroundPadding",
+		},
 		{
 			name: "full prop set, multiple sources, unused props",
 			start: Component{
@@ -1768,6 +1861,44 @@ func TestDateTimeVerifyAndTestDateTimeJSONData(t *testing.T) {
 			},
 			props: render.NamedProperties{"some time": struct{ Message string }{Message: "Please replace me with real data"}},
 		},
+		{
+			name: "effects",
+			start: Component{
+				fs: ttfFS,
+			},
+			input: &datetimeFormat{
+				Font:          fontFormat{FontFile: "myFont.ttf"},
+				Time:          "$some time$",
+				TimeFormat:    time.RFC822,
+				StartX:        "1",
+				StartY:        "2",
+				MaxWidth:      "3",
+				Size:          "4",
+				TextAlignment: "left",
+				Colour:        colourFormat{Red: "0", Green: "0", Blue: "0", Alpha: "255"},
+				Shadow:        &cutils.TextShadowStrings{OffsetX: "2", OffsetY: "2", Blur: "$softness$"},
+				Background:    &cutils.TextBackgroundStrings{Colour: cutils.ColourStrings{R: "255", G: "255", B: "255", A: "255"}, Padding: "3"},
+			},
+			res: Component{
+				fs:            ttfFS,
+				Font:          func() *truetype.Font { f, _ := truetype.Parse(goregular.TTF); return f }(),
+				TimeFormat:    time.RFC822,
+				Start:         image.Pt(1, 2),
+				MaxWidth:      3,
+				Size:          4,
+				TextAlignment: cutils.TextAlignmentLeft,
+				Colour:        color.NRGBA{A: 255},
+				Effects: cutils.TextEffects{
+					Shadow:     &render.Shadow{Offset: image.Pt(2, 2), Colour: color.NRGBA{A: 128}},
+					Background: &cutils.TextBackground{Colour: color.NRGBA{R: 255, G: 255, B: 255, A: 255}, Padding: 3},
+				},
+				NamedPropertiesMap: map[string][]string{"some time": {"time"}, "softness": {"shadowBlur"}},
+			},
+			props: render.NamedProperties{
+				"some time": struct{ Message string }{Message: "Please replace me with real data"},
+				"softness":  struct{ Message string }{Message: "Please replace me with real data"},
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
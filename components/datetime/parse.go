@@ -20,10 +20,18 @@ func (component Component) parseJSONFormat(stringStruct *datetimeFormat, startTi
 	err = cutils.CombineErrors(err, parseErr)
 	c.Size, c.NamedPropertiesMap, parseErr = cutils.ExtractFloat(stringStruct.Size, "size", c.NamedPropertiesMap)
 	err = cutils.CombineErrors(err, parseErr)
+	c.Overflow, c.NamedPropertiesMap, parseErr = cutils.ExtractTextOverflow(stringStruct.Overflow, "overflow", c.NamedPropertiesMap)
+	err = cutils.CombineErrors(err, parseErr)
+	if stringStruct.MinSize != "" {
+		c.MinSize, c.NamedPropertiesMap, parseErr = cutils.ExtractFloat(stringStruct.MinSize, "minSize", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	}
 	c.TextAlignment, c.NamedPropertiesMap, parseErr = cutils.ExtractTextAlignment(stringStruct.TextAlignment, "alignment", c.NamedPropertiesMap)
 	err = cutils.CombineErrors(err, parseErr)
 	c.Colour, c.NamedPropertiesMap, parseErr = cutils.ParseColourStrings(cutils.ColourStrings{R: stringStruct.Colour.Red, G: stringStruct.Colour.Green, B: stringStruct.Colour.Blue, A: stringStruct.Colour.Alpha}, "", c.NamedPropertiesMap)
 	err = cutils.CombineErrors(err, parseErr)
+	c.Effects, c.NamedPropertiesMap, parseErr = cutils.ParseTextEffects(stringStruct.Stroke, stringStruct.Shadow, stringStruct.Background, c.NamedPropertiesMap)
+	err = cutils.CombineErrors(err, parseErr)
 
 	// Fill discovered properties with real data
 	for key := range c.NamedPropertiesMap {
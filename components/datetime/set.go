@@ -29,8 +29,16 @@ func (component *Component) delegatedSetProperties(name string, value interface{
 		err = component.setStart(name, value)
 	case "maxWidth":
 		component.MaxWidth, err = cutils.SetInt(value)
+	case "overflow":
+		component.Overflow, err = cutils.SetTextOverflow(value)
+	case "minSize":
+		component.MinSize, err = cutils.SetFloat64(value)
 	default:
-		err = fmt.Errorf("invalid component property in named property map: %v", name)
+		if cutils.IsTextEffectsProperty(name) {
+			err = cutils.SetTextEffectsProperty(&component.Effects, name, value)
+		} else {
+			err = fmt.Errorf("invalid component property in named property map: %v", name)
+		}
 	}
 	return
 }
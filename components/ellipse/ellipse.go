@@ -0,0 +1,113 @@
+// Package ellipse is a simple ellipse component with customisable colour or gradient, location and separate horizontal and vertical radii, which can also be drawn as a pie slice or chord segment.
+package ellipse
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// Component implements the Component interface for ellipses.
+type Component struct {
+	/*
+		NamedPropertiesMap maps user/application variables to properties of the component.
+		This field is filled automatically by VerifyAndSetJSONData, then used in
+		SetNamedProperties to determine whether a variable being passed in is relevant to this
+		component.
+
+		For example, map[string][]string{"ellipseSize": []string{"radiusX", "radiusY"}} would
+		indicate that the user specified variable "ellipseSize" will fill both radii.
+	*/
+	NamedPropertiesMap map[string][]string
+	/*
+		Centre is the coordinates of the centre of the ellipse relative to the top-left corner
+		of the canvas.
+	*/
+	Centre image.Point
+	// RadiusX is the horizontal radius of the ellipse.
+	RadiusX int
+	// RadiusY is the vertical radius of the ellipse.
+	RadiusY int
+	// Colour is the colour of the ellipse.
+	Colour color.NRGBA
+	/*
+		StartAngle and EndAngle limit the ellipse to an arc running clockwise between them, in
+		degrees clockwise from the positive X axis. The whole ellipse is drawn if they are equal.
+	*/
+	StartAngle float64
+	EndAngle   float64
+	// ArcStyle is the shape filled by an arc, defaulting to a pie slice.
+	ArcStyle render.ArcStyle
+	// Gradient fills the ellipse instead of Colour if it has any colour stops.
+	Gradient render.Gradient
+}
+
+type ellipseFormat struct {
+	CentreX string `json:"centreX"`
+	CentreY string `json:"centreY"`
+	RadiusX string `json:"radiusX"`
+	RadiusY string `json:"radiusY"`
+	Colour  struct {
+		Red   string `json:"R"`
+		Green string `json:"G"`
+		Blue  string `json:"B"`
+		Alpha string `json:"A"`
+	} `json:"colour"`
+	StartAngle string                  `json:"startAngle"`
+	EndAngle   string                  `json:"endAngle"`
+	ArcStyle   string                  `json:"arcStyle"`
+	Gradient   *cutils.GradientStrings `json:"gradient"`
+}
+
+// Write draws an ellipse on the canvas.
+func (component Component) Write(canvas render.Canvas) (render.Canvas, error) {
+	if len(component.NamedPropertiesMap) != 0 {
+		return canvas, fmt.Errorf("cannot draw ellipse, not all named properties are set: %v", component.NamedPropertiesMap)
+	}
+	fill := cutils.FillColour(component.Colour, component.Gradient)
+	if component.StartAngle == component.EndAngle {
+		return canvas.Ellipse(component.Centre, component.RadiusX, component.RadiusY, fill)
+	}
+	style := component.ArcStyle
+	if style == "" {
+		style = render.ArcPie
+	}
+	return canvas.Arc(component.Centre, component.RadiusX, component.RadiusY, component.StartAngle, component.EndAngle, style, fill)
+}
+
+// SetNamedProperties processes the named properties and sets them into the ellipse properties.
+func (component Component) SetNamedProperties(properties render.NamedProperties) (render.Component, error) {
+	c := component
+	var err error
+	c.NamedPropertiesMap, err = render.StandardSetNamedProperties(properties, component.NamedPropertiesMap, (&c).delegatedSetProperties)
+	if err != nil {
+		return component, err
+	}
+	return c, nil
+}
+
+// GetJSONFormat returns the JSON structure of an ellipse component.
+func (component Component) GetJSONFormat() interface{} {
+	return &ellipseFormat{}
+}
+
+// VerifyAndSetJSONData processes the data parsed from JSON and uses it to set ellipse properties and fill the named properties map.
+func (component Component) VerifyAndSetJSONData(data interface{}) (render.Component, render.NamedProperties, error) {
+	c := component
+	props := make(render.NamedProperties)
+	stringStruct, ok := data.(*ellipseFormat)
+	if !ok {
+		return component, props, fmt.Errorf("failed to convert returned data to component properties")
+	}
+	return c.parseJSONFormat(stringStruct, props)
+}
+
+func init() {
+	for _, name := range []string{"ellipse", "Ellipse", "ELLIPSE"} {
+		render.RegisterComponent(name, func(vfs.FileSystem) render.Component { return Component{} })
+	}
+}
@@ -0,0 +1,365 @@
+package ellipse
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEllipseWrite(t *testing.T) {
+	t.Run("not all props set", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		c := Component{NamedPropertiesMap: map[string][]string{"not set": {"something"}}}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "cannot draw ellipse, not all named properties are set: map[not set:[something]]")
+		canvas.AssertExpectations(t)
+	})
+	t.Run("ellipse error", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		canvas.On("Ellipse", image.Pt(0, 0), 0, 0, color.NRGBA{}).Return(canvas, fmt.Errorf("some error"))
+		c := Component{}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "some error")
+		canvas.AssertExpectations(t)
+	})
+	t.Run("ellipse", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		canvas.On("Ellipse", image.Pt(5, 6), 8, 3, color.NRGBA{R: 5, A: 255}).Return(canvas, nil)
+		c := Component{Centre: image.Pt(5, 6), RadiusX: 8, RadiusY: 3, Colour: color.NRGBA{R: 5, A: 255}}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.NoError(t, err)
+		canvas.AssertExpectations(t)
+	})
+	t.Run("arc", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		canvas.On("Arc", image.Pt(5, 6), 8, 3, 0.0, 270.0, render.ArcPie, color.NRGBA{R: 5, A: 255}).Return(canvas, nil)
+		c := Component{Centre: image.Pt(5, 6), RadiusX: 8, RadiusY: 3, Colour: color.NRGBA{R: 5, A: 255}, EndAngle: 270}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.NoError(t, err)
+		canvas.AssertExpectations(t)
+	})
+	t.Run("chord", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		canvas.On("Arc", image.Pt(5, 6), 8, 3, 180.0, 0.0, render.ArcChord, color.NRGBA{R: 5, A: 255}).Return(canvas, fmt.Errorf("some error"))
+		c := Component{Centre: image.Pt(5, 6), RadiusX: 8, RadiusY: 3, Colour: color.NRGBA{R: 5, A: 255}, StartAngle: 180, ArcStyle: render.ArcChord}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "some error")
+		canvas.AssertExpectations(t)
+	})
+	t.Run("gradient arc", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		gradient := render.Gradient{Stops: []render.GradientStop{{Offset: 0, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 1, Colour: color.NRGBA{B: 255, A: 255}}}}
+		canvas.On("Arc", image.Pt(5, 6), 8, 3, 0.0, 270.0, render.ArcPie, gradient).Return(canvas, nil)
+		c := Component{Centre: image.Pt(5, 6), RadiusX: 8, RadiusY: 3, Colour: color.NRGBA{R: 5, A: 255}, EndAngle: 270, Gradient: gradient}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.NoError(t, err)
+		canvas.AssertExpectations(t)
+	})
+	t.Run("real canvas", func(t *testing.T) {
+		canvas, _ := render.NewCanvas(20, 10)
+		c := Component{Centre: image.Pt(10, 5), RadiusX: 9, RadiusY: 4, Colour: color.NRGBA{B: 255, A: 255}}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.NoError(t, err)
+		assert.Equal(t, color.NRGBA{B: 255, A: 255}, modifiedCanvas.GetUnderlyingImage().At(3, 5))
+		assert.Equal(t, color.NRGBA{}, modifiedCanvas.GetUnderlyingImage().At(10, 0))
+	})
+}
+
+func TestEllipseSetNamedProperties(t *testing.T) {
+	type testSet struct {
+		name  string
+		start Component
+		input render.NamedProperties
+		res   Component
+		err   string
+	}
+	tests := []testSet{
+		{
+			name:  "no props",
+			start: Component{},
+			input: render.NamedProperties{},
+			res:   Component{},
+			err:   "",
+		},
+		{
+			name: "RGBA valid",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"R", "G", "B", "A"},
+				},
+			},
+			input: render.NamedProperties{
+				"aProp": uint8(1),
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Colour:             color.NRGBA{R: uint8(1), G: uint8(1), B: uint8(1), A: uint8(1)},
+			},
+			err: "",
+		},
+		{
+			name: "non-RGBA invalid name",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"not a prop"},
+				},
+			},
+			input: render.NamedProperties{
+				"aProp": 12,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"not a prop"},
+				},
+			},
+			err: "invalid component property in named property map: not a prop",
+		},
+		{
+			name: "position and size",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"left":   {"centreX"},
+					"top":    {"centreY"},
+					"wide":   {"radiusX"},
+					"narrow": {"radiusY"},
+				},
+			},
+			input: render.NamedProperties{
+				"left":   3,
+				"top":    4,
+				"wide":   30,
+				"narrow": 10,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Centre:             image.Pt(3, 4),
+				RadiusX:            30,
+				RadiusY:            10,
+			},
+			err: "",
+		},
+		{
+			name: "radius wrong type",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"wide": {"radiusX"},
+				},
+			},
+			input: render.NamedProperties{
+				"wide": "ten",
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"wide": {"radiusX"},
+				},
+			},
+			err: "error converting ten to int",
+		},
+		{
+			name: "arc",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"from":  {"startAngle"},
+					"to":    {"endAngle"},
+					"shape": {"arcStyle"},
+				},
+			},
+			input: render.NamedProperties{
+				"from":  45.0,
+				"to":    135.0,
+				"shape": "pie",
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				StartAngle:         45,
+				EndAngle:           135,
+				ArcStyle:           render.ArcPie,
+			},
+			err: "",
+		},
+		{
+			name: "invalid arc style",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"shape": {"arcStyle"},
+				},
+			},
+			input: render.NamedProperties{
+				"shape": "slice",
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"shape": {"arcStyle"},
+				},
+			},
+			err: "arc style slice does not match defined constants",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := test.start.SetNamedProperties(test.input)
+			assert.Equal(t, test.res, res)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestEllipseGetJSONFormat(t *testing.T) {
+	c := Component{}
+	expectedFormat := &ellipseFormat{}
+	format := c.GetJSONFormat()
+	assert.Equal(t, expectedFormat, format)
+}
+
+type colour = struct {
+	Red   string `json:"R"`
+	Green string `json:"G"`
+	Blue  string `json:"B"`
+	Alpha string `json:"A"`
+}
+
+func TestEllipseVerifyAndSetJSONData(t *testing.T) {
+	type testSet struct {
+		name  string
+		start Component
+		input interface{}
+		res   Component
+		props render.NamedProperties
+		err   string
+	}
+	black := colour{Red: "0", Green: "0", Blue: "0", Alpha: "255"}
+	tests := []testSet{
+		{
+			name:  "incorrect format data",
+			input: "hello",
+			props: render.NamedProperties{},
+			err:   "failed to convert returned data to component properties",
+		},
+		{
+			name:  "invalid centre",
+			input: &ellipseFormat{CentreX: "a", CentreY: "5", RadiusX: "8", RadiusY: "4", Colour: black},
+			props: render.NamedProperties{},
+			err:   `failed to convert property centreX to integer: strconv.ParseInt: parsing "a": invalid syntax`,
+		},
+		{
+			name:  "missing radius",
+			input: &ellipseFormat{CentreX: "10", CentreY: "5", RadiusX: "8", Colour: black},
+			props: render.NamedProperties{},
+			err:   "error parsing data for property radiusY: could not parse empty property",
+		},
+		{
+			name:  "arc missing start",
+			input: &ellipseFormat{CentreX: "10", CentreY: "5", RadiusX: "8", RadiusY: "4", Colour: black, EndAngle: "90"},
+			props: render.NamedProperties{},
+			err:   "error parsing data for property startAngle: could not parse empty property",
+		},
+		{
+			name:  "invalid arc style",
+			input: &ellipseFormat{CentreX: "10", CentreY: "5", RadiusX: "8", RadiusY: "4", Colour: black, ArcStyle: "slice"},
+			props: render.NamedProperties{},
+			err:   "arc style slice does not match defined constants",
+		},
+		{
+			name:  "minimal",
+			input: &ellipseFormat{CentreX: "10", CentreY: "5", RadiusX: "8", RadiusY: "4", Colour: black},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Centre:             image.Pt(10, 5),
+				RadiusX:            8,
+				RadiusY:            4,
+				Colour:             color.NRGBA{A: 255},
+			},
+			props: render.NamedProperties{},
+		},
+		{
+			name:  "gradient and colour",
+			input: &ellipseFormat{CentreX: "10", CentreY: "5", RadiusX: "8", RadiusY: "4", Colour: black, Gradient: &cutils.GradientStrings{}},
+			props: render.NamedProperties{},
+			err:   "an ellipse takes either a colour or a gradient, not both",
+		},
+		{
+			name:  "invalid gradient",
+			input: &ellipseFormat{CentreX: "10", CentreY: "5", RadiusX: "8", RadiusY: "4", Gradient: &cutils.GradientStrings{Stops: make([]cutils.GradientStopStrings, 1)}},
+			props: render.NamedProperties{},
+			err:   "a gradient needs at least 2 colour stops, got 1",
+		},
+		{
+			name:  "gradient",
+			input: &ellipseFormat{CentreX: "10", CentreY: "5", RadiusX: "8", RadiusY: "4", Gradient: &cutils.GradientStrings{Type: "radial", Stops: []cutils.GradientStopStrings{{Colour: cutils.ColourStrings{R: "255", G: "0", B: "0", A: "255"}}, {Colour: cutils.ColourStrings{R: "0", G: "0", B: "0", A: "0"}}}}},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Centre:             image.Pt(10, 5),
+				RadiusX:            8,
+				RadiusY:            4,
+				Gradient:           render.Gradient{Type: render.GradientRadial, Stops: []render.GradientStop{{Offset: 0, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 1}}},
+			},
+			props: render.NamedProperties{},
+		},
+		{
+			name: "valid everything",
+			input: &ellipseFormat{
+				CentreX:    "10",
+				CentreY:    "$top$",
+				RadiusX:    "8",
+				RadiusY:    "$height$",
+				Colour:     colour{Red: "192", Green: "1", Blue: "$blue$", Alpha: "201"},
+				StartAngle: "-90",
+				EndAngle:   "$end$",
+				ArcStyle:   "$shape$",
+			},
+			res: Component{
+				Centre:     image.Pt(10, 0),
+				RadiusX:    8,
+				Colour:     color.NRGBA{R: 192, G: 1, A: 201},
+				StartAngle: -90,
+				NamedPropertiesMap: map[string][]string{
+					"top":    {"centreY"},
+					"height": {"radiusY"},
+					"blue":   {"B"},
+					"end":    {"endAngle"},
+					"shape":  {"arcStyle"},
+				},
+			},
+			props: render.NamedProperties{
+				"top":    struct{ Message string }{Message: "Please replace me with real data"},
+				"height": struct{ Message string }{Message: "Please replace me with real data"},
+				"blue":   struct{ Message string }{Message: "Please replace me with real data"},
+				"end":    struct{ Message string }{Message: "Please replace me with real data"},
+				"shape":  struct{ Message string }{Message: "Please replace me with real data"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, props, err := test.start.VerifyAndSetJSONData(test.input)
+			assert.Equal(t, test.res, res)
+			assert.Equal(t, test.props, props)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestInit(t *testing.T) {
+	c, err := render.Decode("ellipse")
+	assert.NoError(t, err)
+	assert.Equal(t, Component{}, c)
+}
@@ -0,0 +1,59 @@
+package ellipse
+
+import (
+	"fmt"
+
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+)
+
+func (component Component) parseJSONFormat(stringStruct *ellipseFormat, props render.NamedProperties) (c Component, foundProps render.NamedProperties, err error) {
+	c = component
+	var parseErr error
+	// Get named properties and assign each real property
+	c.Centre, c.NamedPropertiesMap, parseErr = cutils.ParsePoint(stringStruct.CentreX, stringStruct.CentreY, "centreX", "centreY", c.NamedPropertiesMap)
+	err = cutils.CombineErrors(err, parseErr)
+	c.RadiusX, c.NamedPropertiesMap, parseErr = cutils.ExtractInt(stringStruct.RadiusX, "radiusX", c.NamedPropertiesMap)
+	err = cutils.CombineErrors(err, parseErr)
+	c.RadiusY, c.NamedPropertiesMap, parseErr = cutils.ExtractInt(stringStruct.RadiusY, "radiusY", c.NamedPropertiesMap)
+	err = cutils.CombineErrors(err, parseErr)
+	colour := cutils.ColourStrings{R: stringStruct.Colour.Red, G: stringStruct.Colour.Green, B: stringStruct.Colour.Blue, A: stringStruct.Colour.Alpha}
+	if stringStruct.Gradient == nil {
+		c.Colour, c.NamedPropertiesMap, parseErr = cutils.ParseColourStrings(colour, "", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	} else if colour != (cutils.ColourStrings{}) {
+		err = cutils.CombineErrors(err, fmt.Errorf("an ellipse takes either a colour or a gradient, not both"))
+	} else {
+		c.Gradient, c.NamedPropertiesMap, parseErr = cutils.ParseGradient(*stringStruct.Gradient, "gradient", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	}
+	// The arc is optional, but needs both angles if either is given
+	if stringStruct.StartAngle != "" || stringStruct.EndAngle != "" {
+		c.StartAngle, c.NamedPropertiesMap, parseErr = cutils.ExtractFloat(stringStruct.StartAngle, "startAngle", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+		c.EndAngle, c.NamedPropertiesMap, parseErr = cutils.ExtractFloat(stringStruct.EndAngle, "endAngle", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	}
+	if stringStruct.ArcStyle != "" {
+		var styleName string
+		styleName, c.NamedPropertiesMap, parseErr = cutils.ExtractString(stringStruct.ArcStyle, "arcStyle", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+		if styleName != "" {
+			c.ArcStyle, parseErr = render.ToArcStyle(styleName)
+			err = cutils.CombineErrors(err, parseErr)
+		}
+	}
+
+	// Fill discovered properties with real data
+	for key := range c.NamedPropertiesMap {
+		props[key] = struct {
+			Message string
+		}{Message: "Please replace me with real data"}
+	}
+
+	// Return original component on error
+	if err != nil {
+		c = component
+	}
+	return c, props, err
+}
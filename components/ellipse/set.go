@@ -0,0 +1,41 @@
+package ellipse
+
+import (
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+)
+
+func (component *Component) delegatedSetProperties(name string, value interface{}) (err error) {
+	switch name {
+	case "R":
+		component.Colour.R, err = cutils.SetUint8(value)
+	case "G":
+		component.Colour.G, err = cutils.SetUint8(value)
+	case "B":
+		component.Colour.B, err = cutils.SetUint8(value)
+	case "A":
+		component.Colour.A, err = cutils.SetUint8(value)
+	case "centreX":
+		component.Centre.X, err = cutils.SetInt(value)
+	case "centreY":
+		component.Centre.Y, err = cutils.SetInt(value)
+	case "radiusX":
+		component.RadiusX, err = cutils.SetInt(value)
+	case "radiusY":
+		component.RadiusY, err = cutils.SetInt(value)
+	case "startAngle":
+		component.StartAngle, err = cutils.SetFloat64(value)
+	case "endAngle":
+		component.EndAngle, err = cutils.SetFloat64(value)
+	case "arcStyle":
+		var styleName string
+		styleName, err = cutils.SetString(value)
+		if err == nil {
+			component.ArcStyle, err = render.ToArcStyle(styleName)
+		}
+	default:
+		// Anything else is either part of the gradient or invalid
+		err = cutils.SetGradientProperty(&component.Gradient, "gradient", name, value)
+	}
+	return
+}
@@ -1,9 +1,10 @@
-// Package image is an embedded image component with support for jpg, png, bmp and tiff files.
+// Package image is an embedded image component with support for jpg, png, bmp, tiff, gif and webp files. JPEG photos are rotated upright according to their EXIF orientation.
 package image
 
 import (
 	"fmt"
 	"image"
+	_ "image/gif"  // gif imported for image decoding
 	_ "image/jpeg" // jpeg imported for image decoding
 	_ "image/png"  // png imported for image decoding
 
@@ -11,6 +12,7 @@ import (
 	"github.com/disintegration/imaging"
 	_ "golang.org/x/image/bmp"  // bmp imported for image decoding
 	_ "golang.org/x/image/tiff" // tiff imported for image decoding
+	_ "golang.org/x/image/webp" // webp imported for image decoding
 	"golang.org/x/tools/godoc/vfs"
 )
 
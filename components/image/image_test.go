@@ -6,10 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"image/color"
+	"image/gif"
 	"runtime/debug"
 	"testing"
 
 	fs "github.com/LLKennedy/imagetemplate/v3/internal/filesystem"
+	"github.com/LLKennedy/imagetemplate/v3/internal/testimage"
 	"github.com/LLKennedy/imagetemplate/v3/render"
 	"github.com/stretchr/testify/assert"
 	_ "golang.org/x/image/bmp" // bmp imported for image decoding
@@ -557,3 +560,62 @@ func TestInit(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, Component{fs: vfs.OS(".")}, c)
 }
+
+func TestImageDecodeFormats(t *testing.T) {
+	isRed := func(c color.Color) bool {
+		r, g, b, _ := c.RGBA()
+		return r > 0xc000 && g < 0x4000 && b < 0x4000
+	}
+	rotated := testimage.OrientedJPEG(6)
+	webpData, err := base64.StdEncoding.DecodeString("UklGRiIAAABXRUJQVlA4IBYAAAAwAQCdASoBAAEADsD+JaQAA3AAAAAA")
+	assert.NoError(t, err)
+	var gifData bytes.Buffer
+	assert.NoError(t, gif.Encode(&gifData, image.NewPaletted(image.Rect(0, 0, 3, 2), color.Palette{color.Black}), nil))
+	checkRotated := func(t *testing.T, img image.Image) {
+		if assert.NotNil(t, img) {
+			assert.Equal(t, image.Rect(0, 0, 2, 4), img.Bounds())
+			assert.True(t, isRed(img.At(1, 0)))
+			assert.False(t, isRed(img.At(1, 3)))
+		}
+	}
+	t.Run("set data bytes", func(t *testing.T) {
+		c := Component{}
+		assert.NoError(t, c.setData(rotated))
+		checkRotated(t, c.Image)
+	})
+	t.Run("set data reader", func(t *testing.T) {
+		c := Component{}
+		assert.NoError(t, c.setData(bytes.NewReader(webpData)))
+		if assert.NotNil(t, c.Image) {
+			assert.Equal(t, image.Rect(0, 0, 1, 1), c.Image.Bounds())
+		}
+	})
+	t.Run("set data string", func(t *testing.T) {
+		c := Component{}
+		assert.NoError(t, c.setData(base64.StdEncoding.EncodeToString(gifData.Bytes())))
+		if assert.NotNil(t, c.Image) {
+			assert.Equal(t, image.Rect(0, 0, 3, 2), c.Image.Bounds())
+		}
+	})
+	t.Run("set file name", func(t *testing.T) {
+		mfs := fs.NewMockFileSystem()
+		mfs.On("Open", "phone.jpg").Return(fs.NewMockFile("", rotated), nil)
+		c := Component{fs: mfs}
+		assert.NoError(t, c.setFileName("phone.jpg"))
+		checkRotated(t, c.Image)
+	})
+	t.Run("parse data", func(t *testing.T) {
+		c, err := Component{}.parseImageFile("", base64.StdEncoding.EncodeToString(rotated), render.NamedProperties{})
+		assert.NoError(t, err)
+		checkRotated(t, c.Image)
+	})
+	t.Run("parse file", func(t *testing.T) {
+		mfs := fs.NewMockFileSystem()
+		mfs.On("Open", "avatar.webp").Return(fs.NewMockFile("", webpData), nil)
+		c, err := Component{fs: mfs}.parseImageFile("avatar.webp", "", render.NamedProperties{})
+		assert.NoError(t, err)
+		if assert.NotNil(t, c.Image) {
+			assert.Equal(t, image.Rect(0, 0, 1, 1), c.Image.Bounds())
+		}
+	})
+}
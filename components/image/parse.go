@@ -2,11 +2,11 @@ package image
 
 import (
 	"encoding/base64"
-	"image"
 	"strings"
 
 	"github.com/LLKennedy/imagetemplate/v3/cutils"
 	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/disintegration/imaging"
 	"golang.org/x/tools/godoc/vfs"
 )
 
@@ -70,7 +70,7 @@ func (component Component) parseImageFile(filename, data string, props render.Na
 				return component, err
 			}
 			defer bytesVal.Close()
-			img, _, err := image.Decode(bytesVal)
+			img, err := imaging.Decode(bytesVal, imaging.AutoOrientation(true))
 			if err != nil {
 				return component, err
 			}
@@ -78,7 +78,7 @@ func (component Component) parseImageFile(filename, data string, props render.Na
 		case 1:
 			base64Val := extractedVal.(string)
 			r := base64.NewDecoder(base64.StdEncoding, strings.NewReader(base64Val))
-			img, _, err := image.Decode(r)
+			img, err := imaging.Decode(r, imaging.AutoOrientation(true))
 			if err != nil {
 				return component, err
 			}
@@ -4,11 +4,11 @@ import (
 	"bytes"
 	"encoding/base64"
 	"fmt"
-	"image"
 	"io"
 	"strings"
 
 	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/disintegration/imaging"
 )
 
 func (component *Component) delegatedSetProperties(name string, value interface{}) (err error) {
@@ -47,7 +47,7 @@ func (component *Component) setData(value interface{}) error {
 	} else if isReader {
 		reader = readerVal
 	}
-	img, _, err := image.Decode(reader)
+	img, err := imaging.Decode(reader, imaging.AutoOrientation(true))
 	if err != nil {
 		return err
 	}
@@ -65,7 +65,7 @@ func (component *Component) setFileName(value interface{}) error {
 		return err
 	}
 	defer bytesVal.Close()
-	img, _, err := image.Decode(bytesVal)
+	img, err := imaging.Decode(bytesVal, imaging.AutoOrientation(true))
 	if err != nil {
 		return err
 	}
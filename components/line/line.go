@@ -0,0 +1,100 @@
+// Package line is a simple line component with customisable points, width, colour, caps, joins and dash pattern.
+package line
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// Component implements the Component interface for lines.
+type Component struct {
+	/*
+		NamedPropertiesMap maps user/application variables to properties of the component.
+		This field is filled automatically by VerifyAndSetJSONData, then used in
+		SetNamedProperties to determine whether a variable being passed in is relevant to this
+		component.
+
+		For example, map[string][]string{"lineEnd": []string{"pointX1", "pointY1"}} would
+		indicate that the user specified variable "lineEnd" will fill both coordinates of the
+		second point.
+	*/
+	NamedPropertiesMap map[string][]string
+	// Points are the coordinates the line passes through in turn, relative to the top-left corner of the canvas.
+	Points []image.Point
+	// Width is the width of the line in pixels.
+	Width float64
+	// Colour is the colour of the line.
+	Colour color.NRGBA
+	// Cap is the shape of the ends of the line.
+	Cap render.LineCap
+	// Join is the shape of the corners of the line.
+	Join render.LineJoin
+	// Dash is the repeating pattern of dash and gap lengths, or empty for a solid line.
+	Dash []float64
+}
+
+type lineFormat struct {
+	Points []struct {
+		X string `json:"x"`
+		Y string `json:"y"`
+	} `json:"points"`
+	Width  string `json:"width"`
+	Colour struct {
+		Red   string `json:"R"`
+		Green string `json:"G"`
+		Blue  string `json:"B"`
+		Alpha string `json:"A"`
+	} `json:"colour"`
+	Cap  string   `json:"cap"`
+	Join string   `json:"join"`
+	Dash []string `json:"dash"`
+}
+
+// Write draws a line on the canvas.
+func (component Component) Write(canvas render.Canvas) (render.Canvas, error) {
+	if len(component.NamedPropertiesMap) != 0 {
+		return canvas, fmt.Errorf("cannot draw line, not all named properties are set: %v", component.NamedPropertiesMap)
+	}
+	style := render.StrokeStyle{Width: component.Width, Cap: component.Cap, Join: component.Join, Dash: component.Dash}
+	return canvas.Polyline(component.Points, style, component.Colour)
+}
+
+// SetNamedProperties processes the named properties and sets them into the line properties.
+func (component Component) SetNamedProperties(properties render.NamedProperties) (render.Component, error) {
+	c := component
+	// The points and dash pattern are set by index, so they must not be shared with the original component.
+	c.Points = append([]image.Point(nil), component.Points...)
+	c.Dash = append([]float64(nil), component.Dash...)
+	var err error
+	c.NamedPropertiesMap, err = render.StandardSetNamedProperties(properties, component.NamedPropertiesMap, (&c).delegatedSetProperties)
+	if err != nil {
+		return component, err
+	}
+	return c, nil
+}
+
+// GetJSONFormat returns the JSON structure of a line component.
+func (component Component) GetJSONFormat() interface{} {
+	return &lineFormat{}
+}
+
+// VerifyAndSetJSONData processes the data parsed from JSON and uses it to set line properties and fill the named properties map.
+func (component Component) VerifyAndSetJSONData(data interface{}) (render.Component, render.NamedProperties, error) {
+	c := component
+	props := make(render.NamedProperties)
+	stringStruct, ok := data.(*lineFormat)
+	if !ok {
+		return component, props, fmt.Errorf("failed to convert returned data to component properties")
+	}
+	return c.parseJSONFormat(stringStruct, props)
+}
+
+func init() {
+	for _, name := range []string{"line", "Line", "LINE"} {
+		render.RegisterComponent(name, func(vfs.FileSystem) render.Component { return Component{} })
+	}
+}
@@ -0,0 +1,393 @@
+package line
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineWrite(t *testing.T) {
+	t.Run("not all props set", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		c := Component{NamedPropertiesMap: map[string][]string{"not set": {"something"}}}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "cannot draw line, not all named properties are set: map[not set:[something]]")
+		canvas.AssertExpectations(t)
+	})
+	t.Run("line error", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		canvas.On("Polyline", []image.Point(nil), render.StrokeStyle{}, color.NRGBA{}).Return(canvas, fmt.Errorf("some error"))
+		c := Component{}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "some error")
+		canvas.AssertExpectations(t)
+	})
+	t.Run("passing", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		points := []image.Point{{X: 1, Y: 2}, {X: 30, Y: 40}}
+		style := render.StrokeStyle{Width: 2.5, Cap: render.CapRound, Join: render.JoinBevel, Dash: []float64{4, 2}}
+		canvas.On("Polyline", points, style, color.NRGBA{R: 5, A: 255}).Return(canvas, nil)
+		c := Component{Points: points, Width: 2.5, Colour: color.NRGBA{R: 5, A: 255}, Cap: render.CapRound, Join: render.JoinBevel, Dash: []float64{4, 2}}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.NoError(t, err)
+		canvas.AssertExpectations(t)
+	})
+	t.Run("real canvas", func(t *testing.T) {
+		canvas, _ := render.NewCanvas(10, 10)
+		c := Component{Points: []image.Point{{X: 1, Y: 5}, {X: 8, Y: 5}}, Width: 1, Colour: color.NRGBA{B: 255, A: 255}}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.NoError(t, err)
+		assert.Equal(t, color.NRGBA{B: 255, A: 255}, modifiedCanvas.GetUnderlyingImage().At(4, 5))
+	})
+}
+
+func TestLineSetNamedProperties(t *testing.T) {
+	type testSet struct {
+		name  string
+		start Component
+		input render.NamedProperties
+		res   Component
+		err   string
+	}
+	tests := []testSet{
+		{
+			name:  "no props",
+			start: Component{},
+			input: render.NamedProperties{},
+			res:   Component{},
+			err:   "",
+		},
+		{
+			name: "RGBA valid",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"R", "G", "B", "A"},
+				},
+			},
+			input: render.NamedProperties{
+				"aProp": uint8(1),
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Colour:             color.NRGBA{R: uint8(1), G: uint8(1), B: uint8(1), A: uint8(1)},
+			},
+			err: "",
+		},
+		{
+			name: "non-RGBA invalid name",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"not a prop"},
+				},
+			},
+			input: render.NamedProperties{
+				"aProp": 12,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"not a prop"},
+				},
+			},
+			err: "invalid component property in named property map: not a prop",
+		},
+		{
+			name: "points",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"startX": {"pointX0"},
+					"endY":   {"pointY1"},
+				},
+				Points: []image.Point{{X: 1, Y: 2}, {X: 3, Y: 4}},
+			},
+			input: render.NamedProperties{
+				"startX": 10,
+				"endY":   40,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Points:             []image.Point{{X: 10, Y: 2}, {X: 3, Y: 40}},
+			},
+			err: "",
+		},
+		{
+			name: "point out of range",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"pointX2"},
+				},
+				Points: []image.Point{{X: 1, Y: 2}, {X: 3, Y: 4}},
+			},
+			input: render.NamedProperties{
+				"aProp": 10,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"pointX2"},
+				},
+				Points: []image.Point{{X: 1, Y: 2}, {X: 3, Y: 4}},
+			},
+			err: "invalid component property in named property map: pointX2",
+		},
+		{
+			name: "point wrong type",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"pointY0"},
+				},
+				Points: []image.Point{{X: 1, Y: 2}, {X: 3, Y: 4}},
+			},
+			input: render.NamedProperties{
+				"aProp": "ten",
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"pointY0"},
+				},
+				Points: []image.Point{{X: 1, Y: 2}, {X: 3, Y: 4}},
+			},
+			err: "error converting ten to int",
+		},
+		{
+			name: "style",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"thickness": {"width"},
+					"ends":      {"cap"},
+					"corners":   {"join"},
+					"gap":       {"dash1"},
+				},
+				Dash: []float64{5, 0},
+			},
+			input: render.NamedProperties{
+				"thickness": 3.5,
+				"ends":      "square",
+				"corners":   "round",
+				"gap":       2.0,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Width:              3.5,
+				Cap:                render.CapSquare,
+				Join:               render.JoinRound,
+				Dash:               []float64{5, 2},
+			},
+			err: "",
+		},
+		{
+			name: "invalid cap",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"ends": {"cap"},
+				},
+			},
+			input: render.NamedProperties{
+				"ends": "pointy",
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"ends": {"cap"},
+				},
+			},
+			err: "line cap pointy does not match defined constants",
+		},
+		{
+			name: "invalid join",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"corners": {"join"},
+				},
+			},
+			input: render.NamedProperties{
+				"corners": "pointy",
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"corners": {"join"},
+				},
+			},
+			err: "line join pointy does not match defined constants",
+		},
+		{
+			name: "invalid dash index",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"gap": {"dashes"},
+				},
+				Dash: []float64{5, 0},
+			},
+			input: render.NamedProperties{
+				"gap": 2.0,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"gap": {"dashes"},
+				},
+				Dash: []float64{5, 0},
+			},
+			err: "invalid component property in named property map: dashes",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := test.start.SetNamedProperties(test.input)
+			assert.Equal(t, test.res, res)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+	t.Run("original unchanged", func(t *testing.T) {
+		start := Component{
+			NamedPropertiesMap: map[string][]string{"startX": {"pointX0"}},
+			Points:             []image.Point{{X: 1, Y: 2}, {X: 3, Y: 4}},
+		}
+		_, err := start.SetNamedProperties(render.NamedProperties{"startX": 10})
+		assert.NoError(t, err)
+		assert.Equal(t, []image.Point{{X: 1, Y: 2}, {X: 3, Y: 4}}, start.Points)
+	})
+}
+
+func TestLineGetJSONFormat(t *testing.T) {
+	c := Component{}
+	expectedFormat := &lineFormat{}
+	format := c.GetJSONFormat()
+	assert.Equal(t, expectedFormat, format)
+}
+
+type point = struct {
+	X string `json:"x"`
+	Y string `json:"y"`
+}
+
+type colour = struct {
+	Red   string `json:"R"`
+	Green string `json:"G"`
+	Blue  string `json:"B"`
+	Alpha string `json:"A"`
+}
+
+func TestLineVerifyAndSetJSONData(t *testing.T) {
+	type testSet struct {
+		name  string
+		start Component
+		input interface{}
+		res   Component
+		props render.NamedProperties
+		err   string
+	}
+	black := colour{Red: "0", Green: "0", Blue: "0", Alpha: "255"}
+	tests := []testSet{
+		{
+			name:  "incorrect format data",
+			input: "hello",
+			props: render.NamedProperties{},
+			err:   "failed to convert returned data to component properties",
+		},
+		{
+			name:  "too few points",
+			input: &lineFormat{Points: []point{{X: "1", Y: "2"}}, Width: "1", Colour: black},
+			props: render.NamedProperties{},
+			err:   "a line needs at least 2 points, got 1",
+		},
+		{
+			name:  "invalid point",
+			input: &lineFormat{Points: []point{{X: "1", Y: "2"}, {X: "three", Y: "4"}}, Width: "1", Colour: black},
+			props: render.NamedProperties{},
+			err:   "failed to convert property pointX1 to integer: strconv.ParseInt: parsing \"three\": invalid syntax",
+		},
+		{
+			name:  "missing width",
+			input: &lineFormat{Points: []point{{X: "1", Y: "2"}, {X: "3", Y: "4"}}, Colour: black},
+			props: render.NamedProperties{},
+			err:   "error parsing data for property width: could not parse empty property",
+		},
+		{
+			name:  "invalid cap",
+			input: &lineFormat{Points: []point{{X: "1", Y: "2"}, {X: "3", Y: "4"}}, Width: "1", Colour: black, Cap: "pointy"},
+			props: render.NamedProperties{},
+			err:   "line cap pointy does not match defined constants",
+		},
+		{
+			name:  "invalid join",
+			input: &lineFormat{Points: []point{{X: "1", Y: "2"}, {X: "3", Y: "4"}}, Width: "1", Colour: black, Join: "pointy"},
+			props: render.NamedProperties{},
+			err:   "line join pointy does not match defined constants",
+		},
+		{
+			name:  "invalid dash",
+			input: &lineFormat{Points: []point{{X: "1", Y: "2"}, {X: "3", Y: "4"}}, Width: "1", Colour: black, Dash: []string{"4", "wide"}},
+			props: render.NamedProperties{},
+			err:   "failed to convert property dash1 to float64: strconv.ParseFloat: parsing \"wide\": invalid syntax",
+		},
+		{
+			name:  "minimal",
+			input: &lineFormat{Points: []point{{X: "1", Y: "2"}, {X: "3", Y: "4"}}, Width: "1.5", Colour: black},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Points:             []image.Point{{X: 1, Y: 2}, {X: 3, Y: 4}},
+				Width:              1.5,
+				Colour:             color.NRGBA{A: 255},
+			},
+			props: render.NamedProperties{},
+		},
+		{
+			name: "valid everything",
+			input: &lineFormat{
+				Points: []point{{X: "1", Y: "2"}, {X: "$endX$", Y: "4"}, {X: "5", Y: "$endY$"}},
+				Width:  "2",
+				Colour: colour{Red: "192", Green: "1", Blue: "$blue$", Alpha: "201"},
+				Cap:    "round",
+				Join:   "$join$",
+				Dash:   []string{"6", "$gap$"},
+			},
+			res: Component{
+				Points: []image.Point{{X: 1, Y: 2}, {X: 0, Y: 4}, {X: 5, Y: 0}},
+				Width:  2,
+				Colour: color.NRGBA{R: 192, G: 1, A: 201},
+				Cap:    render.CapRound,
+				Dash:   []float64{6, 0},
+				NamedPropertiesMap: map[string][]string{
+					"endX": {"pointX1"},
+					"endY": {"pointY2"},
+					"blue": {"B"},
+					"join": {"join"},
+					"gap":  {"dash1"},
+				},
+			},
+			props: render.NamedProperties{
+				"endX": struct{ Message string }{Message: "Please replace me with real data"},
+				"endY": struct{ Message string }{Message: "Please replace me with real data"},
+				"blue": struct{ Message string }{Message: "Please replace me with real data"},
+				"join": struct{ Message string }{Message: "Please replace me with real data"},
+				"gap":  struct{ Message string }{Message: "Please replace me with real data"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, props, err := test.start.VerifyAndSetJSONData(test.input)
+			assert.Equal(t, test.res, res)
+			assert.Equal(t, test.props, props)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestInit(t *testing.T) {
+	c, err := render.Decode("line")
+	assert.NoError(t, err)
+	assert.Equal(t, Component{}, c)
+}
@@ -0,0 +1,66 @@
+package line
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+)
+
+func (component Component) parseJSONFormat(stringStruct *lineFormat, props render.NamedProperties) (c Component, foundProps render.NamedProperties, err error) {
+	c = component
+	var parseErr error
+	// Get named properties and assign each real property
+	if len(stringStruct.Points) < 2 {
+		err = cutils.CombineErrors(err, fmt.Errorf("a line needs at least 2 points, got %d", len(stringStruct.Points)))
+	}
+	c.Points = make([]image.Point, len(stringStruct.Points))
+	for i, point := range stringStruct.Points {
+		c.Points[i], c.NamedPropertiesMap, parseErr = cutils.ParsePoint(point.X, point.Y, fmt.Sprintf("pointX%d", i), fmt.Sprintf("pointY%d", i), c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	}
+	c.Width, c.NamedPropertiesMap, parseErr = cutils.ExtractFloat(stringStruct.Width, "width", c.NamedPropertiesMap)
+	err = cutils.CombineErrors(err, parseErr)
+	c.Colour, c.NamedPropertiesMap, parseErr = cutils.ParseColourStrings(cutils.ColourStrings{R: stringStruct.Colour.Red, G: stringStruct.Colour.Green, B: stringStruct.Colour.Blue, A: stringStruct.Colour.Alpha}, "", c.NamedPropertiesMap)
+	err = cutils.CombineErrors(err, parseErr)
+	// Cap, join and dash pattern are optional
+	if stringStruct.Cap != "" {
+		var capName string
+		capName, c.NamedPropertiesMap, parseErr = cutils.ExtractString(stringStruct.Cap, "cap", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+		if capName != "" {
+			c.Cap, parseErr = render.ToLineCap(capName)
+			err = cutils.CombineErrors(err, parseErr)
+		}
+	}
+	if stringStruct.Join != "" {
+		var joinName string
+		joinName, c.NamedPropertiesMap, parseErr = cutils.ExtractString(stringStruct.Join, "join", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+		if joinName != "" {
+			c.Join, parseErr = render.ToLineJoin(joinName)
+			err = cutils.CombineErrors(err, parseErr)
+		}
+	}
+	if len(stringStruct.Dash) > 0 {
+		c.Dash = make([]float64, len(stringStruct.Dash))
+		for i, length := range stringStruct.Dash {
+			c.Dash[i], c.NamedPropertiesMap, parseErr = cutils.ExtractFloat(length, fmt.Sprintf("dash%d", i), c.NamedPropertiesMap)
+			err = cutils.CombineErrors(err, parseErr)
+		}
+	}
+
+	// Fill discovered properties with real data
+	for key := range c.NamedPropertiesMap {
+		props[key] = struct {
+			Message string
+		}{Message: "Please replace me with real data"}
+	}
+
+	// Return original component on error
+	if err != nil {
+		c = component
+	}
+	return c, props, err
+}
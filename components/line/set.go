@@ -0,0 +1,67 @@
+package line
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+)
+
+func (component *Component) delegatedSetProperties(name string, value interface{}) (err error) {
+	switch {
+	case name == "R":
+		component.Colour.R, err = cutils.SetUint8(value)
+	case name == "G":
+		component.Colour.G, err = cutils.SetUint8(value)
+	case name == "B":
+		component.Colour.B, err = cutils.SetUint8(value)
+	case name == "A":
+		component.Colour.A, err = cutils.SetUint8(value)
+	case name == "width":
+		component.Width, err = cutils.SetFloat64(value)
+	case name == "cap":
+		var capName string
+		capName, err = cutils.SetString(value)
+		if err == nil {
+			component.Cap, err = render.ToLineCap(capName)
+		}
+	case name == "join":
+		var joinName string
+		joinName, err = cutils.SetString(value)
+		if err == nil {
+			component.Join, err = render.ToLineJoin(joinName)
+		}
+	case strings.HasPrefix(name, "pointX"):
+		var i int
+		i, err = indexedProperty(name, "pointX", len(component.Points))
+		if err == nil {
+			component.Points[i].X, err = cutils.SetInt(value)
+		}
+	case strings.HasPrefix(name, "pointY"):
+		var i int
+		i, err = indexedProperty(name, "pointY", len(component.Points))
+		if err == nil {
+			component.Points[i].Y, err = cutils.SetInt(value)
+		}
+	case strings.HasPrefix(name, "dash"):
+		var i int
+		i, err = indexedProperty(name, "dash", len(component.Dash))
+		if err == nil {
+			component.Dash[i], err = cutils.SetFloat64(value)
+		}
+	default:
+		err = fmt.Errorf("invalid component property in named property map: %v", name)
+	}
+	return
+}
+
+// indexedProperty finds the index of a property referring to an element of a list, such as pointX2 for the third point.
+func indexedProperty(name, prefix string, length int) (int, error) {
+	i, err := strconv.Atoi(strings.TrimPrefix(name, prefix))
+	if err != nil || i < 0 || i >= length {
+		return 0, fmt.Errorf("invalid component property in named property map: %v", name)
+	}
+	return i, nil
+}
@@ -0,0 +1,69 @@
+package path
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+)
+
+func (component Component) parseJSONFormat(stringStruct *pathFormat, props render.NamedProperties) (c Component, foundProps render.NamedProperties, err error) {
+	c = component
+	var parseErr error
+	// Get named properties and assign each real property
+	switch {
+	case stringStruct.Data != "" && len(stringStruct.Points) > 0:
+		err = cutils.CombineErrors(err, fmt.Errorf("a path takes either data or points, not both"))
+	case stringStruct.Data != "":
+		c.Data, c.NamedPropertiesMap, parseErr = cutils.ExtractString(stringStruct.Data, "data", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+		if c.Data != "" {
+			_, parseErr = render.ParsePath(c.Data)
+			err = cutils.CombineErrors(err, parseErr)
+		}
+	case len(stringStruct.Points) < 3:
+		err = cutils.CombineErrors(err, fmt.Errorf("a path needs data or at least 3 points, got %d points", len(stringStruct.Points)))
+	default:
+		c.Points = make([]image.Point, len(stringStruct.Points))
+		for i, point := range stringStruct.Points {
+			c.Points[i], c.NamedPropertiesMap, parseErr = cutils.ParsePoint(point.X, point.Y, fmt.Sprintf("pointX%d", i), fmt.Sprintf("pointY%d", i), c.NamedPropertiesMap)
+			err = cutils.CombineErrors(err, parseErr)
+		}
+	}
+	// Fill and stroke are each optional, but a stroke needs a width and a gradient replaces the fill
+	fill := cutils.ColourStrings{R: stringStruct.Fill.Red, G: stringStruct.Fill.Green, B: stringStruct.Fill.Blue, A: stringStruct.Fill.Alpha}
+	switch {
+	case fill != (cutils.ColourStrings{}) && stringStruct.Gradient != nil:
+		err = cutils.CombineErrors(err, fmt.Errorf("a path takes either a fill or a gradient, not both"))
+	case fill != (cutils.ColourStrings{}):
+		c.Fill, c.NamedPropertiesMap, parseErr = cutils.ParseColourStrings(fill, "fill", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	case stringStruct.Gradient != nil:
+		c.Gradient, c.NamedPropertiesMap, parseErr = cutils.ParseGradient(*stringStruct.Gradient, "gradient", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	}
+	stroke := cutils.ColourStrings{R: stringStruct.Stroke.Red, G: stringStruct.Stroke.Green, B: stringStruct.Stroke.Blue, A: stringStruct.Stroke.Alpha}
+	if stroke != (cutils.ColourStrings{}) || stringStruct.StrokeWidth != "" {
+		c.Stroke, c.NamedPropertiesMap, parseErr = cutils.ParseColourStrings(stroke, "stroke", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+		c.StrokeWidth, c.NamedPropertiesMap, parseErr = cutils.ExtractFloat(stringStruct.StrokeWidth, "strokeWidth", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	}
+	if fill == (cutils.ColourStrings{}) && stringStruct.Gradient == nil && stroke == (cutils.ColourStrings{}) && stringStruct.StrokeWidth == "" {
+		err = cutils.CombineErrors(err, fmt.Errorf("a path needs a fill, a stroke or both"))
+	}
+
+	// Fill discovered properties with real data
+	for key := range c.NamedPropertiesMap {
+		props[key] = struct {
+			Message string
+		}{Message: "Please replace me with real data"}
+	}
+
+	// Return original component on error
+	if err != nil {
+		c = component
+	}
+	return c, props, err
+}
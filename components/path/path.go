@@ -0,0 +1,111 @@
+// Package path is a vector path component drawn from SVG path data or a list of polygon vertices, with customisable fill or gradient and stroke.
+package path
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// Component implements the Component interface for paths.
+type Component struct {
+	/*
+		NamedPropertiesMap maps user/application variables to properties of the component.
+		This field is filled automatically by VerifyAndSetJSONData, then used in
+		SetNamedProperties to determine whether a variable being passed in is relevant to this
+		component.
+
+		For example, map[string][]string{"shape": []string{"data"}} would indicate that the
+		user specified variable "shape" will fill the SVG path data of the component.
+	*/
+	NamedPropertiesMap map[string][]string
+	// Data is the path in SVG path data syntax, such as "M 0 0 L 10 0 L 5 8 Z". Either Data or Points is used, not both.
+	Data string
+	// Points are the vertices of a closed polygon, relative to the top-left corner of the canvas.
+	Points []image.Point
+	// Fill is the colour of the inside of the path.
+	Fill color.NRGBA
+	// Stroke is the colour of the outline of the path.
+	Stroke color.NRGBA
+	// StrokeWidth is the width of the outline in pixels, or zero for no outline.
+	StrokeWidth float64
+	// Gradient fills the inside of the path instead of Fill if it has any colour stops.
+	Gradient render.Gradient
+}
+
+type pathFormat struct {
+	Data   string `json:"data"`
+	Points []struct {
+		X string `json:"x"`
+		Y string `json:"y"`
+	} `json:"points"`
+	Fill struct {
+		Red   string `json:"R"`
+		Green string `json:"G"`
+		Blue  string `json:"B"`
+		Alpha string `json:"A"`
+	} `json:"fill"`
+	Stroke struct {
+		Red   string `json:"R"`
+		Green string `json:"G"`
+		Blue  string `json:"B"`
+		Alpha string `json:"A"`
+	} `json:"stroke"`
+	StrokeWidth string                  `json:"strokeWidth"`
+	Gradient    *cutils.GradientStrings `json:"gradient"`
+}
+
+// Write draws a path on the canvas.
+func (component Component) Write(canvas render.Canvas) (render.Canvas, error) {
+	if len(component.NamedPropertiesMap) != 0 {
+		return canvas, fmt.Errorf("cannot draw path, not all named properties are set: %v", component.NamedPropertiesMap)
+	}
+	path := render.NewPolygonPath(component.Points)
+	if component.Data != "" {
+		var err error
+		path, err = render.ParsePath(component.Data)
+		if err != nil {
+			return canvas, err
+		}
+	}
+	return canvas.Path(path, cutils.FillColour(component.Fill, component.Gradient), component.Stroke, render.StrokeStyle{Width: component.StrokeWidth})
+}
+
+// SetNamedProperties processes the named properties and sets them into the path properties.
+func (component Component) SetNamedProperties(properties render.NamedProperties) (render.Component, error) {
+	c := component
+	// The points are set by index, so they must not be shared with the original component.
+	c.Points = append([]image.Point(nil), component.Points...)
+	var err error
+	c.NamedPropertiesMap, err = render.StandardSetNamedProperties(properties, component.NamedPropertiesMap, (&c).delegatedSetProperties)
+	if err != nil {
+		return component, err
+	}
+	return c, nil
+}
+
+// GetJSONFormat returns the JSON structure of a path component.
+func (component Component) GetJSONFormat() interface{} {
+	return &pathFormat{}
+}
+
+// VerifyAndSetJSONData processes the data parsed from JSON and uses it to set path properties and fill the named properties map.
+func (component Component) VerifyAndSetJSONData(data interface{}) (render.Component, render.NamedProperties, error) {
+	c := component
+	props := make(render.NamedProperties)
+	stringStruct, ok := data.(*pathFormat)
+	if !ok {
+		return component, props, fmt.Errorf("failed to convert returned data to component properties")
+	}
+	return c.parseJSONFormat(stringStruct, props)
+}
+
+func init() {
+	for _, name := range []string{"path", "Path", "PATH"} {
+		render.RegisterComponent(name, func(vfs.FileSystem) render.Component { return Component{} })
+	}
+}
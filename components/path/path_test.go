@@ -0,0 +1,398 @@
+package path
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathWrite(t *testing.T) {
+	t.Run("not all props set", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		c := Component{NamedPropertiesMap: map[string][]string{"not set": {"something"}}}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "cannot draw path, not all named properties are set: map[not set:[something]]")
+		canvas.AssertExpectations(t)
+	})
+	t.Run("invalid data", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		c := Component{Data: "L 1 2"}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "path data must start with a move command, found 'L'")
+		canvas.AssertExpectations(t)
+	})
+	t.Run("path error", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		canvas.On("Path", render.NewPolygonPath(nil), color.NRGBA{}, color.NRGBA{}, render.StrokeStyle{}).Return(canvas, fmt.Errorf("some error"))
+		c := Component{}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "some error")
+		canvas.AssertExpectations(t)
+	})
+	t.Run("data", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		path, _ := render.ParsePath("M 1 1 C 5 0 5 10 1 9 Z")
+		canvas.On("Path", path, color.NRGBA{R: 5, A: 255}, color.NRGBA{B: 5, A: 255}, render.StrokeStyle{Width: 2}).Return(canvas, nil)
+		c := Component{Data: "M 1 1 C 5 0 5 10 1 9 Z", Fill: color.NRGBA{R: 5, A: 255}, Stroke: color.NRGBA{B: 5, A: 255}, StrokeWidth: 2}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.NoError(t, err)
+		canvas.AssertExpectations(t)
+	})
+	t.Run("points", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		points := []image.Point{{X: 1, Y: 2}, {X: 30, Y: 40}, {X: 1, Y: 40}}
+		canvas.On("Path", render.NewPolygonPath(points), color.NRGBA{G: 5, A: 255}, color.NRGBA{}, render.StrokeStyle{}).Return(canvas, nil)
+		c := Component{Points: points, Fill: color.NRGBA{G: 5, A: 255}}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.NoError(t, err)
+		canvas.AssertExpectations(t)
+	})
+	t.Run("gradient", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		points := []image.Point{{X: 1, Y: 2}, {X: 30, Y: 40}, {X: 1, Y: 40}}
+		gradient := render.Gradient{Angle: 45, Stops: []render.GradientStop{{Offset: 0, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 1, Colour: color.NRGBA{B: 255, A: 255}}}}
+		canvas.On("Path", render.NewPolygonPath(points), gradient, color.NRGBA{}, render.StrokeStyle{}).Return(canvas, nil)
+		c := Component{Points: points, Gradient: gradient}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.NoError(t, err)
+		canvas.AssertExpectations(t)
+	})
+	t.Run("real canvas", func(t *testing.T) {
+		canvas, _ := render.NewCanvas(10, 10)
+		c := Component{Data: "M 1 1 H 9 V 9 H 1 Z", Fill: color.NRGBA{B: 255, A: 255}}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.NoError(t, err)
+		assert.Equal(t, color.NRGBA{B: 255, A: 255}, modifiedCanvas.GetUnderlyingImage().At(4, 5))
+		assert.Equal(t, color.NRGBA{}, modifiedCanvas.GetUnderlyingImage().At(0, 5))
+	})
+}
+
+func TestPathSetNamedProperties(t *testing.T) {
+	type testSet struct {
+		name  string
+		start Component
+		input render.NamedProperties
+		res   Component
+		err   string
+	}
+	tests := []testSet{
+		{
+			name:  "no props",
+			start: Component{},
+			input: render.NamedProperties{},
+			res:   Component{},
+			err:   "",
+		},
+		{
+			name: "colours valid",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"inside":  {"fillR", "fillG", "fillB", "fillA"},
+					"outside": {"strokeR", "strokeG", "strokeB", "strokeA"},
+				},
+			},
+			input: render.NamedProperties{
+				"inside":  uint8(1),
+				"outside": uint8(2),
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Fill:               color.NRGBA{R: 1, G: 1, B: 1, A: 1},
+				Stroke:             color.NRGBA{R: 2, G: 2, B: 2, A: 2},
+			},
+			err: "",
+		},
+		{
+			name: "non-RGBA invalid name",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"not a prop"},
+				},
+			},
+			input: render.NamedProperties{
+				"aProp": 12,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"not a prop"},
+				},
+			},
+			err: "invalid component property in named property map: not a prop",
+		},
+		{
+			name: "data and width",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"shape":     {"data"},
+					"thickness": {"strokeWidth"},
+				},
+			},
+			input: render.NamedProperties{
+				"shape":     "M 0 0 L 5 5 Z",
+				"thickness": 1.5,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Data:               "M 0 0 L 5 5 Z",
+				StrokeWidth:        1.5,
+			},
+			err: "",
+		},
+		{
+			name: "data wrong type",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"shape": {"data"},
+				},
+			},
+			input: render.NamedProperties{
+				"shape": 12,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"shape": {"data"},
+				},
+			},
+			err: "error converting 12 to string",
+		},
+		{
+			name: "points",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"cornerX": {"pointX0"},
+					"tipY":    {"pointY2"},
+				},
+				Points: []image.Point{{X: 1, Y: 2}, {X: 3, Y: 4}, {X: 5, Y: 6}},
+			},
+			input: render.NamedProperties{
+				"cornerX": 10,
+				"tipY":    60,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Points:             []image.Point{{X: 10, Y: 2}, {X: 3, Y: 4}, {X: 5, Y: 60}},
+			},
+			err: "",
+		},
+		{
+			name: "point out of range",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"pointY3"},
+				},
+				Points: []image.Point{{X: 1, Y: 2}, {X: 3, Y: 4}, {X: 5, Y: 6}},
+			},
+			input: render.NamedProperties{
+				"aProp": 10,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"pointY3"},
+				},
+				Points: []image.Point{{X: 1, Y: 2}, {X: 3, Y: 4}, {X: 5, Y: 6}},
+			},
+			err: "invalid component property in named property map: pointY3",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := test.start.SetNamedProperties(test.input)
+			assert.Equal(t, test.res, res)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+	t.Run("original unchanged", func(t *testing.T) {
+		start := Component{
+			NamedPropertiesMap: map[string][]string{"cornerX": {"pointX0"}},
+			Points:             []image.Point{{X: 1, Y: 2}, {X: 3, Y: 4}, {X: 5, Y: 6}},
+		}
+		_, err := start.SetNamedProperties(render.NamedProperties{"cornerX": 10})
+		assert.NoError(t, err)
+		assert.Equal(t, []image.Point{{X: 1, Y: 2}, {X: 3, Y: 4}, {X: 5, Y: 6}}, start.Points)
+	})
+	t.Run("original gradient unchanged", func(t *testing.T) {
+		start := Component{
+			NamedPropertiesMap: map[string][]string{"highlight": {"gradientStop1G"}},
+			Gradient:           render.Gradient{Stops: []render.GradientStop{{Offset: 0}, {Offset: 1}}},
+		}
+		res, err := start.SetNamedProperties(render.NamedProperties{"highlight": uint8(100)})
+		assert.NoError(t, err)
+		assert.Equal(t, []render.GradientStop{{Offset: 0}, {Offset: 1, Colour: color.NRGBA{G: 100}}}, res.(Component).Gradient.Stops)
+		assert.Equal(t, []render.GradientStop{{Offset: 0}, {Offset: 1}}, start.Gradient.Stops)
+	})
+}
+
+func TestPathGetJSONFormat(t *testing.T) {
+	c := Component{}
+	expectedFormat := &pathFormat{}
+	format := c.GetJSONFormat()
+	assert.Equal(t, expectedFormat, format)
+}
+
+type point = struct {
+	X string `json:"x"`
+	Y string `json:"y"`
+}
+
+type colour = struct {
+	Red   string `json:"R"`
+	Green string `json:"G"`
+	Blue  string `json:"B"`
+	Alpha string `json:"A"`
+}
+
+func TestPathVerifyAndSetJSONData(t *testing.T) {
+	type testSet struct {
+		name  string
+		start Component
+		input interface{}
+		res   Component
+		props render.NamedProperties
+		err   string
+	}
+	black := colour{Red: "0", Green: "0", Blue: "0", Alpha: "255"}
+	triangle := []point{{X: "1", Y: "1"}, {X: "9", Y: "1"}, {X: "5", Y: "8"}}
+	tests := []testSet{
+		{
+			name:  "incorrect format data",
+			input: "hello",
+			props: render.NamedProperties{},
+			err:   "failed to convert returned data to component properties",
+		},
+		{
+			name:  "data and points",
+			input: &pathFormat{Data: "M 0 0 L 1 1 Z", Points: triangle, Fill: black},
+			props: render.NamedProperties{},
+			err:   "a path takes either data or points, not both",
+		},
+		{
+			name:  "no shape",
+			input: &pathFormat{Fill: black},
+			props: render.NamedProperties{},
+			err:   "a path needs data or at least 3 points, got 0 points",
+		},
+		{
+			name:  "invalid data",
+			input: &pathFormat{Data: "M 0 0 L 1", Fill: black},
+			props: render.NamedProperties{},
+			err:   "expected a number in path data at offset 9",
+		},
+		{
+			name:  "invalid point",
+			input: &pathFormat{Points: []point{{X: "1", Y: "2"}, {X: "three", Y: "4"}, {X: "5", Y: "6"}}, Fill: black},
+			props: render.NamedProperties{},
+			err:   "failed to convert property pointX1 to integer: strconv.ParseInt: parsing \"three\": invalid syntax",
+		},
+		{
+			name:  "nothing to draw",
+			input: &pathFormat{Points: triangle},
+			props: render.NamedProperties{},
+			err:   "a path needs a fill, a stroke or both",
+		},
+		{
+			name:  "stroke without width",
+			input: &pathFormat{Points: triangle, Stroke: black},
+			props: render.NamedProperties{},
+			err:   "error parsing data for property strokeWidth: could not parse empty property",
+		},
+		{
+			name:  "invalid fill",
+			input: &pathFormat{Points: triangle, Fill: colour{Red: "0", Green: "0", Blue: "0", Alpha: "opaque"}},
+			props: render.NamedProperties{},
+			err:   "failed to convert property fillA to uint8: strconv.ParseUint: parsing \"opaque\": invalid syntax",
+		},
+		{
+			name:  "fill only",
+			input: &pathFormat{Points: triangle, Fill: black},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Points:             []image.Point{{X: 1, Y: 1}, {X: 9, Y: 1}, {X: 5, Y: 8}},
+				Fill:               color.NRGBA{A: 255},
+			},
+			props: render.NamedProperties{},
+		},
+		{
+			name:  "fill and gradient",
+			input: &pathFormat{Points: triangle, Fill: black, Gradient: &cutils.GradientStrings{}},
+			props: render.NamedProperties{},
+			err:   "a path takes either a fill or a gradient, not both",
+		},
+		{
+			name:  "gradient only",
+			input: &pathFormat{Points: triangle, Gradient: &cutils.GradientStrings{Stops: []cutils.GradientStopStrings{{Colour: cutils.ColourStrings{R: "0", G: "0", B: "0", A: "255"}}, {Offset: "$offset$", Colour: cutils.ColourStrings{R: "255", G: "255", B: "255", A: "255"}}}}},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{"offset": {"gradientStop1Offset"}},
+				Points:             []image.Point{{X: 1, Y: 1}, {X: 9, Y: 1}, {X: 5, Y: 8}},
+				Gradient:           render.Gradient{Type: render.GradientLinear, Stops: []render.GradientStop{{Offset: 0, Colour: color.NRGBA{A: 255}}, {Offset: 0, Colour: color.NRGBA{R: 255, G: 255, B: 255, A: 255}}}},
+			},
+			props: render.NamedProperties{"offset": struct{ Message string }{Message: "Please replace me with real data"}},
+		},
+		{
+			name:  "stroke only",
+			input: &pathFormat{Data: "M 1 1 A 4 4 0 0 1 9 1", Stroke: black, StrokeWidth: "1.5"},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Data:               "M 1 1 A 4 4 0 0 1 9 1",
+				Stroke:             color.NRGBA{A: 255},
+				StrokeWidth:        1.5,
+			},
+			props: render.NamedProperties{},
+		},
+		{
+			name: "valid everything",
+			input: &pathFormat{
+				Data:        "$shape$",
+				Fill:        colour{Red: "192", Green: "1", Blue: "$blue$", Alpha: "201"},
+				Stroke:      black,
+				StrokeWidth: "$thickness$",
+			},
+			res: Component{
+				Fill:   color.NRGBA{R: 192, G: 1, A: 201},
+				Stroke: color.NRGBA{A: 255},
+				NamedPropertiesMap: map[string][]string{
+					"shape":     {"data"},
+					"blue":      {"fillB"},
+					"thickness": {"strokeWidth"},
+				},
+			},
+			props: render.NamedProperties{
+				"shape":     struct{ Message string }{Message: "Please replace me with real data"},
+				"blue":      struct{ Message string }{Message: "Please replace me with real data"},
+				"thickness": struct{ Message string }{Message: "Please replace me with real data"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, props, err := test.start.VerifyAndSetJSONData(test.input)
+			assert.Equal(t, test.res, res)
+			assert.Equal(t, test.props, props)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestInit(t *testing.T) {
+	c, err := render.Decode("path")
+	assert.NoError(t, err)
+	assert.Equal(t, Component{}, c)
+}
@@ -0,0 +1,59 @@
+package path
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
+)
+
+func (component *Component) delegatedSetProperties(name string, value interface{}) (err error) {
+	switch {
+	case name == "data":
+		component.Data, err = cutils.SetString(value)
+	case name == "fillR":
+		component.Fill.R, err = cutils.SetUint8(value)
+	case name == "fillG":
+		component.Fill.G, err = cutils.SetUint8(value)
+	case name == "fillB":
+		component.Fill.B, err = cutils.SetUint8(value)
+	case name == "fillA":
+		component.Fill.A, err = cutils.SetUint8(value)
+	case name == "strokeR":
+		component.Stroke.R, err = cutils.SetUint8(value)
+	case name == "strokeG":
+		component.Stroke.G, err = cutils.SetUint8(value)
+	case name == "strokeB":
+		component.Stroke.B, err = cutils.SetUint8(value)
+	case name == "strokeA":
+		component.Stroke.A, err = cutils.SetUint8(value)
+	case name == "strokeWidth":
+		component.StrokeWidth, err = cutils.SetFloat64(value)
+	case strings.HasPrefix(name, "pointX"):
+		var i int
+		i, err = pointIndex(name, "pointX", len(component.Points))
+		if err == nil {
+			component.Points[i].X, err = cutils.SetInt(value)
+		}
+	case strings.HasPrefix(name, "pointY"):
+		var i int
+		i, err = pointIndex(name, "pointY", len(component.Points))
+		if err == nil {
+			component.Points[i].Y, err = cutils.SetInt(value)
+		}
+	default:
+		// Anything else is either part of the gradient or invalid
+		err = cutils.SetGradientProperty(&component.Gradient, "gradient", name, value)
+	}
+	return
+}
+
+// pointIndex finds the index of a property referring to a polygon vertex, such as pointX2 for the third vertex.
+func pointIndex(name, prefix string, length int) (int, error) {
+	i, err := strconv.Atoi(strings.TrimPrefix(name, prefix))
+	if err != nil || i < 0 || i >= length {
+		return 0, fmt.Errorf("invalid component property in named property map: %v", name)
+	}
+	return i, nil
+}
@@ -1,4 +1,4 @@
-// Package rectangle is a simple rectangle component with customisable size, colour and location.
+// Package rectangle is a simple rectangle component with customisable size, colour or gradient and location, optionally with rounded corners and a border.
 package rectangle
 
 import (
@@ -35,6 +35,16 @@ type Component struct {
 	Height int
 	// Colour is the colour of the rectangle.
 	Colour color.NRGBA
+	// CornerRadii are the radii of each rounded corner, with zero for a square corner.
+	CornerRadii render.CornerRadii
+	// BorderWidth is the width of the border drawn just inside the edge of the rectangle, or zero for no border.
+	BorderWidth float64
+	// BorderColour is the colour of the border.
+	BorderColour color.NRGBA
+	// Outline leaves the inside of the rectangle unfilled, so only the border is drawn.
+	Outline bool
+	// Gradient fills the rectangle instead of Colour if it has any colour stops.
+	Gradient render.Gradient
 }
 
 type rectangleFormat struct {
@@ -48,6 +58,23 @@ type rectangleFormat struct {
 		Blue  string `json:"B"`
 		Alpha string `json:"A"`
 	} `json:"colour"`
+	CornerRadius struct {
+		TopLeft     string `json:"topLeft"`
+		TopRight    string `json:"topRight"`
+		BottomRight string `json:"bottomRight"`
+		BottomLeft  string `json:"bottomLeft"`
+	} `json:"cornerRadius"`
+	Border struct {
+		Width  string `json:"width"`
+		Colour struct {
+			Red   string `json:"R"`
+			Green string `json:"G"`
+			Blue  string `json:"B"`
+			Alpha string `json:"A"`
+		} `json:"colour"`
+	} `json:"border"`
+	Outline  string                  `json:"outline"`
+	Gradient *cutils.GradientStrings `json:"gradient"`
 }
 
 // Write draws a rectangle on the canvas.
@@ -55,7 +82,18 @@ func (component Component) Write(canvas render.Canvas) (render.Canvas, error) {
 	if len(component.NamedPropertiesMap) != 0 {
 		return canvas, fmt.Errorf("cannot draw rectangle, not all named properties are set: %v", component.NamedPropertiesMap)
 	}
-	return canvas.Rectangle(component.TopLeft, component.Width, component.Height, component.Colour)
+	if component.CornerRadii == (render.CornerRadii{}) && component.BorderWidth == 0 && !component.Outline {
+		return canvas.Rectangle(component.TopLeft, component.Width, component.Height, cutils.FillColour(component.Colour, component.Gradient))
+	}
+	if component.Width <= 0 || component.Height <= 0 {
+		return canvas, fmt.Errorf("invalid rectangle size %dx%d", component.Width, component.Height)
+	}
+	fill := cutils.FillColour(component.Colour, component.Gradient)
+	if component.Outline {
+		fill = nil
+	}
+	path := render.NewRectanglePath(component.TopLeft, component.Width, component.Height, component.CornerRadii)
+	return canvas.Path(path, fill, component.BorderColour, render.StrokeStyle{Width: component.BorderWidth, Inside: true})
 }
 
 // SetNamedProperties processes the named properties and sets them into the rectangle properties.
@@ -101,6 +139,29 @@ func (component Component) VerifyAndSetJSONData(data interface{}) (render.Compon
 	if err != nil {
 		return component, props, err
 	}
+	if stringStruct.Outline != "" {
+		c.NamedPropertiesMap, newVal, err = render.ExtractSingleProp(stringStruct.Outline, "outline", render.BoolType, c.NamedPropertiesMap)
+		if err != nil {
+			return component, props, err
+		}
+		if newVal != nil {
+			c.Outline = newVal.(bool)
+		}
+	}
+	colourSet := stringStruct.Colour.Red != "" || stringStruct.Colour.Green != "" || stringStruct.Colour.Blue != "" || stringStruct.Colour.Alpha != ""
+	if stringStruct.Gradient != nil {
+		if colourSet {
+			return component, props, fmt.Errorf("a rectangle takes either a colour or a gradient, not both")
+		}
+		c.Gradient, c.NamedPropertiesMap, err = cutils.ParseGradient(*stringStruct.Gradient, "gradient", c.NamedPropertiesMap)
+		if err != nil {
+			return component, props, err
+		}
+	}
+	// An outline has no fill and a gradient replaces the colour, so neither needs a colour
+	if (c.Outline || stringStruct.Gradient != nil) && !colourSet {
+		return c.parseBorder(component, stringStruct, props)
+	}
 	c.NamedPropertiesMap, newVal, err = render.ExtractSingleProp(stringStruct.Colour.Red, "R", render.Uint8Type, c.NamedPropertiesMap)
 	if err != nil {
 		return component, props, err
@@ -129,7 +190,46 @@ func (component Component) VerifyAndSetJSONData(data interface{}) (render.Compon
 	if newVal != nil {
 		c.Colour.A = newVal.(uint8)
 	}
+	return c.parseBorder(component, stringStruct, props)
+}
 
+// parseBorder sets the optional corner radii and border and fills the named properties, returning the original component on error.
+func (component Component) parseBorder(original Component, stringStruct *rectangleFormat, props render.NamedProperties) (render.Component, render.NamedProperties, error) {
+	c := component
+	var err error
+	corners := []struct {
+		raw    string
+		name   string
+		radius *float64
+	}{
+		{raw: stringStruct.CornerRadius.TopLeft, name: "topLeftRadius", radius: &c.CornerRadii.TopLeft},
+		{raw: stringStruct.CornerRadius.TopRight, name: "topRightRadius", radius: &c.CornerRadii.TopRight},
+		{raw: stringStruct.CornerRadius.BottomRight, name: "bottomRightRadius", radius: &c.CornerRadii.BottomRight},
+		{raw: stringStruct.CornerRadius.BottomLeft, name: "bottomLeftRadius", radius: &c.CornerRadii.BottomLeft},
+	}
+	for _, corner := range corners {
+		if corner.raw == "" {
+			continue
+		}
+		*corner.radius, c.NamedPropertiesMap, err = cutils.ExtractFloat(corner.raw, corner.name, c.NamedPropertiesMap)
+		if err != nil {
+			return original, props, err
+		}
+	}
+	border := stringStruct.Border
+	borderColour := cutils.ColourStrings{R: border.Colour.Red, G: border.Colour.Green, B: border.Colour.Blue, A: border.Colour.Alpha}
+	if border.Width != "" || borderColour != (cutils.ColourStrings{}) {
+		c.BorderWidth, c.NamedPropertiesMap, err = cutils.ExtractFloat(border.Width, "borderWidth", c.NamedPropertiesMap)
+		if err != nil {
+			return original, props, err
+		}
+		c.BorderColour, c.NamedPropertiesMap, err = cutils.ParseColourStrings(borderColour, "border", c.NamedPropertiesMap)
+		if err != nil {
+			return original, props, err
+		}
+	} else if c.Outline {
+		return original, props, fmt.Errorf("an outline rectangle needs a border")
+	}
 	for key := range c.NamedPropertiesMap {
 		props[key] = struct {
 			Message string
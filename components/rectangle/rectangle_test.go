@@ -6,6 +6,7 @@ import (
 	"image/color"
 	"testing"
 
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
 	"github.com/LLKennedy/imagetemplate/v3/render"
 	"github.com/stretchr/testify/assert"
 )
@@ -37,6 +38,62 @@ func TestRectangleWrite(t *testing.T) {
 		assert.NoError(t, err)
 		canvas.AssertExpectations(t)
 	})
+	t.Run("rounded", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		radii := render.CornerRadii{TopLeft: 4, BottomRight: 2}
+		canvas.On("Path", render.NewRectanglePath(image.Pt(1, 2), 30, 20, radii), color.NRGBA{R: 5, A: 255}, color.NRGBA{}, render.StrokeStyle{Inside: true}).Return(canvas, nil)
+		c := Component{TopLeft: image.Pt(1, 2), Width: 30, Height: 20, Colour: color.NRGBA{R: 5, A: 255}, CornerRadii: radii}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.NoError(t, err)
+		canvas.AssertExpectations(t)
+	})
+	t.Run("outline", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		canvas.On("Path", render.NewRectanglePath(image.Pt(1, 2), 30, 20, render.CornerRadii{}), nil, color.NRGBA{B: 5, A: 255}, render.StrokeStyle{Width: 3, Inside: true}).Return(canvas, fmt.Errorf("some error"))
+		c := Component{TopLeft: image.Pt(1, 2), Width: 30, Height: 20, Colour: color.NRGBA{R: 5, A: 255}, BorderWidth: 3, BorderColour: color.NRGBA{B: 5, A: 255}, Outline: true}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "some error")
+		canvas.AssertExpectations(t)
+	})
+	t.Run("gradient", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		gradient := render.Gradient{Type: render.GradientRadial, Stops: []render.GradientStop{{Offset: 0, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 1, Colour: color.NRGBA{B: 255, A: 255}}}}
+		canvas.On("Rectangle", image.Pt(1, 2), 30, 20, gradient).Return(canvas, nil)
+		c := Component{TopLeft: image.Pt(1, 2), Width: 30, Height: 20, Colour: color.NRGBA{R: 5, A: 255}, Gradient: gradient}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.NoError(t, err)
+		canvas.AssertExpectations(t)
+	})
+	t.Run("invalid size", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		c := Component{TopLeft: image.Pt(1, 2), Width: 30, Height: -20, BorderWidth: 3}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid rectangle size 30x-20")
+		canvas.AssertExpectations(t)
+	})
+	t.Run("real canvas", func(t *testing.T) {
+		canvas, _ := render.NewCanvas(20, 20)
+		c := Component{
+			TopLeft:      image.Pt(0, 0),
+			Width:        20,
+			Height:       20,
+			Colour:       color.NRGBA{R: 255, A: 255},
+			CornerRadii:  render.CornerRadii{TopLeft: 6, TopRight: 6, BottomRight: 6, BottomLeft: 6},
+			BorderWidth:  2,
+			BorderColour: color.NRGBA{B: 255, A: 255},
+		}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, color.NRGBA{}, img.At(0, 0))
+		assert.Equal(t, color.NRGBA{B: 255, A: 255}, img.At(0, 10))
+		assert.Equal(t, color.NRGBA{B: 255, A: 255}, img.At(10, 1))
+		assert.Equal(t, color.NRGBA{R: 255, A: 255}, img.At(10, 10))
+	})
 }
 
 func TestRectangleSetNamedProperties(t *testing.T) {
@@ -169,6 +226,67 @@ func TestRectangleSetNamedProperties(t *testing.T) {
 			},
 			err: "",
 		},
+		{
+			name: "corners and border",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"round":  {"topLeftRadius", "topRightRadius", "bottomRightRadius", "bottomLeftRadius"},
+					"thick":  {"borderWidth"},
+					"frame":  {"borderR", "borderG", "borderB", "borderA"},
+					"hollow": {"outline"},
+				},
+			},
+			input: render.NamedProperties{
+				"round":  4.5,
+				"thick":  2.0,
+				"frame":  uint8(200),
+				"hollow": true,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				CornerRadii:        render.CornerRadii{TopLeft: 4.5, TopRight: 4.5, BottomRight: 4.5, BottomLeft: 4.5},
+				BorderWidth:        2,
+				BorderColour:       color.NRGBA{R: 200, G: 200, B: 200, A: 200},
+				Outline:            true,
+			},
+			err: "",
+		},
+		{
+			name: "gradient",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"direction": {"gradientAngle"},
+					"start":     {"gradientStop0R", "gradientStop0A"},
+				},
+				Gradient: render.Gradient{Stops: make([]render.GradientStop, 2)},
+			},
+			input: render.NamedProperties{
+				"direction": 90.0,
+				"start":     uint8(255),
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Gradient:           render.Gradient{Angle: 90, Stops: []render.GradientStop{{Colour: color.NRGBA{R: 255, A: 255}}, {}}},
+			},
+			err: "",
+		},
+		{
+			name: "outline wrong type",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"hollow": {"outline"},
+				},
+			},
+			input: render.NamedProperties{
+				"hollow": "yes",
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"hollow": {"outline"},
+				},
+			},
+			err: "error converting yes to bool",
+		},
 		{
 			name: "full prop set, multiple sources, unused props",
 			start: Component{
@@ -383,6 +501,104 @@ func TestRectangleVerifyAndTestRectangleJSONData(t *testing.T) {
 			props: render.NamedProperties{"myWidth": struct{ Message string }{Message: "Please replace me with real data"}},
 		},
 	}
+	rounded := &rectangleFormat{TopLeftX: "1", TopLeftY: "2", Width: "30", Height: "20"}
+	rounded.Colour.Red, rounded.Colour.Green, rounded.Colour.Blue, rounded.Colour.Alpha = "0", "0", "0", "255"
+	rounded.CornerRadius.TopLeft, rounded.CornerRadius.BottomRight = "4", "$corner$"
+	rounded.Border.Width = "1.5"
+	rounded.Border.Colour.Red, rounded.Border.Colour.Green, rounded.Border.Colour.Blue, rounded.Border.Colour.Alpha = "255", "$green$", "0", "255"
+	tests = append(tests, testSet{
+		name:  "rounded with border",
+		input: rounded,
+		res: Component{
+			TopLeft:      image.Pt(1, 2),
+			Width:        30,
+			Height:       20,
+			Colour:       color.NRGBA{A: 255},
+			CornerRadii:  render.CornerRadii{TopLeft: 4},
+			BorderWidth:  1.5,
+			BorderColour: color.NRGBA{R: 255, A: 255},
+			NamedPropertiesMap: map[string][]string{
+				"corner": {"bottomRightRadius"},
+				"green":  {"borderG"},
+			},
+		},
+		props: render.NamedProperties{
+			"corner": struct{ Message string }{Message: "Please replace me with real data"},
+			"green":  struct{ Message string }{Message: "Please replace me with real data"},
+		},
+	})
+	invalidCorner := *rounded
+	invalidCorner.CornerRadius.TopRight = "round"
+	tests = append(tests, testSet{
+		name:  "invalid corner",
+		input: &invalidCorner,
+		props: render.NamedProperties{},
+		err:   "failed to convert property topRightRadius to float64: strconv.ParseFloat: parsing \"round\": invalid syntax",
+	})
+	missingWidth := *rounded
+	missingWidth.Border.Width = ""
+	tests = append(tests, testSet{
+		name:  "border without width",
+		input: &missingWidth,
+		props: render.NamedProperties{},
+		err:   "error parsing data for property borderWidth: could not parse empty property",
+	})
+	outline := &rectangleFormat{TopLeftX: "1", TopLeftY: "2", Width: "30", Height: "20", Outline: "true"}
+	tests = append(tests, testSet{
+		name:  "outline without border",
+		input: outline,
+		props: render.NamedProperties{},
+		err:   "an outline rectangle needs a border",
+	})
+	outlineWithBorder := *outline
+	outlineWithBorder.Border = rounded.Border
+	outlineWithBorder.Border.Colour.Green = "0"
+	tests = append(tests, testSet{
+		name:  "outline",
+		input: &outlineWithBorder,
+		res: Component{
+			NamedPropertiesMap: map[string][]string{},
+			TopLeft:            image.Pt(1, 2),
+			Width:              30,
+			Height:             20,
+			BorderWidth:        1.5,
+			BorderColour:       color.NRGBA{R: 255, A: 255},
+			Outline:            true,
+		},
+		props: render.NamedProperties{},
+	})
+	invalidOutline := *outline
+	invalidOutline.Outline = "sometimes"
+	tests = append(tests, testSet{
+		name:  "invalid outline",
+		input: &invalidOutline,
+		props: render.NamedProperties{},
+		err:   "failed to convert property outline to bool: strconv.ParseBool: parsing \"sometimes\": invalid syntax",
+	})
+	gradient := &rectangleFormat{TopLeftX: "1", TopLeftY: "2", Width: "30", Height: "20", Gradient: &cutils.GradientStrings{
+		Type:  "radial",
+		Stops: []cutils.GradientStopStrings{{Colour: cutils.ColourStrings{R: "255", G: "0", B: "0", A: "255"}}, {Colour: cutils.ColourStrings{R: "0", G: "0", B: "$blue$", A: "255"}}},
+	}}
+	tests = append(tests, testSet{
+		name:  "gradient",
+		input: gradient,
+		res: Component{
+			NamedPropertiesMap: map[string][]string{"blue": {"gradientStop1B"}},
+			TopLeft:            image.Pt(1, 2),
+			Width:              30,
+			Height:             20,
+			Gradient:           render.Gradient{Type: render.GradientRadial, Stops: []render.GradientStop{{Offset: 0, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 1, Colour: color.NRGBA{A: 255}}}},
+		},
+		props: render.NamedProperties{"blue": struct{ Message string }{Message: "Please replace me with real data"}},
+	})
+	gradientAndColour := *gradient
+	gradientAndColour.Colour.Red = "255"
+	tests = append(tests, testSet{
+		name:  "gradient and colour",
+		input: &gradientAndColour,
+		props: render.NamedProperties{},
+		err:   "a rectangle takes either a colour or a gradient, not both",
+	})
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			res, props, err := test.start.VerifyAndSetJSONData(test.input)
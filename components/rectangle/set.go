@@ -1,8 +1,6 @@
 package rectangle
 
 import (
-	"fmt"
-
 	"github.com/LLKennedy/imagetemplate/v3/cutils"
 )
 
@@ -24,8 +22,29 @@ func (component *Component) delegatedSetProperties(name string, value interface{
 		component.Width, err = cutils.SetInt(value)
 	case "height":
 		component.Height, err = cutils.SetInt(value)
+	case "topLeftRadius":
+		component.CornerRadii.TopLeft, err = cutils.SetFloat64(value)
+	case "topRightRadius":
+		component.CornerRadii.TopRight, err = cutils.SetFloat64(value)
+	case "bottomRightRadius":
+		component.CornerRadii.BottomRight, err = cutils.SetFloat64(value)
+	case "bottomLeftRadius":
+		component.CornerRadii.BottomLeft, err = cutils.SetFloat64(value)
+	case "borderWidth":
+		component.BorderWidth, err = cutils.SetFloat64(value)
+	case "borderR":
+		component.BorderColour.R, err = cutils.SetUint8(value)
+	case "borderG":
+		component.BorderColour.G, err = cutils.SetUint8(value)
+	case "borderB":
+		component.BorderColour.B, err = cutils.SetUint8(value)
+	case "borderA":
+		component.BorderColour.A, err = cutils.SetUint8(value)
+	case "outline":
+		component.Outline, err = cutils.SetBool(value)
 	default:
-		err = fmt.Errorf("invalid component property in named property map: %v", name)
+		// Anything else is either part of the gradient or invalid
+		err = cutils.SetGradientProperty(&component.Gradient, "gradient", name, value)
 	}
 	return
 }
@@ -1,8 +1,12 @@
 package text
 
 import (
+	"fmt"
+	"image/color"
+
 	"github.com/LLKennedy/imagetemplate/v3/cutils"
 	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/golang/freetype/truetype"
 )
 
 func (component Component) parseJSONFormat(stringStruct *textFormat, props render.NamedProperties) (c Component, foundProps render.NamedProperties, err error) {
@@ -11,7 +15,24 @@ func (component Component) parseJSONFormat(stringStruct *textFormat, props rende
 	// Get named properties and assign each real property
 	c.Font, c.NamedPropertiesMap, parseErr = cutils.ParseFont(stringStruct.Font.FontName, stringStruct.Font.FontFile, stringStruct.Font.FontURL, cutils.ParseFontOptions{Props: c.NamedPropertiesMap, FileSystem: c.getFileSystem(), FontPool: c.getFontPool()})
 	err = cutils.CombineErrors(err, parseErr)
-	c.Content, c.NamedPropertiesMap, parseErr = cutils.ExtractString(stringStruct.Content, "content", c.NamedPropertiesMap)
+	if len(stringStruct.Spans) == 0 {
+		c.Content, c.NamedPropertiesMap, parseErr = cutils.ExtractString(stringStruct.Content, "content", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	} else if stringStruct.Content != "" {
+		err = cutils.CombineErrors(err, fmt.Errorf("text takes either content or spans, not both"))
+	} else {
+		c.Spans, c.NamedPropertiesMap, parseErr = c.parseSpans(stringStruct.Spans, c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	}
+	if stringStruct.Markup != "" {
+		c.Markup, c.NamedPropertiesMap, parseErr = cutils.ExtractBool(stringStruct.Markup, "markup", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	}
+	c.BoldFont, parseErr = c.loadFont(stringStruct.BoldFont)
+	err = cutils.CombineErrors(err, parseErr)
+	c.ItalicFont, parseErr = c.loadFont(stringStruct.ItalicFont)
+	err = cutils.CombineErrors(err, parseErr)
+	c.BoldItalicFont, parseErr = c.loadFont(stringStruct.BoldItalicFont)
 	err = cutils.CombineErrors(err, parseErr)
 	c.Start, c.NamedPropertiesMap, parseErr = cutils.ParsePoint(stringStruct.StartX, stringStruct.StartY, "startX", "startY", c.NamedPropertiesMap)
 	err = cutils.CombineErrors(err, parseErr)
@@ -19,9 +40,35 @@ func (component Component) parseJSONFormat(stringStruct *textFormat, props rende
 	err = cutils.CombineErrors(err, parseErr)
 	c.Size, c.NamedPropertiesMap, parseErr = cutils.ExtractFloat(stringStruct.Size, "size", c.NamedPropertiesMap)
 	err = cutils.CombineErrors(err, parseErr)
+	if stringStruct.MaxHeight != "" {
+		c.MaxHeight, c.NamedPropertiesMap, parseErr = cutils.ExtractInt(stringStruct.MaxHeight, "maxHeight", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	}
+	if stringStruct.LineHeight != "" {
+		c.LineHeight, c.NamedPropertiesMap, parseErr = cutils.ExtractFloat(stringStruct.LineHeight, "lineHeight", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	}
+	c.Overflow, c.NamedPropertiesMap, parseErr = cutils.ExtractTextOverflow(stringStruct.Overflow, "overflow", c.NamedPropertiesMap)
+	err = cutils.CombineErrors(err, parseErr)
+	if stringStruct.MinSize != "" {
+		c.MinSize, c.NamedPropertiesMap, parseErr = cutils.ExtractFloat(stringStruct.MinSize, "minSize", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	}
 	c.TextAlignment, c.NamedPropertiesMap, parseErr = cutils.ExtractTextAlignment(stringStruct.TextAlignment, "alignment", c.NamedPropertiesMap)
 	err = cutils.CombineErrors(err, parseErr)
-	c.Colour, c.NamedPropertiesMap, parseErr = cutils.ParseColourStrings(cutils.ColourStrings{R: stringStruct.Colour.Red, G: stringStruct.Colour.Green, B: stringStruct.Colour.Blue, A: stringStruct.Colour.Alpha}, "", c.NamedPropertiesMap)
+	c.Anchor, c.NamedPropertiesMap, parseErr = cutils.ExtractTextAnchor(stringStruct.Anchor, "anchor", c.NamedPropertiesMap)
+	err = cutils.CombineErrors(err, parseErr)
+	colour := cutils.ColourStrings{R: stringStruct.Colour.Red, G: stringStruct.Colour.Green, B: stringStruct.Colour.Blue, A: stringStruct.Colour.Alpha}
+	if stringStruct.Gradient == nil {
+		c.Colour, c.NamedPropertiesMap, parseErr = cutils.ParseColourStrings(colour, "", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	} else if colour != (cutils.ColourStrings{}) {
+		err = cutils.CombineErrors(err, fmt.Errorf("text takes either a colour or a gradient, not both"))
+	} else {
+		c.Gradient, c.NamedPropertiesMap, parseErr = cutils.ParseGradient(*stringStruct.Gradient, "gradient", c.NamedPropertiesMap)
+		err = cutils.CombineErrors(err, parseErr)
+	}
+	c.Effects, c.NamedPropertiesMap, parseErr = cutils.ParseTextEffects(stringStruct.Stroke, stringStruct.Shadow, stringStruct.Background, c.NamedPropertiesMap)
 	err = cutils.CombineErrors(err, parseErr)
 
 	// Fill discovered properties with real data
@@ -37,3 +84,64 @@ func (component Component) parseJSONFormat(stringStruct *textFormat, props rende
 	}
 	return c, props, err
 }
+
+// parseSpans parses each of the spans, naming their properties like span0Text and span0R.
+func (component Component) parseSpans(formats []spanFormat, inputProps map[string][]string) ([]Span, map[string][]string, error) {
+	spans := make([]Span, len(formats))
+	props := inputProps
+	var err error
+	for i, format := range formats {
+		prefix := fmt.Sprintf("span%d", i)
+		var parseErr error
+		spans[i].Text, props, parseErr = cutils.ExtractString(format.Text, prefix+"Text", props)
+		err = cutils.CombineErrors(err, parseErr)
+		if format.Size != "" {
+			spans[i].Size, props, parseErr = cutils.ExtractFloat(format.Size, prefix+"Size", props)
+			err = cutils.CombineErrors(err, parseErr)
+		}
+		toggles := []struct {
+			raw   string
+			name  string
+			value *bool
+		}{
+			{raw: format.Bold, name: "Bold", value: &spans[i].Bold},
+			{raw: format.Italic, name: "Italic", value: &spans[i].Italic},
+			{raw: format.Underline, name: "Underline", value: &spans[i].Underline},
+			{raw: format.Strikethrough, name: "Strikethrough", value: &spans[i].Strikethrough},
+		}
+		for _, toggle := range toggles {
+			if toggle.raw != "" {
+				*toggle.value, props, parseErr = cutils.ExtractBool(toggle.raw, prefix+toggle.name, props)
+				err = cutils.CombineErrors(err, parseErr)
+			}
+		}
+		spans[i].Font, parseErr = component.loadFont(format.Font)
+		err = cutils.CombineErrors(err, parseErr)
+		if format.Colour != nil {
+			var colour color.NRGBA
+			colour, props, parseErr = cutils.ParseColourStrings(cutils.ColourStrings{R: format.Colour.Red, G: format.Colour.Green, B: format.Colour.Blue, A: format.Colour.Alpha}, prefix, props)
+			err = cutils.CombineErrors(err, parseErr)
+			spans[i].Colour = &colour
+		}
+	}
+	if err != nil {
+		return nil, inputProps, err
+	}
+	return spans, props, nil
+}
+
+// loadFont loads a typeface by name or from a file, which unlike the main font of the component can't be named properties.
+func (component Component) loadFont(format *fontFormat) (*truetype.Font, error) {
+	switch {
+	case format == nil:
+		return nil, nil
+	case format.FontName != "" && format.FontFile != "":
+		return nil, fmt.Errorf("a font takes either a fontName or a fontFile, not both")
+	case format.FontName != "":
+		return component.getFontPool().GetFont(format.FontName)
+	case format.FontFile != "":
+		return cutils.LoadFontFile(component.getFileSystem(), format.FontFile)
+	default:
+		return nil, fmt.Errorf("a font needs a fontName or a fontFile")
+	}
+}
@@ -0,0 +1,280 @@
+package text
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+)
+
+// Span is a run of text in a single style, which can differ from the rest of the component.
+type Span struct {
+	// Text is the text of the span.
+	Text string
+	// Font is the typeface of the span, or nil to use the typeface of the component.
+	Font *truetype.Font
+	// Size is the size of the span in points, or zero to use the size of the component.
+	Size float64
+	// Colour is the colour of the span, or nil to use the colour or gradient of the component.
+	Colour *color.NRGBA
+	// Bold and Italic pick the bold and italic typefaces of the component, or are imitated if there are none.
+	Bold, Italic bool
+	// Underline and Strikethrough draw lines under or through the span.
+	Underline, Strikethrough bool
+}
+
+type spanFormat struct {
+	Text          string      `json:"text"`
+	Size          string      `json:"size"`
+	Bold          string      `json:"bold"`
+	Italic        string      `json:"italic"`
+	Underline     string      `json:"underline"`
+	Strikethrough string      `json:"strikethrough"`
+	Font          *fontFormat `json:"font"`
+	Colour        *struct {
+		Red   string `json:"R"`
+		Green string `json:"G"`
+		Blue  string `json:"B"`
+		Alpha string `json:"A"`
+	} `json:"colour"`
+}
+
+// fontFormat is a typeface which can't be a named property.
+type fontFormat struct {
+	FontName string `json:"fontName"`
+	FontFile string `json:"fontFile"`
+}
+
+// isRich returns whether the component is drawn from styled spans rather than plain content.
+func (component Component) isRich() bool {
+	return component.Markup || len(component.Spans) > 0
+}
+
+// spans returns the text of the component split into spans of the same style.
+func (component Component) spans() ([]Span, error) {
+	if len(component.Spans) > 0 {
+		return component.Spans, nil
+	}
+	if component.Markup {
+		return component.parseMarkup(component.Content)
+	}
+	return []Span{{Text: component.Content}}, nil
+}
+
+// plainText returns the text of the component without any styles.
+func plainText(spans []Span) string {
+	var text strings.Builder
+	for _, span := range spans {
+		text.WriteString(span.Text)
+	}
+	return text.String()
+}
+
+// markupToggles are the markers which switch a style on and off, longest first so that "**" isn't read as two "*".
+var markupToggles = []struct {
+	marker string
+	toggle func(*Span)
+}{
+	{marker: "**", toggle: func(span *Span) { span.Bold = !span.Bold }},
+	{marker: "__", toggle: func(span *Span) { span.Underline = !span.Underline }},
+	{marker: "~~", toggle: func(span *Span) { span.Strikethrough = !span.Strikethrough }},
+	{marker: "*", toggle: func(span *Span) { span.Italic = !span.Italic }},
+}
+
+/*
+parseMarkup splits content into spans using a small markup. "**" toggles bold, "*" italic,
+"__" underline and "~~" strikethrough. "{colour=#rrggbb}", "{size=12}" and "{font=Name}" change
+the colour, size and font until the matching "{/colour}", "{/size}" or "{/font}", and "color" is
+accepted for "colour". A backslash makes the character after it plain text.
+*/
+func (component Component) parseMarkup(content string) ([]Span, error) {
+	var spans []Span
+	var text strings.Builder
+	current := Span{}
+	// Each tag saves the style it replaces, so the closing tag can restore it.
+	var saved []struct {
+		tag   string
+		style Span
+	}
+	flush := func() {
+		if text.Len() > 0 {
+			span := current
+			span.Text = text.String()
+			spans = append(spans, span)
+			text.Reset()
+		}
+	}
+	for index := 0; index < len(content); {
+		if content[index] == '\\' && index+1 < len(content) {
+			text.WriteByte(content[index+1])
+			index += 2
+			continue
+		}
+		toggled := false
+		for _, toggle := range markupToggles {
+			if strings.HasPrefix(content[index:], toggle.marker) {
+				flush()
+				toggle.toggle(&current)
+				index += len(toggle.marker)
+				toggled = true
+				break
+			}
+		}
+		if toggled {
+			continue
+		}
+		if content[index] != '{' {
+			text.WriteByte(content[index])
+			index++
+			continue
+		}
+		end := strings.IndexByte(content[index:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed markup tag at offset %d", index)
+		}
+		tag := content[index+1 : index+end]
+		index += end + 1
+		flush()
+		if strings.HasPrefix(tag, "/") {
+			name := markupTagName(strings.TrimPrefix(tag, "/"))
+			if len(saved) == 0 || saved[len(saved)-1].tag != name {
+				return nil, fmt.Errorf("unexpected markup tag {%v}", tag)
+			}
+			style := saved[len(saved)-1].style
+			current.Colour, current.Size, current.Font = style.Colour, style.Size, style.Font
+			saved = saved[:len(saved)-1]
+			continue
+		}
+		equals := strings.IndexByte(tag, '=')
+		if equals < 0 {
+			return nil, fmt.Errorf("unknown markup tag {%v}", tag)
+		}
+		name, value := markupTagName(tag[:equals]), tag[equals+1:]
+		saved = append(saved, struct {
+			tag   string
+			style Span
+		}{tag: name, style: current})
+		var err error
+		switch name {
+		case "colour":
+			var colour color.NRGBA
+			colour, err = parseHexColour(value)
+			current.Colour = &colour
+		case "size":
+			current.Size, err = strconv.ParseFloat(value, 64)
+			if err == nil && (current.Size < 0 || math.IsNaN(current.Size) || math.IsInf(current.Size, 0)) {
+				err = fmt.Errorf("invalid size %v, expected a finite number of at least 0", value)
+			}
+		case "font":
+			current.Font, err = component.getFontPool().GetFont(value)
+		default:
+			err = fmt.Errorf("unknown markup tag {%v}", tag)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	flush()
+	return spans, nil
+}
+
+// markupTagName returns the name of a markup tag, accepting the American spelling of colour.
+func markupTagName(name string) string {
+	if name == "color" {
+		return "colour"
+	}
+	return name
+}
+
+// parseHexColour parses a CSS style hex colour of the form #rgb, #rrggbb or #rrggbbaa.
+func parseHexColour(hex string) (color.NRGBA, error) {
+	digits := strings.TrimPrefix(hex, "#")
+	if len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+	if len(digits) == 6 {
+		digits += "ff"
+	}
+	value, err := strconv.ParseUint(digits, 16, 32)
+	if len(digits) != 8 || !strings.HasPrefix(hex, "#") || err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid colour %v, expected #rgb, #rrggbb or #rrggbbaa", hex)
+	}
+	return color.NRGBA{R: uint8(value >> 24), G: uint8(value >> 16), B: uint8(value >> 8), A: uint8(value)}, nil
+}
+
+// textStyle is a span style ready to draw with.
+type textStyle struct {
+	face   render.FontFace
+	colour color.Color
+	// fauxBold and fauxItalic imitate a style the component has no typeface for.
+	fauxBold, fauxItalic     bool
+	underline, strikethrough bool
+}
+
+// styles returns the style to draw each of the spans with when the component is drawn at the given size.
+func (component Component) styles(spans []Span, size, ppi float64) []textStyle {
+	styles := make([]textStyle, len(spans))
+	for index, span := range spans {
+		ttf, bold, italic := component.Font, span.Bold, span.Italic
+		switch {
+		case span.Font != nil:
+			ttf = span.Font
+		case bold && italic && component.BoldItalicFont != nil:
+			ttf, bold, italic = component.BoldItalicFont, false, false
+		case bold && component.BoldFont != nil:
+			ttf, bold = component.BoldFont, false
+		case italic && component.ItalicFont != nil:
+			ttf, italic = component.ItalicFont, false
+		}
+		colour := cutils.FillColour(component.Colour, component.Gradient)
+		if span.Colour != nil {
+			colour = *span.Colour
+		}
+		styles[index] = textStyle{
+			face:          render.NewFontFace(ttf, &truetype.Options{Size: component.spanSize(span, size), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: ppi}),
+			colour:        colour,
+			fauxBold:      bold,
+			fauxItalic:    italic,
+			underline:     span.Underline,
+			strikethrough: span.Strikethrough,
+		}
+	}
+	return styles
+}
+
+// spanSize returns the size of the span in points when the component is drawn at the given size, keeping its size relative to the component.
+func (component Component) spanSize(span Span, size float64) float64 {
+	if span.Size == 0 {
+		return size
+	}
+	if component.Size == 0 {
+		return span.Size
+	}
+	return span.Size * size / component.Size
+}
+
+// smallestSize returns the size in points of the smallest span when the component is drawn at the given size.
+func (component Component) smallestSize(spans []Span, size float64) float64 {
+	smallest := size
+	for index, span := range spans {
+		if spanSize := component.spanSize(span, size); index == 0 || spanSize < smallest {
+			smallest = spanSize
+		}
+	}
+	return smallest
+}
+
+// sizeForSmallest returns the size to draw the component at for its smallest span to be the given size.
+func (component Component) sizeForSmallest(spans []Span, smallest float64) float64 {
+	unscaled := component.smallestSize(spans, component.Size)
+	if unscaled == component.Size || unscaled == 0 {
+		return smallest
+	}
+	return smallest * component.Size / unscaled
+}
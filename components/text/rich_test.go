@@ -0,0 +1,75 @@
+package text
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMarkup(t *testing.T) {
+	red := &color.NRGBA{R: 255, A: 255}
+	good, _ := fakeSysFonts{}.GetFont("good")
+	tests := []struct {
+		name    string
+		content string
+		spans   []Span
+		err     string
+	}{
+		{name: "plain", content: "hello world", spans: []Span{{Text: "hello world"}}},
+		{name: "empty", content: ""},
+		{name: "bold and italic", content: "a **b *c*** d", spans: []Span{{Text: "a "}, {Text: "b ", Bold: true}, {Text: "c", Bold: true, Italic: true}, {Text: " d"}}},
+		{name: "lines", content: "__a__~~b~~", spans: []Span{{Text: "a", Underline: true}, {Text: "b", Strikethrough: true}}},
+		{name: "escaped", content: `\*a\{b\\`, spans: []Span{{Text: `*a{b\`}}},
+		{name: "colour", content: "a{color=#f00}b{/color}c", spans: []Span{{Text: "a"}, {Text: "b", Colour: red}, {Text: "c"}}},
+		{name: "nested tags", content: "{size=12}a{colour=#ff0000}b{/colour}c{/size}", spans: []Span{{Text: "a", Size: 12}, {Text: "b", Size: 12, Colour: red}, {Text: "c", Size: 12}}},
+		{name: "font", content: "{font=good}a{/font}", spans: []Span{{Text: "a", Font: good}}},
+		{name: "bad font", content: "{font=bad}a{/font}", err: "bad font requested"},
+		{name: "bad size", content: "{size=big}a{/size}", err: "strconv.ParseFloat: parsing \"big\": invalid syntax"},
+		{name: "negative size", content: "{size=-2}a{/size}", err: "invalid size -2, expected a finite number of at least 0"},
+		{name: "NaN size", content: "{size=NaN}a{/size}", err: "invalid size NaN, expected a finite number of at least 0"},
+		{name: "infinite size", content: "{size=+Inf}a{/size}", err: "invalid size +Inf, expected a finite number of at least 0"},
+		{name: "zero size", content: "{size=0}a{/size}", spans: []Span{{Text: "a"}}},
+		{name: "unclosed tag", content: "a{size=12", err: "unclosed markup tag at offset 1"},
+		{name: "mismatched tag", content: "{size=12}a{/colour}", err: "unexpected markup tag {/colour}"},
+		{name: "unknown tag", content: "{weight=bold}a", err: "unknown markup tag {weight=bold}"},
+		{name: "tag without value", content: "{size}a", err: "unknown markup tag {size}"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spans, err := Component{fontPool: fakeSysFonts{}}.parseMarkup(test.content)
+			assert.Equal(t, test.spans, spans)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestParseHexColour(t *testing.T) {
+	tests := []struct {
+		hex    string
+		colour color.NRGBA
+		err    string
+	}{
+		{hex: "#f80", colour: color.NRGBA{R: 255, G: 136, A: 255}},
+		{hex: "#102030", colour: color.NRGBA{R: 16, G: 32, B: 48, A: 255}},
+		{hex: "#10203040", colour: color.NRGBA{R: 16, G: 32, B: 48, A: 64}},
+		{hex: "102030", err: "invalid colour 102030, expected #rgb, #rrggbb or #rrggbbaa"},
+		{hex: "#12345", err: "invalid colour #12345, expected #rgb, #rrggbb or #rrggbbaa"},
+		{hex: "#ggg", err: "invalid colour #ggg, expected #rgb, #rrggbb or #rrggbbaa"},
+	}
+	for _, test := range tests {
+		t.Run(test.hex, func(t *testing.T) {
+			colour, err := parseHexColour(test.hex)
+			assert.Equal(t, test.colour, colour)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@ package text
 
 import (
 	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
 
 	"github.com/LLKennedy/imagetemplate/v3/cutils"
 )
@@ -20,14 +23,33 @@ func (component *Component) delegatedSetProperties(name string, value interface{
 		component.Size, err = cutils.SetFloat64(value)
 	case "alignment":
 		err = component.setTextAlignment(value)
+	case "anchor":
+		component.Anchor, err = cutils.SetTextAnchor(value)
 	case "R", "G", "B", "A":
 		err = component.setColour(name, value)
 	case "startX", "startY":
 		err = component.setStart(name, value)
 	case "maxWidth":
 		component.MaxWidth, err = cutils.SetInt(value)
+	case "maxHeight":
+		component.MaxHeight, err = cutils.SetInt(value)
+	case "lineHeight":
+		component.LineHeight, err = cutils.SetFloat64(value)
+	case "overflow":
+		component.Overflow, err = cutils.SetTextOverflow(value)
+	case "minSize":
+		component.MinSize, err = cutils.SetFloat64(value)
+	case "markup":
+		component.Markup, err = cutils.SetBool(value)
 	default:
-		err = fmt.Errorf("invalid component property in named property map: %v", name)
+		// Anything else is either part of a span, part of the effects, part of the gradient or invalid
+		if strings.HasPrefix(name, "span") {
+			err = component.setSpanProperty(name, value)
+		} else if cutils.IsTextEffectsProperty(name) {
+			err = cutils.SetTextEffectsProperty(&component.Effects, name, value)
+		} else {
+			err = cutils.SetGradientProperty(&component.Gradient, "gradient", name, value)
+		}
 	}
 	return
 }
@@ -104,3 +126,54 @@ func (component *Component) setStart(name string, value interface{}) (err error)
 	component.Start.Y, err = cutils.SetInt(value)
 	return err
 }
+
+func (component *Component) setSpanProperty(name string, value interface{}) (err error) {
+	invalid := fmt.Errorf("invalid component property in named property map: %v", name)
+	property := strings.TrimPrefix(name, "span")
+	digits := strings.IndexFunc(property, func(r rune) bool { return r < '0' || r > '9' })
+	if digits <= 0 {
+		return invalid
+	}
+	i, convErr := strconv.Atoi(property[:digits])
+	if convErr != nil || i >= len(component.Spans) {
+		return invalid
+	}
+	// Components are copied before their properties are set, so the spans must not be shared with the original
+	component.Spans = append([]Span(nil), component.Spans...)
+	span := &component.Spans[i]
+	switch property[digits:] {
+	case "Text":
+		span.Text, err = cutils.SetString(value)
+	case "Size":
+		span.Size, err = cutils.SetFloat64(value)
+	case "Bold":
+		span.Bold, err = cutils.SetBool(value)
+	case "Italic":
+		span.Italic, err = cutils.SetBool(value)
+	case "Underline":
+		span.Underline, err = cutils.SetBool(value)
+	case "Strikethrough":
+		span.Strikethrough, err = cutils.SetBool(value)
+	case "R", "G", "B", "A":
+		var colour color.NRGBA
+		if span.Colour != nil {
+			colour = *span.Colour
+		}
+		var channel uint8
+		channel, err = cutils.SetUint8(value)
+		switch property[digits:] {
+		case "R":
+			colour.R = channel
+		case "G":
+			colour.G = channel
+		case "B":
+			colour.B = channel
+		default:
+			colour.A = channel
+		}
+		span.Colour = &colour
+	default:
+		err = invalid
+	}
+	return err
+}
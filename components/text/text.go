@@ -1,4 +1,4 @@
-// Package text is a simple text component with customisable content, size, colour, location and font.
+// Package text is a simple text component with customisable content, size, colour or gradient, location and font.
 package text
 
 import (
@@ -29,18 +29,47 @@ type Component struct {
 	NamedPropertiesMap map[string][]string
 	// Content is the text to render.
 	Content string
-	// Start is the coordinates of the dot relative to the top-left corner of the canvas.
+	// Markup is whether Content is written in the small markup described in the Text documentation, which splits it into spans of different styles.
+	Markup bool
+	// Spans are runs of text in their own styles, drawn one after the other in place of Content.
+	Spans []Span
+	// Start is the coordinates of the anchor of the text relative to the top-left corner of the canvas, which by default is the dot.
 	Start image.Point
 	// Size is the size of the text in points.
 	Size float64
 	// MaxWidth is the maximum number of horizontal pixels the dot can move before scaling text.
 	MaxWidth int
-	// cutils.TextAlignment aligns text to the left, right or centre.
+	/*
+		MaxHeight is the height of the text box in pixels. If it is set, the text is wrapped
+		onto as many lines as fit within MaxWidth and MaxHeight, with Start as the top-left
+		corner of the box unless Anchor places it elsewhere. Otherwise the text is drawn on a
+		single line.
+	*/
+	MaxHeight int
+	// LineHeight is the spacing between the baselines of wrapped lines as a multiple of the natural line spacing of the font, where zero is the same as 1.
+	LineHeight float64
+	// Overflow is how text too big for MaxWidth, or for MaxHeight when wrapped, is fitted, where empty is the same as cutils.OverflowScale.
+	Overflow cutils.TextOverflow
+	// MinSize is the smallest size in points the text can be scaled down to, or zero for no limit.
+	MinSize float64
+	// cutils.TextAlignment aligns text to the left, right, centre or, when wrapped, justified.
 	TextAlignment cutils.TextAlignment
+	/*
+		Anchor is the point of the text placed at Start, measured from the font at the size the
+		text is finally drawn. The zero value places the baseline of a single line, or the top of
+		a text box, at Start, with MaxWidth stretching to the right of it.
+	*/
+	Anchor cutils.TextAnchor
 	// Font is the typeface to use.
 	Font *truetype.Font
+	// BoldFont, ItalicFont and BoldItalicFont are the typefaces for bold and italic spans, which are imitated with Font if they are nil.
+	BoldFont, ItalicFont, BoldItalicFont *truetype.Font
+	// Effects are the stroke, shadow and background drawn with the text.
+	Effects cutils.TextEffects
 	// Colour is the colour of the text.
 	Colour color.NRGBA
+	// Gradient fills the text instead of Colour if it has any colour stops.
+	Gradient render.Gradient
 	// fs is the file system.
 	fs vfs.FileSystem
 	// fontPool is the pool of available fonts.
@@ -48,23 +77,37 @@ type Component struct {
 }
 
 type textFormat struct {
-	Content       string `json:"content"`
-	StartX        string `json:"startX"`
-	StartY        string `json:"startY"`
-	Size          string `json:"size"`
-	MaxWidth      string `json:"maxWidth"`
-	TextAlignment string `json:"alignment"`
+	Content       string       `json:"content"`
+	Markup        string       `json:"markup"`
+	Spans         []spanFormat `json:"spans"`
+	StartX        string       `json:"startX"`
+	StartY        string       `json:"startY"`
+	Size          string       `json:"size"`
+	MaxWidth      string       `json:"maxWidth"`
+	MaxHeight     string       `json:"maxHeight"`
+	LineHeight    string       `json:"lineHeight"`
+	Overflow      string       `json:"overflow"`
+	MinSize       string       `json:"minSize"`
+	TextAlignment string       `json:"alignment"`
+	Anchor        string       `json:"anchor"`
 	Font          struct {
 		FontName string `json:"fontName"`
 		FontFile string `json:"fontFile"`
 		FontURL  string `json:"fontURL"`
 	} `json:"font"`
-	Colour struct {
+	BoldFont       *fontFormat `json:"boldFont"`
+	ItalicFont     *fontFormat `json:"italicFont"`
+	BoldItalicFont *fontFormat `json:"boldItalicFont"`
+	Colour         struct {
 		Red   string `json:"R"`
 		Green string `json:"G"`
 		Blue  string `json:"B"`
 		Alpha string `json:"A"`
 	} `json:"colour"`
+	Gradient   *cutils.GradientStrings       `json:"gradient"`
+	Stroke     *cutils.TextStrokeStrings     `json:"stroke"`
+	Shadow     *cutils.TextShadowStrings     `json:"shadow"`
+	Background *cutils.TextBackgroundStrings `json:"background"`
 }
 
 // Write draws text on the canvas.
@@ -76,22 +119,39 @@ func (component Component) Write(canvas render.Canvas) (c render.Canvas, err err
 			err = fmt.Errorf("failed to write to canvas: %v\n%s", p, debug.Stack())
 		}
 	}()
+	if component.MaxHeight > 0 || component.isRich() {
+		return component.writeLayout(canvas)
+	}
+	if component.Overflow != "" && component.Overflow != cutils.OverflowScale {
+		face := render.NewFontFace(component.Font, &truetype.Options{Size: component.Size, Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: canvas.GetPPI()})
+		return cutils.WriteOverflowingText(canvas, component.Content, component.Anchor.LineStart(component.Start, face, component.MaxWidth), face, cutils.FillColour(component.Colour, component.Gradient), component.MaxWidth, component.TextAlignment, component.Overflow, component.Effects)
+	}
 	fontSize := component.Size
 	fits := false
 	tries := 0
 	var face font.Face
-	var alignmentOffset int
+	var alignmentOffset, realWidth int
 	for !fits && tries < 10 {
 		tries++
-		face = truetype.NewFace(component.Font, &truetype.Options{Size: fontSize, Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: canvas.GetPPI()})
-		var realWidth int
+		face = render.NewFontFace(component.Font, &truetype.Options{Size: fontSize, Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: canvas.GetPPI()})
 		fits, realWidth = c.TryText(component.Content, component.Start, font.Face(face), component.Colour, component.MaxWidth)
+		previousSize := fontSize
 		fontSize, alignmentOffset = cutils.ScaleFontsToWidth(fontSize, realWidth, component.MaxWidth, component.TextAlignment)
+		if !fits && fontSize < component.MinSize {
+			if previousSize <= component.MinSize {
+				return canvas, fmt.Errorf("unable to fit text %v into maxWidth %d without going below the minimum size %v", component.Content, component.MaxWidth, component.MinSize)
+			}
+			fontSize = component.MinSize
+		}
 	}
 	if !fits {
 		return canvas, fmt.Errorf("unable to fit text %v into maxWidth %d after %d tries", component.Content, component.MaxWidth, tries)
 	}
-	c, err = c.Text(component.Content, image.Pt(component.Start.X+alignmentOffset, component.Start.Y), font.Face(face), component.Colour, component.MaxWidth)
+	start := component.Anchor.LineStart(component.Start, face, component.MaxWidth)
+	start.X += alignmentOffset
+	c, err = component.Effects.Write(c, cutils.TextBounds(start, realWidth, face), func(c render.Canvas, outline bool) (render.Canvas, error) {
+		return component.Effects.Text(c, outline, component.Content, start, face, cutils.FillColour(component.Colour, component.Gradient), component.MaxWidth)
+	})
 	if err != nil {
 		return canvas, err
 	}
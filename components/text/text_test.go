@@ -1,10 +1,12 @@
 package text
 
 import (
+	"bytes"
 	"fmt"
 	"image"
 	"image/color"
 	"runtime/debug"
+	"strings"
 	"testing"
 
 	"golang.org/x/image/font"
@@ -16,6 +18,7 @@ import (
 	"github.com/LLKennedy/imagetemplate/v3/render"
 	"github.com/golang/freetype/truetype"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestTextWrite(t *testing.T) {
@@ -32,7 +35,7 @@ func TestTextWrite(t *testing.T) {
 		canvas.AssertExpectations(t)
 	})
 	t.Run("text error", func(t *testing.T) {
-		expectedFont := truetype.NewFace(goreg, &truetype.Options{Size: 14, Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		expectedFont := render.NewFontFace(goreg, &truetype.Options{Size: 14, Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
 		canvas := new(render.MockCanvas)
 		canvas.On("GetPPI").Return(float64(72))
 		canvas.On("TryText", "", image.Point{}, expectedFont, color.NRGBA{}, 100).Return(true, 10)
@@ -44,9 +47,9 @@ func TestTextWrite(t *testing.T) {
 		canvas.AssertExpectations(t)
 	})
 	t.Run("multiple passes required", func(t *testing.T) {
-		expectedFont := truetype.NewFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
-		expectedFont2 := truetype.NewFace(goreg, &truetype.Options{Size: float64(12), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
-		expectedFont3 := truetype.NewFace(goreg, &truetype.Options{Size: float64(8), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		expectedFont := render.NewFontFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		expectedFont2 := render.NewFontFace(goreg, &truetype.Options{Size: float64(12), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		expectedFont3 := render.NewFontFace(goreg, &truetype.Options{Size: float64(8), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
 		canvas := new(render.MockCanvas)
 		canvas.On("GetPPI").Return(float64(72))
 		canvas.On("TryText", "", image.Point{}, expectedFont, color.NRGBA{}, 100).Return(false, 200)
@@ -59,8 +62,21 @@ func TestTextWrite(t *testing.T) {
 		assert.NoError(t, err)
 		canvas.AssertExpectations(t)
 	})
+	t.Run("gradient", func(t *testing.T) {
+		expectedFont := render.NewFontFace(goreg, &truetype.Options{Size: 14, Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		gradient := render.Gradient{Angle: 90, Stops: []render.GradientStop{{Offset: 0, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 1, Colour: color.NRGBA{B: 255, A: 255}}}}
+		canvas := new(render.MockCanvas)
+		canvas.On("GetPPI").Return(float64(72))
+		canvas.On("TryText", "hello", image.Point{}, expectedFont, color.NRGBA{}, 100).Return(true, 10)
+		canvas.On("Text", "hello", image.Point{}, expectedFont, gradient, 100).Return(canvas, nil)
+		c := Component{Content: "hello", Font: goreg, Size: 14, MaxWidth: 100, Gradient: gradient}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.NoError(t, err)
+		canvas.AssertExpectations(t)
+	})
 	t.Run("can't ever fit", func(t *testing.T) {
-		expectedFont := truetype.NewFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		expectedFont := render.NewFontFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
 		canvas := new(render.MockCanvas)
 		canvas.On("GetPPI").Return(float64(72))
 		canvas.On("TryText", "", image.Point{}, expectedFont, color.NRGBA{}, 100).Return(false, 100)
@@ -70,8 +86,46 @@ func TestTextWrite(t *testing.T) {
 		assert.EqualError(t, err, "unable to fit text  into maxWidth 100 after 10 tries")
 		canvas.AssertExpectations(t)
 	})
+	t.Run("minimum size", func(t *testing.T) {
+		expectedFont := render.NewFontFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		expectedFont2 := render.NewFontFace(goreg, &truetype.Options{Size: float64(16), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		t.Run("reached", func(t *testing.T) {
+			canvas := new(render.MockCanvas)
+			canvas.On("GetPPI").Return(float64(72))
+			canvas.On("TryText", "hello", image.Point{}, expectedFont, color.NRGBA{}, 100).Return(false, 200)
+			canvas.On("TryText", "hello", image.Point{}, expectedFont2, color.NRGBA{}, 100).Return(true, 100)
+			canvas.On("Text", "hello", image.Point{}, expectedFont2, color.NRGBA{}, 100).Return(canvas, nil)
+			c := Component{Content: "hello", Font: goreg, Size: 24, MaxWidth: 100, MinSize: 16}
+			modifiedCanvas, err := c.Write(canvas)
+			assert.Equal(t, canvas, modifiedCanvas)
+			assert.NoError(t, err)
+			canvas.AssertExpectations(t)
+		})
+		t.Run("too small", func(t *testing.T) {
+			canvas := new(render.MockCanvas)
+			canvas.On("GetPPI").Return(float64(72))
+			canvas.On("TryText", "hello", image.Point{}, expectedFont, color.NRGBA{}, 100).Return(false, 200)
+			canvas.On("TryText", "hello", image.Point{}, expectedFont2, color.NRGBA{}, 100).Return(false, 150)
+			c := Component{Content: "hello", Font: goreg, Size: 24, MaxWidth: 100, MinSize: 16}
+			modifiedCanvas, err := c.Write(canvas)
+			assert.Equal(t, canvas, modifiedCanvas)
+			assert.EqualError(t, err, "unable to fit text hello into maxWidth 100 without going below the minimum size 16")
+			canvas.AssertExpectations(t)
+		})
+	})
+	t.Run("overflow", func(t *testing.T) {
+		expectedFont := render.NewFontFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		canvas := new(render.MockCanvas)
+		canvas.On("GetPPI").Return(float64(72))
+		canvas.On("TryText", "hello", image.Point{}, expectedFont, color.NRGBA{}, 100).Return(false, 200)
+		c := Component{Content: "hello", Font: goreg, Size: 24, MaxWidth: 100, Overflow: cutils.OverflowError}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "text hello is wider than maxWidth 100")
+		canvas.AssertExpectations(t)
+	})
 	t.Run("different alignments", func(t *testing.T) {
-		expectedFont := truetype.NewFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
+		expectedFont := render.NewFontFace(goreg, &truetype.Options{Size: float64(24), Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: float64(72)})
 		canvas := new(render.MockCanvas)
 		canvas.On("GetPPI").Return(float64(72))
 		canvas.On("TryText", "", image.Point{}, expectedFont, color.NRGBA{}, 100).Return(true, 50)
@@ -104,6 +158,243 @@ func TestTextWrite(t *testing.T) {
 		})
 		canvas.AssertExpectations(t)
 	})
+	// Measuring text fills the glyph cache of a face, so faces are matched by their options.
+	sizeTen := mock.MatchedBy(func(face render.FontFace) bool { return face.Font == goreg && face.Options.Size == 10 })
+	t.Run("text box", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			content   string
+			alignment cutils.TextAlignment
+			calls     map[string]image.Point
+		}{
+			{name: "left", content: "the quick brown fox", calls: map[string]image.Point{"the quick": image.Pt(5, 12), "brown fox": image.Pt(5, 22)}},
+			{name: "right", content: "the quick brown fox", alignment: cutils.TextAlignmentRight, calls: map[string]image.Point{"the quick": image.Pt(13, 12), "brown fox": image.Pt(10, 22)}},
+			{name: "centre", content: "the quick brown fox", alignment: cutils.TextAlignmentCentre, calls: map[string]image.Point{"the quick": image.Pt(9, 12), "brown fox": image.Pt(7, 22)}},
+			{name: "justify", content: "the quick brown fox", alignment: cutils.TextAlignmentJustify, calls: map[string]image.Point{"the": image.Pt(5, 12), "quick": image.Pt(31, 12), "brown fox": image.Pt(5, 22)}},
+			{name: "newlines", content: "the\r\n\n  fox ", calls: map[string]image.Point{"the": image.Pt(5, 12), "fox": image.Pt(5, 32)}},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				canvas := new(render.MockCanvas)
+				canvas.On("GetPPI").Return(float64(72))
+				for text, start := range test.calls {
+					canvas.On("Text", text, start, sizeTen, color.NRGBA{}, 50).Return(canvas, nil).Once()
+				}
+				c := Component{Content: test.content, Start: image.Pt(5, 2), Font: goreg, Size: 10, MaxWidth: 50, MaxHeight: 40, TextAlignment: test.alignment}
+				modifiedCanvas, err := c.Write(canvas)
+				assert.Equal(t, canvas, modifiedCanvas)
+				assert.NoError(t, err)
+				canvas.AssertExpectations(t)
+			})
+		}
+	})
+	t.Run("text box line height", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		canvas.On("GetPPI").Return(float64(72))
+		canvas.On("Text", "the quick", image.Pt(0, 10), sizeTen, color.NRGBA{}, 50).Return(canvas, nil)
+		canvas.On("Text", "brown fox", image.Pt(0, 25), sizeTen, color.NRGBA{}, 50).Return(canvas, nil)
+		c := Component{Content: "the quick brown fox", Font: goreg, Size: 10, MaxWidth: 50, MaxHeight: 40, LineHeight: 1.5}
+		_, err := c.Write(canvas)
+		assert.NoError(t, err)
+		canvas.AssertExpectations(t)
+	})
+	t.Run("text box shrinks to fit", func(t *testing.T) {
+		canvas, _ := render.NewCanvas(50, 15)
+		c := Component{Content: "the quick brown fox", Font: goreg, Size: 10, MaxWidth: 50, MaxHeight: 15, Colour: color.NRGBA{A: 255}}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage().(*image.NRGBA)
+		inked := false
+		for index := 3; index < len(img.Pix); index += 4 {
+			inked = inked || img.Pix[index] > 0
+		}
+		assert.True(t, inked, "the text should be drawn at a smaller size")
+	})
+	t.Run("text box overflow", func(t *testing.T) {
+		write := func(t *testing.T, overflow cutils.TextOverflow, minSize float64) (string, error) {
+			canvas, _ := render.NewSVGCanvas(60, 30)
+			c := Component{Content: "the quick brown fox jumps", Font: goreg, Size: 10, MaxWidth: 50, MaxHeight: 22, Overflow: overflow, MinSize: minSize}
+			modifiedCanvas, err := c.Write(canvas)
+			var buf bytes.Buffer
+			modifiedCanvas.(render.SVGCanvas).WriteSVG(&buf)
+			return buf.String(), err
+		}
+		t.Run("ellipsis", func(t *testing.T) {
+			svg, err := write(t, cutils.OverflowEllipsis, 0)
+			assert.NoError(t, err)
+			assert.Contains(t, svg, `y="10" font-family="Go" font-size="10" fill="#000000" fill-opacity="0" xml:space="preserve">the quick</text>`)
+			assert.Contains(t, svg, `xml:space="preserve">brown fo…</text>`)
+			assert.NotContains(t, svg, "jumps")
+		})
+		t.Run("justified ellipsis", func(t *testing.T) {
+			canvas, _ := render.NewSVGCanvas(60, 30)
+			c := Component{Content: "the quick brown fox jumps", Font: goreg, Size: 10, MaxWidth: 50, MaxHeight: 22, Overflow: cutils.OverflowEllipsis, TextAlignment: cutils.TextAlignmentJustify}
+			modifiedCanvas, err := c.Write(canvas)
+			assert.NoError(t, err)
+			var buf bytes.Buffer
+			modifiedCanvas.(render.SVGCanvas).WriteSVG(&buf)
+			svg := buf.String()
+			assert.Contains(t, svg, `<text x="0" y="20" font-family="Go" font-size="10" fill="#000000" fill-opacity="0" xml:space="preserve">brown</text>`)
+			assert.Contains(t, svg, `<text x="31" y="20" font-family="Go" font-size="10" fill="#000000" fill-opacity="0" xml:space="preserve">fo…</text>`, "the cut short line should still be justified, as more of the paragraph follows it")
+		})
+		t.Run("clip", func(t *testing.T) {
+			svg, err := write(t, cutils.OverflowClip, 0)
+			assert.NoError(t, err)
+			assert.Contains(t, svg, `<clipPath id="clip1">`)
+			assert.Contains(t, svg, `y="30" font-family="Go" font-size="10" fill="#000000" fill-opacity="0" xml:space="preserve">jumps</text>`)
+		})
+		t.Run("error", func(t *testing.T) {
+			_, err := write(t, cutils.OverflowError, 0)
+			assert.EqualError(t, err, "text the quick brown fox jumps does not fit into a 50x22 box")
+		})
+		t.Run("minimum size", func(t *testing.T) {
+			_, err := write(t, cutils.OverflowScale, 9.5)
+			assert.EqualError(t, err, "unable to fit text the quick brown fox jumps into a 50x22 box without going below the minimum size 9.5")
+		})
+	})
+	t.Run("text box error", func(t *testing.T) {
+		canvas := new(render.MockCanvas)
+		canvas.On("GetPPI").Return(float64(72))
+		canvas.On("Text", "the", image.Pt(0, 10), sizeTen, color.NRGBA{}, 50).Return(canvas, fmt.Errorf("some error"))
+		c := Component{Content: "the quick brown fox", Font: goreg, Size: 10, MaxWidth: 50, MaxHeight: 40, TextAlignment: cutils.TextAlignmentJustify}
+		modifiedCanvas, err := c.Write(canvas)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "some error")
+	})
+	t.Run("rich text", func(t *testing.T) {
+		write := func(t *testing.T, c Component) (string, error) {
+			canvas, _ := render.NewSVGCanvas(200, 60)
+			c.Start, c.Font, c.Size = image.Pt(5, 20), goreg, 10
+			modifiedCanvas, err := c.Write(canvas)
+			var buf bytes.Buffer
+			modifiedCanvas.(render.SVGCanvas).WriteSVG(&buf)
+			return buf.String(), err
+		}
+		t.Run("markup", func(t *testing.T) {
+			svg, err := write(t, Component{Content: "the **quick** {colour=#f00}fox{/colour}", Markup: true, MaxWidth: 80})
+			assert.NoError(t, err)
+			assert.Contains(t, svg, `<text x="5" y="20" font-family="Go" font-size="10" fill="#000000" fill-opacity="0" xml:space="preserve">the </text>`)
+			assert.Contains(t, svg, `<text x="23" y="20" font-family="Go" font-size="10" fill="#000000" fill-opacity="0" xml:space="preserve">quick</text>`)
+			assert.Contains(t, svg, `<text x="24" y="20" font-family="Go" font-size="10" fill="#000000" fill-opacity="0" xml:space="preserve">quick</text>`, "bold should be imitated without a bold typeface")
+			assert.Contains(t, svg, `<text x="50" y="20" font-family="Go" font-size="10" fill="#ff0000" xml:space="preserve">fox</text>`)
+		})
+		t.Run("bold typeface", func(t *testing.T) {
+			svg, err := write(t, Component{Spans: []Span{{Text: "the "}, {Text: "quick", Bold: true}}, BoldFont: goreg, MaxWidth: 80})
+			assert.NoError(t, err)
+			assert.Equal(t, 1, strings.Count(svg, ">quick</text>"))
+		})
+		t.Run("italic and lines", func(t *testing.T) {
+			svg, err := write(t, Component{Content: "the *quick* __brown__ ~~fox~~", Markup: true, MaxWidth: 200})
+			assert.NoError(t, err)
+			assert.Contains(t, svg, "<g transform=\"matrix(1 0 -0.2126 1 4.2511 0)\">\n<text x=\"23\" y=\"20\"")
+			assert.Contains(t, svg, `<rect x="50" y="21" width="28" height="1"`, "brown should be underlined")
+			assert.Contains(t, svg, `<rect x="81" y="17" width="14" height="1"`, "fox should be struck through")
+		})
+		t.Run("wrapped spans", func(t *testing.T) {
+			svg, err := write(t, Component{Spans: []Span{{Text: "the quick "}, {Text: "brown", Size: 20}, {Text: " fox"}}, MaxWidth: 60, MaxHeight: 60})
+			assert.NoError(t, err)
+			assert.Contains(t, svg, `<text x="5" y="30" font-family="Go" font-size="10" fill="#000000" fill-opacity="0" xml:space="preserve">the quick</text>`)
+			assert.Contains(t, svg, `<text x="5" y="50" font-family="Go" font-size="20" fill="#000000" fill-opacity="0" xml:space="preserve">brown</text>`)
+			assert.Contains(t, svg, `<text x="5" y="70" font-family="Go" font-size="10" fill="#000000" fill-opacity="0" xml:space="preserve">fox</text>`, "lines should be spaced by the taller of the two")
+		})
+		t.Run("shrinks to fit", func(t *testing.T) {
+			svg, err := write(t, Component{Content: "the **quick** brown fox", Markup: true, MaxWidth: 50})
+			assert.NoError(t, err)
+			assert.Contains(t, svg, `y="20" font-family="Go" font-size="5.5556" fill="#000000" fill-opacity="0" xml:space="preserve">the </text>`)
+		})
+		t.Run("minimum size", func(t *testing.T) {
+			_, err := write(t, Component{Spans: []Span{{Text: "the quick "}, {Text: "brown fox", Size: 8}}, MaxWidth: 50, MinSize: 6})
+			assert.EqualError(t, err, "unable to fit text the quick brown fox into maxWidth 50 without going below the minimum size 6")
+		})
+		t.Run("ellipsis", func(t *testing.T) {
+			svg, err := write(t, Component{Content: "the **quick** brown fox", Markup: true, MaxWidth: 50, Overflow: cutils.OverflowEllipsis})
+			assert.NoError(t, err)
+			assert.Contains(t, svg, `xml:space="preserve">quic…</text>`)
+			assert.NotContains(t, svg, "brown")
+		})
+		t.Run("error", func(t *testing.T) {
+			_, err := write(t, Component{Content: "the **quick** brown fox", Markup: true, MaxWidth: 50, Overflow: cutils.OverflowError})
+			assert.EqualError(t, err, "text the quick brown fox is wider than maxWidth 50")
+		})
+		t.Run("invalid markup", func(t *testing.T) {
+			_, err := write(t, Component{Content: "the {colour=red}fox{/colour}", Markup: true, MaxWidth: 50})
+			assert.EqualError(t, err, "invalid colour red, expected #rgb, #rrggbb or #rrggbbaa")
+		})
+	})
+	t.Run("effects", func(t *testing.T) {
+		effects := cutils.TextEffects{
+			Stroke:     &cutils.TextStroke{Width: 1, Colour: color.NRGBA{R: 255, A: 255}},
+			Shadow:     &render.Shadow{Offset: image.Pt(1, 1), Colour: color.NRGBA{A: 128}},
+			Background: &cutils.TextBackground{Colour: color.NRGBA{B: 255, A: 255}, Padding: 2},
+		}
+		write := func(t *testing.T, c Component) (string, error) {
+			canvas, _ := render.NewSVGCanvas(200, 60)
+			c.Start, c.Font, c.Size, c.Colour, c.Effects = image.Pt(5, 20), goreg, 10, color.NRGBA{A: 255}, effects
+			modifiedCanvas, err := c.Write(canvas)
+			var buf bytes.Buffer
+			modifiedCanvas.(render.SVGCanvas).WriteSVG(&buf)
+			return buf.String(), err
+		}
+		t.Run("single line", func(t *testing.T) {
+			svg, err := write(t, Component{Content: "the fox", MaxWidth: 100, TextAlignment: cutils.TextAlignmentRight})
+			assert.NoError(t, err)
+			assert.Regexp(t, `(?s)<rect x="(\d+)" y="7" width="(\d+)" height="19" fill="#0000ff"/>.*<g filter="url\(#filter1\)">\s*<path d="[^"]+" fill="none" stroke="#ff0000" stroke-width="2"[^>]*/>\s*<text x="\d+" y="20"[^>]*>the fox</text>\s*</g>`, svg)
+			assert.Contains(t, svg, `<rect x="70" y="7" width="38" height="19" fill="#0000ff"/>`, "the background should fit the text, its stroke and its padding")
+		})
+		t.Run("clipped", func(t *testing.T) {
+			svg, err := write(t, Component{Content: "the quick brown fox", MaxWidth: 50, Overflow: cutils.OverflowClip})
+			assert.NoError(t, err)
+			assert.Contains(t, svg, `<rect x="2" y="7" width="56" height="19" fill="#0000ff"/>`, "the background should stop at the clip")
+			assert.Equal(t, 2, strings.Count(svg, `clip-path=`), "both the outline and the text should be clipped")
+		})
+		t.Run("text box", func(t *testing.T) {
+			svg, err := write(t, Component{Content: "the __quick__ brown fox", Markup: true, MaxWidth: 50, MaxHeight: 40})
+			assert.NoError(t, err)
+			assert.Contains(t, svg, `<rect x="2" y="17" width="51" height="29" fill="#0000ff"/>`, "the background should start at the top of the box")
+			assert.Equal(t, 3, strings.Count(svg, `stroke="#ff0000"`), "each run should be outlined")
+			assert.Equal(t, 1, strings.Count(svg, `height="1" fill="#000000"`), "the underline should only be drawn once")
+		})
+	})
+	t.Run("anchor", func(t *testing.T) {
+		write := func(t *testing.T, c Component) string {
+			canvas, _ := render.NewSVGCanvas(200, 60)
+			c.Start, c.Font, c.Size, c.MaxWidth, c.TextAlignment = image.Pt(100, 30), goreg, 10, 80, cutils.TextAlignmentCentre
+			modifiedCanvas, err := c.Write(canvas)
+			assert.NoError(t, err)
+			var buf bytes.Buffer
+			modifiedCanvas.(render.SVGCanvas).WriteSVG(&buf)
+			return buf.String()
+		}
+		middle := cutils.TextAnchor{Vertical: cutils.AnchorMiddle, Horizontal: cutils.TextAlignmentCentre}
+		t.Run("default", func(t *testing.T) {
+			assert.Contains(t, write(t, Component{Content: "hi"}), `<text x="136" y="30"`)
+		})
+		t.Run("middle centre", func(t *testing.T) {
+			assert.Contains(t, write(t, Component{Content: "hi", Anchor: middle}), `<text x="96" y="34"`)
+		})
+		t.Run("scaled", func(t *testing.T) {
+			assert.Contains(t, write(t, Component{Content: "the quick brown fox jumps", Anchor: middle}), `<text x="60" y="32"`, "smaller text should move down to stay centred")
+		})
+		t.Run("top right", func(t *testing.T) {
+			assert.Contains(t, write(t, Component{Content: "hi", Anchor: cutils.TextAnchor{Vertical: cutils.AnchorTop, Horizontal: cutils.TextAlignmentRight}}), `<text x="56" y="40"`)
+		})
+		t.Run("overflow", func(t *testing.T) {
+			assert.Contains(t, write(t, Component{Content: "the quick brown fox jumps", Anchor: middle, Overflow: cutils.OverflowEllipsis}), `<text x="61" y="34"`)
+		})
+		t.Run("rich text", func(t *testing.T) {
+			assert.Contains(t, write(t, Component{Content: "**hi**", Markup: true, Anchor: middle}), `<text x="96" y="34"`)
+		})
+		t.Run("text box", func(t *testing.T) {
+			svg := write(t, Component{Content: "the quick brown fox", MaxHeight: 40, Anchor: cutils.TextAnchor{Vertical: cutils.AnchorBottom, Horizontal: cutils.TextAlignmentCentre}})
+			assert.Contains(t, svg, `<text x="63" y="18" font-family="Go" font-size="10" fill="#000000" fill-opacity="0" xml:space="preserve">the quick brown</text>`)
+			assert.Contains(t, svg, `<text x="93" y="28" font-family="Go" font-size="10" fill="#000000" fill-opacity="0" xml:space="preserve">fox</text>`, "the text should sit at the bottom of the box")
+		})
+		t.Run("clipped text box", func(t *testing.T) {
+			svg := write(t, Component{Content: "the quick brown fox", MaxHeight: 12, Overflow: cutils.OverflowClip, Anchor: middle})
+			assert.Contains(t, svg, `<path d="M 60 24 L 140 24 L 140 36 L 60 36 L 60 24 Z"/>`, "the box should be centred on the start")
+			assert.Contains(t, svg, `<text x="63" y="29"`, "the overflowing text should be centred on the start too")
+		})
+	})
 }
 
 type fakeSysFonts struct{}
@@ -560,6 +851,128 @@ func TestTextSetNamedProperties(t *testing.T) {
 			},
 			err: "",
 		},
+		{
+			name: "text box",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"height":  {"maxHeight"},
+					"spacing": {"lineHeight"},
+					"mode":    {"overflow"},
+					"min":     {"minSize"},
+				},
+			},
+			input: render.NamedProperties{
+				"height":  40,
+				"spacing": 1.25,
+				"mode":    "ellipsis",
+				"min":     8.0,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				MaxHeight:          40,
+				LineHeight:         1.25,
+				Overflow:           cutils.OverflowEllipsis,
+				MinSize:            8,
+			},
+			err: "",
+		},
+		{
+			name: "rich text",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"styled": {"markup"},
+					"name":   {"span1Text"},
+					"loud":   {"span1Bold", "span1Underline"},
+					"red":    {"span1R", "span1A"},
+					"big":    {"span1Size"},
+				},
+				Spans: []Span{{Text: "hello "}, {}},
+			},
+			input: render.NamedProperties{
+				"styled": true,
+				"name":   "world",
+				"loud":   true,
+				"red":    uint8(255),
+				"big":    float64(12),
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Markup:             true,
+				Spans:              []Span{{Text: "hello "}, {Text: "world", Size: 12, Colour: &color.NRGBA{R: 255, A: 255}, Bold: true, Underline: true}},
+			},
+			err: "",
+		},
+		{
+			name: "invalid span property",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"span3Text"},
+				},
+				Spans: []Span{{Text: "hello"}},
+			},
+			input: render.NamedProperties{
+				"aProp": "world",
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"aProp": {"span3Text"},
+				},
+				Spans: []Span{{Text: "hello"}},
+			},
+			err: "invalid component property in named property map: span3Text",
+		},
+		{
+			name: "anchor",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"place": {"anchor"},
+				},
+			},
+			input: render.NamedProperties{
+				"place": "middle-centre",
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Anchor:             cutils.TextAnchor{Vertical: cutils.AnchorMiddle, Horizontal: cutils.TextAlignmentCentre},
+			},
+			err: "",
+		},
+		{
+			name: "invalid anchor",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"place": {"anchor"},
+				},
+			},
+			input: render.NamedProperties{
+				"place": "centre-middle-left",
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{
+					"place": {"anchor"},
+				},
+			},
+			err: "anchor centre-middle-left does not match defined constants",
+		},
+		{
+			name: "effects",
+			start: Component{
+				NamedPropertiesMap: map[string][]string{
+					"outline": {"strokeR", "strokeA"},
+					"padding": {"backgroundPadding"},
+				},
+				Effects: cutils.TextEffects{Stroke: &cutils.TextStroke{Width: 1}, Background: &cutils.TextBackground{}},
+			},
+			input: render.NamedProperties{
+				"outline": uint8(255),
+				"padding": 4,
+			},
+			res: Component{
+				NamedPropertiesMap: map[string][]string{},
+				Effects:            cutils.TextEffects{Stroke: &cutils.TextStroke{Width: 1, Colour: color.NRGBA{R: 255, A: 255}}, Background: &cutils.TextBackground{Padding: 4}},
+			},
+			err: "",
+		},
 		{
 			name: "invalid maxWidth type",
 			start: Component{
@@ -1513,6 +1926,362 @@ func TestTextVerifyAndTestTextJSONData(t *testing.T) {
 			props: render.NamedProperties{},
 			err:   "error parsing data for property A: could not parse empty property",
 		},
+		{
+			name: "effects",
+			start: Component{
+				fs: ttfFS,
+			},
+			input: &textFormat{
+				Font: struct {
+					FontName string `json:"fontName"`
+					FontFile string `json:"fontFile"`
+					FontURL  string `json:"fontURL"`
+				}{
+					FontFile: "myFont.ttf",
+				},
+				Content:       "hello",
+				StartX:        "123",
+				StartY:        "45",
+				MaxWidth:      "67",
+				Size:          "89",
+				TextAlignment: "left",
+				Colour: struct {
+					Red   string `json:"R"`
+					Green string `json:"G"`
+					Blue  string `json:"B"`
+					Alpha string `json:"A"`
+				}{
+					Red:   "255",
+					Green: "255",
+					Blue:  "255",
+					Alpha: "255",
+				},
+				Stroke:     &cutils.TextStrokeStrings{Width: "$outline$", Colour: cutils.ColourStrings{R: "0", G: "0", B: "0", A: "255"}},
+				Background: &cutils.TextBackgroundStrings{Colour: cutils.ColourStrings{R: "0", G: "0", B: "0", A: "128"}, Padding: "4", CornerRadius: "6"},
+			},
+			res: Component{
+				fs:            ttfFS,
+				Content:       "hello",
+				Font:          func() *truetype.Font { f, _ := truetype.Parse(goregular.TTF); return f }(),
+				Start:         image.Pt(123, 45),
+				MaxWidth:      67,
+				Size:          89,
+				TextAlignment: cutils.TextAlignmentLeft,
+				Colour:        color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+				Effects: cutils.TextEffects{
+					Stroke:     &cutils.TextStroke{Colour: color.NRGBA{A: 255}},
+					Background: &cutils.TextBackground{Colour: color.NRGBA{A: 128}, Padding: 4, CornerRadius: 6},
+				},
+				NamedPropertiesMap: map[string][]string{"outline": {"strokeWidth"}},
+			},
+			props: render.NamedProperties{"outline": struct{ Message string }{Message: "Please replace me with real data"}},
+		},
+		{
+			name: "gradient",
+			start: Component{
+				fs: ttfFS,
+			},
+			input: &textFormat{
+				Font: struct {
+					FontName string `json:"fontName"`
+					FontFile string `json:"fontFile"`
+					FontURL  string `json:"fontURL"`
+				}{
+					FontFile: "myFont.ttf",
+				},
+				Content:       "hello",
+				StartX:        "123",
+				StartY:        "45",
+				MaxWidth:      "67",
+				Size:          "89",
+				TextAlignment: "something else",
+				Gradient: &cutils.GradientStrings{
+					Type:  "radial",
+					Stops: []cutils.GradientStopStrings{{Colour: cutils.ColourStrings{R: "6", G: "53", B: "197", A: "244"}}, {Colour: cutils.ColourStrings{R: "0", G: "0", B: "0", A: "$fade$"}}},
+				},
+			},
+			res: Component{
+				fs:                 ttfFS,
+				Content:            "hello",
+				Font:               func() *truetype.Font { f, _ := truetype.Parse(goregular.TTF); return f }(),
+				Start:              image.Pt(123, 45),
+				MaxWidth:           67,
+				Size:               89,
+				TextAlignment:      cutils.TextAlignmentLeft,
+				Gradient:           render.Gradient{Type: render.GradientRadial, Stops: []render.GradientStop{{Offset: 0, Colour: color.NRGBA{R: 6, G: 53, B: 197, A: 244}}, {Offset: 1}}},
+				NamedPropertiesMap: map[string][]string{"fade": {"gradientStop1A"}},
+			},
+			props: render.NamedProperties{"fade": struct{ Message string }{Message: "Please replace me with real data"}},
+		},
+		{
+			name: "text box",
+			start: Component{
+				fs: ttfFS,
+			},
+			input: &textFormat{
+				Font: struct {
+					FontName string `json:"fontName"`
+					FontFile string `json:"fontFile"`
+					FontURL  string `json:"fontURL"`
+				}{
+					FontFile: "myFont.ttf",
+				},
+				Content:       "hello",
+				StartX:        "123",
+				StartY:        "45",
+				MaxWidth:      "67",
+				MaxHeight:     "$height$",
+				LineHeight:    "1.5",
+				Overflow:      "clip",
+				MinSize:       "6",
+				Size:          "89",
+				TextAlignment: "justify",
+				Anchor:        "bottom-right",
+				Colour: struct {
+					Red   string `json:"R"`
+					Green string `json:"G"`
+					Blue  string `json:"B"`
+					Alpha string `json:"A"`
+				}{
+					Red:   "6",
+					Green: "53",
+					Blue:  "197",
+					Alpha: "244",
+				},
+			},
+			res: Component{
+				fs:                 ttfFS,
+				Content:            "hello",
+				Font:               func() *truetype.Font { f, _ := truetype.Parse(goregular.TTF); return f }(),
+				Start:              image.Pt(123, 45),
+				MaxWidth:           67,
+				LineHeight:         1.5,
+				Overflow:           cutils.OverflowClip,
+				MinSize:            6,
+				Size:               89,
+				TextAlignment:      cutils.TextAlignmentJustify,
+				Anchor:             cutils.TextAnchor{Vertical: cutils.AnchorBottom, Horizontal: cutils.TextAlignmentRight},
+				Colour:             color.NRGBA{R: 6, G: 53, B: 197, A: 244},
+				NamedPropertiesMap: map[string][]string{"height": {"maxHeight"}},
+			},
+			props: render.NamedProperties{"height": struct{ Message string }{Message: "Please replace me with real data"}},
+		},
+		{
+			name: "spans",
+			start: Component{
+				fs: ttfFS,
+			},
+			input: &textFormat{
+				Font: struct {
+					FontName string `json:"fontName"`
+					FontFile string `json:"fontFile"`
+					FontURL  string `json:"fontURL"`
+				}{
+					FontFile: "myFont.ttf",
+				},
+				Spans: []spanFormat{
+					{Text: "hello ", Italic: "true"},
+					{Text: "$name$", Size: "12", Bold: "$loud$", Underline: "true", Strikethrough: "false", Font: &fontFormat{FontFile: "myFont.ttf"}, Colour: &struct {
+						Red   string `json:"R"`
+						Green string `json:"G"`
+						Blue  string `json:"B"`
+						Alpha string `json:"A"`
+					}{Red: "255", Green: "0", Blue: "0", Alpha: "255"}},
+				},
+				StartX:        "123",
+				StartY:        "45",
+				MaxWidth:      "67",
+				Size:          "89",
+				BoldFont:      &fontFormat{FontFile: "myFont.ttf"},
+				TextAlignment: "left",
+				Colour: struct {
+					Red   string `json:"R"`
+					Green string `json:"G"`
+					Blue  string `json:"B"`
+					Alpha string `json:"A"`
+				}{
+					Red:   "6",
+					Green: "53",
+					Blue:  "197",
+					Alpha: "244",
+				},
+			},
+			res: Component{
+				fs:                 ttfFS,
+				Font:               func() *truetype.Font { f, _ := truetype.Parse(goregular.TTF); return f }(),
+				Spans:              []Span{{Text: "hello ", Italic: true}, {Size: 12, Underline: true, Font: func() *truetype.Font { f, _ := truetype.Parse(goregular.TTF); return f }(), Colour: &color.NRGBA{R: 255, A: 255}}},
+				Start:              image.Pt(123, 45),
+				MaxWidth:           67,
+				Size:               89,
+				BoldFont:           func() *truetype.Font { f, _ := truetype.Parse(goregular.TTF); return f }(),
+				Colour:             color.NRGBA{R: 6, G: 53, B: 197, A: 244},
+				NamedPropertiesMap: map[string][]string{"name": {"span1Text"}, "loud": {"span1Bold"}},
+			},
+			props: render.NamedProperties{"name": struct{ Message string }{Message: "Please replace me with real data"}, "loud": struct{ Message string }{Message: "Please replace me with real data"}},
+		},
+		{
+			name: "markup",
+			start: Component{
+				fs:       ttfFS,
+				fontPool: fakeSysFonts{},
+			},
+			input: &textFormat{
+				Font: struct {
+					FontName string `json:"fontName"`
+					FontFile string `json:"fontFile"`
+					FontURL  string `json:"fontURL"`
+				}{
+					FontFile: "myFont.ttf",
+				},
+				Content:  "hello **world**",
+				Markup:   "true",
+				StartX:   "123",
+				StartY:   "45",
+				MaxWidth: "67",
+				Size:     "89",
+				Colour: struct {
+					Red   string `json:"R"`
+					Green string `json:"G"`
+					Blue  string `json:"B"`
+					Alpha string `json:"A"`
+				}{
+					Red:   "6",
+					Green: "53",
+					Blue:  "197",
+					Alpha: "244",
+				},
+				ItalicFont:    &fontFormat{FontName: "good"},
+				TextAlignment: "left",
+			},
+			res: Component{
+				fs:                 ttfFS,
+				fontPool:           fakeSysFonts{},
+				Content:            "hello **world**",
+				Markup:             true,
+				Font:               func() *truetype.Font { f, _ := truetype.Parse(goregular.TTF); return f }(),
+				ItalicFont:         func() *truetype.Font { f, _ := truetype.Parse(goregular.TTF); return f }(),
+				Start:              image.Pt(123, 45),
+				MaxWidth:           67,
+				Size:               89,
+				Colour:             color.NRGBA{R: 6, G: 53, B: 197, A: 244},
+				NamedPropertiesMap: map[string][]string{},
+			},
+			props: render.NamedProperties{},
+		},
+		{
+			name: "spans and content",
+			start: Component{
+				fs: ttfFS,
+			},
+			input: &textFormat{
+				Font: struct {
+					FontName string `json:"fontName"`
+					FontFile string `json:"fontFile"`
+					FontURL  string `json:"fontURL"`
+				}{
+					FontFile: "myFont.ttf",
+				},
+				Content:       "hello",
+				Spans:         []spanFormat{{Text: "world"}},
+				StartX:        "123",
+				StartY:        "45",
+				MaxWidth:      "67",
+				Size:          "89",
+				TextAlignment: "left",
+				Colour: struct {
+					Red   string `json:"R"`
+					Green string `json:"G"`
+					Blue  string `json:"B"`
+					Alpha string `json:"A"`
+				}{
+					Red:   "6",
+					Green: "53",
+					Blue:  "197",
+					Alpha: "244",
+				},
+			},
+			res: Component{
+				fs: ttfFS,
+			},
+			props: render.NamedProperties{},
+			err:   "text takes either content or spans, not both",
+		},
+		{
+			name: "bad span",
+			start: Component{
+				fs: ttfFS,
+			},
+			input: &textFormat{
+				Font: struct {
+					FontName string `json:"fontName"`
+					FontFile string `json:"fontFile"`
+					FontURL  string `json:"fontURL"`
+				}{
+					FontFile: "myFont.ttf",
+				},
+				Spans:          []spanFormat{{Size: "big", Font: &fontFormat{FontName: "bad", FontFile: "myFont.ttf"}}},
+				StartX:         "123",
+				StartY:         "45",
+				MaxWidth:       "67",
+				Size:           "89",
+				BoldItalicFont: &fontFormat{},
+				TextAlignment:  "left",
+				Colour: struct {
+					Red   string `json:"R"`
+					Green string `json:"G"`
+					Blue  string `json:"B"`
+					Alpha string `json:"A"`
+				}{
+					Red:   "6",
+					Green: "53",
+					Blue:  "197",
+					Alpha: "244",
+				},
+			},
+			res: Component{
+				fs: ttfFS,
+			},
+			props: render.NamedProperties{},
+			err:   "error parsing data for property span0Text: could not parse empty property\nfailed to convert property span0Size to float64: strconv.ParseFloat: parsing \"big\": invalid syntax\na font takes either a fontName or a fontFile, not both\na font needs a fontName or a fontFile",
+		},
+		{
+			name: "gradient and colour",
+			start: Component{
+				fs: ttfFS,
+			},
+			input: &textFormat{
+				Font: struct {
+					FontName string `json:"fontName"`
+					FontFile string `json:"fontFile"`
+					FontURL  string `json:"fontURL"`
+				}{
+					FontFile: "myFont.ttf",
+				},
+				Content:       "hello",
+				StartX:        "123",
+				StartY:        "45",
+				MaxWidth:      "67",
+				Size:          "89",
+				TextAlignment: "something else",
+				Colour: struct {
+					Red   string `json:"R"`
+					Green string `json:"G"`
+					Blue  string `json:"B"`
+					Alpha string `json:"A"`
+				}{
+					Alpha: "244",
+				},
+				Gradient: &cutils.GradientStrings{
+					Type:  "radial",
+					Stops: []cutils.GradientStopStrings{{Colour: cutils.ColourStrings{R: "6", G: "53", B: "197", A: "244"}}, {Colour: cutils.ColourStrings{R: "0", G: "0", B: "0", A: "$fade$"}}},
+				},
+			},
+			res: Component{
+				fs: ttfFS,
+			},
+			props: render.NamedProperties{},
+			err:   "text takes either a colour or a gradient, not both",
+		},
 		{
 			name: "valid everything",
 			start: Component{
@@ -0,0 +1,525 @@
+package text
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// textPiece is part of a word in the style of a single span.
+type textPiece struct {
+	text string
+	// style is the index of the span the piece came from.
+	style int
+}
+
+// textWord is a word made up of pieces in different styles.
+type textWord struct {
+	pieces []textPiece
+	// space is the style of the space before the word.
+	space int
+}
+
+// textParagraph is the words between two newlines.
+type textParagraph struct {
+	words []textWord
+	// style is the style of the newline ending the paragraph, which sets the height of the paragraph if it is empty.
+	style int
+}
+
+// textRun is text drawn in one go in a single style.
+type textRun struct {
+	text  string
+	style int
+	// x is the distance from the start of the words the run came from to the start of the run.
+	x fixed.Int26_6
+	// width is the width of the run.
+	width fixed.Int26_6
+}
+
+// textLine is a line of wrapped text.
+type textLine struct {
+	// words are the words on the line, separated by single spaces when drawn.
+	words []textWord
+	// width is the width of the whole line.
+	width fixed.Int26_6
+	// ascent, descent and height are the largest metrics of the styles on the line.
+	ascent, descent, height fixed.Int26_6
+	// last is whether the line ends a paragraph, so it is never justified.
+	last bool
+}
+
+// textBox is text wrapped to fit a width.
+type textBox struct {
+	lines []textLine
+	// baselines are the distances from the top of the box to the baseline of each line.
+	baselines []fixed.Int26_6
+	// width and height are the size of the box taken up by the text.
+	width, height int
+}
+
+// textLayout lays out spans of text drawn in the matching styles.
+type textLayout struct {
+	styles []textStyle
+	// lineHeight is the spacing between the baselines of neighbouring lines as a multiple of the height of the taller line.
+	lineHeight float64
+}
+
+// splitParagraphs breaks the spans into paragraphs at newlines and words at spaces, keeping track of the style of each piece.
+func splitParagraphs(spans []Span) []textParagraph {
+	paragraphs := []textParagraph{{}}
+	var word textWord
+	finishWord := func() {
+		if len(word.pieces) > 0 {
+			paragraph := &paragraphs[len(paragraphs)-1]
+			paragraph.words = append(paragraph.words, word)
+			word = textWord{}
+		}
+	}
+	for style, span := range spans {
+		for _, r := range span.Text {
+			switch {
+			case r == '\n':
+				finishWord()
+				paragraphs[len(paragraphs)-1].style = style
+				paragraphs = append(paragraphs, textParagraph{})
+			case unicode.IsSpace(r):
+				finishWord()
+				word.space = style
+			case len(word.pieces) > 0 && word.pieces[len(word.pieces)-1].style == style:
+				word.pieces[len(word.pieces)-1].text += string(r)
+			default:
+				word.pieces = append(word.pieces, textPiece{text: string(r), style: style})
+			}
+		}
+		paragraphs[len(paragraphs)-1].style = style
+	}
+	finishWord()
+	return paragraphs
+}
+
+// runs returns the words as runs of the same style, separated by single spaces.
+func (layout textLayout) runs(words []textWord) []textRun {
+	var runs []textRun
+	add := func(text string, style int) {
+		if last := len(runs) - 1; last >= 0 && runs[last].style == style {
+			runs[last].text += text
+			return
+		}
+		runs = append(runs, textRun{text: text, style: style})
+	}
+	for index, word := range words {
+		if index > 0 {
+			add(" ", word.space)
+		}
+		for _, piece := range word.pieces {
+			add(piece.text, piece.style)
+		}
+	}
+	var dot fixed.Int26_6
+	for index := range runs {
+		runs[index].x = dot
+		runs[index].width = font.MeasureString(layout.styles[runs[index].style].face, runs[index].text)
+		dot += runs[index].width
+	}
+	return runs
+}
+
+// width returns the width of the words separated by single spaces.
+func (layout textLayout) width(words []textWord) fixed.Int26_6 {
+	var width fixed.Int26_6
+	for _, run := range layout.runs(words) {
+		width += run.width
+	}
+	return width
+}
+
+// line returns the words measured as a line, using the empty style for an empty line.
+func (layout textLayout) line(words []textWord, empty int, last bool) textLine {
+	line := textLine{words: words, width: layout.width(words), last: last}
+	measure := func(style int) {
+		metrics := layout.styles[style].face.Metrics()
+		if metrics.Ascent > line.ascent {
+			line.ascent = metrics.Ascent
+		}
+		if metrics.Descent > line.descent {
+			line.descent = metrics.Descent
+		}
+		if metrics.Height > line.height {
+			line.height = metrics.Height
+		}
+	}
+	if len(words) == 0 {
+		measure(empty)
+	}
+	for _, run := range layout.runs(words) {
+		measure(run.style)
+	}
+	return line
+}
+
+// wrap breaks the paragraphs into lines no wider than maxWidth at spaces. A single word wider than maxWidth is left on a line of its own.
+func (layout textLayout) wrap(paragraphs []textParagraph, maxWidth int) textBox {
+	limit := fixed.I(maxWidth)
+	var box textBox
+	for _, paragraph := range paragraphs {
+		var words []textWord
+		for _, word := range paragraph.words {
+			candidate := append(words[:len(words):len(words)], word)
+			if len(words) > 0 && layout.width(candidate) > limit {
+				box.lines = append(box.lines, layout.line(words, paragraph.style, false))
+				candidate = []textWord{word}
+			}
+			words = candidate
+		}
+		box.lines = append(box.lines, layout.line(words, paragraph.style, true))
+	}
+	return layout.measured(box)
+}
+
+// measured returns the box with the lines placed one below the other and its size measured from them.
+func (layout textLayout) measured(box textBox) textBox {
+	box.width = 0
+	box.baselines = make([]fixed.Int26_6, len(box.lines))
+	for index, line := range box.lines {
+		if width := line.width.Ceil(); width > box.width {
+			box.width = width
+		}
+		if index == 0 {
+			box.baselines[index] = line.ascent
+			continue
+		}
+		height := line.height
+		if previous := box.lines[index-1].height; previous > height {
+			height = previous
+		}
+		box.baselines[index] = box.baselines[index-1] + fixed.Int26_6(math.Round(float64(height)*layout.lineHeight))
+	}
+	box.height = box.linesHeight(len(box.lines))
+	return box
+}
+
+// linesHeight returns the height taken up by the first count lines of the box.
+func (box textBox) linesHeight(count int) int {
+	if count == 0 {
+		return 0
+	}
+	return (box.baselines[count-1] + box.lines[count-1].descent).Ceil()
+}
+
+// ellipsised returns the box with as many lines as fit within maxHeight, cutting short any line too wide for maxWidth and the last line if any were left out.
+func (layout textLayout) ellipsised(box textBox, maxWidth, maxHeight int) textBox {
+	count := len(box.lines)
+	for count > 1 && box.linesHeight(count) > maxHeight {
+		count--
+	}
+	lines := make([]textLine, count)
+	copy(lines, box.lines)
+	for index, line := range lines {
+		if line.width.Ceil() <= maxWidth && (index < count-1 || count == len(box.lines)) {
+			continue
+		}
+		var text strings.Builder
+		for _, run := range layout.runs(line.words) {
+			text.WriteString(run.text)
+		}
+		fits := func(candidate string) bool {
+			return layout.width(ellipsiseWords(line.words, candidate)).Ceil() <= maxWidth
+		}
+		shortened := cutils.EllipsiseText(text.String(), fits)
+		lines[index] = layout.line(ellipsiseWords(line.words, shortened), 0, line.last)
+		// Keep the height of the line even if nothing of it fits.
+		lines[index].ascent, lines[index].descent, lines[index].height = line.ascent, line.descent, line.height
+	}
+	box.lines = lines
+	return layout.measured(box)
+}
+
+// ellipsiseWords returns the start of the words matching the shortened text, which is the start of the words separated by single spaces followed by an ellipsis. The ellipsis takes the style of the text before it.
+func ellipsiseWords(words []textWord, shortened string) []textWord {
+	if shortened == "" {
+		return nil
+	}
+	remaining := len([]rune(strings.TrimSuffix(shortened, cutils.Ellipsis)))
+	var cut []textWord
+	style := 0
+	for index, word := range words {
+		if index > 0 {
+			if remaining == 0 {
+				break
+			}
+			// The space between the words.
+			remaining--
+			style = word.space
+		}
+		cutWord := textWord{space: word.space}
+		for _, piece := range word.pieces {
+			if remaining == 0 {
+				break
+			}
+			runes := []rune(piece.text)
+			if len(runes) > remaining {
+				runes = runes[:remaining]
+			}
+			cutWord.pieces = append(cutWord.pieces, textPiece{text: string(runes), style: piece.style})
+			remaining -= len(runes)
+			style = piece.style
+		}
+		if len(cutWord.pieces) > 0 {
+			cut = append(cut, cutWord)
+		}
+	}
+	ellipsis := textPiece{text: cutils.Ellipsis, style: style}
+	if len(cut) == 0 {
+		return []textWord{{pieces: []textPiece{ellipsis}}}
+	}
+	last := &cut[len(cut)-1]
+	last.pieces = append(last.pieces[:len(last.pieces):len(last.pieces)], ellipsis)
+	return cut
+}
+
+/*
+writeLayout draws the text laid out from its spans. With MaxHeight set, the text is wrapped onto as
+many lines as it takes to fit within MaxWidth, otherwise it is drawn on a single line, either way
+placed at Start by the Anchor. If the text can't fit within MaxWidth and MaxHeight, or a single word can't fit
+within MaxWidth, the text overflows, which by default scales the font down.
+*/
+func (component Component) writeLayout(canvas render.Canvas) (render.Canvas, error) {
+	spans, err := component.spans()
+	if err != nil {
+		return canvas, err
+	}
+	content := plainText(spans)
+	layout := textLayout{lineHeight: component.LineHeight}
+	if layout.lineHeight == 0 {
+		layout.lineHeight = 1
+	}
+	paragraphs := splitParagraphs(spans)
+	singleLine := component.MaxHeight <= 0
+	limit, maxHeight, bounds := component.MaxWidth, component.MaxHeight, fmt.Sprintf("a %dx%d box", component.MaxWidth, component.MaxHeight)
+	if singleLine {
+		// A single line is never wrapped, so any newlines are treated as spaces.
+		line := textParagraph{}
+		for _, paragraph := range paragraphs {
+			line.words = append(line.words, paragraph.words...)
+			line.style = paragraph.style
+		}
+		paragraphs = []textParagraph{line}
+		limit, maxHeight, bounds = math.MaxInt32>>6, math.MaxInt32, fmt.Sprintf("maxWidth %d", component.MaxWidth)
+	}
+	scale := component.Overflow == "" || component.Overflow == cutils.OverflowScale
+	fontSize := component.Size
+	var box textBox
+	fits := false
+	tries := 0
+	for !fits && tries < 10 {
+		tries++
+		layout.styles = component.styles(spans, fontSize, canvas.GetPPI())
+		box = layout.wrap(paragraphs, limit)
+		fits = box.width <= component.MaxWidth && box.height <= maxHeight
+		if !scale {
+			break
+		}
+		ratio := float64(component.MaxWidth) / float64(box.width)
+		if !singleLine {
+			// Wrapping fills the box in both directions, so the area the text takes up scales with the square of the font size.
+			ratio = math.Min(ratio, math.Sqrt(float64(component.MaxHeight)/float64(box.height)))
+		}
+		previousSize := fontSize
+		fontSize *= math.Min(ratio, 0.95)
+		if !fits && component.smallestSize(spans, fontSize) < component.MinSize {
+			if component.smallestSize(spans, previousSize) <= component.MinSize {
+				return canvas, fmt.Errorf("unable to fit text %v into %s without going below the minimum size %v", content, bounds, component.MinSize)
+			}
+			fontSize = component.sizeForSmallest(spans, component.MinSize)
+		}
+	}
+	// From here on Start is the left of MaxWidth and, for a text box, the top of MaxHeight, with the anchor of the text placed at the original Start.
+	anchor := component.Start
+	component.Start.X = component.Anchor.Left(anchor.X, component.MaxWidth)
+	if !singleLine {
+		component.Start.Y = component.Anchor.BoxTop(anchor.Y, component.MaxHeight)
+	}
+	top := func(box textBox) int {
+		if !singleLine {
+			return component.Anchor.BoxTop(anchor.Y, box.height)
+		}
+		if len(box.baselines) == 0 {
+			return anchor.Y
+		}
+		return component.Anchor.Baseline(anchor.Y, box.baselines[0], box.lines[0].descent) - box.baselines[0].Ceil()
+	}
+	if fits {
+		return component.writeBox(canvas, layout, box, top(box))
+	}
+	switch component.Overflow {
+	case "", cutils.OverflowScale:
+		return canvas, fmt.Errorf("unable to fit text %v into %s after %d tries", content, bounds, tries)
+	case cutils.OverflowEllipsis:
+		box = layout.ellipsised(box, component.MaxWidth, maxHeight)
+		return component.writeBox(canvas, layout, box, top(box))
+	case cutils.OverflowClip:
+		boxTop := top(box)
+		visible := image.Rect(component.Start.X, boxTop, component.Start.X+component.MaxWidth, boxTop+box.height)
+		if !singleLine {
+			visible = image.Rect(component.Start.X, component.Start.Y, component.Start.X+component.MaxWidth, component.Start.Y+component.MaxHeight)
+		}
+		clip := &render.Clip{Path: render.NewRectanglePath(visible.Min, visible.Dx(), visible.Dy(), render.CornerRadii{})}
+		return component.Effects.Write(canvas, component.bounds(box, boxTop).Intersect(visible), func(c render.Canvas, outline bool) (render.Canvas, error) {
+			return c.Layer(render.LayerStyle{Opacity: 1, Clip: clip}, func(layer render.Canvas) (render.Canvas, error) {
+				return component.drawBox(layer, layout, box, boxTop, outline)
+			})
+		})
+	default:
+		if singleLine {
+			return canvas, fmt.Errorf("text %v is wider than maxWidth %d", content, component.MaxWidth)
+		}
+		return canvas, fmt.Errorf("text %v does not fit into %s", content, bounds)
+	}
+}
+
+// writeBox draws the lines of the box with the effects of the component, with the top of the box at top.
+func (component Component) writeBox(canvas render.Canvas, layout textLayout, box textBox, top int) (render.Canvas, error) {
+	return component.Effects.Write(canvas, component.bounds(box, top), func(c render.Canvas, outline bool) (render.Canvas, error) {
+		return component.drawBox(c, layout, box, top, outline)
+	})
+}
+
+// bounds returns the box taken up by the lines of the box as drawBox draws them, with the top of the box at top.
+func (component Component) bounds(box textBox, top int) image.Rectangle {
+	var bounds image.Rectangle
+	for index, line := range box.lines {
+		if len(line.words) == 0 {
+			continue
+		}
+		baseline := top + box.baselines[index].Ceil()
+		width, offset := component.MaxWidth, 0
+		if !component.justified(line) {
+			width = line.width.Ceil()
+			_, offset = cutils.ScaleFontsToWidth(0, width, component.MaxWidth, component.TextAlignment)
+		}
+		start := component.Start.X + offset
+		bounds = bounds.Union(image.Rect(start, baseline-line.ascent.Ceil(), start+width, baseline+line.descent.Ceil()))
+	}
+	return bounds
+}
+
+// justified returns whether the line is spread out to fill MaxWidth.
+func (component Component) justified(line textLine) bool {
+	return component.TextAlignment == cutils.TextAlignmentJustify && !line.last && len(line.words) > 1
+}
+
+// drawBox draws each of the lines of the box in turn, aligned within MaxWidth, with the top of the box at top. If outline is set, just the outlines of the stroke are drawn.
+func (component Component) drawBox(canvas render.Canvas, layout textLayout, box textBox, top int, outline bool) (render.Canvas, error) {
+	// Clipped lines may be wider than the box.
+	maxWidth := component.MaxWidth
+	if box.width > maxWidth {
+		maxWidth = box.width
+	}
+	c := canvas
+	var err error
+	for index, line := range box.lines {
+		if len(line.words) == 0 {
+			continue
+		}
+		baseline := top + box.baselines[index].Ceil()
+		if component.justified(line) {
+			c, err = component.writeJustified(c, layout, line, image.Pt(component.Start.X, baseline), outline)
+			if err != nil {
+				return canvas, err
+			}
+			continue
+		}
+		_, offset := cutils.ScaleFontsToWidth(0, line.width.Ceil(), component.MaxWidth, component.TextAlignment)
+		for _, run := range layout.runs(line.words) {
+			c, err = component.drawRun(c, run, layout.styles[run.style], image.Pt(component.Start.X+offset+run.x.Round(), baseline), maxWidth, outline)
+			if err != nil {
+				return canvas, err
+			}
+		}
+	}
+	return c, nil
+}
+
+// writeJustified draws each word of the line separately, spreading them out so that the line fills MaxWidth.
+func (component Component) writeJustified(canvas render.Canvas, layout textLayout, line textLine, start image.Point, outline bool) (render.Canvas, error) {
+	words := make([][]textRun, len(line.words))
+	var total fixed.Int26_6
+	for index, word := range line.words {
+		words[index] = layout.runs([]textWord{word})
+		for _, run := range words[index] {
+			total += run.width
+		}
+	}
+	gap := (fixed.I(component.MaxWidth) - total) / fixed.Int26_6(len(line.words)-1)
+	c := canvas
+	var err error
+	var dot fixed.Int26_6
+	for _, runs := range words {
+		for _, run := range runs {
+			c, err = component.drawRun(c, run, layout.styles[run.style], image.Pt(start.X+(dot+run.x).Round(), start.Y), component.MaxWidth, outline)
+			if err != nil {
+				return canvas, err
+			}
+		}
+		last := runs[len(runs)-1]
+		dot += last.x + last.width + gap
+	}
+	return c, nil
+}
+
+/*
+drawRun draws a run of text with its baseline starting at start, imitating bold and italic if the
+style asks for them and adding any lines under or through it. If outline is set, just the outline
+of the stroke of the effects is drawn, and the lines are left for the text itself.
+*/
+func (component Component) drawRun(canvas render.Canvas, run textRun, style textStyle, start image.Point, maxWidth int, outline bool) (render.Canvas, error) {
+	pixelSize := style.face.PixelSize()
+	draw := func(c render.Canvas) (render.Canvas, error) {
+		c, err := component.Effects.Text(c, outline, run.text, start, style.face, style.colour, maxWidth)
+		if err != nil || !style.fauxBold {
+			return c, err
+		}
+		// Drawing the text again slightly to the right thickens every stroke.
+		return component.Effects.Text(c, outline, run.text, image.Pt(start.X+int(math.Max(1, math.Round(pixelSize/20))), start.Y), style.face, style.colour, maxWidth)
+	}
+	c := canvas
+	var err error
+	switch {
+	case strings.TrimSpace(run.text) == "":
+		// Spaces are only drawn for their lines.
+	case style.fauxItalic:
+		anchor := start
+		c, err = c.Layer(render.LayerStyle{Opacity: 1, Transform: &render.Transform{ScaleX: 1, ScaleY: 1, SkewX: -12, Anchor: &anchor}}, draw)
+	default:
+		c, err = draw(c)
+	}
+	if err != nil || outline {
+		return c, err
+	}
+	thickness := int(math.Max(1, math.Round(pixelSize/14)))
+	lines := []struct {
+		drawn bool
+		y     int
+	}{
+		{drawn: style.underline, y: start.Y + thickness},
+		{drawn: style.strikethrough, y: start.Y - (style.face.Metrics().Ascent * 3 / 10).Round()},
+	}
+	for _, line := range lines {
+		if !line.drawn || run.width.Ceil() == 0 {
+			continue
+		}
+		c, err = c.Rectangle(image.Pt(start.X, line.y), run.width.Ceil(), thickness, style.colour)
+		if err != nil {
+			return canvas, err
+		}
+	}
+	return c, nil
+}
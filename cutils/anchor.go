@@ -0,0 +1,147 @@
+package cutils
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// VerticalAnchor is the height of text placed at its start.
+type VerticalAnchor string
+
+const (
+	// AnchorTop places the top of the text, as high as the ascent of the font, at the start
+	AnchorTop VerticalAnchor = "top"
+	// AnchorMiddle places the middle of the text, halfway between its top and bottom, at the start
+	AnchorMiddle VerticalAnchor = "middle"
+	// AnchorBaseline places the baseline of the text at the start
+	AnchorBaseline VerticalAnchor = "baseline"
+	// AnchorBottom places the bottom of the text, as low as the descent of the font, at the start
+	AnchorBottom VerticalAnchor = "bottom"
+)
+
+/*
+TextAnchor is the point of text placed at its start. Horizontally it places the left, centre or
+right of the maximum width of the text at the start, and the text is aligned within that width as
+usual. Vertically it places the top, middle, baseline or bottom of the text there, measured from
+the metrics of the font at the size the text is finally drawn, so that text scaled down to fit
+stays in place. The zero value is the start of the baseline of a single line of text, or the
+top-left corner of a text box.
+*/
+type TextAnchor struct {
+	// Vertical is the height of the text placed at the start, where empty is the same as AnchorBaseline.
+	Vertical VerticalAnchor
+	// Horizontal is the side of the maximum width placed at the start, which is never TextAlignmentJustify.
+	Horizontal TextAlignment
+}
+
+// StringToAnchor converts strings such as "middle-centre", "top" or "right" to TextAnchors, erroring on anything unknown. Either part may be left out, and the two may come in either order.
+func StringToAnchor(anchor string) (TextAnchor, error) {
+	var converted TextAnchor
+	if anchor == "" {
+		return converted, nil
+	}
+	invalid := fmt.Errorf("anchor %v does not match defined constants", anchor)
+	vertical, horizontal := false, false
+	for _, part := range strings.Split(anchor, "-") {
+		switch part {
+		case string(AnchorTop), string(AnchorMiddle), string(AnchorBaseline), string(AnchorBottom):
+			if vertical {
+				return TextAnchor{}, invalid
+			}
+			vertical = true
+			converted.Vertical = VerticalAnchor(part)
+		case "left", "centre", "right":
+			if horizontal {
+				return TextAnchor{}, invalid
+			}
+			horizontal = true
+			converted.Horizontal = StringToAlignment(part)
+		default:
+			return TextAnchor{}, invalid
+		}
+	}
+	return converted, nil
+}
+
+// ExtractTextAnchor extracts a TextAnchor from the raw JSON data, leaving it as the zero value if there is none
+func ExtractTextAnchor(raw, name string, props map[string][]string) (TextAnchor, map[string][]string, error) {
+	if raw == "" {
+		return TextAnchor{}, props, nil
+	}
+	str, newProps, err := ExtractString(raw, name, props)
+	if err != nil || str == "" {
+		return TextAnchor{}, newProps, err
+	}
+	anchor, err := StringToAnchor(str)
+	if err != nil {
+		return TextAnchor{}, props, err
+	}
+	return anchor, newProps, nil
+}
+
+// SetTextAnchor turns an interface into a TextAnchor and an error
+func SetTextAnchor(value interface{}) (TextAnchor, error) {
+	if anchor, ok := value.(TextAnchor); ok {
+		if anchor.Horizontal == TextAlignmentJustify {
+			return TextAnchor{}, fmt.Errorf("anchor cannot be justified")
+		}
+		_, err := StringToAnchor(string(anchor.Vertical))
+		if err != nil {
+			return TextAnchor{}, err
+		}
+		return anchor, nil
+	}
+	str, err := SetString(value)
+	if err != nil {
+		return TextAnchor{}, err
+	}
+	return StringToAnchor(str)
+}
+
+// Left returns the left of an area width wide, placed horizontally by the anchor at x.
+func (anchor TextAnchor) Left(x, width int) int {
+	switch anchor.Horizontal {
+	case TextAlignmentCentre:
+		return x - width/2
+	case TextAlignmentRight:
+		return x - width
+	default:
+		return x
+	}
+}
+
+// Baseline returns the baseline of a line of text with the ascent and descent, placed vertically by the anchor at y.
+func (anchor TextAnchor) Baseline(y int, ascent, descent fixed.Int26_6) int {
+	switch anchor.Vertical {
+	case AnchorTop:
+		return y + ascent.Ceil()
+	case AnchorMiddle:
+		return y + ((ascent - descent) / 2).Round()
+	case AnchorBottom:
+		return y - descent.Ceil()
+	default:
+		return y
+	}
+}
+
+// LineStart returns the start of the baseline of a single line of text in the face, maxWidth wide, so that the anchor of the text is at start.
+func (anchor TextAnchor) LineStart(start image.Point, face font.Face, maxWidth int) image.Point {
+	metrics := face.Metrics()
+	return image.Pt(anchor.Left(start.X, maxWidth), anchor.Baseline(start.Y, metrics.Ascent, metrics.Descent))
+}
+
+// BoxTop returns the top of a box height tall, placed vertically by the anchor at y, where the baseline of a box is its top.
+func (anchor TextAnchor) BoxTop(y, height int) int {
+	switch anchor.Vertical {
+	case AnchorMiddle:
+		return y - height/2
+	case AnchorBottom:
+		return y - height
+	default:
+		return y
+	}
+}
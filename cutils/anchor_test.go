@@ -0,0 +1,114 @@
+package cutils
+
+import (
+	"image"
+	"testing"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/golang/freetype/truetype"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestStringToAnchor(t *testing.T) {
+	tests := []struct {
+		anchor    string
+		converted TextAnchor
+		err       string
+	}{
+		{anchor: ""},
+		{anchor: "top", converted: TextAnchor{Vertical: AnchorTop}},
+		{anchor: "right", converted: TextAnchor{Horizontal: TextAlignmentRight}},
+		{anchor: "middle-centre", converted: TextAnchor{Vertical: AnchorMiddle, Horizontal: TextAlignmentCentre}},
+		{anchor: "left-bottom", converted: TextAnchor{Vertical: AnchorBottom, Horizontal: TextAlignmentLeft}},
+		{anchor: "baseline-right", converted: TextAnchor{Vertical: AnchorBaseline, Horizontal: TextAlignmentRight}},
+		{anchor: "top-bottom", err: "anchor top-bottom does not match defined constants"},
+		{anchor: "left-right", err: "anchor left-right does not match defined constants"},
+		{anchor: "justify", err: "anchor justify does not match defined constants"},
+		{anchor: "middle centre", err: "anchor middle centre does not match defined constants"},
+	}
+	for _, test := range tests {
+		t.Run(test.anchor, func(t *testing.T) {
+			converted, err := StringToAnchor(test.anchor)
+			assert.Equal(t, test.converted, converted)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestExtractTextAnchor(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		anchor, props, err := ExtractTextAnchor("", "anchor", map[string][]string{})
+		assert.Equal(t, TextAnchor{}, anchor)
+		assert.Equal(t, map[string][]string{}, props)
+		assert.NoError(t, err)
+	})
+	t.Run("valid", func(t *testing.T) {
+		anchor, props, err := ExtractTextAnchor("top-centre", "anchor", map[string][]string{})
+		assert.Equal(t, TextAnchor{Vertical: AnchorTop, Horizontal: TextAlignmentCentre}, anchor)
+		assert.Equal(t, map[string][]string{}, props)
+		assert.NoError(t, err)
+	})
+	t.Run("named property", func(t *testing.T) {
+		anchor, props, err := ExtractTextAnchor("$place$", "anchor", map[string][]string{})
+		assert.Equal(t, TextAnchor{}, anchor)
+		assert.Equal(t, map[string][]string{"place": {"anchor"}}, props)
+		assert.NoError(t, err)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		anchor, props, err := ExtractTextAnchor("centre-centre", "anchor", map[string][]string{})
+		assert.Equal(t, TextAnchor{}, anchor)
+		assert.Equal(t, map[string][]string{}, props)
+		assert.EqualError(t, err, "anchor centre-centre does not match defined constants")
+	})
+}
+
+func TestSetTextAnchor(t *testing.T) {
+	anchor, err := SetTextAnchor("bottom-right")
+	assert.Equal(t, TextAnchor{Vertical: AnchorBottom, Horizontal: TextAlignmentRight}, anchor)
+	assert.NoError(t, err)
+	anchor, err = SetTextAnchor(TextAnchor{Vertical: AnchorMiddle})
+	assert.Equal(t, TextAnchor{Vertical: AnchorMiddle}, anchor)
+	assert.NoError(t, err)
+	anchor, err = SetTextAnchor(TextAnchor{Vertical: "sideways"})
+	assert.Equal(t, TextAnchor{}, anchor)
+	assert.EqualError(t, err, "anchor sideways does not match defined constants")
+	anchor, err = SetTextAnchor(TextAnchor{Horizontal: TextAlignmentJustify})
+	assert.Equal(t, TextAnchor{}, anchor)
+	assert.EqualError(t, err, "anchor cannot be justified")
+	anchor, err = SetTextAnchor(12)
+	assert.Equal(t, TextAnchor{}, anchor)
+	assert.EqualError(t, err, "error converting 12 to string")
+}
+
+func TestTextAnchorPlacement(t *testing.T) {
+	goreg, err := truetype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Size 10 Go Regular at 72 DPI has an ascent of 9.45 and a descent of 2.11 pixels.
+	face := render.NewFontFace(goreg, &truetype.Options{Size: 10, DPI: 72})
+	tests := []struct {
+		anchor TextAnchor
+		start  image.Point
+		top    int
+	}{
+		{anchor: TextAnchor{}, start: image.Pt(100, 50), top: 50},
+		{anchor: TextAnchor{Vertical: AnchorTop, Horizontal: TextAlignmentLeft}, start: image.Pt(100, 60), top: 50},
+		{anchor: TextAnchor{Vertical: AnchorMiddle, Horizontal: TextAlignmentCentre}, start: image.Pt(60, 54), top: 40},
+		{anchor: TextAnchor{Vertical: AnchorBaseline, Horizontal: TextAlignmentCentre}, start: image.Pt(60, 50), top: 50},
+		{anchor: TextAnchor{Vertical: AnchorBottom, Horizontal: TextAlignmentRight}, start: image.Pt(20, 47), top: 30},
+	}
+	for _, test := range tests {
+		t.Run(string(test.anchor.Vertical), func(t *testing.T) {
+			assert.Equal(t, test.start, test.anchor.LineStart(image.Pt(100, 50), face, 80))
+			assert.Equal(t, test.top, test.anchor.BoxTop(50, 20))
+		})
+	}
+	assert.Equal(t, 51, TextAnchor{Vertical: AnchorMiddle}.Baseline(50, fixed.I(3), fixed.I(1)))
+}
@@ -3,6 +3,9 @@ package cutils
 
 import (
 	"fmt"
+	"image/color"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
 )
 
 // CombineErrors combines two errors, maintaining a history of errors separated by newlines
@@ -42,6 +45,8 @@ const (
 	TextAlignmentRight
 	// TextAlignmentCentre aligns text centrally
 	TextAlignmentCentre
+	// TextAlignmentJustify stretches wrapped lines to fill the width, aligning the last line of each paragraph left
+	TextAlignmentJustify
 )
 
 // StringToAlignment converts strings to TextAlignments, defaulting to Left
@@ -53,6 +58,8 @@ func StringToAlignment(alignment string) (converted TextAlignment) {
 		converted = TextAlignmentRight
 	case "centre":
 		converted = TextAlignmentCentre
+	case "justify":
+		converted = TextAlignmentJustify
 	default:
 		converted = TextAlignmentLeft
 	}
@@ -80,3 +87,11 @@ func ScaleFontsToWidth(currentSize float64, currentWidth, maxWidth int, alignmen
 	}
 	return
 }
+
+// FillColour returns the gradient to fill a component with if it has any colour stops, or the solid colour otherwise
+func FillColour(colour color.NRGBA, gradient render.Gradient) color.Color {
+	if len(gradient.Stops) > 0 {
+		return gradient
+	}
+	return colour
+}
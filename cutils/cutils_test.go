@@ -2,8 +2,10 @@ package cutils
 
 import (
 	"fmt"
+	"image/color"
 	"testing"
 
+	"github.com/LLKennedy/imagetemplate/v3/render"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -82,6 +84,7 @@ func TestStringToAlignment(t *testing.T) {
 	assert.Equal(t, TextAlignmentLeft, StringToAlignment("left"))
 	assert.Equal(t, TextAlignmentCentre, StringToAlignment("centre"))
 	assert.Equal(t, TextAlignmentRight, StringToAlignment("right"))
+	assert.Equal(t, TextAlignmentJustify, StringToAlignment("justify"))
 	assert.Equal(t, TextAlignmentLeft, StringToAlignment("gibberish"))
 }
 
@@ -119,3 +122,9 @@ func TestScaleFontsToWidth(t *testing.T) {
 		})
 	})
 }
+
+func TestFillColour(t *testing.T) {
+	gradient := render.Gradient{Stops: []render.GradientStop{{Colour: color.NRGBA{R: 1}}, {Colour: color.NRGBA{R: 2}}}}
+	assert.Equal(t, gradient, FillColour(color.NRGBA{B: 1}, gradient))
+	assert.Equal(t, color.NRGBA{B: 1}, FillColour(color.NRGBA{B: 1}, render.Gradient{}))
+}
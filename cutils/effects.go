@@ -0,0 +1,253 @@
+package cutils
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"golang.org/x/image/font"
+)
+
+// TextStroke is an outline drawn around text.
+type TextStroke struct {
+	// Width is the width of the outline outside the glyphs, in pixels.
+	Width float64
+	// Colour is the colour of the outline.
+	Colour color.NRGBA
+}
+
+// TextBackground is a box drawn behind text, sized to fit it.
+type TextBackground struct {
+	// Colour is the colour of the box.
+	Colour color.NRGBA
+	// Padding is the space between the text and the edges of the box, in pixels.
+	Padding int
+	// CornerRadius is the radius of the rounded corners of the box, in pixels.
+	CornerRadius int
+}
+
+// TextEffects are the stroke, shadow and background drawn with text, each of which is nil if there is none.
+type TextEffects struct {
+	// Stroke outlines the text.
+	Stroke *TextStroke
+	// Shadow is cast by the text and its outline, but not by its background.
+	Shadow *render.Shadow
+	// Background is drawn behind the text.
+	Background *TextBackground
+}
+
+// TextStrokeStrings is the template format of an outline around text
+type TextStrokeStrings struct {
+	// Width is the width of the outline in pixels
+	Width string `json:"width"`
+	// Colour is the colour of the outline
+	Colour ColourStrings `json:"colour"`
+}
+
+// TextShadowStrings is the template format of a shadow cast by text
+type TextShadowStrings struct {
+	// OffsetX and OffsetY move the shadow away from the text, in pixels
+	OffsetX string `json:"offsetX"`
+	OffsetY string `json:"offsetY"`
+	// Blur is the standard deviation in pixels of a Gaussian blur softening the shadow
+	Blur string `json:"blur"`
+	// Colour is the colour of the shadow, defaulting to translucent black
+	Colour *ColourStrings `json:"colour"`
+}
+
+// TextBackgroundStrings is the template format of a box behind text
+type TextBackgroundStrings struct {
+	// Colour is the colour of the box
+	Colour ColourStrings `json:"colour"`
+	// Padding is the space between the text and the edges of the box in pixels
+	Padding string `json:"padding"`
+	// CornerRadius is the radius of the rounded corners of the box in pixels
+	CornerRadius string `json:"cornerRadius"`
+}
+
+// textEffectsPrefixes are the prefixes of the names of the properties of each of the effects.
+var textEffectsPrefixes = []string{"stroke", "shadow", "background"}
+
+// ParseTextEffects turns the template formats of the stroke, shadow and background of text, any of which may be nil, into TextEffects, naming their properties like strokeWidth, shadowOffsetX and backgroundR
+func ParseTextEffects(stroke *TextStrokeStrings, shadow *TextShadowStrings, background *TextBackgroundStrings, inputProps map[string][]string) (TextEffects, map[string][]string, error) {
+	var effects TextEffects
+	var err, parseErr error
+	props := inputProps
+	if stroke != nil {
+		effects.Stroke = &TextStroke{}
+		effects.Stroke.Width, props, parseErr = ExtractFloat(stroke.Width, "strokeWidth", props)
+		err = CombineErrors(err, parseErr)
+		effects.Stroke.Colour, props, parseErr = ParseColourStrings(stroke.Colour, "stroke", props)
+		err = CombineErrors(err, parseErr)
+	}
+	if shadow != nil {
+		effects.Shadow = &render.Shadow{Colour: color.NRGBA{A: 128}}
+		if shadow.OffsetX != "" || shadow.OffsetY != "" {
+			effects.Shadow.Offset, props, parseErr = ParsePoint(shadow.OffsetX, shadow.OffsetY, "shadowOffsetX", "shadowOffsetY", props)
+			err = CombineErrors(err, parseErr)
+		}
+		if shadow.Blur != "" {
+			effects.Shadow.Blur, props, parseErr = ExtractFloat(shadow.Blur, "shadowBlur", props)
+			err = CombineErrors(err, parseErr)
+		}
+		if shadow.Colour != nil {
+			effects.Shadow.Colour, props, parseErr = ParseColourStrings(*shadow.Colour, "shadow", props)
+			err = CombineErrors(err, parseErr)
+		}
+	}
+	if background != nil {
+		effects.Background = &TextBackground{}
+		effects.Background.Colour, props, parseErr = ParseColourStrings(background.Colour, "background", props)
+		err = CombineErrors(err, parseErr)
+		if background.Padding != "" {
+			effects.Background.Padding, props, parseErr = ExtractInt(background.Padding, "backgroundPadding", props)
+			err = CombineErrors(err, parseErr)
+		}
+		if background.CornerRadius != "" {
+			effects.Background.CornerRadius, props, parseErr = ExtractInt(background.CornerRadius, "backgroundCornerRadius", props)
+			err = CombineErrors(err, parseErr)
+		}
+	}
+	if err != nil {
+		return TextEffects{}, inputProps, err
+	}
+	return effects, props, nil
+}
+
+// IsTextEffectsProperty returns whether the name is of a property named as by ParseTextEffects
+func IsTextEffectsProperty(name string) bool {
+	for _, prefix := range textEffectsPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTextEffectsProperty sets a property of text effects named as by ParseTextEffects, copying the effect it changes so that it isn't shared with the original
+func SetTextEffectsProperty(effects *TextEffects, name string, value interface{}) (err error) {
+	invalid := fmt.Errorf("invalid component property in named property map: %v", name)
+	switch {
+	case strings.HasPrefix(name, "stroke") && effects.Stroke != nil:
+		stroke := *effects.Stroke
+		effects.Stroke = &stroke
+		switch name {
+		case "strokeWidth":
+			stroke.Width, err = SetFloat64(value)
+		default:
+			err = setColourChannel(&stroke.Colour, "stroke", name, value)
+		}
+	case strings.HasPrefix(name, "shadow") && effects.Shadow != nil:
+		shadow := *effects.Shadow
+		effects.Shadow = &shadow
+		switch name {
+		case "shadowOffsetX":
+			shadow.Offset.X, err = SetInt(value)
+		case "shadowOffsetY":
+			shadow.Offset.Y, err = SetInt(value)
+		case "shadowBlur":
+			shadow.Blur, err = SetFloat64(value)
+		default:
+			err = setColourChannel(&shadow.Colour, "shadow", name, value)
+		}
+	case strings.HasPrefix(name, "background") && effects.Background != nil:
+		background := *effects.Background
+		effects.Background = &background
+		switch name {
+		case "backgroundPadding":
+			background.Padding, err = SetInt(value)
+		case "backgroundCornerRadius":
+			background.CornerRadius, err = SetInt(value)
+		default:
+			err = setColourChannel(&background.Colour, "background", name, value)
+		}
+	default:
+		err = invalid
+	}
+	return err
+}
+
+// setColourChannel sets the channel of the colour named by the prefix followed by R, G, B or A
+func setColourChannel(colour *color.NRGBA, prefix, name string, value interface{}) (err error) {
+	switch strings.TrimPrefix(name, prefix) {
+	case "R":
+		colour.R, err = SetUint8(value)
+	case "G":
+		colour.G, err = SetUint8(value)
+	case "B":
+		colour.B, err = SetUint8(value)
+	case "A":
+		colour.A, err = SetUint8(value)
+	default:
+		err = fmt.Errorf("invalid component property in named property map: %v", name)
+	}
+	return err
+}
+
+// TextBounds returns the box taken up by a line of text of the width, from the ascent to the descent of the face, with the baseline starting at start.
+func TextBounds(start image.Point, width int, face font.Face) image.Rectangle {
+	metrics := face.Metrics()
+	return image.Rect(start.X, start.Y-metrics.Ascent.Ceil(), start.X+width, start.Y+metrics.Descent.Ceil())
+}
+
+/*
+Write draws the background of the effects around the bounds of the text, then calls draw to draw the
+text over it, on a layer casting the shadow of the effects. If there is a stroke, draw is called
+twice, first with outline set to draw just the outlines, so that no outline is drawn over the text,
+then again to draw the text itself. Drawing each piece of text with Text takes care of both.
+*/
+func (effects TextEffects) Write(canvas render.Canvas, bounds image.Rectangle, draw func(canvas render.Canvas, outline bool) (render.Canvas, error)) (render.Canvas, error) {
+	c := canvas
+	var err error
+	if background := effects.Background; background != nil {
+		inset := background.Padding
+		if effects.Stroke != nil {
+			inset += int(math.Ceil(effects.Stroke.Width))
+		}
+		box := bounds.Inset(-inset)
+		if background.CornerRadius > 0 {
+			radius := float64(background.CornerRadius)
+			c, err = c.Path(render.NewRectanglePath(box.Min, box.Dx(), box.Dy(), render.CornerRadii{TopLeft: radius, TopRight: radius, BottomRight: radius, BottomLeft: radius}), background.Colour, nil, render.StrokeStyle{})
+		} else {
+			c, err = c.Rectangle(box.Min, box.Dx(), box.Dy(), background.Colour)
+		}
+		if err != nil {
+			return canvas, err
+		}
+	}
+	write := func(layer render.Canvas) (render.Canvas, error) {
+		if effects.Stroke != nil {
+			var err error
+			layer, err = draw(layer, true)
+			if err != nil {
+				return layer, err
+			}
+		}
+		return draw(layer, false)
+	}
+	if effects.Shadow != nil {
+		c, err = c.Layer(render.LayerStyle{Opacity: 1, Shadow: effects.Shadow}, write)
+	} else {
+		c, err = write(c)
+	}
+	if err != nil {
+		return canvas, err
+	}
+	return c, nil
+}
+
+// Text draws text as Canvas.Text does, or if outline is set, just the outline of the stroke of the effects around the glyphs.
+func (effects TextEffects) Text(canvas render.Canvas, outline bool, text string, start image.Point, face font.Face, colour color.Color, maxWidth int) (render.Canvas, error) {
+	if !outline {
+		return canvas.Text(text, start, face, colour, maxWidth)
+	}
+	fontFace, isFontFace := face.(render.FontFace)
+	if effects.Stroke == nil || effects.Stroke.Width <= 0 || !isFontFace || strings.TrimSpace(text) == "" {
+		return canvas, nil
+	}
+	// The outline is centred on the edges of the glyphs, and the inner half is covered by the text.
+	return canvas.Path(render.TextPath(text, start, fontFace), nil, effects.Stroke.Colour, render.StrokeStyle{Width: 2 * effects.Stroke.Width, Join: render.JoinRound})
+}
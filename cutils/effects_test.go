@@ -0,0 +1,158 @@
+package cutils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/golang/freetype/truetype"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestParseTextEffects(t *testing.T) {
+	red := ColourStrings{R: "255", G: "0", B: "0", A: "255"}
+	t.Run("none", func(t *testing.T) {
+		effects, props, err := ParseTextEffects(nil, nil, nil, map[string][]string{})
+		assert.Equal(t, TextEffects{}, effects)
+		assert.Equal(t, map[string][]string{}, props)
+		assert.NoError(t, err)
+	})
+	t.Run("defaults", func(t *testing.T) {
+		effects, props, err := ParseTextEffects(&TextStrokeStrings{Width: "1.5", Colour: red}, &TextShadowStrings{}, &TextBackgroundStrings{Colour: red}, map[string][]string{})
+		assert.Equal(t, TextEffects{
+			Stroke:     &TextStroke{Width: 1.5, Colour: color.NRGBA{R: 255, A: 255}},
+			Shadow:     &render.Shadow{Colour: color.NRGBA{A: 128}},
+			Background: &TextBackground{Colour: color.NRGBA{R: 255, A: 255}},
+		}, effects)
+		assert.Equal(t, map[string][]string{}, props)
+		assert.NoError(t, err)
+	})
+	t.Run("all set", func(t *testing.T) {
+		effects, props, err := ParseTextEffects(nil, &TextShadowStrings{OffsetX: "2", OffsetY: "$drop$", Blur: "3", Colour: &red}, &TextBackgroundStrings{Colour: red, Padding: "4", CornerRadius: "$radius$"}, map[string][]string{})
+		assert.Equal(t, TextEffects{
+			Shadow:     &render.Shadow{Offset: image.Pt(2, 0), Blur: 3, Colour: color.NRGBA{R: 255, A: 255}},
+			Background: &TextBackground{Colour: color.NRGBA{R: 255, A: 255}, Padding: 4},
+		}, effects)
+		assert.Equal(t, map[string][]string{"drop": {"shadowOffsetY"}, "radius": {"backgroundCornerRadius"}}, props)
+		assert.NoError(t, err)
+	})
+	t.Run("missing stroke width", func(t *testing.T) {
+		effects, props, err := ParseTextEffects(&TextStrokeStrings{Colour: red}, nil, nil, map[string][]string{"existing": {"size"}})
+		assert.Equal(t, TextEffects{}, effects)
+		assert.Equal(t, map[string][]string{"existing": {"size"}}, props)
+		assert.EqualError(t, err, "error parsing data for property strokeWidth: could not parse empty property")
+	})
+}
+
+func TestIsTextEffectsProperty(t *testing.T) {
+	for _, name := range []string{"strokeWidth", "shadowOffsetX", "backgroundR"} {
+		assert.True(t, IsTextEffectsProperty(name), name)
+	}
+	for _, name := range []string{"R", "gradientStop0R", "span0Text"} {
+		assert.False(t, IsTextEffectsProperty(name), name)
+	}
+}
+
+func TestSetTextEffectsProperty(t *testing.T) {
+	original := TextEffects{
+		Stroke:     &TextStroke{Width: 1},
+		Shadow:     &render.Shadow{},
+		Background: &TextBackground{},
+	}
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected TextEffects
+		err      string
+	}{
+		{name: "strokeWidth", value: 2.5, expected: TextEffects{Stroke: &TextStroke{Width: 2.5}, Shadow: &render.Shadow{}, Background: &TextBackground{}}},
+		{name: "strokeG", value: uint8(10), expected: TextEffects{Stroke: &TextStroke{Width: 1, Colour: color.NRGBA{G: 10}}, Shadow: &render.Shadow{}, Background: &TextBackground{}}},
+		{name: "shadowOffsetY", value: 3, expected: TextEffects{Stroke: &TextStroke{Width: 1}, Shadow: &render.Shadow{Offset: image.Pt(0, 3)}, Background: &TextBackground{}}},
+		{name: "shadowBlur", value: 1.5, expected: TextEffects{Stroke: &TextStroke{Width: 1}, Shadow: &render.Shadow{Blur: 1.5}, Background: &TextBackground{}}},
+		{name: "shadowA", value: uint8(64), expected: TextEffects{Stroke: &TextStroke{Width: 1}, Shadow: &render.Shadow{Colour: color.NRGBA{A: 64}}, Background: &TextBackground{}}},
+		{name: "backgroundPadding", value: 4, expected: TextEffects{Stroke: &TextStroke{Width: 1}, Shadow: &render.Shadow{}, Background: &TextBackground{Padding: 4}}},
+		{name: "backgroundCornerRadius", value: 5, expected: TextEffects{Stroke: &TextStroke{Width: 1}, Shadow: &render.Shadow{}, Background: &TextBackground{CornerRadius: 5}}},
+		{name: "backgroundB", value: uint8(7), expected: TextEffects{Stroke: &TextStroke{Width: 1}, Shadow: &render.Shadow{}, Background: &TextBackground{Colour: color.NRGBA{B: 7}}}},
+		{name: "strokeWidth", value: "wide", expected: TextEffects{Stroke: &TextStroke{Width: 0}, Shadow: &render.Shadow{}, Background: &TextBackground{}}, err: "error converting wide to float64"},
+		{name: "strokeX", value: 1, expected: TextEffects{Stroke: &TextStroke{Width: 1}, Shadow: &render.Shadow{}, Background: &TextBackground{}}, err: "invalid component property in named property map: strokeX"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			effects := original
+			err := SetTextEffectsProperty(&effects, test.name, test.value)
+			assert.Equal(t, test.expected, effects)
+			assert.Equal(t, TextEffects{Stroke: &TextStroke{Width: 1}, Shadow: &render.Shadow{}, Background: &TextBackground{}}, original, "the original effects should not change")
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+	t.Run("missing effect", func(t *testing.T) {
+		effects := TextEffects{}
+		err := SetTextEffectsProperty(&effects, "shadowBlur", 1.0)
+		assert.Equal(t, TextEffects{}, effects)
+		assert.EqualError(t, err, "invalid component property in named property map: shadowBlur")
+	})
+}
+
+func TestTextEffectsWrite(t *testing.T) {
+	goreg, err := truetype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	face := render.NewFontFace(goreg, &truetype.Options{Size: 10, Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: 72})
+	black := color.NRGBA{A: 255}
+	start := image.Pt(5, 15)
+	write := func(t *testing.T, effects TextEffects) string {
+		canvas, _ := render.NewSVGCanvas(60, 20)
+		modifiedCanvas, err := effects.Write(canvas, TextBounds(start, 20, face), func(c render.Canvas, outline bool) (render.Canvas, error) {
+			return effects.Text(c, outline, "hi", start, face, black, 50)
+		})
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		var buf bytes.Buffer
+		modifiedCanvas.(render.SVGCanvas).WriteSVG(&buf)
+		return buf.String()
+	}
+	t.Run("none", func(t *testing.T) {
+		svg := write(t, TextEffects{})
+		assert.Contains(t, svg, `<text x="5" y="15"`)
+		assert.NotContains(t, svg, "<path")
+		assert.NotContains(t, svg, "<filter")
+	})
+	t.Run("stroke", func(t *testing.T) {
+		svg := write(t, TextEffects{Stroke: &TextStroke{Width: 1, Colour: color.NRGBA{R: 255, A: 255}}})
+		assert.Contains(t, svg, `fill="none" stroke="#ff0000" stroke-width="2" stroke-linecap="butt" stroke-linejoin="round"/>`)
+		assert.Regexp(t, `(?s)stroke="#ff0000".*<text x="5" y="15"`, svg, "the outline should be drawn under the text")
+	})
+	t.Run("shadow", func(t *testing.T) {
+		svg := write(t, TextEffects{Shadow: &render.Shadow{Offset: image.Pt(1, 2), Colour: color.NRGBA{A: 128}}})
+		assert.Contains(t, svg, `<feOffset dx="1" dy="2"/>`)
+		assert.Regexp(t, `(?s)<g filter="url\(#filter1\)">\s*<text x="5" y="15"`, svg)
+	})
+	t.Run("background", func(t *testing.T) {
+		svg := write(t, TextEffects{Background: &TextBackground{Colour: color.NRGBA{B: 255, A: 255}, Padding: 2}})
+		assert.Contains(t, svg, `<rect x="3" y="3" width="24" height="17" fill="#0000ff"/>`)
+	})
+	t.Run("rounded background around stroke", func(t *testing.T) {
+		svg := write(t, TextEffects{Stroke: &TextStroke{Width: 1, Colour: black}, Background: &TextBackground{Colour: color.NRGBA{B: 255, A: 255}, Padding: 2, CornerRadius: 3}})
+		assert.Contains(t, svg, `<path d="M 5 2 L 25 2 C`)
+		assert.Contains(t, svg, `fill="#0000ff"/>`)
+	})
+}
+
+func TestTextBounds(t *testing.T) {
+	goreg, err := truetype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	face := render.NewFontFace(goreg, &truetype.Options{Size: 10, DPI: 72})
+	assert.Equal(t, image.Rect(5, 5, 25, 18), TextBounds(image.Pt(5, 15), 20, face))
+}
@@ -46,3 +46,32 @@ func ExtractTextAlignment(raw, name string, props map[string][]string) (TextAlig
 	str, newProps, err := ExtractString(raw, name, props)
 	return StringToAlignment(str), newProps, err
 }
+
+// ExtractTextOverflow extracts a TextOverflow from the raw JSON data, leaving it empty if there is none
+func ExtractTextOverflow(raw, name string, props map[string][]string) (TextOverflow, map[string][]string, error) {
+	if raw == "" {
+		return "", props, nil
+	}
+	str, newProps, err := ExtractString(raw, name, props)
+	if err != nil || str == "" {
+		return "", newProps, err
+	}
+	overflow, err := StringToOverflow(str)
+	if err != nil {
+		return "", props, err
+	}
+	return overflow, newProps, nil
+}
+
+// ExtractBool extracts a bool or variable(s) from the raw JSON data
+func ExtractBool(raw, name string, props map[string][]string) (bool, map[string][]string, error) {
+	newProps, newVal, err := render.ExtractSingleProp(raw, name, render.BoolType, props)
+	if err != nil {
+		return false, props, err
+	}
+	var foundBool bool
+	if newVal != nil {
+		foundBool = newVal.(bool)
+	}
+	return foundBool, newProps, nil
+}
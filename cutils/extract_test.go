@@ -69,9 +69,63 @@ func TestExtractFloat(t *testing.T) {
 	})
 }
 
+func TestExtractBool(t *testing.T) {
+	t.Run("empty value", func(t *testing.T) {
+		b, props, err := ExtractBool("", "myProp", map[string][]string{})
+		assert.False(t, b)
+		assert.Equal(t, map[string][]string{}, props)
+		assert.EqualError(t, err, "error parsing data for property myProp: could not parse empty property")
+	})
+	t.Run("valid value", func(t *testing.T) {
+		b, props, err := ExtractBool("true", "myProp", map[string][]string{})
+		assert.True(t, b)
+		assert.Equal(t, map[string][]string{}, props)
+		assert.NoError(t, err)
+	})
+	t.Run("invalid value", func(t *testing.T) {
+		b, props, err := ExtractBool("maybe", "myProp", map[string][]string{})
+		assert.False(t, b)
+		assert.Equal(t, map[string][]string{}, props)
+		assert.EqualError(t, err, "failed to convert property myProp to bool: strconv.ParseBool: parsing \"maybe\": invalid syntax")
+	})
+	t.Run("extracted props", func(t *testing.T) {
+		b, props, err := ExtractBool("$hello$", "myProp", map[string][]string{"preExisting": {"something"}})
+		assert.False(t, b)
+		assert.Equal(t, map[string][]string{"preExisting": {"something"}, "hello": {"myProp"}}, props)
+		assert.NoError(t, err)
+	})
+}
+
 func TestExtractAlignment(t *testing.T) {
 	alignment, props, err := ExtractTextAlignment("right", "al", map[string][]string{})
 	assert.Equal(t, TextAlignmentRight, alignment)
 	assert.Equal(t, map[string][]string{}, props)
 	assert.NoError(t, err)
 }
+
+func TestExtractTextOverflow(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		overflow, props, err := ExtractTextOverflow("", "overflow", map[string][]string{})
+		assert.Equal(t, TextOverflow(""), overflow)
+		assert.Equal(t, map[string][]string{}, props)
+		assert.NoError(t, err)
+	})
+	t.Run("valid", func(t *testing.T) {
+		overflow, props, err := ExtractTextOverflow("ellipsis", "overflow", map[string][]string{})
+		assert.Equal(t, OverflowEllipsis, overflow)
+		assert.Equal(t, map[string][]string{}, props)
+		assert.NoError(t, err)
+	})
+	t.Run("named property", func(t *testing.T) {
+		overflow, props, err := ExtractTextOverflow("$mode$", "overflow", map[string][]string{})
+		assert.Equal(t, TextOverflow(""), overflow)
+		assert.Equal(t, map[string][]string{"mode": {"overflow"}}, props)
+		assert.NoError(t, err)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		overflow, props, err := ExtractTextOverflow("wrap", "overflow", map[string][]string{})
+		assert.Equal(t, TextOverflow(""), overflow)
+		assert.Equal(t, map[string][]string{}, props)
+		assert.EqualError(t, err, "overflow wrap does not match defined constants")
+	})
+}
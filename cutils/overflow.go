@@ -0,0 +1,88 @@
+package cutils
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"golang.org/x/image/font"
+)
+
+// TextOverflow is how text too wide for its maximum width is fitted.
+type TextOverflow string
+
+const (
+	// OverflowScale scales the text down until it fits
+	OverflowScale TextOverflow = "scale"
+	// OverflowEllipsis cuts the text short with an ellipsis
+	OverflowEllipsis TextOverflow = "ellipsis"
+	// OverflowClip cuts the text off at the maximum width
+	OverflowClip TextOverflow = "clip"
+	// OverflowError refuses to draw the text
+	OverflowError TextOverflow = "error"
+)
+
+// Ellipsis is added to the end of text cut short.
+const Ellipsis = "…"
+
+// StringToOverflow converts strings to TextOverflows, erroring on anything unknown.
+func StringToOverflow(overflow string) (TextOverflow, error) {
+	switch converted := TextOverflow(overflow); converted {
+	case OverflowScale, OverflowEllipsis, OverflowClip, OverflowError:
+		return converted, nil
+	default:
+		return "", fmt.Errorf("overflow %v does not match defined constants", overflow)
+	}
+}
+
+// EllipsiseText returns the longest start of the text which fits with an ellipsis added, or an empty string if not even the ellipsis fits.
+func EllipsiseText(text string, fits func(string) bool) string {
+	runes := []rune(text)
+	shortened := ""
+	// Binary search for the longest fitting start, since wider text never fits where narrower text doesn't.
+	low, high := 0, len(runes)
+	for low <= high {
+		middle := (low + high) / 2
+		candidate := strings.TrimRight(string(runes[:middle]), " ") + Ellipsis
+		if fits(candidate) {
+			shortened = candidate
+			low = middle + 1
+		} else {
+			high = middle - 1
+		}
+	}
+	return shortened
+}
+
+// WriteOverflowingText draws a single line of text at its full size with its effects, aligned within maxWidth, fitting it with the overflow mode if it is too wide. Scaling is left to the caller, as it needs to try each size in turn.
+func WriteOverflowingText(canvas render.Canvas, text string, start image.Point, face font.Face, colour color.Color, maxWidth int, alignment TextAlignment, overflow TextOverflow, effects TextEffects) (render.Canvas, error) {
+	fits, width := canvas.TryText(text, start, face, colour, maxWidth)
+	if !fits {
+		switch overflow {
+		case OverflowEllipsis:
+			text = EllipsiseText(text, func(candidate string) bool {
+				fits, _ := canvas.TryText(candidate, start, face, colour, maxWidth)
+				return fits
+			})
+			_, width = canvas.TryText(text, start, face, colour, maxWidth)
+		case OverflowClip:
+			// The clip only needs to cut the text off horizontally, so it reaches well above and below the line.
+			height := face.Metrics().Height.Ceil()
+			clip := &render.Clip{Path: render.NewRectanglePath(image.Pt(start.X, start.Y-2*height), maxWidth, 4*height, render.CornerRadii{})}
+			return effects.Write(canvas, TextBounds(start, maxWidth, face), func(c render.Canvas, outline bool) (render.Canvas, error) {
+				return c.Layer(render.LayerStyle{Opacity: 1, Clip: clip}, func(layer render.Canvas) (render.Canvas, error) {
+					return effects.Text(layer, outline, text, start, face, colour, width)
+				})
+			})
+		default:
+			return canvas, fmt.Errorf("text %v is wider than maxWidth %d", text, maxWidth)
+		}
+	}
+	_, alignmentOffset := ScaleFontsToWidth(0, width, maxWidth, alignment)
+	start = image.Pt(start.X+alignmentOffset, start.Y)
+	return effects.Write(canvas, TextBounds(start, width, face), func(c render.Canvas, outline bool) (render.Canvas, error) {
+		return effects.Text(c, outline, text, start, face, colour, maxWidth)
+	})
+}
@@ -0,0 +1,73 @@
+package cutils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/golang/freetype/truetype"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestStringToOverflow(t *testing.T) {
+	for _, overflow := range []TextOverflow{OverflowScale, OverflowEllipsis, OverflowClip, OverflowError} {
+		converted, err := StringToOverflow(string(overflow))
+		assert.Equal(t, overflow, converted)
+		assert.NoError(t, err)
+	}
+	converted, err := StringToOverflow("wrap")
+	assert.Equal(t, TextOverflow(""), converted)
+	assert.EqualError(t, err, "overflow wrap does not match defined constants")
+}
+
+func TestEllipsiseText(t *testing.T) {
+	shorterThan := func(length int) func(string) bool {
+		return func(text string) bool { return len([]rune(text)) < length }
+	}
+	assert.Equal(t, "hello…", EllipsiseText("hello", shorterThan(10)), "text which fits should keep all of it")
+	assert.Equal(t, "hello…", EllipsiseText("hello world", shorterThan(8)), "trailing spaces should be trimmed")
+	assert.Equal(t, "hello w…", EllipsiseText("hello world", shorterThan(9)))
+	assert.Equal(t, "…", EllipsiseText("hello", shorterThan(2)))
+	assert.Equal(t, "", EllipsiseText("hello", shorterThan(1)))
+}
+
+func TestWriteOverflowingText(t *testing.T) {
+	goreg, err := truetype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	face := render.NewFontFace(goreg, &truetype.Options{Size: 10, Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64, DPI: 72})
+	black := color.NRGBA{A: 255}
+	write := func(t *testing.T, text string, alignment TextAlignment, overflow TextOverflow) string {
+		canvas, _ := render.NewSVGCanvas(60, 20)
+		modifiedCanvas, err := WriteOverflowingText(canvas, text, image.Pt(5, 15), face, black, 50, alignment, overflow, TextEffects{})
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		var buf bytes.Buffer
+		modifiedCanvas.(render.SVGCanvas).WriteSVG(&buf)
+		return buf.String()
+	}
+	t.Run("fits", func(t *testing.T) {
+		assert.Contains(t, write(t, "the quick", TextAlignmentRight, OverflowError), `<text x="13" y="15"`)
+	})
+	t.Run("ellipsis", func(t *testing.T) {
+		assert.Contains(t, write(t, "the quick brown fox", TextAlignmentLeft, OverflowEllipsis), `xml:space="preserve">the quic…</text>`)
+	})
+	t.Run("clip", func(t *testing.T) {
+		svg := write(t, "the quick brown fox", TextAlignmentRight, OverflowClip)
+		assert.Contains(t, svg, `<clipPath id="clip1">`)
+		assert.Contains(t, svg, `<text x="5" y="15"`, "clipped text should start at the start")
+		assert.Contains(t, svg, `xml:space="preserve">the quick brown fox</text>`)
+	})
+	t.Run("error", func(t *testing.T) {
+		canvas, _ := render.NewCanvas(60, 20)
+		modifiedCanvas, err := WriteOverflowingText(canvas, "the quick brown fox", image.Pt(5, 15), face, black, 50, TextAlignmentLeft, OverflowError, TextEffects{})
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "text the quick brown fox is wider than maxWidth 50")
+	})
+}
@@ -114,3 +114,60 @@ func ParsePoint(x, y, xName, yName string, props map[string][]string) (image.Poi
 	}
 	return image.Pt(pX, pY), newProps, CombineErrors(xErr, yErr)
 }
+
+// GradientStrings is the template format of a gradient fill
+type GradientStrings struct {
+	// Type is linear or radial, defaulting to linear
+	Type string `json:"type"`
+	// Angle is the direction of a linear gradient in degrees clockwise from the positive X axis
+	Angle string `json:"angle"`
+	// Stops are the colours to blend between, in order
+	Stops []GradientStopStrings `json:"stops"`
+}
+
+// GradientStopStrings is the template format of a colour stop in a gradient
+type GradientStopStrings struct {
+	// Offset is the position of the stop from 0 to 1, defaulting to spacing the stops evenly
+	Offset string `json:"offset"`
+	// Colour is the colour at the stop
+	Colour ColourStrings `json:"colour"`
+}
+
+// ParseGradient turns a set of gradient strings and an identifying prefix into a render.Gradient, naming the properties of each stop like prefixStop0Offset and prefixStop0R
+func ParseGradient(gradient GradientStrings, prefix string, inputProps map[string][]string) (render.Gradient, map[string][]string, error) {
+	if len(gradient.Stops) < 2 {
+		return render.Gradient{}, inputProps, fmt.Errorf("a gradient needs at least 2 colour stops, got %d", len(gradient.Stops))
+	}
+	parsed := render.Gradient{Type: render.GradientLinear, Stops: make([]render.GradientStop, len(gradient.Stops))}
+	var err error
+	props := inputProps
+	if gradient.Type != "" {
+		var newVal interface{}
+		var parseErr error
+		props, newVal, parseErr = render.ExtractSingleProp(gradient.Type, prefix+"Type", render.StringType, props)
+		if parseErr == nil && newVal != nil {
+			parsed.Type, parseErr = render.ToGradientType(newVal.(string))
+		}
+		err = CombineErrors(err, parseErr)
+	}
+	if gradient.Angle != "" {
+		var parseErr error
+		parsed.Angle, props, parseErr = ExtractFloat(gradient.Angle, prefix+"Angle", props)
+		err = CombineErrors(err, parseErr)
+	}
+	for i, stop := range gradient.Stops {
+		stopPrefix := fmt.Sprintf("%sStop%d", prefix, i)
+		parsed.Stops[i].Offset = float64(i) / float64(len(gradient.Stops)-1)
+		var parseErr error
+		if stop.Offset != "" {
+			parsed.Stops[i].Offset, props, parseErr = ExtractFloat(stop.Offset, stopPrefix+"Offset", props)
+			err = CombineErrors(err, parseErr)
+		}
+		parsed.Stops[i].Colour, props, parseErr = ParseColourStrings(stop.Colour, stopPrefix, props)
+		err = CombineErrors(err, parseErr)
+	}
+	if err != nil {
+		return render.Gradient{}, inputProps, err
+	}
+	return parsed, props, nil
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/LLKennedy/imagetemplate/v3/internal/filesystem"
+	"github.com/LLKennedy/imagetemplate/v3/render"
 	"github.com/golang/freetype/truetype"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/image/font/gofont/goregular"
@@ -27,6 +28,47 @@ func TestParseColourStrings(t *testing.T) {
 	})
 }
 
+func TestParseGradient(t *testing.T) {
+	red, blue := ColourStrings{R: "255", G: "0", B: "0", A: "255"}, ColourStrings{R: "0", G: "0", B: "255", A: "255"}
+	t.Run("defaults", func(t *testing.T) {
+		gradient, props, err := ParseGradient(GradientStrings{Stops: []GradientStopStrings{{Colour: red}, {Colour: blue}, {Colour: red}}}, "gradient", map[string][]string{})
+		assert.Equal(t, render.Gradient{Type: render.GradientLinear, Stops: []render.GradientStop{
+			{Offset: 0, Colour: color.NRGBA{R: 255, A: 255}},
+			{Offset: 0.5, Colour: color.NRGBA{B: 255, A: 255}},
+			{Offset: 1, Colour: color.NRGBA{R: 255, A: 255}},
+		}}, gradient)
+		assert.Equal(t, map[string][]string{}, props)
+		assert.NoError(t, err)
+	})
+	t.Run("all set", func(t *testing.T) {
+		gradient, props, err := ParseGradient(GradientStrings{Type: "radial", Angle: "45", Stops: []GradientStopStrings{{Offset: "0.2", Colour: red}, {Offset: "$end$", Colour: ColourStrings{R: "0", G: "0", B: "$blue$", A: "255"}}}}, "fill", map[string][]string{})
+		assert.Equal(t, render.Gradient{Type: render.GradientRadial, Angle: 45, Stops: []render.GradientStop{
+			{Offset: 0.2, Colour: color.NRGBA{R: 255, A: 255}},
+			{Colour: color.NRGBA{A: 255}},
+		}}, gradient)
+		assert.Equal(t, map[string][]string{"end": {"fillStop1Offset"}, "blue": {"fillStop1B"}}, props)
+		assert.NoError(t, err)
+	})
+	t.Run("named type", func(t *testing.T) {
+		gradient, props, err := ParseGradient(GradientStrings{Type: "$shape$", Stops: []GradientStopStrings{{Colour: red}, {Colour: blue}}}, "gradient", map[string][]string{})
+		assert.Equal(t, render.GradientLinear, gradient.Type)
+		assert.Equal(t, map[string][]string{"shape": {"gradientType"}}, props)
+		assert.NoError(t, err)
+	})
+	t.Run("too few stops", func(t *testing.T) {
+		gradient, props, err := ParseGradient(GradientStrings{Stops: []GradientStopStrings{{Colour: red}}}, "gradient", map[string][]string{})
+		assert.Equal(t, render.Gradient{}, gradient)
+		assert.Equal(t, map[string][]string{}, props)
+		assert.EqualError(t, err, "a gradient needs at least 2 colour stops, got 1")
+	})
+	t.Run("all failing", func(t *testing.T) {
+		gradient, props, err := ParseGradient(GradientStrings{Type: "conic", Angle: "steep", Stops: []GradientStopStrings{{Offset: "start", Colour: red}, {Colour: ColourStrings{R: "$red$", G: "0", B: "0"}}}}, "gradient", map[string][]string{})
+		assert.Equal(t, render.Gradient{}, gradient)
+		assert.Equal(t, map[string][]string{"red": {"gradientStop1R"}}, props)
+		assert.EqualError(t, err, "gradient type conic does not match defined constants\nfailed to convert property gradientAngle to float64: strconv.ParseFloat: parsing \"steep\": invalid syntax\nfailed to convert property gradientStop0Offset to float64: strconv.ParseFloat: parsing \"start\": invalid syntax\nerror parsing data for property gradientStop1A: could not parse empty property")
+	})
+}
+
 type fakeSysFonts struct{}
 
 func (f fakeSysFonts) GetFont(req string) (*truetype.Font, error) {
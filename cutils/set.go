@@ -5,8 +5,11 @@ import (
 	"image"
 	"image/color"
 	"io/ioutil"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/LLKennedy/imagetemplate/v3/render"
 	"github.com/golang/freetype/truetype"
 	"golang.org/x/tools/godoc/vfs"
 )
@@ -26,7 +29,7 @@ func LoadFontFile(fs vfs.FileSystem, fileName interface{}) (*truetype.Font, erro
 	if err != nil {
 		return nil, err
 	}
-	return truetype.Parse(fontData)
+	return render.ParseFont(fontData)
 }
 
 // SetString turns an interface into a string and an error
@@ -35,6 +38,18 @@ func SetString(value interface{}) (string, error) {
 	return str.(string), err
 }
 
+// SetTextOverflow turns an interface into a TextOverflow and an error
+func SetTextOverflow(value interface{}) (TextOverflow, error) {
+	if overflow, ok := value.(TextOverflow); ok {
+		return StringToOverflow(string(overflow))
+	}
+	str, err := SetString(value)
+	if err != nil {
+		return "", err
+	}
+	return StringToOverflow(str)
+}
+
 // SetInt turns an interface into a string and an error
 func SetInt(value interface{}) (int, error) {
 	i, err := setType(value, setIntType)
@@ -146,3 +161,49 @@ func setType(value interface{}, typeName setTypes) (converted interface{}, err e
 	}
 	return
 }
+
+// SetGradientProperty sets a property of a gradient named as by ParseGradient with the same prefix
+func SetGradientProperty(gradient *render.Gradient, prefix, name string, value interface{}) (err error) {
+	invalid := fmt.Errorf("invalid component property in named property map: %v", name)
+	switch name {
+	case prefix + "Type":
+		var typeName string
+		typeName, err = SetString(value)
+		if err == nil {
+			gradient.Type, err = render.ToGradientType(typeName)
+		}
+		return err
+	case prefix + "Angle":
+		gradient.Angle, err = SetFloat64(value)
+		return err
+	}
+	if !strings.HasPrefix(name, prefix+"Stop") {
+		return invalid
+	}
+	stop := strings.TrimPrefix(name, prefix+"Stop")
+	digits := strings.IndexFunc(stop, func(r rune) bool { return r < '0' || r > '9' })
+	if digits <= 0 {
+		return invalid
+	}
+	i, convErr := strconv.Atoi(stop[:digits])
+	if convErr != nil || i >= len(gradient.Stops) {
+		return invalid
+	}
+	// Components are copied before their properties are set, so the stops must not be shared with the original
+	gradient.Stops = append([]render.GradientStop(nil), gradient.Stops...)
+	switch stop[digits:] {
+	case "Offset":
+		gradient.Stops[i].Offset, err = SetFloat64(value)
+	case "R":
+		gradient.Stops[i].Colour.R, err = SetUint8(value)
+	case "G":
+		gradient.Stops[i].Colour.G, err = SetUint8(value)
+	case "B":
+		gradient.Stops[i].Colour.B, err = SetUint8(value)
+	case "A":
+		gradient.Stops[i].Colour.A, err = SetUint8(value)
+	default:
+		err = invalid
+	}
+	return err
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/LLKennedy/imagetemplate/v3/internal/filesystem"
+	"github.com/LLKennedy/imagetemplate/v3/render"
 	"github.com/golang/freetype/truetype"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/image/font/gofont/goregular"
@@ -56,6 +57,23 @@ func TestSetString(t *testing.T) {
 	})
 }
 
+func TestSetTextOverflow(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		converted, err := SetTextOverflow("clip")
+		assert.Equal(t, OverflowClip, converted)
+		assert.NoError(t, err)
+		converted, err = SetTextOverflow(OverflowError)
+		assert.Equal(t, OverflowError, converted)
+		assert.NoError(t, err)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := SetTextOverflow(1)
+		assert.EqualError(t, err, "error converting 1 to string")
+		_, err = SetTextOverflow(TextOverflow("wrap"))
+		assert.EqualError(t, err, "overflow wrap does not match defined constants")
+	})
+}
+
 func TestSetInt(t *testing.T) {
 	t.Run("valid", func(t *testing.T) {
 		converted, err := SetInt(1)
@@ -173,3 +191,43 @@ func TestSetColour(t *testing.T) {
 		assert.EqualError(t, err, "error converting a to colour")
 	})
 }
+
+func TestSetGradientProperty(t *testing.T) {
+	type testSet struct {
+		name  string
+		value interface{}
+		res   render.Gradient
+		err   string
+	}
+	tests := []testSet{
+		{name: "fillType", value: "radial", res: render.Gradient{Type: render.GradientRadial}},
+		{name: "fillType", value: "conic", err: "gradient type conic does not match defined constants"},
+		{name: "fillType", value: 5, err: "error converting 5 to string"},
+		{name: "fillAngle", value: 30.0, res: render.Gradient{Angle: 30}},
+		{name: "fillStop1Offset", value: 0.75, res: render.Gradient{Stops: []render.GradientStop{{}, {Offset: 0.75}}}},
+		{name: "fillStop0R", value: uint8(1), res: render.Gradient{Stops: []render.GradientStop{{Colour: color.NRGBA{R: 1}}, {}}}},
+		{name: "fillStop0G", value: uint8(2), res: render.Gradient{Stops: []render.GradientStop{{Colour: color.NRGBA{G: 2}}, {}}}},
+		{name: "fillStop0B", value: uint8(3), res: render.Gradient{Stops: []render.GradientStop{{Colour: color.NRGBA{B: 3}}, {}}}},
+		{name: "fillStop0A", value: uint8(4), res: render.Gradient{Stops: []render.GradientStop{{Colour: color.NRGBA{A: 4}}, {}}}},
+		{name: "fillStop0A", value: 4, err: "error converting 4 to uint8"},
+		{name: "fillStop2A", value: uint8(4), err: "invalid component property in named property map: fillStop2A"},
+		{name: "fillStopR", value: uint8(4), err: "invalid component property in named property map: fillStopR"},
+		{name: "fillStop0Colour", value: uint8(4), err: "invalid component property in named property map: fillStop0Colour"},
+		{name: "fillColour", value: uint8(4), err: "invalid component property in named property map: fillColour"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gradient := render.Gradient{}
+			if test.res.Stops != nil || test.err != "" {
+				gradient.Stops = make([]render.GradientStop, 2)
+			}
+			err := SetGradientProperty(&gradient, "fill", test.name, test.value)
+			if test.err == "" {
+				assert.Equal(t, test.res, gradient)
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"image"
+	"image/draw"
 	"io"
 	"io/ioutil"
 	"runtime/debug"
@@ -37,6 +38,20 @@ type WriteOptions interface {
 	ToCanvas(props render.NamedProperties) (render.Canvas, error)
 	ToImage(props render.NamedProperties) (image.Image, error)
 	ToBMPReader(props render.NamedProperties) (io.Reader, error)
+	ToPNG(props render.NamedProperties) ([]byte, error)
+	ToJPEG(props render.NamedProperties, quality int) ([]byte, error)
+	ToGIF(props render.NamedProperties) ([]byte, error)
+	ToTIFF(props render.NamedProperties) ([]byte, error)
+	ToFormat(props render.NamedProperties, format scaffold.ImageFormat, opts *scaffold.EncodeOptions) ([]byte, error)
+	ToWriter(props render.NamedProperties, w io.Writer, format scaffold.ImageFormat, opts *scaffold.EncodeOptions) error
+	ToPDF(props render.NamedProperties) ([]byte, error)
+	ToSVG(props render.NamedProperties) ([]byte, error)
+	ToZPL(props render.NamedProperties) ([]byte, error)
+	ToESCPOS(props render.NamedProperties, dotWidth int) ([]byte, error)
+	ToDitheredImage(props render.NamedProperties, opts scaffold.DitherOptions) (image.Image, error)
+	ToDitheredPNG(props render.NamedProperties, opts scaffold.DitherOptions) ([]byte, error)
+	ToAnimatedGIF(frames []render.NamedProperties, delays []int) ([]byte, error)
+	ToAPNG(frames []render.NamedProperties, delays []int) ([]byte, error)
 }
 
 type loader struct {
@@ -203,6 +218,222 @@ func (l loader) ToBMPReader(props render.NamedProperties) (rdr io.Reader, err er
 	return bytes.NewReader(rawData), nil
 }
 
+// ToPNG returns the finished render as the bytes of a PNG file.
+func (l loader) ToPNG(props render.NamedProperties) (raw []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic in ToPNG: %v\n%s", r, debug.Stack())
+		}
+	}()
+	l.builder, err = applyProps(l.builder, props)
+	if err != nil {
+		return nil, err
+	}
+	return l.builder.WriteToPNG()
+}
+
+// ToJPEG returns the finished render as the bytes of a JPEG file of the specified quality, from 1 to 100.
+func (l loader) ToJPEG(props render.NamedProperties, quality int) (raw []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic in ToJPEG: %v\n%s", r, debug.Stack())
+		}
+	}()
+	l.builder, err = applyProps(l.builder, props)
+	if err != nil {
+		return nil, err
+	}
+	return l.builder.WriteToJPEG(quality)
+}
+
+// ToGIF returns the finished render as the bytes of a GIF file.
+func (l loader) ToGIF(props render.NamedProperties) (raw []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic in ToGIF: %v\n%s", r, debug.Stack())
+		}
+	}()
+	l.builder, err = applyProps(l.builder, props)
+	if err != nil {
+		return nil, err
+	}
+	return l.builder.WriteToGIF()
+}
+
+// ToTIFF returns the finished render as the bytes of a TIFF file.
+func (l loader) ToTIFF(props render.NamedProperties) (raw []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic in ToTIFF: %v\n%s", r, debug.Stack())
+		}
+	}()
+	l.builder, err = applyProps(l.builder, props)
+	if err != nil {
+		return nil, err
+	}
+	return l.builder.WriteToTIFF()
+}
+
+// ToFormat returns the finished render as the bytes of a file in the specified format, using any relevant encoding options provided.
+func (l loader) ToFormat(props render.NamedProperties, format scaffold.ImageFormat, opts *scaffold.EncodeOptions) (raw []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic in ToFormat: %v\n%s", r, debug.Stack())
+		}
+	}()
+	l.builder, err = applyProps(l.builder, props)
+	if err != nil {
+		return nil, err
+	}
+	return l.builder.WriteToFormat(format, opts)
+}
+
+// ToWriter streams the finished render to the provided writer as a file in the specified format, using any relevant encoding options provided.
+func (l loader) ToWriter(props render.NamedProperties, w io.Writer, format scaffold.ImageFormat, opts *scaffold.EncodeOptions) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic in ToWriter: %v\n%s", r, debug.Stack())
+		}
+	}()
+	l.builder, err = applyProps(l.builder, props)
+	if err != nil {
+		return err
+	}
+	return l.builder.WriteFormatTo(w, format, opts)
+}
+
+// ToPDF returns the finished render as the bytes of a PDF file, with components drawn as vector paths and text where possible. Fonts loaded from files are embedded whole, while fonts found by name are embedded as unhinted outlines of their glyphs, since their files aren't known.
+func (l loader) ToPDF(props render.NamedProperties) (raw []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic in ToPDF: %v\n%s", r, debug.Stack())
+		}
+	}()
+	l.builder, err = applyPropsTo(l.builder, props, func(base render.Canvas) (render.Canvas, error) {
+		return render.NewPDFCanvasFrom(base)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l.builder.WriteToPDF()
+}
+
+// ToSVG returns the finished render as the bytes of an SVG file, with components drawn as SVG elements where possible.
+func (l loader) ToSVG(props render.NamedProperties) (raw []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic in ToSVG: %v\n%s", r, debug.Stack())
+		}
+	}()
+	l.builder, err = applyPropsTo(l.builder, props, func(base render.Canvas) (render.Canvas, error) {
+		return render.NewSVGCanvasFrom(base)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l.builder.WriteToSVG()
+}
+
+// ToZPL returns the finished render as a ZPL II label for Zebra printers, with one pixel per printer dot. Barcodes and text are printed with the printer's native commands where possible.
+func (l loader) ToZPL(props render.NamedProperties) (raw []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic in ToZPL: %v\n%s", r, debug.Stack())
+		}
+	}()
+	l.builder, err = applyPropsTo(l.builder, props, func(base render.Canvas) (render.Canvas, error) {
+		return render.NewZPLCanvasFrom(base)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l.builder.WriteToZPL()
+}
+
+// ToESCPOS returns the finished render as ESC/POS raster commands for a receipt printer with the specified dot width, usually 384 or 576. Wider renders are scaled down to fit, and narrower ones are padded with white.
+func (l loader) ToESCPOS(props render.NamedProperties, dotWidth int) (raw []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic in ToESCPOS: %v\n%s", r, debug.Stack())
+		}
+	}()
+	l.builder, err = applyProps(l.builder, props)
+	if err != nil {
+		return nil, err
+	}
+	return l.builder.WriteToESCPOS(dotWidth)
+}
+
+// ToDitheredImage returns the finished render as a paletted greyscale image.Image object, reduced using the dither options.
+func (l loader) ToDitheredImage(props render.NamedProperties, opts scaffold.DitherOptions) (img image.Image, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic in ToDitheredImage: %v\n%s", r, debug.Stack())
+		}
+	}()
+	l.builder, err = applyProps(l.builder, props)
+	if err != nil {
+		return nil, err
+	}
+	dithered, err := scaffold.Dither(l.builder.GetCanvas().GetUnderlyingImage(), opts)
+	if err != nil {
+		return nil, err
+	}
+	return dithered, nil
+}
+
+// ToDitheredPNG returns the finished render as the bytes of a greyscale PNG file, reduced using the dither options.
+func (l loader) ToDitheredPNG(props render.NamedProperties, opts scaffold.DitherOptions) (raw []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic in ToDitheredPNG: %v\n%s", r, debug.Stack())
+		}
+	}()
+	l.builder, err = applyProps(l.builder, props)
+	if err != nil {
+		return nil, err
+	}
+	return l.builder.WriteToDitheredPNG(opts)
+}
+
+// ToAnimatedGIF renders the template once for each set of properties and returns the frames as the bytes of a looping animated GIF file. Delays are in hundredths of a second, either one per frame or a single delay for every frame.
+func (l loader) ToAnimatedGIF(frames []render.NamedProperties, delays []int) (raw []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic in ToAnimatedGIF: %v\n%s", r, debug.Stack())
+		}
+	}()
+	images, err := renderFrames(l.builder, frames)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	err = scaffold.WriteAnimatedGIF(buf, images, delays)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ToAPNG renders the template once for each set of properties and returns the frames as the bytes of a looping animated PNG file. Delays are in hundredths of a second, either one per frame or a single delay for every frame.
+func (l loader) ToAPNG(frames []render.NamedProperties, delays []int) (raw []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic in ToAPNG: %v\n%s", r, debug.Stack())
+		}
+	}()
+	images, err := renderFrames(l.builder, frames)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	err = scaffold.WriteAPNG(buf, images, delays)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func applyProps(builder scaffold.Builder, props render.NamedProperties) (newBuilder scaffold.Builder, err error) {
 	builder, err = builder.SetNamedProperties(props)
 	if err != nil {
@@ -211,3 +442,33 @@ func applyProps(builder scaffold.Builder, props render.NamedProperties) (newBuil
 	builder, err = builder.ApplyComponents()
 	return builder, err
 }
+
+// applyPropsTo sets the properties and then replaces the canvas with a different implementation seeded from the base image, before applying the components.
+func applyPropsTo(builder scaffold.Builder, props render.NamedProperties, convert func(base render.Canvas) (render.Canvas, error)) (newBuilder scaffold.Builder, err error) {
+	builder, err = builder.SetNamedProperties(props)
+	if err != nil {
+		return builder, err
+	}
+	canvas, err := convert(builder.GetCanvas())
+	if err != nil {
+		return builder, err
+	}
+	return builder.SetCanvas(canvas).ApplyComponents()
+}
+
+// renderFrames renders the builder once for each set of properties, each time starting from a fresh copy of the base image.
+func renderFrames(builder scaffold.Builder, frames []render.NamedProperties) ([]image.Image, error) {
+	base := builder.GetCanvas()
+	baseImage := base.GetUnderlyingImage()
+	images := make([]image.Image, 0, len(frames))
+	for i, props := range frames {
+		frame := image.NewNRGBA(baseImage.Bounds())
+		draw.Draw(frame, frame.Rect, baseImage, frame.Rect.Min, draw.Src)
+		frameBuilder, err := applyProps(builder.SetCanvas(base.SetUnderlyingImage(frame)), props)
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %v", i, err)
+		}
+		images = append(images, frameBuilder.GetCanvas().GetUnderlyingImage())
+	}
+	return images, nil
+}
@@ -5,8 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"io/ioutil"
 	"testing"
 
+	_ "github.com/LLKennedy/imagetemplate/v3/components/rectangle"
 	fs "github.com/LLKennedy/imagetemplate/v3/internal/filesystem"
 	"github.com/LLKennedy/imagetemplate/v3/render"
 	"github.com/LLKennedy/imagetemplate/v3/scaffold"
@@ -58,6 +64,50 @@ func (b *mockBuilder) WriteToBMP() ([]byte, error) {
 	args := b.Called()
 	return args.Get(0).([]byte), args.Error(1)
 }
+func (b *mockBuilder) WriteToPNG() ([]byte, error) {
+	args := b.Called()
+	return args.Get(0).([]byte), args.Error(1)
+}
+func (b *mockBuilder) WriteToJPEG(quality int) ([]byte, error) {
+	args := b.Called(quality)
+	return args.Get(0).([]byte), args.Error(1)
+}
+func (b *mockBuilder) WriteToGIF() ([]byte, error) {
+	args := b.Called()
+	return args.Get(0).([]byte), args.Error(1)
+}
+func (b *mockBuilder) WriteToTIFF() ([]byte, error) {
+	args := b.Called()
+	return args.Get(0).([]byte), args.Error(1)
+}
+func (b *mockBuilder) WriteToFormat(format scaffold.ImageFormat, opts *scaffold.EncodeOptions) ([]byte, error) {
+	args := b.Called(format, opts)
+	return args.Get(0).([]byte), args.Error(1)
+}
+func (b *mockBuilder) WriteFormatTo(w io.Writer, format scaffold.ImageFormat, opts *scaffold.EncodeOptions) error {
+	args := b.Called(w, format, opts)
+	return args.Error(0)
+}
+func (b *mockBuilder) WriteToPDF() ([]byte, error) {
+	args := b.Called()
+	return args.Get(0).([]byte), args.Error(1)
+}
+func (b *mockBuilder) WriteToSVG() ([]byte, error) {
+	args := b.Called()
+	return args.Get(0).([]byte), args.Error(1)
+}
+func (b *mockBuilder) WriteToZPL() ([]byte, error) {
+	args := b.Called()
+	return args.Get(0).([]byte), args.Error(1)
+}
+func (b *mockBuilder) WriteToESCPOS(dotWidth int) ([]byte, error) {
+	args := b.Called(dotWidth)
+	return args.Get(0).([]byte), args.Error(1)
+}
+func (b *mockBuilder) WriteToDitheredPNG(opts scaffold.DitherOptions) ([]byte, error) {
+	args := b.Called(opts)
+	return args.Get(0).([]byte), args.Error(1)
+}
 
 func TestLoadWrite(t *testing.T) {
 	l := New()
@@ -149,8 +199,30 @@ func TestWriteMethods(t *testing.T) {
 	mockCanvas := new(render.MockCanvas)
 	baseImage := image.NewNRGBA(image.Rect(0, 0, 2, 2))
 	mockCanvas.On("GetUnderlyingImage").Return(baseImage)
+	mockCanvas.On("GetWidth").Return(2)
+	mockCanvas.On("GetHeight").Return(2)
+	mockCanvas.On("GetPPI").Return(float64(300))
 	b.On("GetCanvas").Return(mockCanvas)
+	b.On("SetCanvas", mock.AnythingOfType("render.PDFCanvas")).Return(b)
+	b.On("WriteToPDF").Return([]byte("a pdf"), nil)
+	b.On("SetCanvas", mock.AnythingOfType("render.SVGCanvas")).Return(b)
+	b.On("WriteToSVG").Return([]byte("an svg"), fmt.Errorf("svg error"))
+	b.On("SetCanvas", mock.AnythingOfType("render.ZPLCanvas")).Return(b)
+	b.On("WriteToZPL").Return([]byte("a label"), nil)
+	b.On("WriteToESCPOS", 576).Return([]byte("a receipt"), nil)
+	ditherOpts := scaffold.DitherOptions{Method: scaffold.DitherAtkinson, Levels: 4}
+	b.On("WriteToDitheredPNG", ditherOpts).Return([]byte("a dithered png"), nil)
+	mockCanvas.On("SetUnderlyingImage", mock.AnythingOfType("*image.NRGBA")).Return(mockCanvas)
+	b.On("SetCanvas", mockCanvas).Return(b)
 	b.On("WriteToBMP").Return([]byte("a bmp"), fmt.Errorf("bmp error"))
+	b.On("WriteToPNG").Return([]byte("a png"), nil)
+	b.On("WriteToJPEG", 80).Return([]byte("a jpeg"), nil)
+	b.On("WriteToGIF").Return([]byte("a gif"), nil)
+	b.On("WriteToTIFF").Return([]byte("a tiff"), fmt.Errorf("tiff error"))
+	jpegOpts := &scaffold.EncodeOptions{JPEGQuality: 50}
+	b.On("WriteToFormat", scaffold.FormatJPEG, jpegOpts).Return([]byte("a formatted jpeg"), nil)
+	var writer bytes.Buffer
+	b.On("WriteFormatTo", &writer, scaffold.FormatPNG, (*scaffold.EncodeOptions)(nil)).Return(fmt.Errorf("write error"))
 	mfs := fs.NewMockFileSystem()
 	l := loader{
 		builder: b,
@@ -231,9 +303,227 @@ func TestWriteMethods(t *testing.T) {
 			})
 		})
 	})
+	t.Run("ToPNG", func(t *testing.T) {
+		t.Run("invalid props", func(t *testing.T) {
+			res, err := l.Write().ToPNG(badProps)
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "bad props")
+		})
+		t.Run("valid props", func(t *testing.T) {
+			res, err := l.Write().ToPNG(nilProps)
+			assert.Equal(t, []byte("a png"), res)
+			assert.NoError(t, err)
+		})
+	})
+	t.Run("ToJPEG", func(t *testing.T) {
+		t.Run("invalid props", func(t *testing.T) {
+			res, err := l.Write().ToJPEG(badProps, 80)
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "bad props")
+		})
+		t.Run("valid props", func(t *testing.T) {
+			res, err := l.Write().ToJPEG(nilProps, 80)
+			assert.Equal(t, []byte("a jpeg"), res)
+			assert.NoError(t, err)
+		})
+	})
+	t.Run("ToGIF", func(t *testing.T) {
+		t.Run("invalid props", func(t *testing.T) {
+			res, err := l.Write().ToGIF(badProps)
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "bad props")
+		})
+		t.Run("valid props", func(t *testing.T) {
+			res, err := l.Write().ToGIF(nilProps)
+			assert.Equal(t, []byte("a gif"), res)
+			assert.NoError(t, err)
+		})
+	})
+	t.Run("ToTIFF", func(t *testing.T) {
+		t.Run("invalid props", func(t *testing.T) {
+			res, err := l.Write().ToTIFF(badProps)
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "bad props")
+		})
+		t.Run("valid props", func(t *testing.T) {
+			res, err := l.Write().ToTIFF(nilProps)
+			assert.Equal(t, []byte("a tiff"), res)
+			assert.EqualError(t, err, "tiff error")
+		})
+	})
+	t.Run("ToFormat", func(t *testing.T) {
+		t.Run("invalid props", func(t *testing.T) {
+			res, err := l.Write().ToFormat(badProps, scaffold.FormatJPEG, jpegOpts)
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "bad props")
+		})
+		t.Run("valid props", func(t *testing.T) {
+			res, err := l.Write().ToFormat(nilProps, scaffold.FormatJPEG, jpegOpts)
+			assert.Equal(t, []byte("a formatted jpeg"), res)
+			assert.NoError(t, err)
+		})
+	})
+	t.Run("ToWriter", func(t *testing.T) {
+		t.Run("invalid props", func(t *testing.T) {
+			err := l.Write().ToWriter(badProps, &writer, scaffold.FormatPNG, nil)
+			assert.EqualError(t, err, "bad props")
+		})
+		t.Run("valid props", func(t *testing.T) {
+			err := l.Write().ToWriter(nilProps, &writer, scaffold.FormatPNG, nil)
+			assert.EqualError(t, err, "write error")
+		})
+	})
+	t.Run("ToPDF", func(t *testing.T) {
+		t.Run("invalid props", func(t *testing.T) {
+			res, err := l.Write().ToPDF(badProps)
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "bad props")
+		})
+		t.Run("valid props", func(t *testing.T) {
+			res, err := l.Write().ToPDF(nilProps)
+			assert.Equal(t, []byte("a pdf"), res)
+			assert.NoError(t, err)
+		})
+	})
+	t.Run("ToSVG", func(t *testing.T) {
+		t.Run("invalid props", func(t *testing.T) {
+			res, err := l.Write().ToSVG(badProps)
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "bad props")
+		})
+		t.Run("valid props", func(t *testing.T) {
+			res, err := l.Write().ToSVG(nilProps)
+			assert.Equal(t, []byte("an svg"), res)
+			assert.EqualError(t, err, "svg error")
+		})
+	})
+	t.Run("ToZPL", func(t *testing.T) {
+		t.Run("invalid props", func(t *testing.T) {
+			res, err := l.Write().ToZPL(badProps)
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "bad props")
+		})
+		t.Run("valid props", func(t *testing.T) {
+			res, err := l.Write().ToZPL(nilProps)
+			assert.Equal(t, []byte("a label"), res)
+			assert.NoError(t, err)
+		})
+	})
+	t.Run("ToESCPOS", func(t *testing.T) {
+		t.Run("invalid props", func(t *testing.T) {
+			res, err := l.Write().ToESCPOS(badProps, 576)
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "bad props")
+		})
+		t.Run("valid props", func(t *testing.T) {
+			res, err := l.Write().ToESCPOS(nilProps, 576)
+			assert.Equal(t, []byte("a receipt"), res)
+			assert.NoError(t, err)
+		})
+	})
+	t.Run("ToDitheredImage", func(t *testing.T) {
+		t.Run("invalid props", func(t *testing.T) {
+			res, err := l.Write().ToDitheredImage(badProps, ditherOpts)
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "bad props")
+		})
+		t.Run("invalid options", func(t *testing.T) {
+			res, err := l.Write().ToDitheredImage(nilProps, scaffold.DitherOptions{Method: "sketchy"})
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "unsupported dither method: sketchy")
+		})
+		t.Run("valid props", func(t *testing.T) {
+			res, err := l.Write().ToDitheredImage(nilProps, ditherOpts)
+			assert.NoError(t, err)
+			if assert.IsType(t, &image.Paletted{}, res) {
+				assert.Len(t, res.(*image.Paletted).Palette, 4)
+				assert.Equal(t, baseImage.Bounds(), res.Bounds())
+			}
+		})
+	})
+	t.Run("ToDitheredPNG", func(t *testing.T) {
+		t.Run("invalid props", func(t *testing.T) {
+			res, err := l.Write().ToDitheredPNG(badProps, ditherOpts)
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "bad props")
+		})
+		t.Run("valid props", func(t *testing.T) {
+			res, err := l.Write().ToDitheredPNG(nilProps, ditherOpts)
+			assert.Equal(t, []byte("a dithered png"), res)
+			assert.NoError(t, err)
+		})
+	})
+	t.Run("ToAnimatedGIF", func(t *testing.T) {
+		t.Run("invalid props", func(t *testing.T) {
+			res, err := l.Write().ToAnimatedGIF([]render.NamedProperties{nilProps, badProps}, []int{10})
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "frame 1: bad props")
+		})
+		t.Run("invalid delays", func(t *testing.T) {
+			res, err := l.Write().ToAnimatedGIF([]render.NamedProperties{nilProps, nilProps, nilProps}, []int{10, 20})
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "expected 3 delays, got 2")
+		})
+		t.Run("valid props", func(t *testing.T) {
+			res, err := l.Write().ToAnimatedGIF([]render.NamedProperties{nilProps, nilProps}, []int{10, 20})
+			assert.NoError(t, err)
+			animation, err := gif.DecodeAll(bytes.NewReader(res))
+			if assert.NoError(t, err) {
+				assert.Len(t, animation.Image, 2)
+				assert.Equal(t, []int{10, 20}, animation.Delay)
+				assert.Equal(t, 2, animation.Config.Width)
+			}
+		})
+	})
+	t.Run("ToAPNG", func(t *testing.T) {
+		t.Run("invalid props", func(t *testing.T) {
+			res, err := l.Write().ToAPNG([]render.NamedProperties{badProps}, []int{10})
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "frame 0: bad props")
+		})
+		t.Run("no frames", func(t *testing.T) {
+			res, err := l.Write().ToAPNG(nil, []int{10})
+			assert.Nil(t, res)
+			assert.EqualError(t, err, "no frames to animate")
+		})
+		t.Run("valid props", func(t *testing.T) {
+			res, err := l.Write().ToAPNG([]render.NamedProperties{nilProps, nilProps}, []int{10})
+			assert.NoError(t, err)
+			// The first frame is an ordinary PNG image for viewers without APNG support.
+			img, err := png.Decode(bytes.NewReader(res))
+			if assert.NoError(t, err) {
+				assert.Equal(t, baseImage.Bounds(), img.Bounds())
+			}
+			assert.Contains(t, string(res), "acTL")
+			assert.Contains(t, string(res), "fdAT")
+		})
+	})
 	b.AssertExpectations(t)
 }
 
+func TestAnimatedFrames(t *testing.T) {
+	l, _, err := New().Load().FromBytes([]byte(`{
+		"baseImage": {"baseColour": {"R": "255", "G": "255", "B": "255", "A": "255"}, "width": "2", "height": "1"},
+		"components": [{"type": "rectangle", "properties": {"topLeftX": "0", "topLeftY": "0", "width": "1", "height": "1", "colour": {"R": "$r$", "G": "0", "B": "0", "A": "255"}}}]
+	}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	frames := []render.NamedProperties{{"r": uint8(255)}, {"r": uint8(0)}, {"r": uint8(255)}}
+	res, err := l.Write().ToAnimatedGIF(frames, []int{10})
+	if !assert.NoError(t, err) {
+		return
+	}
+	animation, err := gif.DecodeAll(bytes.NewReader(res))
+	if assert.NoError(t, err) && assert.Len(t, animation.Image, 3) {
+		red, black := color.RGBA{R: 255, A: 255}, color.RGBA{A: 255}
+		for i, expected := range []color.RGBA{red, black, red} {
+			r, g, b, a := animation.Image[i].At(0, 0).RGBA()
+			assert.Equal(t, expected, color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(a >> 8)}, "frame %d", i)
+		}
+	}
+}
+
 func TestPanics(t *testing.T) {
 	l := loader{}
 	nilProps := render.NamedProperties(nil)
@@ -292,4 +582,73 @@ func TestPanics(t *testing.T) {
 		assert.Nil(t, rdr)
 		assert.Error(t, err)
 	})
+	t.Run("ToPNG", func(t *testing.T) {
+		raw, err := l.ToPNG(nil)
+		assert.Nil(t, raw)
+		assert.Error(t, err)
+	})
+	t.Run("ToJPEG", func(t *testing.T) {
+		raw, err := l.ToJPEG(nil, 90)
+		assert.Nil(t, raw)
+		assert.Error(t, err)
+	})
+	t.Run("ToGIF", func(t *testing.T) {
+		raw, err := l.ToGIF(nil)
+		assert.Nil(t, raw)
+		assert.Error(t, err)
+	})
+	t.Run("ToTIFF", func(t *testing.T) {
+		raw, err := l.ToTIFF(nil)
+		assert.Nil(t, raw)
+		assert.Error(t, err)
+	})
+	t.Run("ToFormat", func(t *testing.T) {
+		raw, err := l.ToFormat(nil, scaffold.FormatPNG, nil)
+		assert.Nil(t, raw)
+		assert.Error(t, err)
+	})
+	t.Run("ToWriter", func(t *testing.T) {
+		err := l.ToWriter(nil, ioutil.Discard, scaffold.FormatPNG, nil)
+		assert.Error(t, err)
+	})
+	t.Run("ToPDF", func(t *testing.T) {
+		raw, err := l.ToPDF(nil)
+		assert.Nil(t, raw)
+		assert.Error(t, err)
+	})
+	t.Run("ToSVG", func(t *testing.T) {
+		raw, err := l.ToSVG(nil)
+		assert.Nil(t, raw)
+		assert.Error(t, err)
+	})
+	t.Run("ToZPL", func(t *testing.T) {
+		raw, err := l.ToZPL(nil)
+		assert.Nil(t, raw)
+		assert.Error(t, err)
+	})
+	t.Run("ToESCPOS", func(t *testing.T) {
+		raw, err := l.ToESCPOS(nil, 384)
+		assert.Nil(t, raw)
+		assert.Error(t, err)
+	})
+	t.Run("ToDitheredImage", func(t *testing.T) {
+		img, err := l.ToDitheredImage(nil, scaffold.DitherOptions{})
+		assert.Nil(t, img)
+		assert.Error(t, err)
+	})
+	t.Run("ToDitheredPNG", func(t *testing.T) {
+		raw, err := l.ToDitheredPNG(nil, scaffold.DitherOptions{})
+		assert.Nil(t, raw)
+		assert.Error(t, err)
+	})
+	t.Run("ToAnimatedGIF", func(t *testing.T) {
+		raw, err := l.ToAnimatedGIF([]render.NamedProperties{nil}, []int{10})
+		assert.Nil(t, raw)
+		assert.Error(t, err)
+	})
+	t.Run("ToAPNG", func(t *testing.T) {
+		raw, err := l.ToAPNG([]render.NamedProperties{nil}, []int{10})
+		assert.Nil(t, raw)
+		assert.Error(t, err)
+	})
 }
@@ -0,0 +1,20 @@
+package testimage
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrientedJPEG(t *testing.T) {
+	data := OrientedJPEG(6)
+	assert.Equal(t, []byte{0xff, 0xd8, 0xff, 0xe1}, data[:4], "the EXIF segment should follow the start of image marker")
+	assert.Equal(t, byte(6), data[6+25])
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if assert.NoError(t, err) {
+		assert.Equal(t, image.Rect(0, 0, 4, 2), img.Bounds())
+	}
+}
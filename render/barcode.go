@@ -109,6 +109,33 @@ func (canvas ImageCanvas) Barcode(codeType BarcodeType, content []byte, extra Ba
 	if c.Image == nil {
 		return canvas, errors.New("no image set for canvas to draw on")
 	}
+	encodedBarcode, err := encodeBarcode(codeType, content, extra, width, height)
+	if err != nil {
+		return canvas, err
+	}
+	if dataColour == nil {
+		dataColour = color.Black
+	}
+	if backgroundColour == nil {
+		backgroundColour = color.White
+	}
+	boundRect := encodedBarcode.Bounds()
+	draw.DrawMask(c.Image, image.Rect(start.X, start.Y, start.X+width, start.Y+height), image.NewUniform(backgroundColour), image.ZP, blackAndWhiteMask{bw: encodedBarcode, bColour: color.Transparent, wColour: color.Opaque}, boundRect.Min, draw.Over)
+	draw.DrawMask(c.Image, image.Rect(start.X, start.Y, start.X+width, start.Y+height), image.NewUniform(dataColour), image.ZP, blackAndWhiteMask{bw: encodedBarcode, bColour: color.Opaque, wColour: color.Transparent}, boundRect.Min, draw.Over)
+	return c, nil
+}
+
+// encodeBarcode encodes the content as the specified barcode type and scales it to the specified size.
+func encodeBarcode(codeType BarcodeType, content []byte, extra BarcodeExtraData, width, height int) (barcode.Barcode, error) {
+	encodedBarcode, err := encodeRawBarcode(codeType, content, extra)
+	if err != nil {
+		return nil, err
+	}
+	return barcode.Scale(encodedBarcode, width, height)
+}
+
+// encodeRawBarcode encodes the content as the specified barcode type with one pixel per module.
+func encodeRawBarcode(codeType BarcodeType, content []byte, extra BarcodeExtraData) (barcode.Barcode, error) {
 	var encodedBarcode barcode.Barcode
 	var err error
 	switch codeType {
@@ -148,24 +175,51 @@ func (canvas ImageCanvas) Barcode(codeType BarcodeType, content []byte, extra Ba
 	case BarcodeType2of5Interleaved:
 		encodedBarcode, err = twooffive.Encode(string(content), true)
 	}
-	if err != nil {
-		return canvas, err
+	return encodedBarcode, err
+}
+
+// barcodeRects reduces a scaled barcode to the rectangles covering its data channel, merging identical runs on consecutive rows so vector canvases can draw each bar or module as a single shape.
+func barcodeRects(code image.Image) []image.Rectangle {
+	var done, open []image.Rectangle
+	bounds := code.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		var runs []image.Rectangle
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if code.At(x, y) != color.Black {
+				continue
+			}
+			if len(runs) > 0 && runs[len(runs)-1].Max.X == x {
+				runs[len(runs)-1].Max.X++
+			} else {
+				runs = append(runs, image.Rect(x, y, x+1, y+1))
+			}
+		}
+		if sameRuns(open, runs) {
+			for i := range open {
+				open[i].Max.Y++
+			}
+			continue
+		}
+		done = append(done, open...)
+		open = runs
 	}
-	encodedBarcode, err = barcode.Scale(encodedBarcode, width, height)
-	if err != nil {
-		return canvas, err
+	done = append(done, open...)
+	for i := range done {
+		done[i] = done[i].Sub(bounds.Min)
 	}
+	return done
+}
 
-	if dataColour == nil {
-		dataColour = color.Black
+func sameRuns(a, b []image.Rectangle) bool {
+	if len(a) != len(b) {
+		return false
 	}
-	if backgroundColour == nil {
-		backgroundColour = color.White
+	for i := range a {
+		if a[i].Min.X != b[i].Min.X || a[i].Max.X != b[i].Max.X {
+			return false
+		}
 	}
-	boundRect := encodedBarcode.Bounds()
-	draw.DrawMask(c.Image, image.Rect(start.X, start.Y, start.X+width, start.Y+height), image.NewUniform(backgroundColour), image.ZP, blackAndWhiteMask{bw: encodedBarcode, bColour: color.Transparent, wColour: color.Opaque}, boundRect.Min, draw.Over)
-	draw.DrawMask(c.Image, image.Rect(start.X, start.Y, start.X+width, start.Y+height), image.NewUniform(dataColour), image.ZP, blackAndWhiteMask{bw: encodedBarcode, bColour: color.Opaque, wColour: color.Transparent}, boundRect.Min, draw.Over)
-	return c, nil
+	return true
 }
 
 type blackAndWhiteMask struct {
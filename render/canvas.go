@@ -21,10 +21,16 @@ type Canvas interface {
 	SetPPI(float64) Canvas
 	Rectangle(topLeft image.Point, width, height int, colour color.Color) (Canvas, error)
 	Circle(centre image.Point, radius int, colour color.Color) (Canvas, error)
+	Ellipse(centre image.Point, radiusX, radiusY int, colour color.Color) (Canvas, error)
+	Arc(centre image.Point, radiusX, radiusY int, startAngle, endAngle float64, style ArcStyle, colour color.Color) (Canvas, error)
+	Line(start, end image.Point, style StrokeStyle, colour color.Color) (Canvas, error)
+	Polyline(points []image.Point, style StrokeStyle, colour color.Color) (Canvas, error)
+	Path(path Path, fill, stroke color.Color, style StrokeStyle) (Canvas, error)
 	Text(text string, start image.Point, typeFace font.Face, colour color.Color, maxWidth int) (Canvas, error)
 	TryText(text string, start image.Point, typeFace font.Face, colour color.Color, maxWidth int) (bool, int)
 	DrawImage(start image.Point, subImage image.Image) (Canvas, error)
 	Barcode(codeType BarcodeType, content []byte, extra BarcodeExtraData, start image.Point, width, height int, dataColour color.Color, bgColour color.Color) (Canvas, error)
+	Layer(style LayerStyle, draw func(Canvas) (Canvas, error)) (Canvas, error)
 }
 
 // ImageCanvas uses golang's native Image package to implement the Canvas interface.
@@ -105,19 +111,3 @@ func (canvas ImageCanvas) SetPPI(ppi float64) Canvas {
 func (canvas ImageCanvas) GetPPI() float64 {
 	return canvas.pixelsPerInch
 }
-
-func (c *circle) ColorModel() color.Model {
-	return color.AlphaModel
-}
-
-func (c *circle) Bounds() image.Rectangle {
-	return image.Rect(c.p.X-c.r, c.p.Y-c.r, c.p.X+c.r, c.p.Y+c.r)
-}
-
-func (c *circle) At(x, y int) color.Color {
-	xx, yy, rr := float64(x-c.p.X)+0.5, float64(y-c.p.Y)+0.5, float64(c.r)
-	if xx*xx+yy*yy < rr*rr {
-		return color.Alpha{255}
-	}
-	return color.Alpha{0}
-}
@@ -6,6 +6,7 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"math"
 	"testing"
 
 	"github.com/boombuler/barcode"
@@ -355,25 +356,123 @@ func TestCircle(t *testing.T) {
 	t.Run("valid circle", func(t *testing.T) {
 		modifiedCanvas, err := newCanvas.Circle(image.Pt(3, 3), 2, color.NRGBA{G: 255, A: 255})
 		assert.NoError(t, err)
-		testCircle := &circle{p: image.Pt(3, 3), r: 2}
-		assert.Equal(t, color.AlphaModel, testCircle.ColorModel())
 		underlyingImage := modifiedCanvas.GetUnderlyingImage()
-		tMask := color.Alpha{0}
-		oMask := color.Alpha{255}
+		var area float64
 		for x := 0; x < 10; x++ {
 			for y := 0; y < 10; y++ {
-				t.Run(fmt.Sprintf("x=%d,y=%d", x, y), func(t *testing.T) {
-					currentPixel := testCircle.At(x, y)
-					if currentPixel == tMask {
-						assert.Equal(t, color.NRGBA{}, underlyingImage.At(x, y))
-					} else if currentPixel == oMask {
-						assert.Equal(t, color.NRGBA{G: 255, A: 255}, underlyingImage.At(x, y))
-					} else {
-						assert.Fail(t, fmt.Sprintf("Invalid value for current pixel: %v", currentPixel))
-					}
-				})
+				pixel := toNRGBA(underlyingImage.At(x, y))
+				if pixel.A != 0 {
+					assert.Equal(t, uint8(255), pixel.G)
+				}
+				area += float64(pixel.A) / 255
 			}
 		}
+		// Pixels entirely inside the circle are solid, those on the edge are partly covered.
+		for _, p := range []image.Point{{X: 2, Y: 2}, {X: 3, Y: 2}, {X: 2, Y: 3}, {X: 3, Y: 3}} {
+			assert.Equal(t, uint8(255), alphaAt(modifiedCanvas, p.X, p.Y), "pixel %v", p)
+		}
+		for _, p := range []image.Point{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 4, Y: 3}, {X: 4, Y: 4}} {
+			alpha := alphaAt(modifiedCanvas, p.X, p.Y)
+			assert.True(t, alpha > 0 && alpha < 255, "expected pixel %v to be partly covered, got alpha %d", p, alpha)
+		}
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 0, 3))
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 5, 3))
+		assert.InDelta(t, math.Pi*4, area, 0.1)
+	})
+}
+
+func TestEllipse(t *testing.T) {
+	newCanvas, _ := NewCanvas(20, 10)
+	t.Run("invalid radii", func(t *testing.T) {
+		modifiedCanvas, err := newCanvas.Ellipse(image.Pt(3, 3), 0, -1, color.Black)
+		assert.Equal(t, newCanvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid x and y radius")
+		modifiedCanvas, err = newCanvas.Ellipse(image.Pt(3, 3), 0, 2, color.Black)
+		assert.Equal(t, newCanvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid x radius")
+		modifiedCanvas, err = newCanvas.Ellipse(image.Pt(3, 3), 2, 0, color.Black)
+		assert.Equal(t, newCanvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid y radius")
+	})
+	t.Run("no image", func(t *testing.T) {
+		canvas := ImageCanvas{}
+		modifiedCanvas, err := canvas.Ellipse(image.Pt(3, 3), 2, 2, color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "no image set for canvas to draw on")
+	})
+	t.Run("valid ellipse", func(t *testing.T) {
+		newCanvas, _ := NewCanvas(20, 10)
+		modifiedCanvas, err := newCanvas.Ellipse(image.Pt(10, 5), 8, 3, color.Black)
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(255), alphaAt(modifiedCanvas, 4, 5))
+		assert.Equal(t, uint8(255), alphaAt(modifiedCanvas, 15, 4))
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 10, 1))
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 1, 5))
+		assert.True(t, alphaAt(modifiedCanvas, 2, 4) > 0, "the ends of the ellipse are partly covered")
+	})
+}
+
+func TestToArcStyle(t *testing.T) {
+	for _, style := range []ArcStyle{ArcPie, ArcChord} {
+		converted, err := ToArcStyle(string(style))
+		assert.NoError(t, err)
+		assert.Equal(t, style, converted)
+	}
+	converted, err := ToArcStyle("slice")
+	assert.Equal(t, ArcStyle(""), converted)
+	assert.EqualError(t, err, "arc style slice does not match defined constants")
+}
+
+func TestArc(t *testing.T) {
+	newCanvas, _ := NewCanvas(20, 20)
+	t.Run("invalid", func(t *testing.T) {
+		modifiedCanvas, err := newCanvas.Arc(image.Pt(10, 10), 0, 5, 0, 90, ArcPie, color.Black)
+		assert.Equal(t, newCanvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid x radius")
+		modifiedCanvas, err = newCanvas.Arc(image.Pt(10, 10), 5, 5, 0, 90, "slice", color.Black)
+		assert.Equal(t, newCanvas, modifiedCanvas)
+		assert.EqualError(t, err, "unsupported arc style: slice")
+		modifiedCanvas, err = newCanvas.Arc(image.Pt(10, 10), 5, 5, 45, 45, ArcPie, color.Black)
+		assert.Equal(t, newCanvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid arc angles 45 to 45")
+		modifiedCanvas, err = newCanvas.Arc(image.Pt(10, 10), 5, 5, 0, math.Inf(1), ArcPie, color.Black)
+		assert.Equal(t, newCanvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid arc angles 0 to +Inf")
+	})
+	t.Run("pie", func(t *testing.T) {
+		newCanvas, _ := NewCanvas(20, 20)
+		// A quarter from the positive X axis clockwise to the positive Y axis, which is down the canvas.
+		modifiedCanvas, err := newCanvas.Arc(image.Pt(10, 10), 8, 8, 0, 90, ArcPie, color.Black)
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(255), alphaAt(modifiedCanvas, 11, 11))
+		assert.Equal(t, uint8(255), alphaAt(modifiedCanvas, 10, 16))
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 9, 11))
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 11, 9))
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 17, 17))
+	})
+	t.Run("chord", func(t *testing.T) {
+		newCanvas, _ := NewCanvas(20, 20)
+		modifiedCanvas, err := newCanvas.Arc(image.Pt(10, 10), 8, 8, 0, 90, ArcChord, color.Black)
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 11, 11), "the centre is cut off by the chord")
+		assert.Equal(t, uint8(255), alphaAt(modifiedCanvas, 14, 14))
+	})
+	t.Run("wrapping", func(t *testing.T) {
+		// From the top clockwise round to the left, which is three quarters of the way round.
+		newCanvas, _ := NewCanvas(20, 20)
+		modifiedCanvas, err := newCanvas.Arc(image.Pt(10, 10), 8, 8, -90, 180, ArcPie, color.Black)
+		assert.NoError(t, err)
+		otherCanvas, _ := NewCanvas(20, 20)
+		turnedCanvas, err := otherCanvas.Arc(image.Pt(10, 10), 8, 8, 270, 540, ArcPie, color.Black)
+		assert.NoError(t, err)
+		assert.Equal(t, modifiedCanvas.GetUnderlyingImage(), turnedCanvas.GetUnderlyingImage())
+		assert.Equal(t, uint8(255), alphaAt(modifiedCanvas, 13, 13))
+		assert.Equal(t, uint8(255), alphaAt(modifiedCanvas, 6, 13))
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 6, 6))
+		fullCanvas, _ := NewCanvas(20, 20)
+		full, err := fullCanvas.Arc(image.Pt(10, 10), 8, 8, 0, 360, ArcChord, color.Black)
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(255), alphaAt(full, 6, 6))
 	})
 }
 
@@ -438,6 +537,37 @@ func TestTryText(t *testing.T) {
 	})
 }
 
+func TestTextPath(t *testing.T) {
+	ttFont, _ := truetype.Parse(goregular.TTF)
+	face := NewFontFace(ttFont, &truetype.Options{Size: 20, Hinting: font.HintingFull, SubPixelsX: 64, SubPixelsY: 64})
+	t.Run("matches drawn text", func(t *testing.T) {
+		drawn, _ := NewCanvas(60, 30)
+		drawn.Text("Hog", image.Pt(3, 20), face, color.Black, 60)
+		filled, _ := NewCanvas(60, 30)
+		filled.Path(TextPath("Hog", image.Pt(3, 20), face), color.Black, nil, StrokeStyle{})
+		assert.Equal(t, contentBounds(drawn.Image), contentBounds(filled.Image))
+		different := 0
+		for y := 0; y < 30; y++ {
+			for x := 0; x < 60; x++ {
+				_, _, _, drawnAlpha := drawn.Image.At(x, y).RGBA()
+				_, _, _, filledAlpha := filled.Image.At(x, y).RGBA()
+				if math.Abs(float64(drawnAlpha)-float64(filledAlpha)) > 0x8000 {
+					different++
+				}
+			}
+		}
+		assert.True(t, different < 10, "%d pixels differ between the text and its outline", different)
+	})
+	t.Run("contours", func(t *testing.T) {
+		path := TextPath("o o", image.Pt(0, 20), face)
+		assert.Equal(t, 4, bytes.Count([]byte(path.svgData()), []byte("Z")), "each o has an inner and outer contour, and the space has none")
+		assert.Contains(t, path.svgData(), "Q")
+	})
+	t.Run("empty", func(t *testing.T) {
+		assert.Equal(t, Path{}, TextPath("", image.Pt(0, 20), face))
+	})
+}
+
 func TestDrawImage(t *testing.T) {
 	var newCanvas, otherCanvas Canvas
 	sx := 30
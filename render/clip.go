@@ -0,0 +1,36 @@
+package render
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Clip is the shape a layer is clipped to, in canvas coordinates, so that only the parts of the layer inside it are composited. Clips are applied after the layer is transformed, so the shape doesn't move with the layer.
+type Clip struct {
+	// Path is the shape to clip to, filled with the non-zero winding rule and smoothed at the edges. It is ignored if Mask is set.
+	Path Path
+	// Mask is an image whose alpha is how much of the layer shows through each pixel, placed on the canvas by its bounds. Nothing shows outside the bounds of the mask.
+	Mask image.Image
+}
+
+// coverage returns how much of each pixel within the bounds is inside the clip.
+func (clip Clip) coverage(bounds image.Rectangle) *image.Alpha {
+	coverage := image.NewAlpha(bounds)
+	if clip.Mask != nil {
+		draw.Draw(coverage, bounds, clip.Mask, bounds.Min, draw.Src)
+	} else {
+		fillPolygons(coverage, clip.Path.fillPolygons(), image.Opaque)
+	}
+	return coverage
+}
+
+// clipped returns the layer with everything outside the clip of the style removed, with the same bounds.
+func (style LayerStyle) clipped(layer image.Image) image.Image {
+	if style.Clip == nil {
+		return layer
+	}
+	bounds := layer.Bounds()
+	clipped := image.NewNRGBA(bounds)
+	draw.DrawMask(clipped, bounds, layer, bounds.Min, style.Clip.coverage(bounds), bounds.Min, draw.Src)
+	return clipped
+}
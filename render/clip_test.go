@@ -0,0 +1,66 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageCanvasClippedLayer(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	drawSquare := func(canvas Canvas) (Canvas, error) {
+		return canvas.Rectangle(image.ZP, 10, 10, red)
+	}
+	t.Run("circle", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Clip: &Clip{Path: NewEllipsePath(image.Pt(5, 5), 4, 4)}}, drawSquare)
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, red, img.At(5, 5))
+		assert.Equal(t, color.NRGBA{}, img.At(0, 0), "corners should be clipped away")
+		edge := toNRGBA(img.At(1, 3))
+		assert.True(t, edge.A > 0 && edge.A < 255, "edges should be smoothed")
+	})
+	t.Run("rounded rectangle", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Clip: &Clip{Path: NewRectanglePath(image.Pt(2, 2), 6, 6, CornerRadii{TopLeft: 3})}}, drawSquare)
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.True(t, toNRGBA(img.At(2, 2)).A < 10, "the rounded corner should be clipped away")
+		assert.Equal(t, red, img.At(7, 2))
+		assert.Equal(t, red, img.At(7, 7))
+		assert.Equal(t, color.NRGBA{}, img.At(8, 8))
+	})
+	t.Run("mask", func(t *testing.T) {
+		mask := image.NewAlpha(image.Rect(2, 2, 4, 3))
+		mask.SetAlpha(2, 2, color.Alpha{A: 255})
+		mask.SetAlpha(3, 2, color.Alpha{A: 51})
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Clip: &Clip{Mask: mask}}, drawSquare)
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, red, img.At(2, 2))
+		assert.Equal(t, color.NRGBA{R: 255, A: 51}, img.At(3, 2))
+		assert.Equal(t, color.NRGBA{}, img.At(4, 2), "nothing should show outside the mask")
+	})
+	t.Run("transformed", func(t *testing.T) {
+		canvas, _ := NewCanvas(4, 4)
+		style := LayerStyle{Opacity: 1, Transform: &Transform{Rotation: 90, ScaleX: 1, ScaleY: 1, Anchor: &image.Point{X: 2, Y: 2}}, Clip: &Clip{Path: NewRectanglePath(image.Pt(3, 1), 1, 3, CornerRadii{})}}
+		modifiedCanvas, err := canvas.Layer(style, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.ZP, 3, 1, red)
+		})
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, color.NRGBA{}, img.At(3, 0), "the clip should not move with the layer")
+		assert.Equal(t, red, img.At(3, 1))
+		assert.Equal(t, red, img.At(3, 2))
+	})
+	t.Run("empty path", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Clip: &Clip{}}, drawSquare)
+		assert.NoError(t, err)
+		assert.True(t, isTransparent(modifiedCanvas.GetUnderlyingImage()))
+	})
+}
@@ -44,8 +44,14 @@ type Component interface {
 // PropertySetFunc maps property names and values to component inner properties.
 type PropertySetFunc func(string, interface{}) error
 
-// StandardSetNamedProperties iterates over all named properties, retrieves their value, and calls the provided function to map properties to inner component properties. Each implementation of Component should call this within its SetNamedProperties function.
+// StandardSetNamedProperties iterates over all named properties, retrieves their value, and calls the provided function to map properties to inner component properties. Each implementation of Component should call this within its SetNamedProperties function. The leftovers are a copy, so that the same component can have its properties set again, such as once for each frame of an animation.
 func StandardSetNamedProperties(properties NamedProperties, propMap map[string][]string, setFunc PropertySetFunc) (leftovers map[string][]string, err error) {
+	if propMap != nil {
+		leftovers = make(map[string][]string, len(propMap))
+		for name, innerPropNames := range propMap {
+			leftovers[name] = innerPropNames
+		}
+	}
 	for name, value := range properties {
 		innerPropNames := propMap[name]
 		if len(innerPropNames) <= 0 {
@@ -58,9 +64,9 @@ func StandardSetNamedProperties(properties NamedProperties, propMap map[string][
 				return propMap, err
 			}
 		}
-		delete(propMap, name)
+		delete(leftovers, name)
 	}
-	return propMap, nil
+	return leftovers, nil
 }
 
 // DeconstructedDataValue is a string broken down into static values and property names. The reconstruction always starts with a static value, always has one more static value than props, and always alternates static, prop, static, prop... if any props exist.
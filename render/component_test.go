@@ -179,6 +179,13 @@ func TestStandardSetNamedProperties(t *testing.T) {
 		}
 		assert.True(t, finalResult)
 	})
+	t.Run("property map is not changed", func(t *testing.T) {
+		propMap := map[string][]string{"username": {"innerPropUsername"}, "age": {"innerPropAge"}}
+		leftovers, err := StandardSetNamedProperties(NamedProperties{"username": "john smith"}, propMap, successSetFunc)
+		assert.Equal(t, map[string][]string{"age": {"innerPropAge"}}, leftovers)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]string{"username": {"innerPropUsername"}, "age": {"innerPropAge"}}, propMap)
+	})
 }
 
 func TestIsSingleProp(t *testing.T) {
@@ -75,6 +75,8 @@ type conditionalGroup struct {
 // SetValue sets the value of a specific named property through this conditional chain, evaluating any conditions along the way.
 func (c ComponentConditional) SetValue(name string, value interface{}) (conditional ComponentConditional, err error) {
 	conditional = c
+	// The group is copied so that it isn't shared with the original conditional
+	conditional.Group.Conditionals = append([]ComponentConditional(nil), c.Group.Conditionals...)
 	for conIndex, con := range conditional.Group.Conditionals {
 		conditional.Group.Conditionals[conIndex], err = con.SetValue(name, value)
 		if err != nil {
@@ -0,0 +1,53 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/disintegration/imaging"
+)
+
+// Shadow is a blurred silhouette of a layer drawn beneath it, as with the CSS drop-shadow() filter. A shadow with no offset and a light colour makes a glow.
+type Shadow struct {
+	// Offset moves the shadow away from the layer, in pixels.
+	Offset image.Point
+	// Blur is the standard deviation of the Gaussian blur softening the shadow, in pixels, or 0 for a hard edged shadow.
+	Blur float64
+	// Colour is the colour of the shadow, whose alpha is scaled by the alpha of the layer above it.
+	Colour color.NRGBA
+}
+
+// blurred returns the layer blurred by the style, with the same bounds.
+func (style LayerStyle) blurred(layer image.Image) image.Image {
+	if style.Blur <= 0 {
+		return layer
+	}
+	return blurImage(layer, style.Blur)
+}
+
+// shadow returns the shadow of the style cast by the layer, with the same bounds.
+func (style LayerStyle) shadow(layer image.Image) *image.NRGBA {
+	bounds := layer.Bounds()
+	silhouette := image.NewNRGBA(bounds)
+	draw.DrawMask(silhouette, bounds.Add(style.Shadow.Offset), image.NewUniform(style.Shadow.Colour), image.ZP, layer, bounds.Min, draw.Src)
+	return blurImage(silhouette, style.Shadow.Blur)
+}
+
+// shadowed returns the layer with the shadow of the style drawn beneath it, with the same bounds.
+func (style LayerStyle) shadowed(layer image.Image) image.Image {
+	if style.Shadow == nil {
+		return layer
+	}
+	shadowed := style.shadow(layer)
+	draw.Draw(shadowed, shadowed.Bounds(), layer, layer.Bounds().Min, draw.Over)
+	return shadowed
+}
+
+// blurImage returns a copy of the image blurred with a Gaussian blur of the standard deviation, with the same bounds.
+func blurImage(img image.Image, deviation float64) *image.NRGBA {
+	blurred := imaging.Blur(img, deviation)
+	// The imaging package always moves the top-left corner to the origin.
+	blurred.Rect = blurred.Rect.Add(img.Bounds().Min)
+	return blurred
+}
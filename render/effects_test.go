@@ -0,0 +1,52 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageCanvasEffects(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	drawSquare := func(canvas Canvas) (Canvas, error) {
+		return canvas.Rectangle(image.Pt(4, 4), 2, 2, red)
+	}
+	t.Run("blur", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Blur: 1}, drawSquare)
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		centre, edge := toNRGBA(img.At(4, 4)), toNRGBA(img.At(3, 4))
+		assert.True(t, centre.A < 255, "the square should be softened")
+		assert.True(t, edge.A > 0 && edge.A < centre.A, "the square should spread beyond its edges")
+		assert.Equal(t, uint8(255), edge.R, "blurring should not darken the edges")
+		assert.Equal(t, color.NRGBA{}, img.At(0, 0))
+	})
+	t.Run("hard shadow", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Shadow: &Shadow{Offset: image.Pt(1, 2), Colour: color.NRGBA{B: 255, A: 128}}}, drawSquare)
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, red, img.At(5, 5), "the shadow should be beneath the layer")
+		assert.Equal(t, color.NRGBA{B: 255, A: 128}, img.At(6, 7))
+		assert.Equal(t, color.NRGBA{}, img.At(4, 7))
+	})
+	t.Run("soft shadow", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Shadow: &Shadow{Blur: 1, Colour: color.NRGBA{A: 255}}}, drawSquare)
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, red, img.At(4, 4), "the layer itself should stay sharp")
+		glow := toNRGBA(img.At(3, 4))
+		assert.True(t, glow.A > 0 && glow.A < 255, "the shadow should spread around the layer")
+	})
+	t.Run("offset origin", func(t *testing.T) {
+		img := image.NewNRGBA(image.Rect(5, 5, 8, 8))
+		img.Set(6, 6, red)
+		blurred := blurImage(img, 1)
+		assert.Equal(t, img.Bounds(), blurred.Bounds())
+		assert.True(t, blurred.NRGBAAt(6, 6).A > blurred.NRGBAAt(5, 5).A)
+	})
+}
@@ -0,0 +1,196 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// GradientType is the shape of a gradient.
+type GradientType string
+
+const (
+	// GradientLinear blends the colours along a straight line at an angle
+	GradientLinear GradientType = "linear"
+	// GradientRadial blends the colours outwards from the centre
+	GradientRadial GradientType = "radial"
+)
+
+// ToGradientType converts a string to a GradientType, returning an error if it doesn't match any of the defined constants.
+func ToGradientType(raw string) (GradientType, error) {
+	switch gradientType := GradientType(raw); gradientType {
+	case GradientLinear, GradientRadial:
+		return gradientType, nil
+	default:
+		return "", fmt.Errorf("gradient type %v does not match defined constants", raw)
+	}
+}
+
+// GradientStop is a colour at a point along a gradient.
+type GradientStop struct {
+	// Offset is the position of the stop along the gradient, from 0 at the start to 1 at the end.
+	Offset float64
+	// Colour is the colour at the stop.
+	Colour color.NRGBA
+}
+
+/*
+Gradient is a fill blending smoothly between colour stops across the bounding box of whatever it fills.
+
+Gradient implements color.Color, so it can be passed to a canvas anywhere a fill colour can.
+Anywhere a gradient can't be drawn, such as an outline or a barcode, it is drawn as the solid
+colour halfway along it instead.
+
+As in CSS, stops are clamped between 0 and 1 and any stop before the one preceding it is moved
+up to meet it, so two stops at the same offset make a hard edge. Colours are interpolated with
+premultiplied alpha, so fading to a transparent colour doesn't darken the fill.
+*/
+type Gradient struct {
+	// Type is the shape of the gradient, defaulting to linear.
+	Type GradientType
+	// Angle is the direction of a linear gradient in degrees clockwise from the positive X axis, so 0 runs left to right and 90 runs top to bottom.
+	Angle float64
+	// Stops are the colours to blend between, in order.
+	Stops []GradientStop
+}
+
+// RGBA returns the colour halfway along the gradient.
+func (gradient Gradient) RGBA() (r, g, b, a uint32) {
+	return gradient.at(gradient.stops(), 0.5).RGBA()
+}
+
+// Image returns an image of the gradient stretched across the bounds. Like image.Uniform, the image extends infinitely in every direction.
+func (gradient Gradient) Image(bounds image.Rectangle) image.Image {
+	geometry := gradient.geometry(bounds)
+	return gradientImage{gradient: gradient, stops: gradient.stops(), geometry: geometry}
+}
+
+// stops returns the colour stops with their offsets fixed up as in CSS.
+func (gradient Gradient) stops() []GradientStop {
+	stops := make([]GradientStop, len(gradient.Stops))
+	previous := 0.0
+	for i, stop := range gradient.Stops {
+		stop.Offset = math.Min(math.Max(stop.Offset, previous), 1)
+		if math.IsNaN(stop.Offset) {
+			stop.Offset = previous
+		}
+		stops[i] = stop
+		previous = stop.Offset
+	}
+	return stops
+}
+
+// opaque returns whether the gradient has colour stops and all of them are opaque.
+func (gradient Gradient) opaque() bool {
+	for _, stop := range gradient.Stops {
+		if stop.Colour.A != 255 {
+			return false
+		}
+	}
+	return len(gradient.Stops) > 0
+}
+
+// at returns the colour at a point along the gradient, with stops as returned by stops.
+func (gradient Gradient) at(stops []GradientStop, t float64) color.Color {
+	if len(stops) == 0 {
+		return color.Transparent
+	}
+	if t < stops[0].Offset {
+		return stops[0].Colour
+	}
+	for i := 1; i < len(stops); i++ {
+		next := stops[i]
+		if t >= next.Offset {
+			continue
+		}
+		previous := stops[i-1]
+		fraction := (t - previous.Offset) / (next.Offset - previous.Offset)
+		return blendPremultiplied(previous.Colour, next.Colour, fraction)
+	}
+	return stops[len(stops)-1].Colour
+}
+
+// blendPremultiplied blends two colours with premultiplied alpha, with a fraction of 0 giving the first colour and 1 the second.
+func blendPremultiplied(from, to color.NRGBA, fraction float64) color.RGBA64 {
+	r1, g1, b1, a1 := from.RGBA()
+	r2, g2, b2, a2 := to.RGBA()
+	mix := func(x, y uint32) uint16 {
+		return uint16(math.Round(float64(x) + (float64(y)-float64(x))*fraction))
+	}
+	return color.RGBA64{R: mix(r1, r2), G: mix(g1, g2), B: mix(b1, b2), A: mix(a1, a2)}
+}
+
+// gradientGeometry positions a gradient in canvas coordinates.
+type gradientGeometry struct {
+	radial bool
+	// centre is the centre of the bounds the gradient is stretched across.
+	centre pointF
+	// start and end are the ends of the line a linear gradient runs along.
+	start, end pointF
+	// radiusX and radiusY are the radii of the ellipse a radial gradient ends on.
+	radiusX, radiusY float64
+}
+
+// geometry stretches the gradient across the bounds. A linear gradient runs through the centre, just long enough for the stops at 0 and 1 to touch opposite corners, and a radial gradient ends on the ellipse through all four corners, as in CSS.
+func (gradient Gradient) geometry(bounds image.Rectangle) gradientGeometry {
+	width, height := float64(bounds.Dx()), float64(bounds.Dy())
+	geometry := gradientGeometry{
+		radial: gradient.Type == GradientRadial,
+		centre: pointF{X: float64(bounds.Min.X) + width/2, Y: float64(bounds.Min.Y) + height/2},
+	}
+	if geometry.radial {
+		geometry.radiusX, geometry.radiusY = width/math.Sqrt2, height/math.Sqrt2
+		return geometry
+	}
+	angle := gradient.Angle * math.Pi / 180
+	direction := pointF{X: math.Cos(angle), Y: math.Sin(angle)}
+	length := math.Abs(width*direction.X) + math.Abs(height*direction.Y)
+	geometry.start = geometry.centre.sub(direction.scale(length / 2))
+	geometry.end = geometry.centre.add(direction.scale(length / 2))
+	return geometry
+}
+
+// position returns how far along the gradient a point is, where 0 is the start and 1 is the end.
+func (geometry gradientGeometry) position(p pointF) float64 {
+	if geometry.radial {
+		if geometry.radiusX == 0 || geometry.radiusY == 0 {
+			return 0
+		}
+		return math.Hypot((p.X-geometry.centre.X)/geometry.radiusX, (p.Y-geometry.centre.Y)/geometry.radiusY)
+	}
+	line := geometry.end.sub(geometry.start)
+	lengthSquared := line.X*line.X + line.Y*line.Y
+	if lengthSquared == 0 {
+		return 0
+	}
+	offset := p.sub(geometry.start)
+	return (offset.X*line.X + offset.Y*line.Y) / lengthSquared
+}
+
+// gradientImage is an infinite image of a gradient, sampled at the centre of each pixel.
+type gradientImage struct {
+	gradient Gradient
+	stops    []GradientStop
+	geometry gradientGeometry
+}
+
+func (img gradientImage) ColorModel() color.Model {
+	return color.RGBA64Model
+}
+
+func (img gradientImage) Bounds() image.Rectangle {
+	return image.Rectangle{Min: image.Point{X: -1e9, Y: -1e9}, Max: image.Point{X: 1e9, Y: 1e9}}
+}
+
+func (img gradientImage) At(x, y int) color.Color {
+	return img.gradient.at(img.stops, img.geometry.position(pointF{X: float64(x) + 0.5, Y: float64(y) + 0.5}))
+}
+
+// paint returns the image to fill a shape with the given bounds in a colour, which may be a Gradient.
+func paint(colour color.Color, bounds image.Rectangle) image.Image {
+	if gradient, isGradient := colour.(Gradient); isGradient {
+		return gradient.Image(bounds)
+	}
+	return image.NewUniform(colour)
+}
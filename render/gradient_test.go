@@ -0,0 +1,95 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToGradientType(t *testing.T) {
+	gradientType, err := ToGradientType("radial")
+	assert.Equal(t, GradientRadial, gradientType)
+	assert.NoError(t, err)
+	gradientType, err = ToGradientType("conic")
+	assert.Equal(t, GradientType(""), gradientType)
+	assert.EqualError(t, err, "gradient type conic does not match defined constants")
+}
+
+func TestGradient(t *testing.T) {
+	red, blue := color.NRGBA{R: 255, A: 255}, color.NRGBA{B: 255, A: 255}
+	t.Run("stops", func(t *testing.T) {
+		gradient := Gradient{Stops: []GradientStop{{Offset: -1, Colour: red}, {Offset: 0.6, Colour: blue}, {Offset: 0.4, Colour: red}, {Offset: 2, Colour: blue}}}
+		assert.Equal(t, []GradientStop{{Offset: 0, Colour: red}, {Offset: 0.6, Colour: blue}, {Offset: 0.6, Colour: red}, {Offset: 1, Colour: blue}}, gradient.stops())
+		assert.Equal(t, []GradientStop{{Offset: -1, Colour: red}, {Offset: 0.6, Colour: blue}, {Offset: 0.4, Colour: red}, {Offset: 2, Colour: blue}}, gradient.Stops, "the original stops should be untouched")
+	})
+	t.Run("colour", func(t *testing.T) {
+		gradient := Gradient{Stops: []GradientStop{{Offset: 0, Colour: red}, {Offset: 1, Colour: blue}}}
+		assert.Equal(t, color.NRGBA{R: 128, B: 128, A: 255}, toNRGBA(gradient))
+		assert.Equal(t, color.NRGBA{}, toNRGBA(Gradient{}))
+		hardEdge := Gradient{Stops: []GradientStop{{Offset: 0.5, Colour: red}, {Offset: 0.5, Colour: blue}}}
+		assert.Equal(t, blue, toNRGBA(hardEdge))
+	})
+	t.Run("premultiplied", func(t *testing.T) {
+		gradient := Gradient{Stops: []GradientStop{{Offset: 0, Colour: red}, {Offset: 1, Colour: color.NRGBA{}}}}
+		assert.Equal(t, color.NRGBA{R: 255, A: 128}, toNRGBA(gradient), "fading to transparent should keep the colour")
+	})
+	t.Run("opaque", func(t *testing.T) {
+		assert.True(t, Gradient{Stops: []GradientStop{{Colour: red}, {Colour: blue}}}.opaque())
+		assert.False(t, Gradient{Stops: []GradientStop{{Colour: red}, {Colour: color.NRGBA{A: 254}}}}.opaque())
+		assert.False(t, Gradient{}.opaque())
+	})
+	t.Run("linear", func(t *testing.T) {
+		gradient := Gradient{Stops: []GradientStop{{Offset: 0, Colour: color.NRGBA{A: 255}}, {Offset: 1, Colour: color.NRGBA{R: 200, A: 255}}}}
+		img := gradient.Image(image.Rect(10, 10, 20, 20))
+		assert.Equal(t, color.NRGBA{R: 10, A: 255}, toNRGBA(img.At(10, 15)))
+		assert.Equal(t, color.NRGBA{R: 190, A: 255}, toNRGBA(img.At(19, 10)))
+		assert.Equal(t, color.NRGBA{A: 255}, toNRGBA(img.At(0, 0)), "the image should extend beyond the bounds")
+		gradient.Angle = 90
+		img = gradient.Image(image.Rect(10, 10, 20, 20))
+		assert.Equal(t, color.NRGBA{R: 10, A: 255}, toNRGBA(img.At(19, 10)))
+		assert.Equal(t, color.NRGBA{R: 190, A: 255}, toNRGBA(img.At(10, 19)))
+		gradient.Angle = 45
+		img = gradient.Image(image.Rect(0, 0, 10, 10))
+		assert.Equal(t, color.NRGBA{R: 10, A: 255}, toNRGBA(img.At(0, 0)), "a diagonal gradient should run corner to corner")
+		assert.Equal(t, color.NRGBA{R: 100, A: 255}, toNRGBA(img.At(9, 0)))
+	})
+	t.Run("radial", func(t *testing.T) {
+		gradient := Gradient{Type: GradientRadial, Stops: []GradientStop{{Offset: 0, Colour: color.NRGBA{A: 255}}, {Offset: 1, Colour: color.NRGBA{R: 200, A: 255}}}}
+		img := gradient.Image(image.Rect(0, 0, 20, 10))
+		assert.Equal(t, color.NRGBA{R: 15, A: 255}, toNRGBA(img.At(9, 5)))
+		assert.Equal(t, color.NRGBA{R: 135, A: 255}, toNRGBA(img.At(19, 5)))
+		assert.Equal(t, color.NRGBA{R: 127, A: 255}, toNRGBA(img.At(10, 9)), "the gradient should be stretched into an ellipse")
+		assert.Equal(t, color.NRGBA{R: 200, A: 255}, toNRGBA(img.At(25, 15)))
+		assert.Equal(t, color.NRGBA{A: 255}, toNRGBA(gradient.Image(image.Rectangle{}).At(3, 3)))
+	})
+}
+
+func TestGradientCanvas(t *testing.T) {
+	gradient := Gradient{Stops: []GradientStop{{Offset: 0, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 1, Colour: color.NRGBA{B: 255, A: 255}}}}
+	t.Run("rectangle", func(t *testing.T) {
+		canvas, _ := NewCanvas(20, 10)
+		modifiedCanvas, err := canvas.Rectangle(image.Pt(5, 0), 10, 10, gradient)
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, color.NRGBA{}, img.At(4, 5))
+		assert.Equal(t, color.NRGBA{R: 243, B: 12, A: 255}, img.At(5, 5))
+		assert.Equal(t, color.NRGBA{R: 12, B: 243, A: 255}, img.At(14, 5))
+	})
+	t.Run("path", func(t *testing.T) {
+		canvas, _ := NewCanvas(20, 10)
+		modifiedCanvas, err := canvas.Path(NewPolygonPath([]image.Point{{X: 10, Y: 0}, {X: 20, Y: 0}, {X: 20, Y: 10}, {X: 10, Y: 10}}), gradient, nil, StrokeStyle{})
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, color.NRGBA{R: 243, B: 12, A: 255}, img.At(10, 5), "the gradient should be stretched across the path")
+		assert.Equal(t, color.NRGBA{R: 12, B: 243, A: 255}, img.At(19, 5))
+	})
+	t.Run("outline", func(t *testing.T) {
+		canvas, _ := NewCanvas(20, 10)
+		modifiedCanvas, err := canvas.Line(image.Pt(0, 5), image.Pt(19, 5), StrokeStyle{Width: 1}, gradient)
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, img.At(0, 5), img.At(19, 5), "outlines should be drawn in a solid colour")
+	})
+}
@@ -0,0 +1,177 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// BlendMode is how the colours of a layer are mixed with the colours beneath it.
+type BlendMode string
+
+const (
+	// BlendNormal draws the layer over the colours beneath it
+	BlendNormal BlendMode = "normal"
+	// BlendMultiply multiplies the colours, which always darkens them, like tinted film
+	BlendMultiply BlendMode = "multiply"
+	// BlendScreen multiplies the inverse of the colours, which always lightens them, like overlapping projectors
+	BlendScreen BlendMode = "screen"
+	// BlendOverlay multiplies dark colours beneath the layer and screens light ones, increasing contrast
+	BlendOverlay BlendMode = "overlay"
+	// BlendDarken keeps the darker of each colour channel
+	BlendDarken BlendMode = "darken"
+	// BlendLighten keeps the lighter of each colour channel
+	BlendLighten BlendMode = "lighten"
+)
+
+// ToBlendMode converts a string to a BlendMode, returning an error if it doesn't match any of the defined constants.
+func ToBlendMode(raw string) (BlendMode, error) {
+	switch mode := BlendMode(raw); mode {
+	case BlendNormal, BlendMultiply, BlendScreen, BlendOverlay, BlendDarken, BlendLighten:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("blend mode %v does not match defined constants", raw)
+	}
+}
+
+// LayerStyle is how a layer is composited onto the canvas beneath it.
+type LayerStyle struct {
+	// Opacity scales the alpha of the whole layer, from 0 for invisible to 1 for unchanged.
+	Opacity float64
+	// BlendMode is how the colours of the layer are mixed with the canvas beneath, defaulting to normal.
+	BlendMode BlendMode
+	// Transform moves the layer before it is composited, or leaves it where it is if nil.
+	Transform *Transform
+	// Clip is the shape the layer is clipped to after it is transformed, or nil to leave it unclipped.
+	Clip *Clip
+	// Blur is the standard deviation of a Gaussian blur applied to the layer after it is clipped, in pixels, or 0 to leave it sharp.
+	Blur float64
+	// Shadow is drawn beneath the layer after it is blurred, or nil for no shadow.
+	Shadow *Shadow
+}
+
+// plain returns whether compositing a layer with the style is no different to drawing directly on the canvas.
+func (style LayerStyle) plain() bool {
+	return style.Opacity == 1 && (style.BlendMode == "" || style.BlendMode == BlendNormal) && style.Transform == nil && style.Clip == nil && style.Blur <= 0 && style.Shadow == nil
+}
+
+// transformed returns the layer moved by the transform of the style, with the same bounds.
+func (style LayerStyle) transformed(layer image.Image) image.Image {
+	if style.Transform == nil {
+		return layer
+	}
+	return transformImage(layer, style.Transform.matrix(contentBounds(layer)), layer.Bounds())
+}
+
+// styled returns the layer transformed, clipped, blurred and shadowed by the style, with the same bounds, ready to be composited.
+func (style LayerStyle) styled(layer image.Image) image.Image {
+	return style.shadowed(style.blurred(style.clipped(style.transformed(layer))))
+}
+
+// contentBounds returns the bounding box of the pixels of the image which aren't fully transparent.
+func contentBounds(img image.Image) image.Rectangle {
+	var content image.Rectangle
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0 {
+				content = content.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return content
+}
+
+// Layer calls draw with a transparent canvas the size of this one, then applies the transform, clip and effects of the style to the result and composites it onto this canvas with the style, as a whole. Overlapping parts of the layer are not blended with each other, so a translucent layer looks like a single translucent sheet.
+func (canvas ImageCanvas) Layer(style LayerStyle, draw func(Canvas) (Canvas, error)) (Canvas, error) {
+	if canvas.Image == nil {
+		return canvas, errors.New("no image set for canvas to draw on")
+	}
+	if style.plain() {
+		return draw(canvas)
+	}
+	layer := ImageCanvas{Image: image.NewNRGBA(canvas.Image.Bounds()), fs: canvas.fs, pixelsPerInch: canvas.pixelsPerInch}
+	drawn, err := draw(layer)
+	if err != nil {
+		return canvas, err
+	}
+	composite(canvas.Image, drawn.GetUnderlyingImage(), style)
+	return canvas, nil
+}
+
+// composite draws the layer over the destination with the style. Unknown blend modes are treated as normal.
+func composite(dst draw.Image, layer image.Image, style LayerStyle) {
+	layer = style.styled(layer)
+	opacity := math.Min(math.Max(style.Opacity, 0), 1)
+	bounds := dst.Bounds().Intersect(layer.Bounds())
+	blend, isBlended := blendFunctions[style.BlendMode]
+	if !isBlended {
+		draw.DrawMask(dst, bounds, layer, bounds.Min, opacityMask(opacity), image.ZP, draw.Over)
+		return
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			sr, sg, sb, sa := layer.At(x, y).RGBA()
+			if sa == 0 || opacity == 0 {
+				continue
+			}
+			dr, dg, db, da := dst.At(x, y).RGBA()
+			// Work with premultiplied channels between 0 and 1, as in the W3C compositing specification.
+			s := [4]float64{float64(sr) / 0xffff * opacity, float64(sg) / 0xffff * opacity, float64(sb) / 0xffff * opacity, float64(sa) / 0xffff * opacity}
+			d := [4]float64{float64(dr) / 0xffff, float64(dg) / 0xffff, float64(db) / 0xffff, float64(da) / 0xffff}
+			var result color.RGBA64
+			channels := []*uint16{&result.R, &result.G, &result.B}
+			for i, channel := range channels {
+				var mixed float64
+				if d[3] > 0 {
+					mixed = blend(d[i]/d[3], s[i]/s[3])
+				}
+				value := s[i]*(1-d[3]) + s[3]*d[3]*mixed + d[i]*(1-s[3])
+				*channel = toChannel(value)
+			}
+			result.A = toChannel(s[3] + d[3]*(1-s[3]))
+			dst.Set(x, y, result)
+		}
+	}
+}
+
+// fade returns a copy of the image with its alpha scaled by the opacity.
+func fade(img image.Image, opacity float64) *image.NRGBA {
+	bounds := img.Bounds()
+	faded := image.NewNRGBA(bounds)
+	draw.DrawMask(faded, bounds, img, bounds.Min, opacityMask(math.Min(math.Max(opacity, 0), 1)), image.ZP, draw.Src)
+	return faded
+}
+
+// opacityMask returns a uniform mask scaling alpha by an opacity between 0 and 1.
+func opacityMask(opacity float64) image.Image {
+	return image.NewUniform(color.Alpha16{A: uint16(math.Round(opacity * 0xffff))})
+}
+
+// toChannel converts a value between 0 and 1 to a 16-bit colour channel.
+func toChannel(value float64) uint16 {
+	return uint16(math.Round(math.Min(math.Max(value, 0), 1) * 0xffff))
+}
+
+// blendFunctions mix an unpremultiplied colour channel beneath a layer with the channel of the layer, as in the W3C compositing specification.
+var blendFunctions = map[BlendMode]func(backdrop, source float64) float64{
+	BlendMultiply: func(backdrop, source float64) float64 {
+		return backdrop * source
+	},
+	BlendScreen: screen,
+	BlendOverlay: func(backdrop, source float64) float64 {
+		if backdrop <= 0.5 {
+			return source * 2 * backdrop
+		}
+		return screen(source, 2*backdrop-1)
+	},
+	BlendDarken:  math.Min,
+	BlendLighten: math.Max,
+}
+
+func screen(backdrop, source float64) float64 {
+	return backdrop + source - backdrop*source
+}
@@ -0,0 +1,100 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToBlendMode(t *testing.T) {
+	mode, err := ToBlendMode("multiply")
+	assert.Equal(t, BlendMultiply, mode)
+	assert.NoError(t, err)
+	mode, err = ToBlendMode("dodge")
+	assert.Equal(t, BlendMode(""), mode)
+	assert.EqualError(t, err, "blend mode dodge does not match defined constants")
+}
+
+func TestImageCanvasLayer(t *testing.T) {
+	backdrop := color.NRGBA{R: 200, G: 100, B: 50, A: 255}
+	source := color.NRGBA{R: 100, G: 200, B: 255, A: 255}
+	newCanvas := func() Canvas {
+		canvas, _ := NewCanvas(2, 1)
+		drawn, _ := canvas.Rectangle(image.ZP, 1, 1, backdrop)
+		return drawn
+	}
+	drawSource := func(canvas Canvas) (Canvas, error) {
+		return canvas.Rectangle(image.ZP, 2, 1, source)
+	}
+	t.Run("no image", func(t *testing.T) {
+		modifiedCanvas, err := ImageCanvas{}.Layer(LayerStyle{Opacity: 0.5}, drawSource)
+		assert.Equal(t, ImageCanvas{}, modifiedCanvas)
+		assert.EqualError(t, err, "no image set for canvas to draw on")
+	})
+	t.Run("draw error", func(t *testing.T) {
+		canvas := newCanvas()
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 0.5}, func(layer Canvas) (Canvas, error) {
+			layer, _ = layer.Rectangle(image.ZP, 2, 1, source)
+			return layer, fmt.Errorf("some error")
+		})
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "some error")
+		assert.Equal(t, backdrop, modifiedCanvas.GetUnderlyingImage().At(0, 0))
+	})
+	t.Run("plain", func(t *testing.T) {
+		var drawnOn Canvas
+		canvas := newCanvas()
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, BlendMode: BlendNormal}, func(layer Canvas) (Canvas, error) {
+			drawnOn = layer
+			return drawSource(layer)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, canvas, drawnOn, "a plain layer should be drawn directly on the canvas")
+		assert.Equal(t, source, modifiedCanvas.GetUnderlyingImage().At(0, 0))
+	})
+	t.Run("opacity", func(t *testing.T) {
+		modifiedCanvas, err := newCanvas().Layer(LayerStyle{Opacity: 0.5}, func(layer Canvas) (Canvas, error) {
+			drawn, _ := drawSource(layer)
+			// Overlapping parts of the layer should not show through each other.
+			return drawn.Rectangle(image.ZP, 1, 1, source)
+		})
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, color.NRGBA{R: 150, G: 150, B: 153, A: 255}, img.At(0, 0))
+		assert.Equal(t, color.NRGBA{R: 100, G: 200, B: 255, A: 128}, img.At(1, 0))
+	})
+	tests := []struct {
+		name     string
+		mode     BlendMode
+		expected color.NRGBA
+	}{
+		{name: "multiply", mode: BlendMultiply, expected: color.NRGBA{R: 78, G: 78, B: 50, A: 255}},
+		{name: "screen", mode: BlendScreen, expected: color.NRGBA{R: 222, G: 222, B: 255, A: 255}},
+		{name: "overlay", mode: BlendOverlay, expected: color.NRGBA{R: 188, G: 157, B: 100, A: 255}},
+		{name: "darken", mode: BlendDarken, expected: color.NRGBA{R: 100, G: 100, B: 50, A: 255}},
+		{name: "lighten", mode: BlendLighten, expected: color.NRGBA{R: 200, G: 200, B: 255, A: 255}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			modifiedCanvas, err := newCanvas().Layer(LayerStyle{Opacity: 1, BlendMode: test.mode}, drawSource)
+			assert.NoError(t, err)
+			img := modifiedCanvas.GetUnderlyingImage()
+			assert.Equal(t, test.expected, img.At(0, 0))
+			assert.Equal(t, source, img.At(1, 0), "nothing beneath the layer should leave it unchanged")
+		})
+	}
+	t.Run("translucent blend", func(t *testing.T) {
+		modifiedCanvas, err := newCanvas().Layer(LayerStyle{Opacity: 0.5, BlendMode: BlendMultiply}, drawSource)
+		assert.NoError(t, err)
+		assert.Equal(t, color.NRGBA{R: 139, G: 89, B: 50, A: 255}, modifiedCanvas.GetUnderlyingImage().At(0, 0))
+	})
+	t.Run("invisible", func(t *testing.T) {
+		modifiedCanvas, err := newCanvas().Layer(LayerStyle{BlendMode: BlendScreen}, drawSource)
+		assert.NoError(t, err)
+		assert.Equal(t, backdrop, modifiedCanvas.GetUnderlyingImage().At(0, 0))
+		assert.Equal(t, color.NRGBA{}, modifiedCanvas.GetUnderlyingImage().At(1, 0))
+	})
+}
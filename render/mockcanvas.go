@@ -61,6 +61,36 @@ func (m *MockCanvas) Circle(centre image.Point, radius int, colour color.Color)
 	return args.Get(0).(Canvas), args.Error(1)
 }
 
+// Ellipse returns the preset value(s).
+func (m *MockCanvas) Ellipse(centre image.Point, radiusX, radiusY int, colour color.Color) (Canvas, error) {
+	args := m.Called(centre, radiusX, radiusY, colour)
+	return args.Get(0).(Canvas), args.Error(1)
+}
+
+// Arc returns the preset value(s).
+func (m *MockCanvas) Arc(centre image.Point, radiusX, radiusY int, startAngle, endAngle float64, style ArcStyle, colour color.Color) (Canvas, error) {
+	args := m.Called(centre, radiusX, radiusY, startAngle, endAngle, style, colour)
+	return args.Get(0).(Canvas), args.Error(1)
+}
+
+// Line returns the preset value(s).
+func (m *MockCanvas) Line(start, end image.Point, style StrokeStyle, colour color.Color) (Canvas, error) {
+	args := m.Called(start, end, style, colour)
+	return args.Get(0).(Canvas), args.Error(1)
+}
+
+// Polyline returns the preset value(s).
+func (m *MockCanvas) Polyline(points []image.Point, style StrokeStyle, colour color.Color) (Canvas, error) {
+	args := m.Called(points, style, colour)
+	return args.Get(0).(Canvas), args.Error(1)
+}
+
+// Path returns the preset value(s).
+func (m *MockCanvas) Path(path Path, fill, stroke color.Color, style StrokeStyle) (Canvas, error) {
+	args := m.Called(path, fill, stroke, style)
+	return args.Get(0).(Canvas), args.Error(1)
+}
+
 // Text returns the preset value(s).
 func (m *MockCanvas) Text(text string, start image.Point, typeFace font.Face, colour color.Color, maxWidth int) (Canvas, error) {
 	args := m.Called(text, start, typeFace, colour, maxWidth)
@@ -84,3 +114,9 @@ func (m *MockCanvas) Barcode(codeType BarcodeType, content []byte, extra Barcode
 	args := m.Called(codeType, content, extra, start, width, height, dataColour, bgColour)
 	return args.Get(0).(Canvas), args.Error(1)
 }
+
+// Layer returns the preset value(s).
+func (m *MockCanvas) Layer(style LayerStyle, draw func(Canvas) (Canvas, error)) (Canvas, error) {
+	args := m.Called(style, draw)
+	return args.Get(0).(Canvas), args.Error(1)
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestMockCanvas(t *testing.T) {
@@ -27,6 +28,26 @@ func TestMockCanvas(t *testing.T) {
 	c, err = m.Circle(image.Pt(0, 0), 6, color.White)
 	assert.Equal(t, m, c)
 	assert.EqualError(t, err, "some error")
+	m.On("Ellipse", image.Pt(0, 0), 6, 3, color.White).Return(m, fmt.Errorf("some error"))
+	c, err = m.Ellipse(image.Pt(0, 0), 6, 3, color.White)
+	assert.Equal(t, m, c)
+	assert.EqualError(t, err, "some error")
+	m.On("Arc", image.Pt(0, 0), 6, 3, 0.0, 90.0, ArcPie, color.White).Return(m, fmt.Errorf("some error"))
+	c, err = m.Arc(image.Pt(0, 0), 6, 3, 0, 90, ArcPie, color.White)
+	assert.Equal(t, m, c)
+	assert.EqualError(t, err, "some error")
+	m.On("Line", image.Pt(0, 0), image.Pt(5, 5), StrokeStyle{Width: 2}, color.White).Return(m, fmt.Errorf("some error"))
+	c, err = m.Line(image.Pt(0, 0), image.Pt(5, 5), StrokeStyle{Width: 2}, color.White)
+	assert.Equal(t, m, c)
+	assert.EqualError(t, err, "some error")
+	m.On("Polyline", []image.Point{{X: 0, Y: 0}, {X: 5, Y: 5}}, StrokeStyle{Width: 2}, color.White).Return(m, fmt.Errorf("some error"))
+	c, err = m.Polyline([]image.Point{{X: 0, Y: 0}, {X: 5, Y: 5}}, StrokeStyle{Width: 2}, color.White)
+	assert.Equal(t, m, c)
+	assert.EqualError(t, err, "some error")
+	m.On("Path", NewPolygonPath([]image.Point{{X: 0, Y: 0}, {X: 5, Y: 5}, {X: 0, Y: 5}}), color.Black, nil, StrokeStyle{}).Return(m, fmt.Errorf("some error"))
+	c, err = m.Path(NewPolygonPath([]image.Point{{X: 0, Y: 0}, {X: 5, Y: 5}, {X: 0, Y: 5}}), color.Black, nil, StrokeStyle{})
+	assert.Equal(t, m, c)
+	assert.EqualError(t, err, "some error")
 	m.On("Text", "test", image.Pt(0, 0), nil, color.White, 50).Return(m, fmt.Errorf("some error"))
 	c, err = m.Text("test", image.Pt(0, 0), nil, color.White, 50)
 	assert.Equal(t, m, c)
@@ -44,6 +65,10 @@ func TestMockCanvas(t *testing.T) {
 	c, err = m.Barcode(barcodeType, []byte{}, BarcodeExtraData{}, image.Pt(0, 0), 50, 20, color.White, color.Black)
 	assert.Equal(t, m, c)
 	assert.EqualError(t, err, "some error")
+	m.On("Layer", LayerStyle{Opacity: 0.5}, mock.Anything).Return(m, fmt.Errorf("some error"))
+	c, err = m.Layer(LayerStyle{Opacity: 0.5}, func(layer Canvas) (Canvas, error) { return layer, nil })
+	assert.Equal(t, m, c)
+	assert.EqualError(t, err, "some error")
 	m.On("SetPPI", float64(100)).Return(m)
 	assert.Equal(t, m, m.SetPPI(float64(100)))
 	m.On("GetPPI").Return(float64(80))
@@ -0,0 +1,575 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// pathOp is the type of a path segment.
+type pathOp int
+
+const (
+	pathMove pathOp = iota
+	pathLine
+	pathQuad
+	pathCube
+	pathClose
+)
+
+// pathSegment is a single drawing operation, with any control points followed by the end point.
+type pathSegment struct {
+	op     pathOp
+	points []pointF
+}
+
+// Path is a shape made up of straight lines and curves, in canvas pixel coordinates. As in SVG, coordinates refer to the edges of pixels rather than their centres, so a square path from (0,0) to (10,10) exactly covers the same pixels as a 10 by 10 rectangle.
+type Path struct {
+	segments []pathSegment
+}
+
+// NewPolygonPath generates a closed path through each of the points in turn.
+func NewPolygonPath(points []image.Point) Path {
+	var path Path
+	for i, p := range points {
+		op := pathLine
+		if i == 0 {
+			op = pathMove
+		}
+		path.segments = append(path.segments, pathSegment{op: op, points: []pointF{{X: float64(p.X), Y: float64(p.Y)}}})
+	}
+	if len(points) > 0 {
+		path.segments = append(path.segments, pathSegment{op: pathClose})
+	}
+	return path
+}
+
+// ParsePath parses SVG path data, supporting every command in the SVG 1.1 specification. Elliptical arcs are converted to cubic Bézier curves.
+func ParsePath(data string) (Path, error) {
+	parser := &pathParser{data: data}
+	var path Path
+	var command byte
+	var current, subpathStart, lastControl pointF
+	// A new subpath starts implicitly if a closed subpath is followed by anything but a move.
+	needMove := true
+	add := func(op pathOp, points ...pointF) {
+		if op != pathMove && needMove {
+			path.segments = append(path.segments, pathSegment{op: pathMove, points: []pointF{current}})
+		}
+		needMove = false
+		path.segments = append(path.segments, pathSegment{op: op, points: points})
+		if op != pathClose {
+			current = points[len(points)-1]
+		}
+	}
+	for !parser.done() {
+		previous := command
+		c := parser.data[parser.pos]
+		switch {
+		case strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", c) >= 0:
+			command = c
+			parser.pos++
+		case command == 0:
+			return Path{}, fmt.Errorf("path data must start with a move command, found %q at offset %d", c, parser.pos)
+		case command == 'Z' || command == 'z' || !parser.hasNumber():
+			return Path{}, fmt.Errorf("unexpected %q in path data at offset %d", c, parser.pos)
+		case command == 'M':
+			// Coordinates following a move are implicit line commands.
+			command = 'L'
+		case command == 'm':
+			command = 'l'
+		}
+		if command != 'M' && command != 'm' && len(path.segments) == 0 {
+			return Path{}, fmt.Errorf("path data must start with a move command, found %q", command)
+		}
+		var origin pointF
+		if command >= 'a' {
+			origin = current
+		}
+		var numbers []float64
+		var err error
+		switch command {
+		case 'M', 'm':
+			numbers, err = parser.numbers(2)
+			if err != nil {
+				return Path{}, err
+			}
+			needMove = false
+			current = origin.add(pointF{X: numbers[0], Y: numbers[1]})
+			subpathStart = current
+			path.segments = append(path.segments, pathSegment{op: pathMove, points: []pointF{current}})
+		case 'L', 'l':
+			numbers, err = parser.numbers(2)
+			if err != nil {
+				return Path{}, err
+			}
+			add(pathLine, origin.add(pointF{X: numbers[0], Y: numbers[1]}))
+		case 'H', 'h':
+			numbers, err = parser.numbers(1)
+			if err != nil {
+				return Path{}, err
+			}
+			add(pathLine, pointF{X: origin.X + numbers[0], Y: current.Y})
+		case 'V', 'v':
+			numbers, err = parser.numbers(1)
+			if err != nil {
+				return Path{}, err
+			}
+			add(pathLine, pointF{X: current.X, Y: origin.Y + numbers[0]})
+		case 'C', 'c':
+			numbers, err = parser.numbers(6)
+			if err != nil {
+				return Path{}, err
+			}
+			lastControl = origin.add(pointF{X: numbers[2], Y: numbers[3]})
+			add(pathCube, origin.add(pointF{X: numbers[0], Y: numbers[1]}), lastControl, origin.add(pointF{X: numbers[4], Y: numbers[5]}))
+		case 'S', 's':
+			numbers, err = parser.numbers(4)
+			if err != nil {
+				return Path{}, err
+			}
+			// The first control point is the reflection of the last one, if the previous command was also a cubic curve.
+			first := current
+			if strings.IndexByte("CcSs", previous) >= 0 {
+				first = current.scale(2).sub(lastControl)
+			}
+			lastControl = origin.add(pointF{X: numbers[0], Y: numbers[1]})
+			add(pathCube, first, lastControl, origin.add(pointF{X: numbers[2], Y: numbers[3]}))
+		case 'Q', 'q':
+			numbers, err = parser.numbers(4)
+			if err != nil {
+				return Path{}, err
+			}
+			lastControl = origin.add(pointF{X: numbers[0], Y: numbers[1]})
+			add(pathQuad, lastControl, origin.add(pointF{X: numbers[2], Y: numbers[3]}))
+		case 'T', 't':
+			numbers, err = parser.numbers(2)
+			if err != nil {
+				return Path{}, err
+			}
+			control := current
+			if strings.IndexByte("QqTt", previous) >= 0 {
+				control = current.scale(2).sub(lastControl)
+			}
+			lastControl = control
+			add(pathQuad, control, origin.add(pointF{X: numbers[0], Y: numbers[1]}))
+		case 'A', 'a':
+			numbers, err = parser.numbers(3)
+			if err != nil {
+				return Path{}, err
+			}
+			var largeArc, sweep bool
+			largeArc, err = parser.flag()
+			if err != nil {
+				return Path{}, err
+			}
+			sweep, err = parser.flag()
+			if err != nil {
+				return Path{}, err
+			}
+			var end []float64
+			end, err = parser.numbers(2)
+			if err != nil {
+				return Path{}, err
+			}
+			to := origin.add(pointF{X: end[0], Y: end[1]})
+			if to == current {
+				// An arc to the current point draws nothing.
+				continue
+			}
+			if numbers[0] == 0 || numbers[1] == 0 {
+				add(pathLine, to)
+				continue
+			}
+			for _, segment := range endpointArc(current, to, math.Abs(numbers[0]), math.Abs(numbers[1]), numbers[2]*math.Pi/180, largeArc, sweep) {
+				add(segment.op, segment.points...)
+			}
+		case 'Z', 'z':
+			if !needMove {
+				add(pathClose)
+			}
+			current = subpathStart
+			needMove = true
+		}
+	}
+	if len(path.segments) == 0 {
+		return Path{}, errors.New("path data is empty")
+	}
+	return path, nil
+}
+
+// pathParser reads the numbers and flags of SVG path data.
+type pathParser struct {
+	data string
+	pos  int
+}
+
+func (parser *pathParser) skip() {
+	for parser.pos < len(parser.data) && strings.IndexByte(" \t\r\n\f,", parser.data[parser.pos]) >= 0 {
+		parser.pos++
+	}
+}
+
+func (parser *pathParser) done() bool {
+	parser.skip()
+	return parser.pos >= len(parser.data)
+}
+
+func (parser *pathParser) hasNumber() bool {
+	parser.skip()
+	return parser.pos < len(parser.data) && strings.IndexByte("0123456789.+-", parser.data[parser.pos]) >= 0
+}
+
+// numbers reads the arguments of a command.
+func (parser *pathParser) numbers(count int) ([]float64, error) {
+	numbers := make([]float64, count)
+	for i := range numbers {
+		if !parser.hasNumber() {
+			return nil, fmt.Errorf("expected a number in path data at offset %d", parser.pos)
+		}
+		start := parser.pos
+		end := start
+		if parser.data[end] == '+' || parser.data[end] == '-' {
+			end++
+		}
+		digits := func() {
+			for end < len(parser.data) && parser.data[end] >= '0' && parser.data[end] <= '9' {
+				end++
+			}
+		}
+		digits()
+		// A second decimal point starts the next number, as in "0.5.5".
+		if end < len(parser.data) && parser.data[end] == '.' {
+			end++
+			digits()
+		}
+		if end < len(parser.data) && (parser.data[end] == 'e' || parser.data[end] == 'E') {
+			exponent := end + 1
+			if exponent < len(parser.data) && (parser.data[exponent] == '+' || parser.data[exponent] == '-') {
+				exponent++
+			}
+			if exponent < len(parser.data) && parser.data[exponent] >= '0' && parser.data[exponent] <= '9' {
+				end = exponent
+				digits()
+			}
+		}
+		value, err := strconv.ParseFloat(parser.data[start:end], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q in path data at offset %d", parser.data[start:end], start)
+		}
+		numbers[i] = value
+		parser.pos = end
+	}
+	return numbers, nil
+}
+
+// flag reads an arc flag, which need not be separated from whatever follows it.
+func (parser *pathParser) flag() (bool, error) {
+	parser.skip()
+	if parser.pos >= len(parser.data) || (parser.data[parser.pos] != '0' && parser.data[parser.pos] != '1') {
+		return false, fmt.Errorf("expected an arc flag of 0 or 1 in path data at offset %d", parser.pos)
+	}
+	parser.pos++
+	return parser.data[parser.pos-1] == '1', nil
+}
+
+// endpointArc converts an SVG elliptical arc between two points to cubic Bézier curves, following the SVG implementation notes.
+func endpointArc(from, to pointF, rx, ry, rotation float64, largeArc, sweep bool) []pathSegment {
+	cos, sin := math.Cos(rotation), math.Sin(rotation)
+	// Find the centre of the ellipse in a coordinate system aligned with its axes.
+	half := from.sub(to).scale(0.5)
+	x1 := cos*half.X + sin*half.Y
+	y1 := -sin*half.X + cos*half.Y
+	// Radii too small to reach the end point are scaled up until they just do.
+	if lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry); lambda > 1 {
+		rx, ry = rx*math.Sqrt(lambda), ry*math.Sqrt(lambda)
+	}
+	numerator := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	denominator := rx*rx*y1*y1 + ry*ry*x1*x1
+	coefficient := math.Sqrt(math.Max(0, numerator/denominator))
+	if largeArc == sweep {
+		coefficient = -coefficient
+	}
+	cx1 := coefficient * rx * y1 / ry
+	cy1 := -coefficient * ry * x1 / rx
+	middle := from.add(to).scale(0.5)
+	centre := pointF{X: cos*cx1 - sin*cy1 + middle.X, Y: sin*cx1 + cos*cy1 + middle.Y}
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	start := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	extent := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && extent > 0 {
+		extent -= 2 * math.Pi
+	} else if sweep && extent < 0 {
+		extent += 2 * math.Pi
+	}
+	segments := ellipseArc(centre, rx, ry, rotation, start, extent)
+	// Make sure rounding errors don't leave a gap at the end of the arc.
+	segments[len(segments)-1].points[2] = to
+	return segments
+}
+
+// ellipseArc approximates part of an ellipse with cubic Bézier curves of no more than a quarter turn each. Angles are in radians, clockwise on screen from the ellipse's X axis.
+func ellipseArc(centre pointF, rx, ry, rotation, start, extent float64) []pathSegment {
+	cos, sin := math.Cos(rotation), math.Sin(rotation)
+	transform := func(u, v float64) pointF {
+		return pointF{X: centre.X + cos*rx*u - sin*ry*v, Y: centre.Y + sin*rx*u + cos*ry*v}
+	}
+	count := int(math.Ceil(math.Abs(extent)/(math.Pi/2) - 1e-9))
+	if count < 1 {
+		count = 1
+	}
+	step := extent / float64(count)
+	k := 4.0 / 3.0 * math.Tan(step/4)
+	segments := make([]pathSegment, count)
+	for i := range segments {
+		a1 := start + float64(i)*step
+		a2 := a1 + step
+		cos1, sin1 := math.Cos(a1), math.Sin(a1)
+		cos2, sin2 := math.Cos(a2), math.Sin(a2)
+		segments[i] = pathSegment{op: pathCube, points: []pointF{
+			transform(cos1-k*sin1, sin1+k*cos1),
+			transform(cos2+k*sin2, sin2-k*cos2),
+			transform(cos2, sin2),
+		}}
+	}
+	return segments
+}
+
+// polyline is a flattened subpath of a Path.
+type polyline struct {
+	points []pointF
+	closed bool
+}
+
+// flatness is the furthest in pixels that flattened curves may stray from the true curve. Even small errors visibly shrink small circles, so this is a tiny fraction of a pixel.
+const flatness = 0.01
+
+// flatten converts the path to straight lines, splitting curves into enough pieces that the error is within the flatness.
+func (path Path) flatten() []polyline {
+	var lines []polyline
+	var current pointF
+	for _, segment := range path.segments {
+		if segment.op == pathMove {
+			current = segment.points[0]
+			lines = append(lines, polyline{points: []pointF{current}})
+			continue
+		}
+		if len(lines) == 0 {
+			lines = append(lines, polyline{points: []pointF{current}})
+		}
+		line := &lines[len(lines)-1]
+		switch segment.op {
+		case pathLine:
+			line.points = append(line.points, segment.points[0])
+		case pathQuad, pathCube:
+			controls := append([]pointF{current}, segment.points...)
+			// Wang's formula bounds how far the curve strays from the straight lines between evenly spaced points on it.
+			degree := float64(len(controls) - 1)
+			bend := 0.0
+			for i := 2; i < len(controls); i++ {
+				bend = math.Max(bend, controls[i-2].sub(controls[i-1].scale(2)).add(controls[i]).length())
+			}
+			steps := int(math.Ceil(math.Sqrt(degree * (degree - 1) * bend / (8 * flatness))))
+			if steps < 1 {
+				steps = 1
+			}
+			for i := 1; i <= steps; i++ {
+				line.points = append(line.points, bezierPoint(controls, float64(i)/float64(steps)))
+			}
+		case pathClose:
+			line.closed = true
+			// Anything after a close without a move continues from the start of the closed subpath.
+			current = line.points[0]
+			lines = append(lines, polyline{points: []pointF{current}})
+			continue
+		}
+		current = line.points[len(line.points)-1]
+	}
+	// Drop subpaths which never went anywhere.
+	result := lines[:0]
+	for _, line := range lines {
+		if len(line.points) > 1 || line.closed {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// bezierPoint evaluates a Bézier curve with any number of control points.
+func bezierPoint(controls []pointF, t float64) pointF {
+	points := append([]pointF{}, controls...)
+	for n := len(points) - 1; n > 0; n-- {
+		for i := 0; i < n; i++ {
+			points[i] = points[i].add(points[i+1].sub(points[i]).scale(t))
+		}
+	}
+	return points[0]
+}
+
+// translated returns a copy of the path moved by the offset.
+func (path Path) translated(offset image.Point) Path {
+	shift := pointF{X: float64(offset.X), Y: float64(offset.Y)}
+	moved := Path{segments: make([]pathSegment, len(path.segments))}
+	for i, segment := range path.segments {
+		points := make([]pointF, len(segment.points))
+		for j, p := range segment.points {
+			points[j] = p.add(shift)
+		}
+		moved.segments[i] = pathSegment{op: segment.op, points: points}
+	}
+	return moved
+}
+
+// fillPolygons returns the polygons filling the path.
+func (path Path) fillPolygons() [][]pointF {
+	var polygons [][]pointF
+	for _, line := range path.flatten() {
+		polygons = append(polygons, line.points)
+	}
+	return polygons
+}
+
+// bounds returns the bounding box of the area filled by the path, which gradients are stretched across.
+func (path Path) bounds() image.Rectangle {
+	return polygonBounds(path.fillPolygons())
+}
+
+// strokePolygons returns the polygons outlining the path with the style, which must be normalised, and the polygons to clip them to if the outline is inside the path.
+func (path Path) strokePolygons(style StrokeStyle) (polygons, clip [][]pointF) {
+	if style.Inside {
+		// Only the inside half of an outline twice as wide is left by clipping it to the path.
+		style.Width *= 2
+		clip = path.fillPolygons()
+	}
+	for _, line := range path.flatten() {
+		polygons = append(polygons, strokePolygons(line.points, line.closed, style)...)
+	}
+	return polygons, clip
+}
+
+// svgData returns the path as SVG path data.
+func (path Path) svgData() string {
+	var data bytes.Buffer
+	for _, segment := range path.segments {
+		if data.Len() > 0 {
+			data.WriteByte(' ')
+		}
+		data.WriteString([]string{"M", "L", "Q", "C", "Z"}[segment.op])
+		for _, p := range segment.points {
+			fmt.Fprintf(&data, " %s %s", formatNumber(p.X), formatNumber(p.Y))
+		}
+	}
+	return data.String()
+}
+
+// pdfData returns the path as PDF path construction operators, with quadratic curves converted to cubic.
+func (path Path) pdfData() string {
+	var data bytes.Buffer
+	var current, start pointF
+	n := formatNumber
+	for _, segment := range path.segments {
+		if data.Len() > 0 {
+			data.WriteByte(' ')
+		}
+		switch segment.op {
+		case pathMove:
+			start = segment.points[0]
+			fmt.Fprintf(&data, "%s %s m", n(start.X), n(start.Y))
+		case pathLine:
+			fmt.Fprintf(&data, "%s %s l", n(segment.points[0].X), n(segment.points[0].Y))
+		case pathQuad:
+			control, end := segment.points[0], segment.points[1]
+			c1 := current.add(control.sub(current).scale(2.0 / 3.0))
+			c2 := end.add(control.sub(end).scale(2.0 / 3.0))
+			fmt.Fprintf(&data, "%s %s %s %s %s %s c", n(c1.X), n(c1.Y), n(c2.X), n(c2.Y), n(end.X), n(end.Y))
+		case pathCube:
+			c1, c2, end := segment.points[0], segment.points[1], segment.points[2]
+			fmt.Fprintf(&data, "%s %s %s %s %s %s c", n(c1.X), n(c1.Y), n(c2.X), n(c2.Y), n(end.X), n(end.Y))
+		case pathClose:
+			data.WriteString("h")
+			current = start
+			continue
+		}
+		current = segment.points[len(segment.points)-1]
+	}
+	return data.String()
+}
+
+// isVisible returns whether a colour would draw anything at all, treating nil as transparent.
+func isVisible(colour color.Color) bool {
+	return toNRGBA(colour).A != 0
+}
+
+// checkPath validates the arguments to Path, returning the normalised stroke style.
+func checkPath(path Path, style StrokeStyle) (StrokeStyle, error) {
+	if len(path.segments) == 0 {
+		return style, errors.New("path has no segments")
+	}
+	if style.Width == 0 {
+		return style, nil
+	}
+	return style.normalise()
+}
+
+// Path fills a path with one colour and outlines it with another. The fill may be a Gradient, which is stretched across the bounding box of the filled area. Either colour may be nil or transparent to skip that part, and a stroke style with zero width draws no outline.
+func (canvas ImageCanvas) Path(path Path, fill, stroke color.Color, style StrokeStyle) (Canvas, error) {
+	style, err := checkPath(path, style)
+	if err != nil {
+		return canvas, err
+	}
+	if canvas.Image == nil {
+		return canvas, errors.New("no image set for canvas to draw on")
+	}
+	if isVisible(fill) {
+		fillShape := path.fillPolygons()
+		fillPolygons(canvas.Image, fillShape, paint(fill, polygonBounds(fillShape)))
+	}
+	if isVisible(stroke) && style.Width != 0 {
+		strokeShape, clip := path.strokePolygons(style)
+		fillClippedPolygons(canvas.Image, strokeShape, clip, image.NewUniform(stroke))
+	}
+	return canvas, nil
+}
+
+// rasterisePath draws a path onto a transparent image just large enough to hold it, for canvases which can't draw it natively. The style must have been checked.
+func rasterisePath(path Path, fill, stroke color.Color, style StrokeStyle) (image.Point, image.Image) {
+	var fillShape, strokeShape, clip [][]pointF
+	if isVisible(fill) {
+		fillShape = path.fillPolygons()
+	}
+	if isVisible(stroke) && style.Width != 0 {
+		strokeShape, clip = path.strokePolygons(style)
+	}
+	bounds := polygonBounds(append(append([][]pointF{}, fillShape...), strokeShape...))
+	img := image.NewNRGBA(bounds)
+	fillPolygons(img, fillShape, paint(fill, polygonBounds(fillShape)))
+	fillClippedPolygons(img, strokeShape, clip, image.NewUniform(stroke))
+	return bounds.Min, img
+}
+
+// fillClippedPolygons fills the polygons as fillPolygons does, but only where they overlap the clipping polygons. No clipping polygons at all leaves the fill unclipped.
+func fillClippedPolygons(dst draw.Image, polygons, clip [][]pointF, src image.Image) {
+	if clip == nil {
+		fillPolygons(dst, polygons, src)
+		return
+	}
+	bounds := polygonBounds(polygons).Intersect(polygonBounds(clip)).Intersect(dst.Bounds())
+	if bounds.Empty() {
+		return
+	}
+	mask, clipMask := image.NewAlpha(bounds), image.NewAlpha(bounds)
+	fillPolygons(mask, polygons, image.Opaque)
+	fillPolygons(clipMask, clip, image.Opaque)
+	for i := range mask.Pix {
+		mask.Pix[i] = uint8((uint32(mask.Pix[i])*uint32(clipMask.Pix[i]) + 127) / 255)
+	}
+	draw.DrawMask(dst, bounds, src, bounds.Min, mask, bounds.Min, draw.Over)
+}
@@ -0,0 +1,252 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePath(t *testing.T) {
+	type testSet struct {
+		name string
+		data string
+		svg  string
+		err  string
+	}
+	tests := []testSet{
+		{name: "empty", data: " ", err: "path data is empty"},
+		{name: "no move", data: "L 1 2", err: "path data must start with a move command, found 'L'"},
+		{name: "number first", data: "1 2", err: "path data must start with a move command, found '1' at offset 0"},
+		{name: "bad command", data: "M 1 2 X 3 4", err: "unexpected 'X' in path data at offset 6"},
+		{name: "missing number", data: "M 1 2 L 3", err: "expected a number in path data at offset 9"},
+		{name: "bad number", data: "M 1 2 L - 3", err: "invalid number \"-\" in path data at offset 8"},
+		{name: "numbers after close", data: "M 1 2 Z 3 4", err: "unexpected '3' in path data at offset 8"},
+		{name: "bad flag", data: "M 0 0 A 5 5 0 2 0 10 0", err: "expected an arc flag of 0 or 1 in path data at offset 14"},
+		{name: "lines", data: "M1,2L3,4 5,6Z", svg: "M 1 2 L 3 4 L 5 6 Z"},
+		{name: "implicit lines", data: "M 1 2 3 4 m 1 1 2 2", svg: "M 1 2 L 3 4 M 4 5 L 6 7"},
+		{name: "compact numbers", data: "M-1-2L.5.5 1e1-1.5E-1", svg: "M -1 -2 L 0.5 0.5 L 10 -0.15"},
+		{name: "horizontal and vertical", data: "M 1 1 H 5 v 3 h -2 V 0", svg: "M 1 1 L 5 1 L 5 4 L 3 4 L 3 0"},
+		{name: "cubic", data: "M 0 0 C 1 2 3 4 5 6 s 1 1 2 2 S 1 1 0 0", svg: "M 0 0 C 1 2 3 4 5 6 C 7 8 6 7 7 8 C 8 9 1 1 0 0"},
+		{name: "smooth cubic without previous", data: "M 1 1 S 2 2 3 3", svg: "M 1 1 C 1 1 2 2 3 3"},
+		{name: "quadratic", data: "M 0 0 Q 1 1 2 0 T 4 0 t 2 0", svg: "M 0 0 Q 1 1 2 0 Q 3 -1 4 0 Q 5 1 6 0"},
+		{name: "smooth quadratic without previous", data: "M 0 0 T 4 0", svg: "M 0 0 Q 0 0 4 0"},
+		{name: "close and continue", data: "M 1 1 L 5 1 L 5 5 z l 1 0", svg: "M 1 1 L 5 1 L 5 5 Z M 1 1 L 2 1"},
+		{name: "double close", data: "M 1 1 L 2 2 Z Z", svg: "M 1 1 L 2 2 Z"},
+		{name: "degenerate arcs", data: "M 0 0 A 0 5 0 0 1 10 0 A 5 5 0 0 1 10 0", svg: "M 0 0 L 10 0"},
+		{name: "semicircle", data: "M 0 0 A 5 5 0 0 1 10 0", svg: "M 0 0 C 0 -2.7614 2.2386 -5 5 -5 C 7.7614 -5 10 -2.7614 10 0"},
+		{name: "compact arc flags", data: "M 0 0 a5 5 0 0010 0", svg: "M 0 0 C 0 2.7614 2.2386 5 5 5 C 7.7614 5 10 2.7614 10 0"},
+		{name: "radii too small", data: "M 0 0 A 1 1 0 1 1 10 0", svg: "M 0 0 C 0 -2.7614 2.2386 -5 5 -5 C 7.7614 -5 10 -2.7614 10 0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, err := ParsePath(test.data)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				assert.Equal(t, Path{}, path)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.svg, path.svgData())
+		})
+	}
+	t.Run("large arc", func(t *testing.T) {
+		path, err := ParsePath("M 10 0 A 10 10 0 1 1 0 10")
+		assert.NoError(t, err)
+		// The long way round clockwise is three quarters of a circle around (10,10), which needs three curves.
+		if assert.Len(t, path.segments, 4) {
+			end := path.segments[3].points[2]
+			assert.Equal(t, pointF{X: 0, Y: 10}, end)
+			middle := path.segments[2].points[2]
+			assert.InDelta(t, 10, middle.X, 1e-9)
+			assert.InDelta(t, 20, middle.Y, 1e-9)
+		}
+	})
+	t.Run("rotated arc", func(t *testing.T) {
+		path, err := ParsePath("M 0 0 A 10 5 90 0 1 0 20")
+		assert.NoError(t, err)
+		// The long axis of the ellipse is vertical, so the arc bulges out clockwise by the short radius.
+		minX, maxX := 0.0, 0.0
+		for _, line := range path.flatten() {
+			for _, p := range line.points {
+				minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+			}
+		}
+		assert.InDelta(t, 0, minX, 1e-9)
+		assert.InDelta(t, 5, maxX, 0.01)
+	})
+}
+
+func TestNewPolygonPath(t *testing.T) {
+	assert.Equal(t, Path{}, NewPolygonPath(nil))
+	path := NewPolygonPath([]image.Point{{X: 1, Y: 2}, {X: 3, Y: 4}, {X: 5, Y: 0}})
+	assert.Equal(t, "M 1 2 L 3 4 L 5 0 Z", path.svgData())
+	assert.Equal(t, "1 2 m 3 4 l 5 0 l h", path.pdfData())
+}
+
+func TestNewRectanglePath(t *testing.T) {
+	path := NewRectanglePath(image.Pt(1, 2), 10, 6, CornerRadii{})
+	assert.Equal(t, "M 1 2 L 11 2 L 11 8 L 1 8 L 1 2 Z", path.svgData())
+	path = NewRectanglePath(image.Pt(0, 0), 10, 6, CornerRadii{TopRight: 2, BottomLeft: -1})
+	assert.Equal(t, "M 0 0 L 8 0 C 9.1046 0 10 0.8954 10 2 L 10 6 L 0 6 L 0 0 Z", path.svgData())
+	// The right hand corners together are 3 times the height, so every corner is scaled down to a third.
+	path = NewRectanglePath(image.Pt(0, 0), 20, 6, CornerRadii{TopLeft: 3, TopRight: 9, BottomRight: 9})
+	assert.Equal(t, "M 1 0 L 17 0 C 18.6569 0 20 1.3431 20 3 L 20 3 C 20 4.6569 18.6569 6 17 6 L 0 6 L 0 1 C 0 0.4477 0.4477 0 1 0 Z", path.svgData())
+}
+
+func TestNewEllipsePath(t *testing.T) {
+	path := NewEllipsePath(image.Pt(10, 5), 4, 2)
+	assert.Equal(t, "M 14 5 C 14 6.1046 12.2091 7 10 7 C 7.7909 7 6 6.1046 6 5 C 6 3.8954 7.7909 3 10 3 C 12.2091 3 14 3.8954 14 5 Z", path.svgData())
+}
+
+func TestPathPDFData(t *testing.T) {
+	path, err := ParsePath("M 0 0 Q 3 3 6 0 C 7 1 8 1 9 0 Z L 3 3")
+	assert.NoError(t, err)
+	assert.Equal(t, "0 0 m 2 2 4 2 6 0 c 7 1 8 1 9 0 c h 0 0 m 3 3 l", path.pdfData())
+}
+
+func TestPathFlatten(t *testing.T) {
+	path, err := ParsePath("M 0 0 L 4 0 M 9 9 Z L 1 1 M 5 5 Q 6 6 7 5")
+	assert.NoError(t, err)
+	lines := path.flatten()
+	if assert.Len(t, lines, 4) {
+		assert.Equal(t, polyline{points: []pointF{{X: 0, Y: 0}, {X: 4, Y: 0}}}, lines[0])
+		assert.Equal(t, polyline{points: []pointF{{X: 9, Y: 9}}, closed: true}, lines[1])
+		assert.Equal(t, polyline{points: []pointF{{X: 9, Y: 9}, {X: 1, Y: 1}}}, lines[2])
+		curve := lines[3].points
+		assert.Equal(t, pointF{X: 5, Y: 5}, curve[0])
+		assert.Equal(t, pointF{X: 7, Y: 5}, curve[len(curve)-1])
+		assert.True(t, len(curve) > 2, "expected the curve to be split, got %v", curve)
+		for _, p := range curve {
+			assert.True(t, p.Y >= 5 && p.Y <= 5.5, "point %v is off the curve", p)
+		}
+	}
+}
+
+func TestPath(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	square := NewPolygonPath([]image.Point{{X: 2, Y: 2}, {X: 8, Y: 2}, {X: 8, Y: 8}, {X: 2, Y: 8}})
+	t.Run("no image", func(t *testing.T) {
+		canvas := ImageCanvas{}
+		modifiedCanvas, err := canvas.Path(square, red, nil, StrokeStyle{})
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "no image set for canvas to draw on")
+	})
+	t.Run("empty path", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Path(Path{}, red, nil, StrokeStyle{})
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "path has no segments")
+	})
+	t.Run("invalid stroke", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Path(square, red, blue, StrokeStyle{Width: -2})
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid stroke width -2")
+	})
+	t.Run("fill", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Path(square, red, blue, StrokeStyle{})
+		assert.NoError(t, err)
+		// The path follows pixel edges, so the square is exactly filled.
+		for y := 0; y < 10; y++ {
+			for x := 0; x < 10; x++ {
+				expected := color.NRGBA{}
+				if x >= 2 && x < 8 && y >= 2 && y < 8 {
+					expected = red
+				}
+				assert.Equal(t, expected, modifiedCanvas.GetUnderlyingImage().At(x, y), "pixel %d,%d", x, y)
+			}
+		}
+	})
+	t.Run("fill and stroke", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Path(square, red, blue, StrokeStyle{Width: 2})
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, blue, img.At(1, 1), "miter joins fill the corners")
+		assert.Equal(t, blue, img.At(2, 5))
+		assert.Equal(t, blue, img.At(8, 5))
+		assert.Equal(t, red, img.At(3, 5))
+		assert.Equal(t, color.NRGBA{}, img.At(0, 5))
+	})
+	t.Run("stroke only", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Path(square, color.NRGBA{}, blue, StrokeStyle{Width: 2, Join: JoinBevel})
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, color.NRGBA{}, img.At(5, 5))
+		assert.Equal(t, blue, img.At(2, 2))
+		assert.Equal(t, uint8(128), toNRGBA(img.At(1, 1)).A, "bevel joins cut the corners in half")
+	})
+	t.Run("holes", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		// The inner square winds the other way, so it is cut out of the outer one.
+		ring, err := ParsePath("M 0 0 H 10 V 10 H 0 Z M 3 3 V 7 H 7 V 3 Z")
+		assert.NoError(t, err)
+		modifiedCanvas, err := canvas.Path(ring, red, nil, StrokeStyle{})
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, red, img.At(1, 1))
+		assert.Equal(t, color.NRGBA{}, img.At(5, 5))
+		// Subpaths winding the same way are both filled.
+		overlap, err := ParsePath("M 0 0 H 10 V 10 H 0 Z M 3 3 H 7 V 7 H 3 Z")
+		assert.NoError(t, err)
+		blank, _ := NewCanvas(10, 10)
+		modifiedCanvas, err = blank.Path(overlap, red, nil, StrokeStyle{})
+		assert.NoError(t, err)
+		assert.Equal(t, red, modifiedCanvas.GetUnderlyingImage().At(5, 5))
+	})
+	t.Run("curves", func(t *testing.T) {
+		canvas, _ := NewCanvas(20, 20)
+		circle, err := ParsePath("M 2 10 A 8 8 0 0 1 18 10 A 8 8 0 0 1 2 10 Z")
+		assert.NoError(t, err)
+		modifiedCanvas, err := canvas.Path(circle, red, nil, StrokeStyle{})
+		assert.NoError(t, err)
+		assert.Equal(t, red, modifiedCanvas.GetUnderlyingImage().At(10, 10))
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 3, 3))
+		edge := alphaAt(modifiedCanvas, 4, 4)
+		assert.True(t, edge > 0 && edge < 255, "expected an anti-aliased edge, got alpha %d", edge)
+	})
+	t.Run("inside stroke", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Path(square, red, blue, StrokeStyle{Width: 2, Inside: true})
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		// The border covers the outer two pixels of the square and nothing outside it.
+		for x := 0; x < 10; x++ {
+			expected := color.NRGBA{}
+			switch {
+			case x == 2 || x == 3 || x == 6 || x == 7:
+				expected = blue
+			case x == 4 || x == 5:
+				expected = red
+			}
+			assert.Equal(t, expected, img.At(x, 5), "pixel %d,5", x)
+		}
+		assert.Equal(t, blue, img.At(2, 2))
+		assert.Equal(t, color.NRGBA{}, img.At(1, 1))
+	})
+	t.Run("inside stroke rounded", func(t *testing.T) {
+		canvas, _ := NewCanvas(20, 20)
+		modifiedCanvas, err := canvas.Path(NewRectanglePath(image.Pt(0, 0), 20, 20, CornerRadii{TopLeft: 8}), nil, blue, StrokeStyle{Width: 2, Inside: true})
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 0, 0), "the border follows the rounded corner")
+		assert.Equal(t, uint8(255), alphaAt(modifiedCanvas, 19, 0))
+		assert.Equal(t, uint8(255), alphaAt(modifiedCanvas, 0, 10))
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 10, 10))
+	})
+	t.Run("dashed closed stroke", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Path(square, nil, blue, StrokeStyle{Width: 1, Dash: []float64{3, 3}})
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		// The top edge starts with a dash, then a gap.
+		assert.Equal(t, uint8(128), toNRGBA(img.At(3, 1)).A)
+		assert.Equal(t, uint8(0), toNRGBA(img.At(6, 1)).A)
+		assert.Equal(t, uint8(0), toNRGBA(img.At(5, 5)).A)
+	})
+}
@@ -0,0 +1,737 @@
+package render
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// PDFCanvas implements the Canvas interface by recording vector drawing operations for a single page PDF document. Every operation is mirrored onto a raster ImageCanvas, which is used to measure text and to provide the underlying image.
+type PDFCanvas struct {
+	// raster mirrors every drawing operation on the canvas.
+	raster ImageCanvas
+	// doc is the document being drawn, shared between copies of the canvas in the same way as the underlying image of an ImageCanvas.
+	doc *pdfDocument
+}
+
+// NewPDFCanvas generates a new PDF canvas of the given width and height in pixels. The page size is calculated from these dimensions and the PPI of the canvas.
+func NewPDFCanvas(width, height int) (PDFCanvas, error) {
+	raster, err := NewCanvas(width, height)
+	if err != nil {
+		return PDFCanvas{}, err
+	}
+	return PDFCanvas{raster: raster, doc: newPDFDocument()}, nil
+}
+
+// NewPDFCanvasFrom generates a new PDF canvas with the size and PPI of an existing canvas, embedding the current contents of that canvas as the background image.
+func NewPDFCanvasFrom(base Canvas) (PDFCanvas, error) {
+	canvas, err := NewPDFCanvas(base.GetWidth(), base.GetHeight())
+	if err != nil {
+		return PDFCanvas{}, err
+	}
+	canvas = canvas.SetPPI(base.GetPPI()).(PDFCanvas)
+	drawn, err := canvas.DrawImage(image.ZP, base.GetUnderlyingImage())
+	if err != nil {
+		return PDFCanvas{}, err
+	}
+	return drawn.(PDFCanvas), nil
+}
+
+// SetUnderlyingImage replaces the contents of the canvas with the given image.
+func (canvas PDFCanvas) SetUnderlyingImage(newImage image.Image) Canvas {
+	canvas.raster = canvas.raster.SetUnderlyingImage(newImage).(ImageCanvas)
+	canvas.doc = newPDFDocument()
+	if newImage != nil {
+		canvas.doc.drawImage(image.ZP, newImage)
+	}
+	return canvas
+}
+
+// GetUnderlyingImage gets the raster image mirroring the PDF contents.
+func (canvas PDFCanvas) GetUnderlyingImage() image.Image {
+	return canvas.raster.GetUnderlyingImage()
+}
+
+// GetWidth returns the width of the canvas in pixels.
+func (canvas PDFCanvas) GetWidth() int {
+	return canvas.raster.GetWidth()
+}
+
+// GetHeight returns the height of the canvas in pixels.
+func (canvas PDFCanvas) GetHeight() int {
+	return canvas.raster.GetHeight()
+}
+
+// SetPPI sets the pixels per inch of the canvas, which determines the page size.
+func (canvas PDFCanvas) SetPPI(ppi float64) Canvas {
+	canvas.raster = canvas.raster.SetPPI(ppi).(ImageCanvas)
+	return canvas
+}
+
+// GetPPI returns the pixels per inch of the canvas.
+func (canvas PDFCanvas) GetPPI() float64 {
+	return canvas.raster.GetPPI()
+}
+
+// Rectangle draws a rectangle of a specific colour on the canvas as a vector path.
+func (canvas PDFCanvas) Rectangle(topLeft image.Point, width, height int, colour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Rectangle(topLeft, width, height, colour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	canvas.doc.fill(colour, image.Rect(topLeft.X, topLeft.Y, topLeft.X+width, topLeft.Y+height), fmt.Sprintf("%d %d %d %d re", topLeft.X, topLeft.Y, width, height))
+	return canvas, nil
+}
+
+// Circle draws a circle of a specific colour on the canvas as a vector path.
+func (canvas PDFCanvas) Circle(centre image.Point, radius int, colour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Circle(centre, radius, colour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	canvas.doc.fill(colour, NewEllipsePath(centre, radius, radius).bounds(), pdfEllipsePath(float64(centre.X), float64(centre.Y), float64(radius), float64(radius)))
+	return canvas, nil
+}
+
+// Ellipse draws an ellipse of a specific colour on the canvas as a vector path.
+func (canvas PDFCanvas) Ellipse(centre image.Point, radiusX, radiusY int, colour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Ellipse(centre, radiusX, radiusY, colour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	canvas.doc.fill(colour, NewEllipsePath(centre, radiusX, radiusY).bounds(), pdfEllipsePath(float64(centre.X), float64(centre.Y), float64(radiusX), float64(radiusY)))
+	return canvas, nil
+}
+
+// Arc draws a pie slice or chord segment of an ellipse of a specific colour on the canvas as a vector path.
+func (canvas PDFCanvas) Arc(centre image.Point, radiusX, radiusY int, startAngle, endAngle float64, style ArcStyle, colour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Arc(centre, radiusX, radiusY, startAngle, endAngle, style, colour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	path, _ := NewArcPath(centre, radiusX, radiusY, startAngle, endAngle, style)
+	canvas.doc.fill(colour, path.bounds(), path.pdfData())
+	return canvas, nil
+}
+
+// Line draws a straight line of a specific colour and style on the canvas as a vector path.
+func (canvas PDFCanvas) Line(start, end image.Point, style StrokeStyle, colour color.Color) (Canvas, error) {
+	return canvas.Polyline([]image.Point{start, end}, style, colour)
+}
+
+// Polyline draws a line of a specific colour and style through each of the points in turn on the canvas as a vector path.
+func (canvas PDFCanvas) Polyline(points []image.Point, style StrokeStyle, colour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Polyline(points, style, colour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	style, _ = style.normalise()
+	canvas.doc.stroke(colour, style, pdfPolylinePath(pixelCentres(points)))
+	return canvas, nil
+}
+
+// Path fills and outlines a path on the canvas as a vector path.
+func (canvas PDFCanvas) Path(path Path, fill, stroke color.Color, style StrokeStyle) (Canvas, error) {
+	raster, err := canvas.raster.Path(path, fill, stroke, style)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	style, _ = checkPath(path, style)
+	data := path.pdfData()
+	if isVisible(fill) {
+		canvas.doc.fill(fill, path.bounds(), data)
+	}
+	if isVisible(stroke) && style.Width != 0 {
+		canvas.doc.stroke(stroke, style, data)
+	}
+	return canvas, nil
+}
+
+/*
+Text draws text on the canvas, embedding the font, so the face must be a FontFace. Fonts parsed with
+ParseFont are embedded whole as TrueType fonts, hinting and all. The data of other fonts, such as
+system fonts found by name, is unknown, so the outlines of their glyphs are embedded instead, which
+viewers draw without hinting. PDF shadings have no transparency, so text in a gradient with
+translucent stops is drawn as an image.
+*/
+func (canvas PDFCanvas) Text(text string, start image.Point, typeFace font.Face, colour color.Color, maxWidth int) (Canvas, error) {
+	face, isFontFace := toFontFace(typeFace)
+	if !isFontFace {
+		return canvas, errors.New("text in a PDF must be drawn with a FontFace so that its font can be embedded")
+	}
+	raster, err := canvas.raster.Text(text, start, typeFace, colour, maxWidth)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	if gradient, isGradient := colour.(Gradient); isGradient && !gradient.opaque() {
+		canvas.doc.drawImage(rasteriseText(text, start, typeFace, colour))
+		return canvas, nil
+	}
+	canvas.doc.text(text, start, face, colour, textBounds(text, start, typeFace))
+	return canvas, nil
+}
+
+// TryText returns whether the text would fit on the canvas, and the width the text would currently use up.
+func (canvas PDFCanvas) TryText(text string, start image.Point, typeFace font.Face, colour color.Color, maxWidth int) (bool, int) {
+	return canvas.raster.TryText(text, start, typeFace, colour, maxWidth)
+}
+
+// DrawImage embeds another image in the canvas.
+func (canvas PDFCanvas) DrawImage(start image.Point, subImage image.Image) (Canvas, error) {
+	raster, err := canvas.raster.DrawImage(start, subImage)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	canvas.doc.drawImage(start, subImage)
+	return canvas, nil
+}
+
+// Barcode draws a barcode on the canvas, with each bar or module drawn as a vector rectangle.
+func (canvas PDFCanvas) Barcode(codeType BarcodeType, content []byte, extra BarcodeExtraData, start image.Point, width, height int, dataColour color.Color, backgroundColour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Barcode(codeType, content, extra, start, width, height, dataColour, backgroundColour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	encodedBarcode, _ := encodeBarcode(codeType, content, extra, width, height)
+	if dataColour == nil {
+		dataColour = color.Black
+	}
+	if backgroundColour == nil {
+		backgroundColour = color.White
+	}
+	// Barcodes are never drawn with gradients, matching the raster canvas.
+	dataColour, backgroundColour = toNRGBA(dataColour), toNRGBA(backgroundColour)
+	canvas.doc.fill(backgroundColour, image.Rectangle{}, fmt.Sprintf("%d %d %d %d re", start.X, start.Y, width, height))
+	var paths bytes.Buffer
+	for _, rect := range barcodeRects(encodedBarcode) {
+		fmt.Fprintf(&paths, "%d %d %d %d re ", start.X+rect.Min.X, start.Y+rect.Min.Y, rect.Dx(), rect.Dy())
+	}
+	if paths.Len() > 0 {
+		canvas.doc.fill(dataColour, image.Rectangle{}, strings.TrimSpace(paths.String()))
+	}
+	return canvas, nil
+}
+
+// Layer draws on a transparency group, embedded in the page as a form and composited with the style as a whole. Blend modes other than normal are written as the PDF blend modes of the same name, the form is transformed with the style's transform, clips are written as clipping paths, or soft masks for image clips, and blurs and shadows are drawn as images.
+func (canvas PDFCanvas) Layer(style LayerStyle, draw func(Canvas) (Canvas, error)) (Canvas, error) {
+	if style.plain() {
+		return draw(canvas)
+	}
+	doc := canvas.doc
+	start := doc.content.Len()
+	doc.formDepth++
+	var drawn Canvas
+	raster, err := canvas.raster.Layer(style, func(layer Canvas) (Canvas, error) {
+		var err error
+		drawn, err = draw(PDFCanvas{raster: layer.(ImageCanvas), doc: doc})
+		return drawn, err
+	})
+	doc.formDepth--
+	// Move everything drawn on the layer out of the page and into the form.
+	content := append([]byte{}, doc.content.Bytes()[start:]...)
+	doc.content.Truncate(start)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	if len(content) == 0 || (style.Clip != nil && style.Clip.Mask == nil && len(style.Clip.Path.segments) == 0) {
+		// Nothing was drawn, or it was all clipped away.
+		return canvas, nil
+	}
+	if style.Blur <= 0 && style.Shadow == nil {
+		doc.placeLayer(content, doc.layerState(style), style, drawn.GetUnderlyingImage())
+		return canvas, nil
+	}
+	// PDF has no blur, so effects are drawn as images, on a form with the layer so that they're composited together.
+	start = doc.content.Len()
+	if style.Blur > 0 {
+		styled := style.styled(drawn.GetUnderlyingImage())
+		doc.drawImage(styled.Bounds().Min, styled)
+	} else {
+		shadow := style.shadow(style.clipped(style.transformed(drawn.GetUnderlyingImage())))
+		doc.drawImage(shadow.Bounds().Min, shadow)
+		var state string
+		if style.Clip != nil && style.Clip.Mask != nil {
+			state = doc.layerState(LayerStyle{Opacity: 1, Clip: style.Clip})
+		}
+		doc.placeLayer(content, state, style, drawn.GetUnderlyingImage())
+	}
+	group := append([]byte{}, doc.content.Bytes()[start:]...)
+	doc.content.Truncate(start)
+	// The clip has already been applied, and mustn't also clip the shadow.
+	unclipped := style
+	unclipped.Clip = nil
+	fmt.Fprintf(&doc.content, "q /%s gs /%s Do Q\n", doc.layerState(unclipped), doc.form(group))
+	return canvas, nil
+}
+
+// placeLayer draws the content of a layer on a form, clipped and transformed with the style and composited with the named graphics state, if any. The drawn image is the raster of the layer, used to find the centre of its content.
+func (doc *pdfDocument) placeLayer(content []byte, state string, style LayerStyle, drawn image.Image) {
+	doc.content.WriteString("q ")
+	if state != "" {
+		fmt.Fprintf(&doc.content, "/%s gs ", state)
+	}
+	if style.Clip != nil && style.Clip.Mask == nil {
+		fmt.Fprintf(&doc.content, "%s W n ", style.Clip.Path.pdfData())
+	}
+	if style.Transform != nil {
+		m := style.Transform.matrix(contentBounds(drawn))
+		n := formatNumber
+		fmt.Fprintf(&doc.content, "%s %s %s %s %s %s cm ", n(m.a), n(m.b), n(m.c), n(m.d), n(m.e), n(m.f))
+	}
+	fmt.Fprintf(&doc.content, "/%s Do Q\n", doc.form(content))
+}
+
+// toFontFace retrieves the FontFace from a font.Face, if it is one.
+func toFontFace(typeFace font.Face) (FontFace, bool) {
+	switch face := typeFace.(type) {
+	case FontFace:
+		return face, true
+	case *FontFace:
+		if face != nil {
+			return *face, true
+		}
+	}
+	return FontFace{}, false
+}
+
+// rasteriseText draws text onto a transparent image just large enough to hold it, returning the position of that image relative to the canvas.
+func rasteriseText(text string, start image.Point, typeFace font.Face, colour color.Color) (image.Point, image.Image) {
+	rect := textBounds(text, start, typeFace)
+	glyphs := image.NewNRGBA(rect)
+	drawer := &font.Drawer{
+		Dot:  fixed.Point26_6{X: fixed.I(start.X), Y: fixed.I(start.Y)},
+		Dst:  glyphs,
+		Face: typeFace,
+		Src:  paint(colour, rect),
+	}
+	drawer.DrawString(text)
+	return rect.Min, glyphs
+}
+
+// pdfPolylinePath joins the points with straight lines.
+func pdfPolylinePath(points []pointF) string {
+	var path bytes.Buffer
+	for i, p := range points {
+		operator := "l"
+		if i == 0 {
+			operator = "m"
+		}
+		fmt.Fprintf(&path, "%s %s %s ", formatNumber(p.X), formatNumber(p.Y), operator)
+	}
+	return strings.TrimSpace(path.String())
+}
+
+// pdfEllipsePath approximates an ellipse with four cubic Bézier curves.
+func pdfEllipsePath(cx, cy, rx, ry float64) string {
+	const k = 0.5522847498
+	n := formatNumber
+	return fmt.Sprintf("%s %s m %s %s %s %s %s %s c %s %s %s %s %s %s c %s %s %s %s %s %s c %s %s %s %s %s %s c h",
+		n(cx+rx), n(cy),
+		n(cx+rx), n(cy+k*ry), n(cx+k*rx), n(cy+ry), n(cx), n(cy+ry),
+		n(cx-k*rx), n(cy+ry), n(cx-rx), n(cy+k*ry), n(cx-rx), n(cy),
+		n(cx-rx), n(cy-k*ry), n(cx-k*rx), n(cy-ry), n(cx), n(cy-ry),
+		n(cx+k*rx), n(cy-ry), n(cx+rx), n(cy-k*ry), n(cx+rx), n(cy))
+}
+
+// pdfDocument holds the page content and the resources it refers to.
+type pdfDocument struct {
+	content  bytes.Buffer
+	fonts    []*pdfFont
+	images   []pdfImage
+	alphas   map[uint8]string
+	patterns []pdfPattern
+	// layerStates are the graphics states compositing each layer, named LS1, LS2 and so on.
+	layerStates []pdfLayerState
+	forms       []pdfForm
+	// formDepth is the number of forms enclosing the content currently being drawn.
+	formDepth int
+}
+
+/*
+pdfFont is a font built from a TrueType font. If the TrueType program the font was parsed from is
+known, it is embedded whole, with the index of each glyph as its two byte character code. Otherwise
+the font is a Type 3 font drawing the outlines of up to 255 glyphs, with one byte character codes.
+*/
+type pdfFont struct {
+	name string
+	ttf  *truetype.Font
+	// program is the TrueType program embedded in the document, or nil for a Type 3 font.
+	program []byte
+	codes   map[truetype.Index]int
+	glyphs  []pdfGlyph
+}
+
+// pdfGlyph is a glyph in a font and the character it represents.
+type pdfGlyph struct {
+	index truetype.Index
+	char  rune
+}
+
+// pdfImage is a compressed image XObject with an optional soft mask for transparency.
+type pdfImage struct {
+	name          string
+	width, height int
+	rgb, alpha    []byte
+}
+
+// pdfPattern is a shading pattern painting a gradient, in canvas pixel coordinates.
+type pdfPattern struct {
+	name string
+	// shading is the shading dictionary.
+	shading string
+	// squash scales the Y axis about the centre of the gradient, turning the circles of a radial shading into ellipses.
+	squash, centreY float64
+	// inForm is whether the pattern is painted inside a form rather than directly on the page.
+	inForm bool
+}
+
+// pdfLayerState is a graphics state dictionary compositing a layer.
+type pdfLayerState struct {
+	// entries are the entries of the dictionary other than the soft mask.
+	entries string
+	// mask is the number of the form whose alpha masks the layer, counting from 1 as in the form's name, or 0 for no mask.
+	mask int
+}
+
+// pdfForm is a form XObject holding the content drawn on a layer, in canvas pixel coordinates.
+type pdfForm struct {
+	name    string
+	content []byte
+}
+
+func newPDFDocument() *pdfDocument {
+	return &pdfDocument{alphas: map[uint8]string{}}
+}
+
+// fill fills the path with the colour, in canvas pixel coordinates. Gradients are stretched across the bounds.
+func (doc *pdfDocument) fill(colour color.Color, bounds image.Rectangle, path string) {
+	doc.content.WriteString("q ")
+	if gradient, isGradient := colour.(Gradient); isGradient && !gradient.opaque() {
+		// PDF shadings have no transparency, so clip an image of the gradient to the path instead.
+		doc.content.WriteString(path)
+		doc.content.WriteString(" W n\n")
+		img := image.NewNRGBA(bounds)
+		draw.Draw(img, bounds, gradient.Image(bounds), bounds.Min, draw.Src)
+		doc.drawImage(bounds.Min, img)
+		doc.content.WriteString("Q\n")
+		return
+	}
+	doc.setFillPaint(colour, bounds)
+	doc.content.WriteString(path)
+	doc.content.WriteString(" f Q\n")
+}
+
+// stroke strokes the path with the colour and style, which must be normalised, in canvas pixel coordinates.
+func (doc *pdfDocument) stroke(colour color.Color, style StrokeStyle, path string) {
+	doc.content.WriteString("q ")
+	if style.Inside {
+		// Only the inside half of an outline twice as wide is left by clipping it to the path.
+		doc.content.WriteString(path)
+		doc.content.WriteString(" W n ")
+		style.Width *= 2
+	}
+	doc.setStrokeColour(colour)
+	caps := map[LineCap]int{CapButt: 0, CapRound: 1, CapSquare: 2}
+	joins := map[LineJoin]int{JoinMiter: 0, JoinRound: 1, JoinBevel: 2}
+	fmt.Fprintf(&doc.content, "%s w %d J %d j %d M ", formatNumber(style.Width), caps[style.Cap], joins[style.Join], miterLimit)
+	if len(style.Dash) > 0 {
+		dash := make([]string, len(style.Dash))
+		for i, length := range style.Dash {
+			dash[i] = formatNumber(length)
+		}
+		fmt.Fprintf(&doc.content, "[%s] 0 d ", strings.Join(dash, " "))
+	}
+	doc.content.WriteString(path)
+	doc.content.WriteString(" S Q\n")
+}
+
+// setFillPaint sets the fill colour, or a pattern painting the gradient across the bounds if the colour is an opaque Gradient.
+func (doc *pdfDocument) setFillPaint(colour color.Color, bounds image.Rectangle) {
+	gradient, isGradient := colour.(Gradient)
+	if !isGradient || !gradient.opaque() {
+		doc.setFillColour(colour)
+		return
+	}
+	geometry := gradient.geometry(bounds)
+	pattern := pdfPattern{name: fmt.Sprintf("P%d", len(doc.patterns)+1), squash: 1, inForm: doc.formDepth > 0}
+	n := formatNumber
+	function := pdfGradientFunction(gradient.stops())
+	if geometry.radial {
+		radius := geometry.radiusX
+		if radius != 0 {
+			pattern.squash, pattern.centreY = geometry.radiusY/radius, geometry.centre.Y
+		}
+		pattern.shading = fmt.Sprintf("<< /ShadingType 3 /ColorSpace /DeviceRGB /Coords [%s %s 0 %s %s %s] /Function %s /Extend [true true] >>",
+			n(geometry.centre.X), n(geometry.centre.Y), n(geometry.centre.X), n(geometry.centre.Y), n(radius), function)
+	} else {
+		pattern.shading = fmt.Sprintf("<< /ShadingType 2 /ColorSpace /DeviceRGB /Coords [%s %s %s %s] /Function %s /Extend [true true] >>",
+			n(geometry.start.X), n(geometry.start.Y), n(geometry.end.X), n(geometry.end.Y), function)
+	}
+	doc.patterns = append(doc.patterns, pattern)
+	fmt.Fprintf(&doc.content, "/Pattern cs /%s scn ", pattern.name)
+}
+
+// pdfGradientFunction returns a function mapping positions along a gradient to its colours, with stops as returned by Gradient.stops.
+func pdfGradientFunction(stops []GradientStop) string {
+	// Shadings only evaluate the function between 0 and 1, so the first and last colours are extended out to meet them.
+	if first := stops[0]; first.Offset > 0 {
+		stops = append([]GradientStop{{Offset: 0, Colour: first.Colour}}, stops...)
+	}
+	if last := stops[len(stops)-1]; last.Offset < 1 {
+		stops = append(stops, GradientStop{Offset: 1, Colour: last.Colour})
+	}
+	rgb := func(c color.NRGBA) string {
+		return fmt.Sprintf("%s %s %s", formatNumber(float64(c.R)/255), formatNumber(float64(c.G)/255), formatNumber(float64(c.B)/255))
+	}
+	var functions, bounds, encode []string
+	for i := 1; i < len(stops); i++ {
+		from, to := stops[i-1], stops[i]
+		if from.Offset == to.Offset {
+			// A hard edge between two stops needs no function of its own.
+			continue
+		}
+		if len(functions) > 0 {
+			bounds = append(bounds, formatNumber(from.Offset))
+		}
+		functions = append(functions, fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 [%s] /C1 [%s] /N 1 >>", rgb(from.Colour), rgb(to.Colour)))
+		encode = append(encode, "0 1")
+	}
+	if len(functions) == 1 {
+		return functions[0]
+	}
+	return fmt.Sprintf("<< /FunctionType 3 /Domain [0 1] /Functions [%s] /Bounds [%s] /Encode [%s] >>", strings.Join(functions, " "), strings.Join(bounds, " "), strings.Join(encode, " "))
+}
+
+func (doc *pdfDocument) setFillColour(colour color.Color) {
+	c := toNRGBA(colour)
+	if c.A != 255 {
+		fmt.Fprintf(&doc.content, "/%s gs ", doc.alphaState(c.A))
+	}
+	fmt.Fprintf(&doc.content, "%s %s %s rg ", formatNumber(float64(c.R)/255), formatNumber(float64(c.G)/255), formatNumber(float64(c.B)/255))
+}
+
+func (doc *pdfDocument) setStrokeColour(colour color.Color) {
+	c := toNRGBA(colour)
+	if c.A != 255 {
+		fmt.Fprintf(&doc.content, "/%s gs ", doc.alphaState(c.A))
+	}
+	fmt.Fprintf(&doc.content, "%s %s %s RG ", formatNumber(float64(c.R)/255), formatNumber(float64(c.G)/255), formatNumber(float64(c.B)/255))
+}
+
+// alphaState returns the name of a graphics state resource applying the alpha value.
+func (doc *pdfDocument) alphaState(alpha uint8) string {
+	name, exists := doc.alphas[alpha]
+	if !exists {
+		name = fmt.Sprintf("GS%d", alpha)
+		doc.alphas[alpha] = name
+	}
+	return name
+}
+
+// layerState returns the name of a graphics state resource compositing a layer with the style. Image clips are drawn on a form of their own, used as a soft mask.
+func (doc *pdfDocument) layerState(style LayerStyle) string {
+	opacity := formatNumber(math.Min(math.Max(style.Opacity, 0), 1))
+	state := pdfLayerState{entries: fmt.Sprintf("/ca %s /CA %s", opacity, opacity)}
+	if _, isBlended := blendFunctions[style.BlendMode]; isBlended {
+		mode := string(style.BlendMode)
+		state.entries += fmt.Sprintf(" /BM /%s%s", strings.ToUpper(mode[:1]), mode[1:])
+	}
+	if style.Clip != nil && style.Clip.Mask != nil {
+		start := doc.content.Len()
+		doc.drawImage(style.Clip.Mask.Bounds().Min, style.Clip.Mask)
+		doc.form(append([]byte{}, doc.content.Bytes()[start:]...))
+		doc.content.Truncate(start)
+		state.mask = len(doc.forms)
+	}
+	doc.layerStates = append(doc.layerStates, state)
+	return fmt.Sprintf("LS%d", len(doc.layerStates))
+}
+
+// form adds a form XObject holding the content, returning its name.
+func (doc *pdfDocument) form(content []byte) string {
+	name := fmt.Sprintf("Fm%d", len(doc.forms)+1)
+	doc.forms = append(doc.forms, pdfForm{name: name, content: content})
+	return name
+}
+
+// text draws text with its baseline starting at start, using the face's TrueType font. Gradients are stretched across the bounds.
+func (doc *pdfDocument) text(text string, start image.Point, face FontFace, colour color.Color, bounds image.Rectangle) {
+	size := formatNumber(face.PixelSize())
+	doc.content.WriteString("q ")
+	doc.setFillPaint(colour, bounds)
+	fmt.Fprintf(&doc.content, "BT %s 0 0 -%s %d %d Tm ", size, size, start.X, start.Y)
+	var current *pdfFont
+	var previous truetype.Index
+	for i, char := range text {
+		index := face.Font.Index(char)
+		pdfFont, code := doc.glyphCode(face.Font, index, char)
+		if pdfFont != current {
+			if current != nil {
+				doc.content.WriteString("] TJ ")
+			}
+			fmt.Fprintf(&doc.content, "/%s 1 Tf [", pdfFont.name)
+			current = pdfFont
+		} else if i > 0 {
+			kern := face.Font.Kern(fixed.I(1000), previous, index)
+			if kern != 0 {
+				fmt.Fprintf(&doc.content, " %s ", formatNumber(-float64(kern)/64))
+			}
+		}
+		doc.content.WriteString(pdfFont.hex(code))
+		previous = index
+	}
+	if current != nil {
+		doc.content.WriteString("] TJ ")
+	}
+	doc.content.WriteString("ET Q\n")
+}
+
+// glyphCode finds or allocates the character code for a glyph in one of the fonts built from the TrueType font.
+func (doc *pdfDocument) glyphCode(ttf *truetype.Font, index truetype.Index, char rune) (*pdfFont, int) {
+	var latest *pdfFont
+	for _, existing := range doc.fonts {
+		if existing.ttf != ttf {
+			continue
+		}
+		if code, found := existing.codes[index]; found {
+			return existing, code
+		}
+		latest = existing
+	}
+	if latest == nil || latest.program == nil && len(latest.glyphs) >= 255 {
+		latest = &pdfFont{
+			name:    fmt.Sprintf("F%d", len(doc.fonts)+1),
+			ttf:     ttf,
+			program: fontProgram(ttf),
+			codes:   map[truetype.Index]int{},
+		}
+		doc.fonts = append(doc.fonts, latest)
+	}
+	latest.glyphs = append(latest.glyphs, pdfGlyph{index: index, char: char})
+	code := len(latest.glyphs)
+	if latest.program != nil {
+		code = int(index)
+	}
+	latest.codes[index] = code
+	return latest, code
+}
+
+// hex formats a character code in the font as a hexadecimal string.
+func (pdfFont *pdfFont) hex(code int) string {
+	if pdfFont.program != nil {
+		return fmt.Sprintf("<%04x>", code)
+	}
+	return fmt.Sprintf("<%02x>", code)
+}
+
+// drawImage embeds a snapshot of the image with its top-left corner at start. Fully transparent images are skipped.
+func (doc *pdfDocument) drawImage(start image.Point, img image.Image) {
+	bounds := img.Bounds()
+	if isTransparent(img) {
+		return
+	}
+	name := fmt.Sprintf("Im%d", len(doc.images)+1)
+	rgb := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	alpha := make([]byte, 0, bounds.Dx()*bounds.Dy())
+	opaque := true
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := toNRGBA(img.At(x, y))
+			rgb = append(rgb, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+			opaque = opaque && c.A == 255
+		}
+	}
+	embedded := pdfImage{name: name, width: bounds.Dx(), height: bounds.Dy(), rgb: compress(rgb)}
+	if !opaque {
+		embedded.alpha = compress(alpha)
+	}
+	doc.images = append(doc.images, embedded)
+	fmt.Fprintf(&doc.content, "q %d 0 0 -%d %d %d cm /%s Do Q\n", bounds.Dx(), bounds.Dy(), start.X, start.Y+bounds.Dy(), name)
+}
+
+func compress(data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+// glyphPath converts a TrueType glyph outline in thousandths of an em to a PDF path, converting quadratic curves to cubic.
+func glyphPath(glyph *truetype.GlyphBuf) string {
+	var path bytes.Buffer
+	var cx, cy float64
+	glyphContours(glyph,
+		func(p pointF) {
+			fmt.Fprintf(&path, "%s %s m\n", formatNumber(p.X), formatNumber(p.Y))
+			cx, cy = p.X, p.Y
+		},
+		func(p pointF) {
+			fmt.Fprintf(&path, "%s %s l\n", formatNumber(p.X), formatNumber(p.Y))
+			cx, cy = p.X, p.Y
+		},
+		func(control, end pointF) {
+			qx, qy, ex, ey := control.X, control.Y, end.X, end.Y
+			fmt.Fprintf(&path, "%s %s %s %s %s %s c\n",
+				formatNumber(cx+2*(qx-cx)/3), formatNumber(cy+2*(qy-cy)/3),
+				formatNumber(ex+2*(qx-ex)/3), formatNumber(ey+2*(qy-ey)/3),
+				formatNumber(ex), formatNumber(ey))
+			cx, cy = ex, ey
+		},
+		func() {
+			path.WriteString("h\n")
+		})
+	return path.String()
+}
+
+// toUnicodeCMap maps the character codes of a font back to the text they represent, so that text in the document can be searched and copied.
+func toUnicodeCMap(pdfFont *pdfFont) []byte {
+	var cmap bytes.Buffer
+	codespace := "<00> <FF>"
+	if pdfFont.program != nil {
+		codespace = "<0000> <FFFF>"
+	}
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n1 begincodespacerange\n")
+	cmap.WriteString(codespace)
+	cmap.WriteString("\nendcodespacerange\n")
+	glyphs := pdfFont.glyphs
+	for blockStart := 0; blockStart < len(glyphs); blockStart += 100 {
+		block := glyphs[blockStart:]
+		if len(block) > 100 {
+			block = block[:100]
+		}
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", len(block))
+		for _, glyph := range block {
+			fmt.Fprintf(&cmap, "%s <", pdfFont.hex(pdfFont.codes[glyph.index]))
+			for _, unit := range utf16.Encode([]rune{glyph.char}) {
+				fmt.Fprintf(&cmap, "%04x", unit)
+			}
+			cmap.WriteString(">\n")
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return cmap.Bytes()
+}
@@ -0,0 +1,498 @@
+package render
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+type failingWriter struct{}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, fmt.Errorf("disk full")
+}
+
+var pdfStreamPattern = regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`)
+
+// inflatePDFStreams decompresses every stream in a PDF document and joins them for inspection.
+func inflatePDFStreams(t *testing.T, data []byte) string {
+	var result bytes.Buffer
+	for _, match := range pdfStreamPattern.FindAllSubmatch(data, -1) {
+		r, err := zlib.NewReader(bytes.NewReader(match[1]))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		inflated, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		result.Write(inflated)
+		result.WriteString("\n")
+	}
+	return result.String()
+}
+
+// checkPDFStructure confirms every cross-reference entry points at the start of its object.
+func checkPDFStructure(t *testing.T, data []byte) {
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if !assert.NotNil(t, startxref) {
+		return
+	}
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	assert.True(t, bytes.HasPrefix(data[xref:], []byte("xref\n")))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	assert.NotEmpty(t, entries)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(data[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+	}
+}
+
+func TestNewPDFCanvas(t *testing.T) {
+	t.Run("invalid size", func(t *testing.T) {
+		canvas, err := NewPDFCanvas(0, 10)
+		assert.Equal(t, PDFCanvas{}, canvas)
+		assert.EqualError(t, err, "invalid width")
+	})
+	t.Run("valid size", func(t *testing.T) {
+		canvas, err := NewPDFCanvas(20, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 20, canvas.GetWidth())
+		assert.Equal(t, 10, canvas.GetHeight())
+		assert.Equal(t, image.Rect(0, 0, 20, 10), canvas.GetUnderlyingImage().Bounds())
+		var data bytes.Buffer
+		err = canvas.WritePDF(&data)
+		assert.NoError(t, err)
+		checkPDFStructure(t, data.Bytes())
+		assert.Contains(t, data.String(), "/MediaBox [0 0 20 10]")
+	})
+	t.Run("from canvas", func(t *testing.T) {
+		base, _ := NewCanvas(8, 4)
+		drawnBase, _ := base.Rectangle(image.ZP, 8, 4, color.White)
+		canvas, err := NewPDFCanvasFrom(drawnBase.SetPPI(144))
+		assert.NoError(t, err)
+		assert.Equal(t, float64(144), canvas.GetPPI())
+		assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, canvas.GetUnderlyingImage().At(3, 3))
+		var data bytes.Buffer
+		err = canvas.WritePDF(&data)
+		assert.NoError(t, err)
+		checkPDFStructure(t, data.Bytes())
+		assert.Contains(t, data.String(), "/MediaBox [0 0 4 2]")
+		assert.Contains(t, data.String(), "/Width 8 /Height 4 /ColorSpace /DeviceRGB")
+		assert.NotContains(t, data.String(), "/SMask")
+		assert.Contains(t, inflatePDFStreams(t, data.Bytes()), "q 0.5 0 0 -0.5 0 2 cm\nq 8 0 0 -4 0 4 cm /Im1 Do Q\n")
+	})
+	t.Run("from invalid canvas", func(t *testing.T) {
+		canvas, err := NewPDFCanvasFrom(ImageCanvas{})
+		assert.Equal(t, PDFCanvas{}, canvas)
+		assert.EqualError(t, err, "invalid width and height")
+	})
+}
+
+func TestPDFCanvasDrawing(t *testing.T) {
+	ttFont, _ := ParseFont(goregular.TTF)
+	write := func(t *testing.T, canvas Canvas) (string, string) {
+		var data bytes.Buffer
+		err := canvas.(PDFCanvas).WritePDF(&data)
+		assert.NoError(t, err)
+		checkPDFStructure(t, data.Bytes())
+		return data.String(), inflatePDFStreams(t, data.Bytes())
+	}
+	t.Run("rectangle", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		modifiedCanvas, err := canvas.Rectangle(image.Pt(1, 2), 3, 4, color.NRGBA{R: 255, A: 255})
+		assert.NoError(t, err)
+		assert.Equal(t, color.NRGBA{R: 255, A: 255}, modifiedCanvas.GetUnderlyingImage().At(2, 3))
+		_, content := write(t, modifiedCanvas)
+		assert.Contains(t, content, "q 1 0 0 rg 1 2 3 4 re f Q\n")
+	})
+	t.Run("invalid rectangle", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		modifiedCanvas, err := canvas.Rectangle(image.ZP, 0, 4, color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid width")
+		_, content := write(t, modifiedCanvas)
+		assert.NotContains(t, content, "re f")
+	})
+	t.Run("transparent circle", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		modifiedCanvas, err := canvas.Circle(image.Pt(5, 5), 4, color.NRGBA{B: 255, A: 128})
+		assert.NoError(t, err)
+		document, content := write(t, modifiedCanvas)
+		assert.Contains(t, document, "/ExtGState << /GS128 << /ca 0.502 /CA 0.502 >> >>")
+		assert.Contains(t, content, "q /GS128 gs 0 0 1 rg 9 5 m 9 7.2091 7.2091 9 5 9 c")
+		assert.Contains(t, content, "c h f Q\n")
+	})
+	t.Run("invalid circle", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		modifiedCanvas, err := canvas.Circle(image.Pt(5, 5), 0, color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid radius")
+	})
+	t.Run("ellipse", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(20, 10)
+		modifiedCanvas, err := canvas.Ellipse(image.Pt(10, 5), 8, 4, color.Black)
+		assert.NoError(t, err)
+		_, content := write(t, modifiedCanvas)
+		assert.Contains(t, content, "q 0 0 0 rg 18 5 m 18 7.2091 14.4183 9 10 9 c")
+		modifiedCanvas, err = canvas.Ellipse(image.Pt(10, 5), 8, 0, color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid y radius")
+	})
+	t.Run("arc", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(20, 20)
+		modifiedCanvas, err := canvas.Arc(image.Pt(10, 10), 8, 8, 0, 90, ArcPie, color.Black)
+		assert.NoError(t, err)
+		_, content := write(t, modifiedCanvas)
+		assert.Contains(t, content, "q 0 0 0 rg 10 10 m 18 10 l 18 14.4183 14.4183 18 10 18 c h f Q\n")
+		modifiedCanvas, err = canvas.Arc(image.Pt(10, 10), 8, 8, 0, 90, "slice", color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "unsupported arc style: slice")
+	})
+	t.Run("line", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		modifiedCanvas, err := canvas.Polyline([]image.Point{{X: 1, Y: 1}, {X: 8, Y: 1}, {X: 8, Y: 8}}, StrokeStyle{Width: 2, Cap: CapRound, Join: JoinBevel, Dash: []float64{3}}, color.NRGBA{G: 255, A: 51})
+		assert.NoError(t, err)
+		document, content := write(t, modifiedCanvas)
+		assert.Contains(t, document, "/GS51 << /ca 0.2 /CA 0.2 >>")
+		assert.Contains(t, content, "q /GS51 gs 0 1 0 RG 2 w 1 J 2 j 4 M [3 3] 0 d 1.5 1.5 m 8.5 1.5 l 8.5 8.5 l S Q\n")
+	})
+	t.Run("invalid line", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		modifiedCanvas, err := canvas.Line(image.ZP, image.Pt(5, 5), StrokeStyle{}, color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid stroke width 0")
+		_, content := write(t, modifiedCanvas)
+		assert.NotContains(t, content, " S Q")
+	})
+	t.Run("path", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		path, _ := ParsePath("M 1 1 Q 5 7 9 1 Z")
+		modifiedCanvas, err := canvas.Path(path, color.NRGBA{R: 255, A: 255}, color.Black, StrokeStyle{Width: 1.5})
+		assert.NoError(t, err)
+		_, content := write(t, modifiedCanvas)
+		assert.Contains(t, content, "q 1 0 0 rg 1 1 m 3.6667 5 6.3333 5 9 1 c h f Q\nq 0 0 0 RG 1.5 w 0 J 0 j 4 M 1 1 m 3.6667 5 6.3333 5 9 1 c h S Q\n")
+		canvas, _ = NewPDFCanvas(10, 10)
+		modifiedCanvas, err = canvas.Path(path, nil, color.Black, StrokeStyle{})
+		assert.NoError(t, err)
+		_, content = write(t, modifiedCanvas)
+		assert.NotContains(t, content, " c h ", "a path without a stroke width or fill colour draws nothing")
+	})
+	t.Run("inside stroke", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		modifiedCanvas, err := canvas.Path(NewRectanglePath(image.Pt(1, 1), 8, 8, CornerRadii{}), nil, color.Black, StrokeStyle{Width: 2, Inside: true})
+		assert.NoError(t, err)
+		_, content := write(t, modifiedCanvas)
+		assert.Contains(t, content, "q 1 1 m 9 1 l 9 9 l 1 9 l 1 1 l h W n 0 0 0 RG 4 w 0 J 0 j 4 M 1 1 m 9 1 l 9 9 l 1 9 l 1 1 l h S Q\n")
+	})
+	t.Run("gradients", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(20, 10)
+		linear := Gradient{Stops: []GradientStop{{Offset: 0, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 1, Colour: color.NRGBA{B: 255, A: 255}}}}
+		radial := Gradient{Type: GradientRadial, Stops: []GradientStop{{Offset: 0.25, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 0.5, Colour: color.NRGBA{G: 255, A: 255}}, {Offset: 0.5, Colour: color.NRGBA{B: 255, A: 255}}}}
+		modifiedCanvas, err := canvas.Rectangle(image.ZP, 20, 10, linear)
+		assert.NoError(t, err)
+		modifiedCanvas, err = modifiedCanvas.Ellipse(image.Pt(10, 5), 8, 4, radial)
+		assert.NoError(t, err)
+		document, content := write(t, modifiedCanvas)
+		assert.Contains(t, document, "/Pattern << /P1 5 0 R /P2 6 0 R >>")
+		assert.Contains(t, document, "<< /Type /Pattern /PatternType 2 /Shading << /ShadingType 2 /ColorSpace /DeviceRGB /Coords [0 5 20 5] /Function << /FunctionType 2 /Domain [0 1] /C0 [1 0 0] /C1 [0 0 1] /N 1 >> /Extend [true true] >> /Matrix [1 0 0 -1 0 10] >>")
+		assert.Contains(t, document, "/ShadingType 3 /ColorSpace /DeviceRGB /Coords [10 5 0 10 5 11.3137] /Function << /FunctionType 3 /Domain [0 1] /Functions [<< /FunctionType 2 /Domain [0 1] /C0 [1 0 0] /C1 [1 0 0] /N 1 >> << /FunctionType 2 /Domain [0 1] /C0 [1 0 0] /C1 [0 1 0] /N 1 >> << /FunctionType 2 /Domain [0 1] /C0 [0 0 1] /C1 [0 0 1] /N 1 >>] /Bounds [0.25 0.5] /Encode [0 1 0 1 0 1] >>")
+		assert.Contains(t, document, "/Matrix [1 0 0 -0.5 0 7.5]", "the radial shading should be squashed into an ellipse")
+		assert.Contains(t, content, "q /Pattern cs /P1 scn 0 0 20 10 re f Q\nq /Pattern cs /P2 scn 18 5 m")
+		translucent := Gradient{Stops: []GradientStop{{Offset: 0, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 1, Colour: color.NRGBA{R: 255}}}}
+		canvas, _ = NewPDFCanvas(20, 10)
+		modifiedCanvas, err = canvas.Path(NewRectanglePath(image.Pt(2, 2), 8, 6, CornerRadii{}), translucent, nil, StrokeStyle{})
+		assert.NoError(t, err)
+		document, content = write(t, modifiedCanvas)
+		assert.NotContains(t, document, "/Pattern")
+		assert.Contains(t, document, "/Width 8 /Height 6 /ColorSpace /DeviceGray")
+		assert.Contains(t, content, "q 2 2 m 10 2 l 10 8 l 2 8 l 2 2 l h W n\nq 8 0 0 -6 2 8 cm /Im1 Do Q\nQ\n")
+	})
+	t.Run("invalid path", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		modifiedCanvas, err := canvas.Path(Path{}, color.Black, nil, StrokeStyle{})
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "path has no segments")
+	})
+	t.Run("embedded text", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(60, 30)
+		face := NewFontFace(ttFont, &truetype.Options{Size: 10, DPI: 144})
+		modifiedCanvas, err := canvas.Text("tet", image.Pt(2, 20), face, color.Black, 60)
+		assert.NoError(t, err)
+		document, content := write(t, modifiedCanvas)
+		assert.Contains(t, document, "/Subtype /Type0 /BaseFont /GoRegular /Encoding /Identity-H")
+		assert.Contains(t, document, "/Subtype /CIDFontType2 /BaseFont /GoRegular")
+		assert.Regexp(t, `/W \[ 72 \[[\d.]+\] 87 \[[\d.]+\] \] /CIDToGIDMap /Identity`, document)
+		assert.Contains(t, document, "/FontFile2")
+		assert.Contains(t, document, fmt.Sprintf("/Length1 %d", len(goregular.TTF)))
+		assert.NotContains(t, document, "/Type3")
+		assert.Contains(t, content, "q 0 0 0 rg BT 20 0 0 -20 2 20 Tm /F1 1 Tf [<0057><0048><0057>] TJ ET Q\n")
+		assert.Contains(t, content, "2 beginbfchar\n<0057> <0074>\n<0048> <0065>\nendbfchar\n")
+		assert.True(t, strings.Contains(content, string(goregular.TTF)), "the font program should be embedded whole")
+		assert.NotContains(t, document, "/XObject")
+	})
+	t.Run("outlined text", func(t *testing.T) {
+		outlined, _ := truetype.Parse(goregular.TTF)
+		canvas, _ := NewPDFCanvas(60, 30)
+		face := NewFontFace(outlined, &truetype.Options{Size: 10, DPI: 144})
+		modifiedCanvas, err := canvas.Text("tet", image.Pt(2, 20), face, color.Black, 60)
+		assert.NoError(t, err)
+		document, content := write(t, modifiedCanvas)
+		assert.Contains(t, document, "/Subtype /Type3")
+		assert.NotContains(t, document, "/FontFile2")
+		assert.Contains(t, document, "/Encoding << /Type /Encoding /Differences [1 /g1 /g2] >> /FirstChar 1 /LastChar 2")
+		assert.Contains(t, content, "q 0 0 0 rg BT 20 0 0 -20 2 20 Tm /F1 1 Tf [<01><02><01>] TJ ET Q\n")
+		assert.Contains(t, content, "2 beginbfchar\n<01> <0074>\n<02> <0065>\nendbfchar\n")
+		assert.Contains(t, content, " d1\n")
+	})
+	t.Run("gradient text", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(60, 30)
+		face := NewFontFace(ttFont, &truetype.Options{Size: 10})
+		gradient := Gradient{Angle: 90, Stops: []GradientStop{{Offset: 0, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 1, Colour: color.NRGBA{B: 255, A: 255}}}}
+		modifiedCanvas, err := canvas.Text("tet", image.Pt(2, 20), face, gradient, 60)
+		assert.NoError(t, err)
+		document, content := write(t, modifiedCanvas)
+		assert.Contains(t, document, "/ShadingType 2")
+		assert.Contains(t, content, "q /Pattern cs /P1 scn BT 10 0 0 -10 2 20 Tm /F1 1 Tf")
+		gradient.Stops[1].Colour.A = 0
+		canvas, _ = NewPDFCanvas(60, 30)
+		modifiedCanvas, err = canvas.Text("tet", image.Pt(2, 20), face, gradient, 60)
+		assert.NoError(t, err)
+		document, content = write(t, modifiedCanvas)
+		assert.NotContains(t, document, "/FontFile2", "translucent gradients should fall back to an image")
+		assert.Contains(t, content, "/Im1 Do Q\n")
+	})
+	t.Run("face pointer", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(60, 30)
+		face := NewFontFace(ttFont, &truetype.Options{Size: 10})
+		modifiedCanvas, err := canvas.Text("AV", image.Pt(2, 20), &face, color.Black, 60)
+		assert.NoError(t, err)
+		_, content := write(t, modifiedCanvas)
+		assert.Contains(t, content, "BT 10 0 0 -10 2 20 Tm /F1 1 Tf [<0024><0039>] TJ ET")
+	})
+	t.Run("face without a font", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(60, 30)
+		face := truetype.NewFace(ttFont, &truetype.Options{Size: 10, Hinting: font.HintingFull})
+		modifiedCanvas, err := canvas.Text("tet", image.Pt(2, 20), face, color.Black, 60)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "text in a PDF must be drawn with a FontFace so that its font can be embedded")
+	})
+	t.Run("invalid text", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(60, 30)
+		face := NewFontFace(ttFont, &truetype.Options{Size: 10})
+		modifiedCanvas, err := canvas.Text("tet", image.Pt(2, 20), face, color.Black, 1)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "resultant drawn text was longer than maxWidth")
+		fits, width := canvas.TryText("tet", image.Pt(2, 20), face, color.Black, 1)
+		assert.False(t, fits)
+		assert.Equal(t, 12, width)
+	})
+	t.Run("barcode", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(80, 30)
+		modifiedCanvas, err := canvas.Barcode(BarcodeTypeCode128, []byte("12"), BarcodeExtraData{}, image.Pt(1, 1), 78, 10, nil, nil)
+		assert.NoError(t, err)
+		_, content := write(t, modifiedCanvas)
+		assert.Contains(t, content, "q 1 1 1 rg 1 1 78 10 re f Q\nq 0 0 0 rg ")
+		assert.Regexp(t, `(\d+ 1 \d+ 10 re ){5,}f Q\n`, content)
+	})
+	t.Run("invalid barcode", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(30, 30)
+		modifiedCanvas, err := canvas.Barcode(BarcodeTypeCode128, []byte("12"), BarcodeExtraData{}, image.Pt(1, 1), 28, 10, nil, nil)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "can not scale barcode to an image smaller than 57x1")
+	})
+	t.Run("image", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		img := image.NewNRGBA(image.Rect(0, 0, 2, 3))
+		img.Set(1, 1, color.NRGBA{G: 255, A: 100})
+		modifiedCanvas, err := canvas.DrawImage(image.Pt(4, 5), img)
+		assert.NoError(t, err)
+		document, content := write(t, modifiedCanvas)
+		assert.Contains(t, document, "/Width 2 /Height 3 /ColorSpace /DeviceGray")
+		assert.Contains(t, content, "q 2 0 0 -3 4 8 cm /Im1 Do Q\n")
+	})
+	t.Run("set underlying image", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		drawnCanvas, _ := canvas.Rectangle(image.ZP, 3, 3, color.Black)
+		newImage := image.NewNRGBA(image.Rect(0, 0, 5, 5))
+		modifiedCanvas := drawnCanvas.SetUnderlyingImage(newImage)
+		assert.Equal(t, 5, modifiedCanvas.GetWidth())
+		_, content := write(t, modifiedCanvas)
+		assert.NotContains(t, content, "re f")
+		assert.NotContains(t, content, "/Im1 Do", "transparent images should not be embedded")
+		newImage.Set(1, 1, color.Black)
+		_, content = write(t, drawnCanvas.SetUnderlyingImage(newImage))
+		assert.Contains(t, content, "/Im1 Do")
+	})
+	t.Run("layer", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		gradient := Gradient{Type: GradientRadial, Stops: []GradientStop{{Offset: 0, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 1, Colour: color.NRGBA{B: 255, A: 255}}}}
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 0.5, BlendMode: BlendMultiply}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 2), 3, 4, gradient)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(128), modifiedCanvas.GetUnderlyingImage().(*image.NRGBA).NRGBAAt(2, 3).A)
+		document, content := write(t, modifiedCanvas)
+		assert.Contains(t, document, "/ExtGState << /LS1 << /ca 0.5 /CA 0.5 /BM /Multiply >> >>")
+		assert.Contains(t, document, "/XObject << /Fm1 ")
+		assert.Contains(t, document, "/Subtype /Form /BBox [0 0 10 10] /Group << /S /Transparency >> /Resources << /ProcSet")
+		assert.Contains(t, document, "/Matrix [1 0 0 1.3333 0 -1.3333]", "patterns in a form should already be in canvas pixel coordinates")
+		assert.Contains(t, content, "q /Pattern cs /P1 scn 1 2 3 4 re f Q\n")
+		assert.Contains(t, content, "q 1 0 0 -1 0 10 cm\nq /LS1 gs /Fm1 Do Q\nQ\n")
+	})
+	t.Run("transformed layer", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Transform: &Transform{Rotation: 90, ScaleX: 1, ScaleY: 1}}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 2), 3, 4, color.Black)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, color.NRGBA{A: 255}, modifiedCanvas.GetUnderlyingImage().At(2, 3))
+		assert.Equal(t, color.NRGBA{}, modifiedCanvas.GetUnderlyingImage().At(2, 1))
+		document, content := write(t, modifiedCanvas)
+		assert.Contains(t, document, "/ExtGState << /LS1 << /ca 1 /CA 1 >> >>")
+		assert.Contains(t, content, "q /LS1 gs 0 1 -1 0 6.5 1.5 cm /Fm1 Do Q\n")
+	})
+	t.Run("clipped layer", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Clip: &Clip{Path: NewPolygonPath([]image.Point{{X: 1, Y: 1}, {X: 3, Y: 1}, {X: 3, Y: 3}})}}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 1), 4, 4, color.Black)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, color.NRGBA{}, modifiedCanvas.GetUnderlyingImage().At(4, 4))
+		_, content := write(t, modifiedCanvas)
+		assert.Contains(t, content, "q /LS1 gs 1 1 m 3 1 l 3 3 l h W n /Fm1 Do Q\n")
+		canvas, _ = NewPDFCanvas(10, 10)
+		modifiedCanvas, err = canvas.Layer(LayerStyle{Opacity: 1, Clip: &Clip{}}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 1), 4, 4, color.Black)
+		})
+		assert.NoError(t, err)
+		document, content := write(t, modifiedCanvas)
+		assert.NotContains(t, document, "/Form", "layers clipped to nothing should be left out")
+		assert.NotContains(t, content, "re f")
+	})
+	t.Run("masked layer", func(t *testing.T) {
+		mask := image.NewAlpha(image.Rect(1, 1, 3, 2))
+		mask.SetAlpha(1, 1, color.Alpha{A: 255})
+		canvas, _ := NewPDFCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 0.5, Clip: &Clip{Mask: mask}}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 1), 4, 4, color.Black)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, color.NRGBA{A: 128}, modifiedCanvas.GetUnderlyingImage().At(1, 1))
+		assert.Equal(t, color.NRGBA{}, modifiedCanvas.GetUnderlyingImage().At(2, 1))
+		document, content := write(t, modifiedCanvas)
+		assert.Regexp(t, `/LS1 << /ca 0.5 /CA 0.5 /SMask << /Type /Mask /S /Alpha /G \d+ 0 R >> >>`, document)
+		assert.Contains(t, document, "/XObject << /Im1 ")
+		assert.Contains(t, content, "q /LS1 gs /Fm2 Do Q\n", "the mask should be drawn on a form of its own")
+	})
+	t.Run("shadowed layer", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		style := LayerStyle{Opacity: 0.5, Transform: &Transform{Rotation: 90, ScaleX: 1, ScaleY: 1}, Clip: &Clip{Path: NewRectanglePath(image.ZP, 10, 10, CornerRadii{})}, Shadow: &Shadow{Offset: image.Pt(1, 1), Colour: color.NRGBA{A: 255}}}
+		modifiedCanvas, err := canvas.Layer(style, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 2), 3, 4, color.Black)
+		})
+		assert.NoError(t, err)
+		document, content := write(t, modifiedCanvas)
+		assert.Contains(t, document, "/ExtGState << /LS1 << /ca 0.5 /CA 0.5 >> >>")
+		assert.Contains(t, document, "/XObject << /Im1 ")
+		assert.Contains(t, content, "q 10 0 0 -10 0 10 cm /Im1 Do Q\nq 0 0 m 10 0 l 10 10 l 0 10 l 0 0 l h W n 0 1 -1 0 6.5 1.5 cm /Fm1 Do Q\n", "the shadow should be drawn beneath the clipped and transformed layer")
+		assert.Contains(t, content, "q /LS1 gs /Fm2 Do Q\n", "the shadow and the layer should be composited together")
+	})
+	t.Run("blurred layer", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Blur: 1}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 2), 3, 4, color.Black)
+		})
+		assert.NoError(t, err)
+		document, content := write(t, modifiedCanvas)
+		assert.Contains(t, document, "/XObject << /Im1 ")
+		assert.Contains(t, document, "q 10 0 0 -10 0 10 cm /Im1 Do Q\n", "the blurred layer should be drawn as an image")
+		assert.Contains(t, content, "q /LS1 gs /Fm1 Do Q\n")
+	})
+	t.Run("plain layer", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 2), 3, 4, color.Black)
+		})
+		assert.NoError(t, err)
+		document, content := write(t, modifiedCanvas)
+		assert.NotContains(t, document, "/Form")
+		assert.Contains(t, content, "q 1 0 0 -1 0 10 cm\nq 0 0 0 rg 1 2 3 4 re f Q\nQ\n")
+	})
+	t.Run("invalid layer", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 0.5}, func(layer Canvas) (Canvas, error) {
+			drawn, _ := layer.Rectangle(image.Pt(1, 2), 3, 4, color.Black)
+			return drawn.Circle(image.Pt(5, 5), 0, color.Black)
+		})
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid radius")
+		document, content := write(t, modifiedCanvas)
+		assert.NotContains(t, document, "/Form")
+		assert.NotContains(t, content, "re f")
+	})
+	t.Run("writer error", func(t *testing.T) {
+		canvas, _ := NewPDFCanvas(10, 10)
+		err := canvas.WritePDF(failingWriter{})
+		assert.EqualError(t, err, "disk full")
+	})
+}
+
+func TestGlyphPath(t *testing.T) {
+	ttFont, _ := truetype.Parse(goregular.TTF)
+	glyph := &truetype.GlyphBuf{}
+	err := glyph.Load(ttFont, 1000<<6, ttFont.Index('o'), font.HintingNone)
+	assert.NoError(t, err)
+	path := glyphPath(glyph)
+	assert.Regexp(t, `^[-\d.]+ [-\d.]+ m\n`, path)
+	assert.Contains(t, path, " c\n")
+	assert.Equal(t, len(glyph.Ends), bytes.Count([]byte(path), []byte("h\n")))
+}
+
+func TestParseFont(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		ttf, err := ParseFont(goregular.TTF)
+		assert.NoError(t, err)
+		assert.Equal(t, goregular.TTF, fontProgram(ttf))
+		other, _ := truetype.Parse(goregular.TTF)
+		assert.Nil(t, fontProgram(other), "fonts parsed elsewhere have no known program")
+	})
+	t.Run("invalid", func(t *testing.T) {
+		ttf, err := ParseFont([]byte("not a font"))
+		assert.Nil(t, ttf)
+		assert.Error(t, err)
+	})
+}
+
+func TestToUnicodeCMap(t *testing.T) {
+	t.Run("type 3", func(t *testing.T) {
+		pdfFont := &pdfFont{codes: map[truetype.Index]int{}}
+		for i := 0; i < 101; i++ {
+			pdfFont.glyphs = append(pdfFont.glyphs, pdfGlyph{index: truetype.Index(i), char: 'a'})
+			pdfFont.codes[truetype.Index(i)] = i + 1
+		}
+		pdfFont.glyphs[100].char = '😀'
+		cmap := string(toUnicodeCMap(pdfFont))
+		assert.Contains(t, cmap, "<00> <FF>\nendcodespacerange\n")
+		assert.Contains(t, cmap, "100 beginbfchar\n<01> <0061>\n")
+		assert.Contains(t, cmap, "1 beginbfchar\n<65> <d83dde00>\nendbfchar\n")
+	})
+	t.Run("embedded", func(t *testing.T) {
+		pdfFont := &pdfFont{program: []byte{0}, codes: map[truetype.Index]int{300: 300}, glyphs: []pdfGlyph{{index: 300, char: 'a'}}}
+		cmap := string(toUnicodeCMap(pdfFont))
+		assert.Contains(t, cmap, "<0000> <FFFF>\nendcodespacerange\n")
+		assert.Contains(t, cmap, "1 beginbfchar\n<012c> <0061>\nendbfchar\n")
+	})
+}
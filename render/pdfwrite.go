@@ -0,0 +1,249 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// pdfWriter writes numbered PDF objects, tracking their offsets for the cross-reference table.
+type pdfWriter struct {
+	w       io.Writer
+	written int
+	offsets []int
+	err     error
+}
+
+func (pw *pdfWriter) printf(format string, args ...interface{}) {
+	if pw.err != nil {
+		return
+	}
+	var n int
+	n, pw.err = fmt.Fprintf(pw.w, format, args...)
+	pw.written += n
+}
+
+func (pw *pdfWriter) write(data []byte) {
+	if pw.err != nil {
+		return
+	}
+	var n int
+	n, pw.err = pw.w.Write(data)
+	pw.written += n
+}
+
+// reserve allocates an object number, to be written later with object or stream.
+func (pw *pdfWriter) reserve() int {
+	pw.offsets = append(pw.offsets, -1)
+	return len(pw.offsets)
+}
+
+func (pw *pdfWriter) object(id int, body string) {
+	pw.offsets[id-1] = pw.written
+	pw.printf("%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream writes a stream object, compressing the data unless it is already compressed.
+func (pw *pdfWriter) stream(id int, dict string, data []byte, compressed bool) {
+	if !compressed {
+		data = compress(data)
+	}
+	pw.offsets[id-1] = pw.written
+	pw.printf("%d 0 obj\n<< %s /Filter /FlateDecode /Length %d >>\nstream\n", id, dict, len(data))
+	pw.write(data)
+	pw.printf("\nendstream\nendobj\n")
+}
+
+// WritePDF writes the canvas to a single page PDF document.
+func (canvas PDFCanvas) WritePDF(w io.Writer) error {
+	doc := canvas.doc
+	if doc == nil {
+		doc = newPDFDocument()
+	}
+	pw := &pdfWriter{w: w}
+	pw.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	catalogID, pagesID, pageID, contentID := pw.reserve(), pw.reserve(), pw.reserve(), pw.reserve()
+	pw.object(catalogID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+	pw.object(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%d 0 R] /Count 1 >>", pageID))
+	// Canvases without a PPI are treated as 72 PPI, matching the default font DPI, so that one pixel is one point.
+	scale := 1.0
+	if ppi := canvas.GetPPI(); ppi > 0 {
+		scale = 72 / ppi
+	}
+	pageWidth, pageHeight := float64(canvas.GetWidth())*scale, float64(canvas.GetHeight())*scale
+	var resources bytes.Buffer
+	resources.WriteString("<< /ProcSet [/PDF /Text /ImageC]")
+	if len(doc.fonts) > 0 {
+		resources.WriteString(" /Font <<")
+		for _, pdfFont := range doc.fonts {
+			fmt.Fprintf(&resources, " /%s %d 0 R", pdfFont.name, pw.writeFont(pdfFont))
+		}
+		resources.WriteString(" >>")
+	}
+	// Forms share the resources of the page, which include the forms themselves, so their object numbers are reserved before the resources are complete.
+	formIDs := make([]int, len(doc.forms))
+	for i := range doc.forms {
+		formIDs[i] = pw.reserve()
+	}
+	if len(doc.images) > 0 || len(doc.forms) > 0 {
+		resources.WriteString(" /XObject <<")
+		for _, img := range doc.images {
+			fmt.Fprintf(&resources, " /%s %d 0 R", img.name, pw.writeImage(img))
+		}
+		for i, form := range doc.forms {
+			fmt.Fprintf(&resources, " /%s %d 0 R", form.name, formIDs[i])
+		}
+		resources.WriteString(" >>")
+	}
+	if len(doc.alphas) > 0 || len(doc.layerStates) > 0 {
+		alphas := make([]int, 0, len(doc.alphas))
+		for alpha := range doc.alphas {
+			alphas = append(alphas, int(alpha))
+		}
+		sort.Ints(alphas)
+		resources.WriteString(" /ExtGState <<")
+		for _, alpha := range alphas {
+			value := formatNumber(float64(alpha) / 255)
+			fmt.Fprintf(&resources, " /%s << /ca %s /CA %s >>", doc.alphas[uint8(alpha)], value, value)
+		}
+		for i, state := range doc.layerStates {
+			fmt.Fprintf(&resources, " /LS%d << %s", i+1, state.entries)
+			if state.mask != 0 {
+				fmt.Fprintf(&resources, " /SMask << /Type /Mask /S /Alpha /G %d 0 R >>", formIDs[state.mask-1])
+			}
+			resources.WriteString(" >>")
+		}
+		resources.WriteString(" >>")
+	}
+	if len(doc.patterns) > 0 {
+		resources.WriteString(" /Pattern <<")
+		for _, pattern := range doc.patterns {
+			fmt.Fprintf(&resources, " /%s %d 0 R", pattern.name, pw.writePattern(pattern, scale, pageHeight))
+		}
+		resources.WriteString(" >>")
+	}
+	resources.WriteString(" >>")
+	for i, form := range doc.forms {
+		pw.stream(formIDs[i], fmt.Sprintf("/Type /XObject /Subtype /Form /BBox [0 0 %d %d] /Group << /S /Transparency >> /Resources %s", canvas.GetWidth(), canvas.GetHeight(), resources.String()), form.content, false)
+	}
+	pw.object(pageID, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R >>", pagesID, formatNumber(pageWidth), formatNumber(pageHeight), resources.String(), contentID))
+	// Flip the page so that content can be written in canvas pixel coordinates with the origin at the top left.
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "q %s 0 0 -%s 0 %s cm\n", formatNumber(scale), formatNumber(scale), formatNumber(pageHeight))
+	content.Write(doc.content.Bytes())
+	content.WriteString("Q\n")
+	pw.stream(contentID, "", content.Bytes(), false)
+	xref := pw.written
+	pw.printf("xref\n0 %d\n0000000000 65535 f \n", len(pw.offsets)+1)
+	for _, offset := range pw.offsets {
+		pw.printf("%010d 00000 n \n", offset)
+	}
+	pw.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(pw.offsets)+1, catalogID, xref)
+	return pw.err
+}
+
+// writeFont writes a font embedding its TrueType program if it is known, or a Type 3 font otherwise, returning the font object number.
+func (pw *pdfWriter) writeFont(pdfFont *pdfFont) int {
+	if pdfFont.program == nil {
+		return pw.writeType3Font(pdfFont)
+	}
+	fontID, cidFontID, descriptorID, programID, toUnicodeID := pw.reserve(), pw.reserve(), pw.reserve(), pw.reserve(), pw.reserve()
+	scale := fixed.I(1000)
+	n := formatNumber
+	name := pdfFontName(pdfFont)
+	glyphs := append([]pdfGlyph{}, pdfFont.glyphs...)
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i].index < glyphs[j].index })
+	var widths bytes.Buffer
+	for _, g := range glyphs {
+		fmt.Fprintf(&widths, " %d [%s]", g.index, n(float64(pdfFont.ttf.HMetric(scale, g.index).AdvanceWidth)/64))
+	}
+	bounds := pdfFont.ttf.Bounds(scale)
+	top, bottom := n(float64(bounds.Max.Y)/64), n(float64(bounds.Min.Y)/64)
+	// Identity-H makes each two byte character code the CID, and CIDToGIDMap makes each CID the glyph index.
+	pw.object(fontID, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>", name, cidFontID, toUnicodeID))
+	pw.object(cidFontID, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /W [%s ] /CIDToGIDMap /Identity >>", name, descriptorID, widths.String()))
+	pw.object(descriptorID, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%s %s %s %s] /ItalicAngle 0 /Ascent %s /Descent %s /CapHeight %s /StemV 80 /FontFile2 %d 0 R >>",
+		name, n(float64(bounds.Min.X)/64), bottom, n(float64(bounds.Max.X)/64), top, top, bottom, top, programID))
+	pw.stream(programID, fmt.Sprintf("/Length1 %d", len(pdfFont.program)), pdfFont.program, false)
+	pw.stream(toUnicodeID, "", toUnicodeCMap(pdfFont), false)
+	return fontID
+}
+
+// pdfFontName returns the PostScript name of the TrueType font, with any characters not allowed in a PDF name removed, or the resource name of the font if it has none.
+func pdfFontName(pdfFont *pdfFont) string {
+	name := strings.Map(func(r rune) rune {
+		if r > ' ' && r < 127 && !strings.ContainsRune("()<>[]{}/%#", r) {
+			return r
+		}
+		return -1
+	}, pdfFont.ttf.Name(truetype.NameIDPostscriptName))
+	if name == "" {
+		return pdfFont.name
+	}
+	return name
+}
+
+// writeType3Font writes a Type 3 font with a glyph procedure drawing the outline of each glyph it uses, returning the font object number.
+func (pw *pdfWriter) writeType3Font(pdfFont *pdfFont) int {
+	fontID, toUnicodeID := pw.reserve(), pw.reserve()
+	scale := fixed.I(1000)
+	glyph := &truetype.GlyphBuf{}
+	var procs, differences, widths bytes.Buffer
+	for i, g := range pdfFont.glyphs {
+		procID := pw.reserve()
+		var proc bytes.Buffer
+		advance := pdfFont.ttf.HMetric(scale, g.index).AdvanceWidth
+		if err := glyph.Load(pdfFont.ttf, scale, g.index, font.HintingNone); err != nil || len(glyph.Ends) == 0 {
+			fmt.Fprintf(&proc, "%s 0 0 0 0 0 d1\n", formatNumber(float64(advance)/64))
+		} else {
+			fmt.Fprintf(&proc, "%s 0 %s %s %s %s d1\n", formatNumber(float64(advance)/64),
+				formatNumber(float64(glyph.Bounds.Min.X)/64), formatNumber(float64(glyph.Bounds.Min.Y)/64),
+				formatNumber(float64(glyph.Bounds.Max.X)/64), formatNumber(float64(glyph.Bounds.Max.Y)/64))
+			proc.WriteString(glyphPath(glyph))
+			proc.WriteString("f\n")
+		}
+		pw.stream(procID, "", proc.Bytes(), false)
+		fmt.Fprintf(&procs, " /g%d %d 0 R", i+1, procID)
+		fmt.Fprintf(&differences, " /g%d", i+1)
+		fmt.Fprintf(&widths, " %s", formatNumber(float64(advance)/64))
+	}
+	bounds := pdfFont.ttf.Bounds(scale)
+	pw.object(fontID, fmt.Sprintf("<< /Type /Font /Subtype /Type3 /FontBBox [%s %s %s %s] /FontMatrix [0.001 0 0 0.001 0 0] /CharProcs <<%s >> /Encoding << /Type /Encoding /Differences [1%s] >> /FirstChar 1 /LastChar %d /Widths [%s ] /Resources << >> /ToUnicode %d 0 R >>",
+		formatNumber(float64(bounds.Min.X)/64), formatNumber(float64(bounds.Min.Y)/64), formatNumber(float64(bounds.Max.X)/64), formatNumber(float64(bounds.Max.Y)/64),
+		procs.String(), differences.String(), len(pdfFont.glyphs), widths.String(), toUnicodeID))
+	pw.stream(toUnicodeID, "", toUnicodeCMap(pdfFont), false)
+	return fontID
+}
+
+// writePattern writes a shading pattern, returning the pattern object number. Patterns ignore the transformations in the page content, so the pattern matrix repeats the flip from canvas pixel coordinates. Forms are already in canvas pixel coordinates, so patterns inside them only need the squash.
+func (pw *pdfWriter) writePattern(pattern pdfPattern, scale, pageHeight float64) int {
+	patternID := pw.reserve()
+	n := formatNumber
+	if pattern.inForm {
+		pw.object(patternID, fmt.Sprintf("<< /Type /Pattern /PatternType 2 /Shading %s /Matrix [1 0 0 %s 0 %s] >>",
+			pattern.shading, n(pattern.squash), n(pattern.centreY*(1-pattern.squash))))
+		return patternID
+	}
+	pw.object(patternID, fmt.Sprintf("<< /Type /Pattern /PatternType 2 /Shading %s /Matrix [%s 0 0 %s 0 %s] >>",
+		pattern.shading, n(scale), n(-scale*pattern.squash), n(pageHeight-scale*pattern.centreY*(1-pattern.squash))))
+	return patternID
+}
+
+// writeImage writes an image XObject and its soft mask, returning the image object number.
+func (pw *pdfWriter) writeImage(img pdfImage) int {
+	imageID := pw.reserve()
+	smask := ""
+	if img.alpha != nil {
+		maskID := pw.reserve()
+		pw.stream(maskID, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8", img.width, img.height), img.alpha, true)
+		smask = fmt.Sprintf(" /SMask %d 0 R", maskID)
+	}
+	pw.stream(imageID, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8%s", img.width, img.height, smask), img.rgb, true)
+	return imageID
+}
@@ -2,12 +2,14 @@ package render
 
 import (
 	"errors"
+	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
+	"math"
 )
 
-// Rectangle draws a rectangle of a specific colour on the canvas.
+// Rectangle draws a rectangle of a specific colour or gradient on the canvas.
 func (canvas ImageCanvas) Rectangle(topLeft image.Point, width, height int, colour color.Color) (Canvas, error) {
 	c := canvas
 	if width <= 0 && height <= 0 {
@@ -20,26 +22,135 @@ func (canvas ImageCanvas) Rectangle(topLeft image.Point, width, height int, colo
 	if c.Image == nil {
 		return canvas, errors.New("no image set for canvas to draw on")
 	}
-	draw.Draw(c.Image, image.Rect(topLeft.X, topLeft.Y, topLeft.X+width, topLeft.Y+height), image.NewUniform(colour), topLeft, draw.Over)
+	draw.Draw(c.Image, image.Rect(topLeft.X, topLeft.Y, topLeft.X+width, topLeft.Y+height), paint(colour, image.Rect(topLeft.X, topLeft.Y, topLeft.X+width, topLeft.Y+height)), topLeft, draw.Over)
 	return c, nil
 }
 
-// Circle draws a circle of a specific colour on the canvas.
+// CornerRadii are the radii of the rounded corners of a rectangle, in pixels.
+type CornerRadii struct {
+	TopLeft     float64
+	TopRight    float64
+	BottomRight float64
+	BottomLeft  float64
+}
+
+// NewRectanglePath generates a closed path around a rectangle with rounded corners. Negative radii are treated as square corners, and if the corners on any side would overlap, all of them are scaled down until they just meet, as in CSS.
+func NewRectanglePath(topLeft image.Point, width, height int, radii CornerRadii) Path {
+	x, y, w, h := float64(topLeft.X), float64(topLeft.Y), float64(width), float64(height)
+	tl, tr, br, bl := math.Max(radii.TopLeft, 0), math.Max(radii.TopRight, 0), math.Max(radii.BottomRight, 0), math.Max(radii.BottomLeft, 0)
+	scale := 1.0
+	for _, side := range [][3]float64{{w, tl, tr}, {w, bl, br}, {h, tl, bl}, {h, tr, br}} {
+		if sum := side[1] + side[2]; sum > side[0] {
+			scale = math.Min(scale, side[0]/sum)
+		}
+	}
+	tl, tr, br, bl = tl*scale, tr*scale, br*scale, bl*scale
+	segments := []pathSegment{{op: pathMove, points: []pointF{{X: x + tl, Y: y}}}}
+	corner := func(edge pointF, centre pointF, radius, start float64) {
+		segments = append(segments, pathSegment{op: pathLine, points: []pointF{edge}})
+		if radius > 0 {
+			segments = append(segments, ellipseArc(centre, radius, radius, 0, start, math.Pi/2)...)
+		}
+	}
+	corner(pointF{X: x + w - tr, Y: y}, pointF{X: x + w - tr, Y: y + tr}, tr, -math.Pi/2)
+	corner(pointF{X: x + w, Y: y + h - br}, pointF{X: x + w - br, Y: y + h - br}, br, 0)
+	corner(pointF{X: x + bl, Y: y + h}, pointF{X: x + bl, Y: y + h - bl}, bl, math.Pi/2)
+	corner(pointF{X: x, Y: y + tl}, pointF{X: x + tl, Y: y + tl}, tl, math.Pi)
+	return Path{segments: append(segments, pathSegment{op: pathClose})}
+}
+
+// ArcStyle is the shape filled by an arc of an ellipse.
+type ArcStyle string
+
+const (
+	// ArcPie fills the slice between the arc and the centre of the ellipse
+	ArcPie ArcStyle = "pie"
+	// ArcChord fills the segment between the arc and the straight line joining its ends
+	ArcChord ArcStyle = "chord"
+)
+
+// ToArcStyle converts a string to an ArcStyle, returning an error if it doesn't match any of the defined constants.
+func ToArcStyle(raw string) (ArcStyle, error) {
+	switch style := ArcStyle(raw); style {
+	case ArcPie, ArcChord:
+		return style, nil
+	default:
+		return "", fmt.Errorf("arc style %v does not match defined constants", raw)
+	}
+}
+
+// Circle draws an anti-aliased circle of a specific colour on the canvas.
 func (canvas ImageCanvas) Circle(centre image.Point, radius int, colour color.Color) (Canvas, error) {
-	c := canvas
 	if radius <= 0 {
 		return canvas, errors.New("invalid radius")
 	}
-	if c.Image == nil {
-		return canvas, errors.New("no image set for canvas to draw on")
+	return canvas.Ellipse(centre, radius, radius, colour)
+}
+
+// Ellipse draws an anti-aliased ellipse of a specific colour on the canvas, with its axes aligned to the canvas.
+func (canvas ImageCanvas) Ellipse(centre image.Point, radiusX, radiusY int, colour color.Color) (Canvas, error) {
+	if err := checkRadii(radiusX, radiusY); err != nil {
+		return canvas, err
 	}
-	mask := &circle{p: centre, r: radius}
-	draw.DrawMask(c.Image, mask.Bounds(), image.NewUniform(colour), image.ZP, mask, mask.Bounds().Min, draw.Over)
-	return c, nil
+	return canvas.Path(NewEllipsePath(centre, radiusX, radiusY), colour, nil, StrokeStyle{})
+}
+
+// Arc draws an anti-aliased pie slice or chord segment of an ellipse of a specific colour on the canvas. Angles are in degrees, clockwise from the positive X axis, and the arc runs clockwise from the start angle to the end angle.
+func (canvas ImageCanvas) Arc(centre image.Point, radiusX, radiusY int, startAngle, endAngle float64, style ArcStyle, colour color.Color) (Canvas, error) {
+	path, err := NewArcPath(centre, radiusX, radiusY, startAngle, endAngle, style)
+	if err != nil {
+		return canvas, err
+	}
+	return canvas.Path(path, colour, nil, StrokeStyle{})
+}
+
+// checkRadii validates the radii of an ellipse.
+func checkRadii(radiusX, radiusY int) error {
+	if radiusX <= 0 && radiusY <= 0 {
+		return errors.New("invalid x and y radius")
+	} else if radiusX <= 0 {
+		return errors.New("invalid x radius")
+	} else if radiusY <= 0 {
+		return errors.New("invalid y radius")
+	}
+	return nil
 }
 
-// Steal the circle example code from https://blog.golang.org/go-imagedraw-package.
-type circle struct {
-	p image.Point
-	r int
+// NewEllipsePath generates a closed path around a whole ellipse with its axes aligned to the canvas, starting from its rightmost point.
+func NewEllipsePath(centre image.Point, radiusX, radiusY int) Path {
+	c := pointF{X: float64(centre.X), Y: float64(centre.Y)}
+	rx, ry := float64(radiusX), float64(radiusY)
+	segments := []pathSegment{{op: pathMove, points: []pointF{{X: c.X + rx, Y: c.Y}}}}
+	segments = append(segments, ellipseArc(c, rx, ry, 0, 0, 2*math.Pi)...)
+	return Path{segments: append(segments, pathSegment{op: pathClose})}
+}
+
+// NewArcPath generates a closed path around a pie slice or chord segment of an ellipse, with angles as for Arc.
+func NewArcPath(centre image.Point, radiusX, radiusY int, startAngle, endAngle float64, style ArcStyle) (Path, error) {
+	if err := checkRadii(radiusX, radiusY); err != nil {
+		return Path{}, err
+	}
+	if style != ArcPie && style != ArcChord {
+		return Path{}, fmt.Errorf("unsupported arc style: %v", style)
+	}
+	if startAngle == endAngle || math.IsNaN(endAngle-startAngle) || math.IsInf(endAngle-startAngle, 0) {
+		return Path{}, fmt.Errorf("invalid arc angles %v to %v", startAngle, endAngle)
+	}
+	// Different angles a whole number of turns apart cover the whole ellipse rather than nothing.
+	extent := math.Mod(endAngle-startAngle, 360)
+	if extent <= 0 {
+		extent += 360
+	}
+	c := pointF{X: float64(centre.X), Y: float64(centre.Y)}
+	rx, ry := float64(radiusX), float64(radiusY)
+	start := startAngle * math.Pi / 180
+	first := pointF{X: c.X + rx*math.Cos(start), Y: c.Y + ry*math.Sin(start)}
+	var segments []pathSegment
+	if style == ArcPie {
+		segments = []pathSegment{{op: pathMove, points: []pointF{c}}, {op: pathLine, points: []pointF{first}}}
+	} else {
+		segments = []pathSegment{{op: pathMove, points: []pointF{first}}}
+	}
+	segments = append(segments, ellipseArc(c, rx, ry, 0, start, extent*math.Pi/180)...)
+	return Path{segments: append(segments, pathSegment{op: pathClose})}, nil
 }
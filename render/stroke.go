@@ -0,0 +1,419 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/vector"
+)
+
+// LineCap is the shape drawn at the ends of an open line.
+type LineCap string
+
+const (
+	// CapButt ends the line exactly at its end points
+	CapButt LineCap = "butt"
+	// CapRound adds a semicircle to each end of the line
+	CapRound LineCap = "round"
+	// CapSquare extends each end of the line by half its width
+	CapSquare LineCap = "square"
+)
+
+// ToLineCap attempts to convert a line cap name to a defined LineCap constant.
+func ToLineCap(raw string) (LineCap, error) {
+	switch raw {
+	case string(CapButt):
+		return CapButt, nil
+	case string(CapRound):
+		return CapRound, nil
+	case string(CapSquare):
+		return CapSquare, nil
+	default:
+		return LineCap(""), fmt.Errorf("line cap %v does not match defined constants", raw)
+	}
+}
+
+// LineJoin is the shape drawn where two segments of a line meet.
+type LineJoin string
+
+const (
+	// JoinMiter extends the outer edges of the segments until they meet, falling back to a bevel for very sharp corners
+	JoinMiter LineJoin = "miter"
+	// JoinRound rounds off the corner with a circle
+	JoinRound LineJoin = "round"
+	// JoinBevel cuts off the corner with a straight edge
+	JoinBevel LineJoin = "bevel"
+)
+
+// ToLineJoin attempts to convert a line join name to a defined LineJoin constant.
+func ToLineJoin(raw string) (LineJoin, error) {
+	switch raw {
+	case string(JoinMiter):
+		return JoinMiter, nil
+	case string(JoinRound):
+		return JoinRound, nil
+	case string(JoinBevel):
+		return JoinBevel, nil
+	default:
+		return LineJoin(""), fmt.Errorf("line join %v does not match defined constants", raw)
+	}
+}
+
+// miterLimit is the longest a miter join can be as a multiple of the stroke width before it is drawn as a bevel, matching the SVG default.
+const miterLimit = 4
+
+// StrokeStyle describes how a line is drawn.
+type StrokeStyle struct {
+	// Width is the width of the line in pixels.
+	Width float64
+	// Cap is the shape of the ends of the line, and of each dash. Empty uses the default of butt.
+	Cap LineCap
+	// Join is the shape of the corners of the line. Empty uses the default of miter.
+	Join LineJoin
+	// Dash is a repeating pattern of alternating dash and gap lengths in pixels. An empty pattern draws a solid line.
+	Dash []float64
+	// Inside keeps the outline of a path within the area it fills, as a border, rather than centring it on the edge. It has no effect on lines.
+	Inside bool
+}
+
+// normalise fills in default values and checks the style is drawable. Odd dash patterns are repeated to give an even number of lengths, as in SVG.
+func (style StrokeStyle) normalise() (StrokeStyle, error) {
+	if style.Width <= 0 || math.IsNaN(style.Width) || math.IsInf(style.Width, 0) {
+		return style, fmt.Errorf("invalid stroke width %v", style.Width)
+	}
+	switch style.Cap {
+	case "":
+		style.Cap = CapButt
+	case CapButt, CapRound, CapSquare:
+	default:
+		return style, fmt.Errorf("unsupported line cap: %v", style.Cap)
+	}
+	switch style.Join {
+	case "":
+		style.Join = JoinMiter
+	case JoinMiter, JoinRound, JoinBevel:
+	default:
+		return style, fmt.Errorf("unsupported line join: %v", style.Join)
+	}
+	if len(style.Dash) > 0 {
+		total := 0.0
+		for _, length := range style.Dash {
+			if length < 0 || math.IsNaN(length) || math.IsInf(length, 0) {
+				return style, fmt.Errorf("invalid dash pattern %v", style.Dash)
+			}
+			total += length
+		}
+		if total == 0 {
+			return style, fmt.Errorf("invalid dash pattern %v", style.Dash)
+		}
+		if len(style.Dash)%2 == 1 {
+			style.Dash = append(append([]float64{}, style.Dash...), style.Dash...)
+		}
+	}
+	return style, nil
+}
+
+// Line draws a straight line of a specific colour and style between two points on the canvas.
+func (canvas ImageCanvas) Line(start, end image.Point, style StrokeStyle, colour color.Color) (Canvas, error) {
+	return canvas.Polyline([]image.Point{start, end}, style, colour)
+}
+
+// Polyline draws a line of a specific colour and style through each of the points in turn on the canvas. Points are the centres of pixels, so a horizontal line one pixel wide exactly covers a row of pixels.
+func (canvas ImageCanvas) Polyline(points []image.Point, style StrokeStyle, colour color.Color) (Canvas, error) {
+	if len(points) < 2 {
+		return canvas, fmt.Errorf("a line needs at least 2 points, got %d", len(points))
+	}
+	style, err := style.normalise()
+	if err != nil {
+		return canvas, err
+	}
+	if canvas.Image == nil {
+		return canvas, errors.New("no image set for canvas to draw on")
+	}
+	fillPolygons(canvas.Image, strokePolygons(pixelCentres(points), false, style), image.NewUniform(colour))
+	return canvas, nil
+}
+
+// rasteriseStroke draws a line onto a transparent image just large enough to hold it, for canvases which have no equivalent of the line style.
+func rasteriseStroke(points []image.Point, style StrokeStyle, colour color.Color) (image.Point, image.Image) {
+	polygons := strokePolygons(pixelCentres(points), false, style)
+	bounds := polygonBounds(polygons)
+	img := image.NewNRGBA(bounds)
+	fillPolygons(img, polygons, image.NewUniform(colour))
+	return bounds.Min, img
+}
+
+// pointF is a point with sub-pixel precision.
+type pointF struct {
+	X, Y float64
+}
+
+func (p pointF) add(q pointF) pointF {
+	return pointF{X: p.X + q.X, Y: p.Y + q.Y}
+}
+
+func (p pointF) sub(q pointF) pointF {
+	return pointF{X: p.X - q.X, Y: p.Y - q.Y}
+}
+
+func (p pointF) scale(factor float64) pointF {
+	return pointF{X: p.X * factor, Y: p.Y * factor}
+}
+
+func (p pointF) length() float64 {
+	return math.Hypot(p.X, p.Y)
+}
+
+// unit returns the point scaled to a length of one, or the zero point if it has no length.
+func (p pointF) unit() pointF {
+	length := p.length()
+	if length == 0 {
+		return pointF{}
+	}
+	return p.scale(1 / length)
+}
+
+// normal returns the point rotated a quarter turn, which is clockwise on screen where Y points down.
+func (p pointF) normal() pointF {
+	return pointF{X: -p.Y, Y: p.X}
+}
+
+// pixelCentres converts pixel coordinates to the centres of those pixels.
+func pixelCentres(points []image.Point) []pointF {
+	result := make([]pointF, len(points))
+	for i, p := range points {
+		result[i] = pointF{X: float64(p.X) + 0.5, Y: float64(p.Y) + 0.5}
+	}
+	return result
+}
+
+// strokePolygons returns polygons whose union is the outline of a line drawn through the points with the style, which must be normalised. Closed lines join their last point back to their first, and have no caps unless they are dashed. Every polygon is wound clockwise, so that overlaps add up rather than cancelling out when filled.
+func strokePolygons(points []pointF, closed bool, style StrokeStyle) [][]pointF {
+	var polygons [][]pointF
+	if closed && len(style.Dash) > 0 {
+		points = append(points[:len(points):len(points)], points[0])
+		closed = false
+	}
+	for _, dash := range dashes(points, style.Dash) {
+		polygons = append(polygons, strokeSegments(dash, closed, style)...)
+	}
+	for _, polygon := range polygons {
+		if polygonArea(polygon) < 0 {
+			for i, j := 0, len(polygon)-1; i < j; i, j = i+1, j-1 {
+				polygon[i], polygon[j] = polygon[j], polygon[i]
+			}
+		}
+	}
+	return polygons
+}
+
+// strokeSegments returns the outline of a single solid run of line.
+func strokeSegments(points []pointF, closed bool, style StrokeStyle) [][]pointF {
+	halfWidth := style.Width / 2
+	// Repeated points have no direction, so they would break the joins.
+	unique := []pointF{points[0]}
+	for _, p := range points[1:] {
+		if p != unique[len(unique)-1] {
+			unique = append(unique, p)
+		}
+	}
+	if closed && len(unique) > 1 && unique[len(unique)-1] == unique[0] {
+		unique = unique[:len(unique)-1]
+	}
+	if len(unique) == 1 {
+		// A dot has no direction, so only round and square caps are visible.
+		switch style.Cap {
+		case CapRound:
+			return [][]pointF{circlePolygon(unique[0], halfWidth)}
+		case CapSquare:
+			p := unique[0]
+			return [][]pointF{{
+				{X: p.X - halfWidth, Y: p.Y - halfWidth},
+				{X: p.X + halfWidth, Y: p.Y - halfWidth},
+				{X: p.X + halfWidth, Y: p.Y + halfWidth},
+				{X: p.X - halfWidth, Y: p.Y + halfWidth},
+			}}
+		}
+		return nil
+	}
+	if closed {
+		// The first point is repeated at the end, so every corner has a segment on either side.
+		unique = append(unique, unique[0], unique[1])
+	}
+	last := len(unique) - 1
+	segments := last
+	if closed {
+		segments--
+	}
+	var polygons [][]pointF
+	for i := 0; i < segments; i++ {
+		start, end := unique[i], unique[i+1]
+		direction := end.sub(start).unit()
+		if style.Cap == CapSquare && !closed {
+			if i == 0 {
+				start = start.sub(direction.scale(halfWidth))
+			}
+			if i == last-1 {
+				end = end.add(direction.scale(halfWidth))
+			}
+		}
+		offset := direction.normal().scale(halfWidth)
+		polygons = append(polygons, []pointF{start.add(offset), end.add(offset), end.sub(offset), start.sub(offset)})
+	}
+	for i := 1; i < last; i++ {
+		join := strokeJoin(unique[i-1], unique[i], unique[i+1], halfWidth, style.Join)
+		if join != nil {
+			polygons = append(polygons, join)
+		}
+	}
+	if style.Cap == CapRound && !closed {
+		polygons = append(polygons, circlePolygon(unique[0], halfWidth), circlePolygon(unique[last], halfWidth))
+	}
+	return polygons
+}
+
+// strokeJoin returns the polygon filling the outside of the corner at vertex, or nil if none is needed.
+func strokeJoin(previous, vertex, next pointF, halfWidth float64, join LineJoin) []pointF {
+	if join == JoinRound {
+		return circlePolygon(vertex, halfWidth)
+	}
+	in := vertex.sub(previous).unit()
+	out := next.sub(vertex).unit()
+	cross := in.X*out.Y - in.Y*out.X
+	if cross == 0 {
+		// Straight on, or doubling back on itself, so there is no outside corner to fill.
+		return nil
+	}
+	// The outside of the corner is on the opposite side to the turn.
+	side := 1.0
+	if cross > 0 {
+		side = -1
+	}
+	inNormal := in.normal().scale(side)
+	outNormal := out.normal().scale(side)
+	first := vertex.add(inNormal.scale(halfWidth))
+	second := vertex.add(outNormal.scale(halfWidth))
+	if join == JoinMiter {
+		bisector := inNormal.add(outNormal).unit()
+		// The miter tip lies along the bisector, far enough out to meet both outer edges.
+		miterLength := halfWidth / (bisector.X*inNormal.X + bisector.Y*inNormal.Y)
+		if miterLength <= miterLimit*halfWidth {
+			return []pointF{vertex, first, vertex.add(bisector.scale(miterLength)), second}
+		}
+	}
+	return []pointF{vertex, first, second}
+}
+
+// circlePolygon approximates a circle with enough sides that the error is well under a pixel.
+func circlePolygon(centre pointF, radius float64) []pointF {
+	sides := int(math.Ceil(math.Pi * math.Sqrt(radius*8)))
+	if sides < 8 {
+		sides = 8
+	}
+	polygon := make([]pointF, sides)
+	for i := range polygon {
+		angle := 2 * math.Pi * float64(i) / float64(sides)
+		polygon[i] = pointF{X: centre.X + radius*math.Cos(angle), Y: centre.Y + radius*math.Sin(angle)}
+	}
+	return polygon
+}
+
+// dashes splits a line into the runs drawn by a dash pattern, which must have an even number of lengths. An empty pattern returns the whole line.
+func dashes(points []pointF, pattern []float64) [][]pointF {
+	if len(pattern) == 0 {
+		return [][]pointF{points}
+	}
+	var runs [][]pointF
+	index := 0
+	remaining := pattern[0]
+	current := []pointF{points[0]}
+	for i := 1; i < len(points); i++ {
+		start, end := points[i-1], points[i]
+		segment := end.sub(start)
+		length := segment.length()
+		travelled := 0.0
+		for length-travelled > remaining {
+			travelled += remaining
+			p := start.add(segment.scale(travelled / length))
+			if index%2 == 0 {
+				// The end of a dash.
+				if current != nil {
+					runs = append(runs, append(current, p))
+					current = nil
+				}
+			} else {
+				// The start of a dash.
+				current = []pointF{p}
+			}
+			index = (index + 1) % len(pattern)
+			remaining = pattern[index]
+		}
+		remaining -= length - travelled
+		if current != nil {
+			current = append(current, end)
+		}
+	}
+	if current != nil {
+		runs = append(runs, current)
+	} else if index%2 == 1 && remaining < 1e-9 {
+		// A gap ending exactly at the end of the line starts a dash with no length, which is still visible with round or square caps.
+		end := points[len(points)-1]
+		runs = append(runs, []pointF{end, end})
+	}
+	return runs
+}
+
+// polygonBounds returns the pixels touched by any of the polygons.
+func polygonBounds(polygons [][]pointF) image.Rectangle {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, polygon := range polygons {
+		for _, p := range polygon {
+			minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+			maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+		}
+	}
+	if minX > maxX {
+		return image.Rectangle{}
+	}
+	return image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+}
+
+// polygonArea returns the signed area of a polygon, which is positive for polygons wound clockwise on screen.
+func polygonArea(polygon []pointF) float64 {
+	area := 0.0
+	for i, p := range polygon {
+		q := polygon[(i+1)%len(polygon)]
+		area += p.X*q.Y - q.X*p.Y
+	}
+	return area / 2
+}
+
+// fillPolygons draws src onto dst through the anti-aliased polygons, using the non-zero winding rule.
+func fillPolygons(dst draw.Image, polygons [][]pointF, src image.Image) {
+	bounds := polygonBounds(polygons).Intersect(dst.Bounds())
+	if bounds.Empty() {
+		return
+	}
+	r := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	origin := pointF{X: float64(bounds.Min.X), Y: float64(bounds.Min.Y)}
+	for _, polygon := range polygons {
+		if len(polygon) < 3 {
+			continue
+		}
+		for i, p := range polygon {
+			p = p.sub(origin)
+			if i == 0 {
+				r.MoveTo(float32(p.X), float32(p.Y))
+			} else {
+				r.LineTo(float32(p.X), float32(p.Y))
+			}
+		}
+		r.ClosePath()
+	}
+	r.Draw(dst, bounds, src, bounds.Min)
+}
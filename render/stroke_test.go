@@ -0,0 +1,198 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToLineCap(t *testing.T) {
+	for _, cap := range []LineCap{CapButt, CapRound, CapSquare} {
+		converted, err := ToLineCap(string(cap))
+		assert.NoError(t, err)
+		assert.Equal(t, cap, converted)
+	}
+	converted, err := ToLineCap("pointy")
+	assert.Equal(t, LineCap(""), converted)
+	assert.EqualError(t, err, "line cap pointy does not match defined constants")
+}
+
+func TestToLineJoin(t *testing.T) {
+	for _, join := range []LineJoin{JoinMiter, JoinRound, JoinBevel} {
+		converted, err := ToLineJoin(string(join))
+		assert.NoError(t, err)
+		assert.Equal(t, join, converted)
+	}
+	converted, err := ToLineJoin("pointy")
+	assert.Equal(t, LineJoin(""), converted)
+	assert.EqualError(t, err, "line join pointy does not match defined constants")
+}
+
+func TestNormaliseStrokeStyle(t *testing.T) {
+	type testSet struct {
+		name   string
+		style  StrokeStyle
+		result StrokeStyle
+		err    string
+	}
+	tests := []testSet{
+		{name: "defaults", style: StrokeStyle{Width: 2}, result: StrokeStyle{Width: 2, Cap: CapButt, Join: JoinMiter}},
+		{name: "zero width", style: StrokeStyle{}, err: "invalid stroke width 0"},
+		{name: "negative width", style: StrokeStyle{Width: -1}, err: "invalid stroke width -1"},
+		{name: "bad cap", style: StrokeStyle{Width: 1, Cap: "pointy"}, err: "unsupported line cap: pointy"},
+		{name: "bad join", style: StrokeStyle{Width: 1, Join: "pointy"}, err: "unsupported line join: pointy"},
+		{name: "negative dash", style: StrokeStyle{Width: 1, Dash: []float64{2, -1}}, err: "invalid dash pattern [2 -1]"},
+		{name: "empty dash", style: StrokeStyle{Width: 1, Dash: []float64{0, 0}}, err: "invalid dash pattern [0 0]"},
+		{name: "odd dash", style: StrokeStyle{Width: 1, Cap: CapRound, Join: JoinBevel, Dash: []float64{1, 2, 3}}, result: StrokeStyle{Width: 1, Cap: CapRound, Join: JoinBevel, Dash: []float64{1, 2, 3, 1, 2, 3}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.style.normalise()
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.result, result)
+		})
+	}
+}
+
+// alphaAt returns the 8-bit alpha of the canvas at a pixel.
+func alphaAt(canvas Canvas, x, y int) uint8 {
+	return toNRGBA(canvas.GetUnderlyingImage().At(x, y)).A
+}
+
+func TestLine(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	t.Run("no image", func(t *testing.T) {
+		canvas := ImageCanvas{}
+		modifiedCanvas, err := canvas.Line(image.ZP, image.Pt(3, 3), StrokeStyle{Width: 1}, red)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "no image set for canvas to draw on")
+	})
+	t.Run("too few points", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Polyline(nil, StrokeStyle{Width: 1}, red)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "a line needs at least 2 points, got 0")
+	})
+	t.Run("invalid style", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Line(image.ZP, image.Pt(3, 3), StrokeStyle{Width: 1, Join: "pointy"}, red)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "unsupported line join: pointy")
+	})
+	t.Run("butt cap", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Line(image.Pt(2, 4), image.Pt(7, 4), StrokeStyle{Width: 1}, red)
+		assert.NoError(t, err)
+		assert.Equal(t, red, modifiedCanvas.GetUnderlyingImage().At(4, 4))
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 4, 3))
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 4, 5))
+		// The line runs between pixel centres, so the end pixels are half covered.
+		assert.Equal(t, uint8(128), alphaAt(modifiedCanvas, 2, 4))
+		assert.Equal(t, uint8(128), alphaAt(modifiedCanvas, 7, 4))
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 1, 4))
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 8, 4))
+	})
+	t.Run("square cap", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Line(image.Pt(2, 4), image.Pt(7, 4), StrokeStyle{Width: 2, Cap: CapSquare}, red)
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(255), alphaAt(modifiedCanvas, 2, 4))
+		assert.Equal(t, uint8(255), alphaAt(modifiedCanvas, 7, 4))
+		assert.Equal(t, uint8(128), alphaAt(modifiedCanvas, 1, 4))
+		assert.Equal(t, uint8(128), alphaAt(modifiedCanvas, 4, 3))
+	})
+	t.Run("round cap", func(t *testing.T) {
+		canvas, _ := NewCanvas(20, 20)
+		modifiedCanvas, err := canvas.Line(image.Pt(5, 10), image.Pt(14, 10), StrokeStyle{Width: 6, Cap: CapRound}, red)
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(255), alphaAt(modifiedCanvas, 3, 10))
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 2, 8), "corners are rounded off")
+		assert.Equal(t, uint8(255), alphaAt(modifiedCanvas, 5, 8))
+	})
+	t.Run("overlapping segments", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		// The line doubles back on itself, which must not cancel out.
+		modifiedCanvas, err := canvas.Polyline([]image.Point{{X: 1, Y: 5}, {X: 8, Y: 5}, {X: 3, Y: 5}}, StrokeStyle{Width: 3}, red)
+		assert.NoError(t, err)
+		assert.Equal(t, red, modifiedCanvas.GetUnderlyingImage().At(5, 5))
+	})
+	t.Run("joins", func(t *testing.T) {
+		corner := []image.Point{{X: 2, Y: 2}, {X: 12, Y: 2}, {X: 12, Y: 12}}
+		miter, _ := NewCanvas(16, 16)
+		miterCanvas, err := miter.Polyline(corner, StrokeStyle{Width: 5}, red)
+		assert.NoError(t, err)
+		bevel, _ := NewCanvas(16, 16)
+		bevelCanvas, err := bevel.Polyline(corner, StrokeStyle{Width: 5, Join: JoinBevel}, red)
+		assert.NoError(t, err)
+		round, _ := NewCanvas(16, 16)
+		roundCanvas, err := round.Polyline(corner, StrokeStyle{Width: 5, Join: JoinRound}, red)
+		assert.NoError(t, err)
+		// The outside corner is only filled by a miter.
+		assert.Equal(t, uint8(255), alphaAt(miterCanvas, 14, 0))
+		assert.Equal(t, uint8(0), alphaAt(bevelCanvas, 14, 0))
+		assert.True(t, alphaAt(roundCanvas, 14, 0) < 128, "expected round join to cut off the corner, got alpha %d", alphaAt(roundCanvas, 14, 0))
+		// Next to the corner is mostly filled by a round join but not a bevel.
+		assert.True(t, alphaAt(roundCanvas, 13, 0) > 160, "expected round join to fill next to the corner, got alpha %d", alphaAt(roundCanvas, 13, 0))
+		assert.True(t, alphaAt(bevelCanvas, 13, 0) < 128, "expected bevel to cut off the corner, got alpha %d", alphaAt(bevelCanvas, 13, 0))
+		// The inside of the corner is the same for all of them.
+		for _, canvas := range []Canvas{miterCanvas, bevelCanvas, roundCanvas} {
+			assert.Equal(t, uint8(255), alphaAt(canvas, 10, 4))
+			assert.Equal(t, uint8(0), alphaAt(canvas, 9, 5))
+		}
+	})
+	t.Run("sharp miter", func(t *testing.T) {
+		canvas, _ := NewCanvas(40, 20)
+		// The miter would be far longer than the limit, so the corner is bevelled.
+		modifiedCanvas, err := canvas.Polyline([]image.Point{{X: 2, Y: 2}, {X: 35, Y: 5}, {X: 2, Y: 8}}, StrokeStyle{Width: 4}, red)
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 39, 5))
+	})
+	t.Run("dashes", func(t *testing.T) {
+		canvas, _ := NewCanvas(20, 5)
+		modifiedCanvas, err := canvas.Line(image.Pt(0, 2), image.Pt(19, 2), StrokeStyle{Width: 1, Dash: []float64{4, 2}}, red)
+		assert.NoError(t, err)
+		var row []uint8
+		for x := 0; x < 20; x++ {
+			row = append(row, alphaAt(modifiedCanvas, x, 2))
+		}
+		assert.Equal(t, []uint8{128, 255, 255, 255, 128, 0, 128, 255, 255, 255, 128, 0, 128, 255, 255, 255, 128, 0, 128, 128}, row)
+	})
+	t.Run("dashes around corners", func(t *testing.T) {
+		runs := dashes([]pointF{{X: 0, Y: 0}, {X: 3, Y: 0}, {X: 3, Y: 3}}, []float64{2, 2})
+		assert.Equal(t, [][]pointF{
+			{{X: 0, Y: 0}, {X: 2, Y: 0}},
+			{{X: 3, Y: 1}, {X: 3, Y: 3}},
+		}, runs)
+		runs = dashes([]pointF{{X: 0, Y: 0}, {X: 5, Y: 0}}, []float64{0, 2})
+		assert.Equal(t, [][]pointF{
+			{{X: 0, Y: 0}, {X: 0, Y: 0}},
+			{{X: 2, Y: 0}, {X: 2, Y: 0}},
+			{{X: 4, Y: 0}, {X: 4, Y: 0}},
+		}, runs)
+	})
+	t.Run("dots", func(t *testing.T) {
+		canvas, _ := NewCanvas(20, 5)
+		modifiedCanvas, err := canvas.Line(image.Pt(2, 2), image.Pt(17, 2), StrokeStyle{Width: 2, Cap: CapRound, Dash: []float64{0, 5}}, red)
+		assert.NoError(t, err)
+		for _, x := range []int{2, 7, 12, 17} {
+			assert.Equal(t, uint8(255), alphaAt(modifiedCanvas, x, 2), "dot at %d", x)
+		}
+		assert.Equal(t, uint8(0), alphaAt(modifiedCanvas, 5, 2))
+		blank, _ := NewCanvas(20, 5)
+		modifiedCanvas, err = blank.Line(image.Pt(2, 2), image.Pt(17, 2), StrokeStyle{Width: 2, Dash: []float64{0, 5}}, red)
+		assert.NoError(t, err)
+		assert.True(t, isTransparent(modifiedCanvas.GetUnderlyingImage()), "butt caps make dots invisible")
+	})
+	t.Run("off canvas", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := canvas.Line(image.Pt(-20, -20), image.Pt(-10, -20), StrokeStyle{Width: 3}, red)
+		assert.NoError(t, err)
+		assert.True(t, isTransparent(modifiedCanvas.GetUnderlyingImage()))
+	})
+}
@@ -0,0 +1,477 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+)
+
+// SVGCanvas implements the Canvas interface by recording drawing operations as SVG elements. Every operation is mirrored onto a raster ImageCanvas, which is used to measure text and to provide the underlying image.
+type SVGCanvas struct {
+	// raster mirrors every drawing operation on the canvas.
+	raster ImageCanvas
+	// doc is the document being drawn, shared between copies of the canvas in the same way as the underlying image of an ImageCanvas.
+	doc *svgDocument
+}
+
+// svgDocument holds the elements drawn on an SVGCanvas.
+type svgDocument struct {
+	body bytes.Buffer
+	ids  int
+}
+
+// newID generates an element ID unique within the document.
+func (doc *svgDocument) newID(prefix string) string {
+	doc.ids++
+	return fmt.Sprintf("%s%d", prefix, doc.ids)
+}
+
+// NewSVGCanvas generates a new SVG canvas of the given width and height in pixels.
+func NewSVGCanvas(width, height int) (SVGCanvas, error) {
+	raster, err := NewCanvas(width, height)
+	if err != nil {
+		return SVGCanvas{}, err
+	}
+	return SVGCanvas{raster: raster, doc: &svgDocument{}}, nil
+}
+
+// NewSVGCanvasFrom generates a new SVG canvas with the size and PPI of an existing canvas, embedding the current contents of that canvas as the background image.
+func NewSVGCanvasFrom(base Canvas) (SVGCanvas, error) {
+	canvas, err := NewSVGCanvas(base.GetWidth(), base.GetHeight())
+	if err != nil {
+		return SVGCanvas{}, err
+	}
+	canvas = canvas.SetPPI(base.GetPPI()).(SVGCanvas)
+	drawn, err := canvas.DrawImage(image.ZP, base.GetUnderlyingImage())
+	if err != nil {
+		return SVGCanvas{}, err
+	}
+	return drawn.(SVGCanvas), nil
+}
+
+// SetUnderlyingImage replaces the contents of the canvas with the given image.
+func (canvas SVGCanvas) SetUnderlyingImage(newImage image.Image) Canvas {
+	canvas.raster = canvas.raster.SetUnderlyingImage(newImage).(ImageCanvas)
+	canvas.doc = &svgDocument{}
+	if newImage != nil {
+		// Only images too large for a PNG fail to encode, and there is no error to return that with here.
+		canvas.doc.image(image.ZP, newImage)
+	}
+	return canvas
+}
+
+// GetUnderlyingImage gets the raster image mirroring the SVG contents.
+func (canvas SVGCanvas) GetUnderlyingImage() image.Image {
+	return canvas.raster.GetUnderlyingImage()
+}
+
+// GetWidth returns the width of the canvas in pixels.
+func (canvas SVGCanvas) GetWidth() int {
+	return canvas.raster.GetWidth()
+}
+
+// GetHeight returns the height of the canvas in pixels.
+func (canvas SVGCanvas) GetHeight() int {
+	return canvas.raster.GetHeight()
+}
+
+// SetPPI sets the pixels per inch of the canvas, which determines the physical size of the document.
+func (canvas SVGCanvas) SetPPI(ppi float64) Canvas {
+	canvas.raster = canvas.raster.SetPPI(ppi).(ImageCanvas)
+	return canvas
+}
+
+// GetPPI returns the pixels per inch of the canvas.
+func (canvas SVGCanvas) GetPPI() float64 {
+	return canvas.raster.GetPPI()
+}
+
+// Rectangle draws a rect element of a specific colour on the canvas.
+func (canvas SVGCanvas) Rectangle(topLeft image.Point, width, height int, colour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Rectangle(topLeft, width, height, colour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	fill := canvas.doc.fill(colour, image.Rect(topLeft.X, topLeft.Y, topLeft.X+width, topLeft.Y+height))
+	fmt.Fprintf(&canvas.doc.body, `<rect x="%d" y="%d" width="%d" height="%d"%s/>`+"\n", topLeft.X, topLeft.Y, width, height, fill)
+	return canvas, nil
+}
+
+// Circle draws a circle element of a specific colour on the canvas.
+func (canvas SVGCanvas) Circle(centre image.Point, radius int, colour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Circle(centre, radius, colour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	fill := canvas.doc.fill(colour, NewEllipsePath(centre, radius, radius).bounds())
+	fmt.Fprintf(&canvas.doc.body, `<circle cx="%d" cy="%d" r="%d"%s/>`+"\n", centre.X, centre.Y, radius, fill)
+	return canvas, nil
+}
+
+// Ellipse draws an ellipse element of a specific colour on the canvas.
+func (canvas SVGCanvas) Ellipse(centre image.Point, radiusX, radiusY int, colour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Ellipse(centre, radiusX, radiusY, colour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	fill := canvas.doc.fill(colour, NewEllipsePath(centre, radiusX, radiusY).bounds())
+	fmt.Fprintf(&canvas.doc.body, `<ellipse cx="%d" cy="%d" rx="%d" ry="%d"%s/>`+"\n", centre.X, centre.Y, radiusX, radiusY, fill)
+	return canvas, nil
+}
+
+// Arc draws a path element for a pie slice or chord segment of an ellipse of a specific colour on the canvas.
+func (canvas SVGCanvas) Arc(centre image.Point, radiusX, radiusY int, startAngle, endAngle float64, style ArcStyle, colour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Arc(centre, radiusX, radiusY, startAngle, endAngle, style, colour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	path, _ := NewArcPath(centre, radiusX, radiusY, startAngle, endAngle, style)
+	fill := canvas.doc.fill(colour, path.bounds())
+	fmt.Fprintf(&canvas.doc.body, `<path d="%s"%s/>`+"\n", path.svgData(), fill)
+	return canvas, nil
+}
+
+// Line draws a line element of a specific colour and style on the canvas.
+func (canvas SVGCanvas) Line(start, end image.Point, style StrokeStyle, colour color.Color) (Canvas, error) {
+	return canvas.Polyline([]image.Point{start, end}, style, colour)
+}
+
+// Polyline draws a polyline element of a specific colour and style through each of the points in turn on the canvas.
+func (canvas SVGCanvas) Polyline(points []image.Point, style StrokeStyle, colour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Polyline(points, style, colour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	style, _ = style.normalise()
+	fmt.Fprintf(&canvas.doc.body, `<polyline points="%s" fill="none"%s/>`+"\n", svgPoints(pixelCentres(points)), svgStroke(colour, style))
+	return canvas, nil
+}
+
+// Path draws a path element on the canvas, filled and outlined with specific colours.
+func (canvas SVGCanvas) Path(path Path, fill, stroke color.Color, style StrokeStyle) (Canvas, error) {
+	raster, err := canvas.raster.Path(path, fill, stroke, style)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	style, _ = checkPath(path, style)
+	fillAttributes := ` fill="none"`
+	if isVisible(fill) {
+		fillAttributes = canvas.doc.fill(fill, path.bounds())
+	}
+	strokeAttributes := ""
+	if isVisible(stroke) && style.Width != 0 {
+		if style.Inside {
+			// Only the inside half of an outline twice as wide is left by clipping it to the path.
+			id := canvas.doc.newID("clip")
+			fmt.Fprintf(&canvas.doc.body, `<clipPath id="%s"><path d="%s"/></clipPath>`+"\n", id, path.svgData())
+			style.Width *= 2
+			strokeAttributes = fmt.Sprintf(` clip-path="url(#%s)"`, id)
+		}
+		strokeAttributes = svgStroke(stroke, style) + strokeAttributes
+	}
+	fmt.Fprintf(&canvas.doc.body, `<path d="%s"%s%s/>`+"\n", path.svgData(), fillAttributes, strokeAttributes)
+	return canvas, nil
+}
+
+// Text draws a text element on the canvas if the face is a FontFace, referring to the font by its family name. Other faces are drawn as an image of the rendered glyphs.
+func (canvas SVGCanvas) Text(text string, start image.Point, typeFace font.Face, colour color.Color, maxWidth int) (Canvas, error) {
+	raster, err := canvas.raster.Text(text, start, typeFace, colour, maxWidth)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	face, isFontFace := toFontFace(typeFace)
+	if !isFontFace {
+		return canvas, canvas.doc.image(rasteriseText(text, start, typeFace, colour))
+	}
+	fill := canvas.doc.fill(colour, textBounds(text, start, typeFace))
+	fmt.Fprintf(&canvas.doc.body, `<text x="%d" y="%d" font-family="%s" font-size="%s"%s xml:space="preserve">%s</text>`+"\n",
+		start.X, start.Y, escapeXML(face.Font.Name(truetype.NameIDFontFamily)), formatNumber(face.PixelSize()), fill, escapeXML(text))
+	return canvas, nil
+}
+
+// TryText returns whether the text would fit on the canvas, and the width the text would currently use up.
+func (canvas SVGCanvas) TryText(text string, start image.Point, typeFace font.Face, colour color.Color, maxWidth int) (bool, int) {
+	return canvas.raster.TryText(text, start, typeFace, colour, maxWidth)
+}
+
+// DrawImage embeds another image in the canvas as a PNG data URI.
+func (canvas SVGCanvas) DrawImage(start image.Point, subImage image.Image) (Canvas, error) {
+	raster, err := canvas.raster.DrawImage(start, subImage)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	return canvas, canvas.doc.image(start, subImage)
+}
+
+// Barcode draws a barcode on the canvas, with each bar or module drawn as a rect element.
+func (canvas SVGCanvas) Barcode(codeType BarcodeType, content []byte, extra BarcodeExtraData, start image.Point, width, height int, dataColour color.Color, backgroundColour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Barcode(codeType, content, extra, start, width, height, dataColour, backgroundColour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	encodedBarcode, _ := encodeBarcode(codeType, content, extra, width, height)
+	if dataColour == nil {
+		dataColour = color.Black
+	}
+	if backgroundColour == nil {
+		backgroundColour = color.White
+	}
+	body := &canvas.doc.body
+	fmt.Fprintf(body, `<g transform="translate(%d %d)">`+"\n", start.X, start.Y)
+	fmt.Fprintf(body, `<rect width="%d" height="%d"%s/>`+"\n", width, height, svgFill(backgroundColour))
+	fmt.Fprintf(body, `<g%s shape-rendering="crispEdges">`+"\n", svgFill(dataColour))
+	for _, rect := range barcodeRects(encodedBarcode) {
+		fmt.Fprintf(body, `<rect x="%d" y="%d" width="%d" height="%d"/>`+"\n", rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy())
+	}
+	body.WriteString("</g>\n</g>\n")
+	return canvas, nil
+}
+
+// Layer draws on a group element, composited with the style as a whole. Blend modes other than normal are written as the CSS mix-blend-mode of the same name, the group is transformed with the style's transform, clips are written as clip paths, or masks for image clips, and blurs and shadows are written as filters.
+func (canvas SVGCanvas) Layer(style LayerStyle, draw func(Canvas) (Canvas, error)) (Canvas, error) {
+	if style.plain() {
+		return draw(canvas)
+	}
+	doc := canvas.doc
+	start := doc.body.Len()
+	var drawn Canvas
+	raster, err := canvas.raster.Layer(style, func(layer Canvas) (Canvas, error) {
+		var err error
+		drawn, err = draw(SVGCanvas{raster: layer.(ImageCanvas), doc: doc})
+		return drawn, err
+	})
+	// Move everything drawn on the layer out of the body and into the group.
+	content := append([]byte{}, doc.body.Bytes()[start:]...)
+	doc.body.Truncate(start)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	if len(content) == 0 {
+		return canvas, nil
+	}
+	var transform string
+	if style.Transform != nil {
+		m := style.Transform.matrix(contentBounds(drawn.GetUnderlyingImage()))
+		n := formatNumber
+		transform = fmt.Sprintf(` transform="matrix(%s %s %s %s %s %s)"`, n(m.a), n(m.b), n(m.c), n(m.d), n(m.e), n(m.f))
+	}
+	var clip string
+	if style.Clip != nil {
+		clip, err = doc.clip(*style.Clip)
+		if err != nil {
+			doc.body.Truncate(start)
+			return canvas, err
+		}
+	}
+	var filter string
+	if style.Blur > 0 || style.Shadow != nil {
+		filter = doc.filter(style, canvas.raster.Image.Bounds())
+	}
+	// Each group is filtered, then clipped, all in its own transformed coordinates, but the layer is to be transformed, then clipped, then filtered. Each of those needs a group of its own, nested inside the next, and the outermost group also fades and blends the layer.
+	var groups []string
+	for _, attribute := range []string{transform, clip, filter} {
+		if attribute != "" {
+			groups = append(groups, attribute)
+		}
+	}
+	if len(groups) == 0 {
+		groups = []string{""}
+	}
+	outer := len(groups) - 1
+	if opacity := math.Min(math.Max(style.Opacity, 0), 1); opacity != 1 {
+		groups[outer] += fmt.Sprintf(` opacity="%s"`, formatNumber(opacity))
+	}
+	if _, isBlended := blendFunctions[style.BlendMode]; isBlended {
+		groups[outer] += fmt.Sprintf(` style="mix-blend-mode:%s"`, style.BlendMode)
+	}
+	for i := outer; i >= 0; i-- {
+		doc.body.WriteString("<g" + groups[i] + ">\n")
+	}
+	doc.body.Write(content)
+	doc.body.WriteString(strings.Repeat("</g>\n", len(groups)))
+	return canvas, nil
+}
+
+// WriteSVG writes the canvas to an SVG document. If the canvas has a PPI, the document is given a physical size in inches.
+func (canvas SVGCanvas) WriteSVG(w io.Writer) error {
+	width, height := canvas.GetWidth(), canvas.GetHeight()
+	widthAttr, heightAttr := fmt.Sprint(width), fmt.Sprint(height)
+	if ppi := canvas.GetPPI(); ppi > 0 {
+		widthAttr, heightAttr = formatNumber(float64(width)/ppi)+"in", formatNumber(float64(height)/ppi)+"in"
+	}
+	_, err := fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" width="%s" height="%s" viewBox="0 0 %d %d">`+"\n", widthAttr, heightAttr, width, height)
+	if err != nil {
+		return err
+	}
+	if canvas.doc != nil {
+		_, err = w.Write(canvas.doc.body.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, "</svg>\n")
+	return err
+}
+
+// image embeds a snapshot of the image as a PNG data URI with its top-left corner at start, or returns the error encoding it. Fully transparent images are skipped.
+func (doc *svgDocument) image(start image.Point, img image.Image) error {
+	bounds := img.Bounds()
+	if isTransparent(img) {
+		return nil
+	}
+	var encoded bytes.Buffer
+	err := png.Encode(&encoded, img)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&doc.body, `<image x="%d" y="%d" width="%d" height="%d" xlink:href="data:image/png;base64,%s"/>`+"\n",
+		start.X, start.Y, bounds.Dx(), bounds.Dy(), base64.StdEncoding.EncodeToString(encoded.Bytes()))
+	return nil
+}
+
+// fill returns the fill attributes for a colour, first writing a gradient element stretched across the bounds if the colour is a Gradient.
+func (doc *svgDocument) fill(colour color.Color, bounds image.Rectangle) string {
+	gradient, isGradient := colour.(Gradient)
+	if !isGradient {
+		return svgFill(colour)
+	}
+	id := doc.newID("gradient")
+	geometry := gradient.geometry(bounds)
+	n := formatNumber
+	if geometry.radial {
+		// Radial gradients are circular, so squash the circle into the ellipse.
+		squash := 1.0
+		if geometry.radiusX != 0 {
+			squash = geometry.radiusY / geometry.radiusX
+		}
+		fmt.Fprintf(&doc.body, `<radialGradient id="%s" gradientUnits="userSpaceOnUse" cx="%s" cy="%s" r="%s" gradientTransform="matrix(1 0 0 %s 0 %s)">`+"\n",
+			id, n(geometry.centre.X), n(geometry.centre.Y), n(geometry.radiusX), n(squash), n(geometry.centre.Y*(1-squash)))
+	} else {
+		fmt.Fprintf(&doc.body, `<linearGradient id="%s" gradientUnits="userSpaceOnUse" x1="%s" y1="%s" x2="%s" y2="%s">`+"\n",
+			id, n(geometry.start.X), n(geometry.start.Y), n(geometry.end.X), n(geometry.end.Y))
+	}
+	for _, stop := range gradient.stops() {
+		c := stop.Colour
+		fmt.Fprintf(&doc.body, `<stop offset="%s" stop-color="#%02x%02x%02x"`, n(stop.Offset), c.R, c.G, c.B)
+		if c.A != 255 {
+			fmt.Fprintf(&doc.body, ` stop-opacity="%s"`, n(float64(c.A)/255))
+		}
+		doc.body.WriteString("/>\n")
+	}
+	if geometry.radial {
+		doc.body.WriteString("</radialGradient>\n")
+	} else {
+		doc.body.WriteString("</linearGradient>\n")
+	}
+	return fmt.Sprintf(` fill="url(#%s)"`, id)
+}
+
+// clip writes a clip path or mask element for the clip, returning the attribute applying it or the error embedding the mask.
+func (doc *svgDocument) clip(clip Clip) (string, error) {
+	if clip.Mask == nil {
+		id := doc.newID("clip")
+		fmt.Fprintf(&doc.body, `<clipPath id="%s"><path d="%s"/></clipPath>`+"\n", id, clip.Path.svgData())
+		return fmt.Sprintf(` clip-path="url(#%s)"`, id), nil
+	}
+	// Masks use luminance by default, so the mask is drawn in white with its own alpha.
+	bounds := clip.Mask.Bounds()
+	white := image.NewNRGBA(bounds)
+	draw.DrawMask(white, bounds, image.White, image.ZP, clip.Mask, bounds.Min, draw.Src)
+	id := doc.newID("mask")
+	fmt.Fprintf(&doc.body, `<mask id="%s" maskUnits="userSpaceOnUse" x="%d" y="%d" width="%d" height="%d">`+"\n", id, bounds.Min.X, bounds.Min.Y, bounds.Dx(), bounds.Dy())
+	err := doc.image(bounds.Min, white)
+	if err != nil {
+		return "", err
+	}
+	doc.body.WriteString("</mask>\n")
+	return fmt.Sprintf(` mask="url(#%s)"`, id), nil
+}
+
+// filter writes a filter element blurring and shadowing with the style across the bounds, returning the attribute applying it.
+func (doc *svgDocument) filter(style LayerStyle, bounds image.Rectangle) string {
+	id := doc.newID("filter")
+	n := formatNumber
+	fmt.Fprintf(&doc.body, `<filter id="%s" filterUnits="userSpaceOnUse" x="%d" y="%d" width="%d" height="%d" color-interpolation-filters="sRGB">`+"\n",
+		id, bounds.Min.X, bounds.Min.Y, bounds.Dx(), bounds.Dy())
+	source := "SourceGraphic"
+	if style.Blur > 0 {
+		fmt.Fprintf(&doc.body, `<feGaussianBlur stdDeviation="%s" result="blurred"/>`+"\n", n(style.Blur))
+		source = "blurred"
+	}
+	if shadow := style.Shadow; shadow != nil {
+		c := shadow.Colour
+		fmt.Fprintf(&doc.body, `<feFlood flood-color="#%02x%02x%02x" flood-opacity="%s"/>`+"\n", c.R, c.G, c.B, n(float64(c.A)/255))
+		fmt.Fprintf(&doc.body, `<feComposite in2="%s" operator="in"/>`+"\n", source)
+		fmt.Fprintf(&doc.body, `<feOffset dx="%d" dy="%d"/>`+"\n", shadow.Offset.X, shadow.Offset.Y)
+		if shadow.Blur > 0 {
+			fmt.Fprintf(&doc.body, `<feGaussianBlur stdDeviation="%s"/>`+"\n", n(shadow.Blur))
+		}
+		fmt.Fprintf(&doc.body, `<feMerge><feMergeNode/><feMergeNode in="%s"/></feMerge>`+"\n", source)
+	}
+	doc.body.WriteString("</filter>\n")
+	return fmt.Sprintf(` filter="url(#%s)"`, id)
+}
+
+// svgFill returns the fill attributes for a colour.
+func svgFill(colour color.Color) string {
+	c := toNRGBA(colour)
+	fill := fmt.Sprintf(` fill="#%02x%02x%02x"`, c.R, c.G, c.B)
+	if c.A != 255 {
+		fill += fmt.Sprintf(` fill-opacity="%s"`, formatNumber(float64(c.A)/255))
+	}
+	return fill
+}
+
+// svgStroke returns the stroke attributes for a colour and a normalised style.
+func svgStroke(colour color.Color, style StrokeStyle) string {
+	c := toNRGBA(colour)
+	stroke := fmt.Sprintf(` stroke="#%02x%02x%02x"`, c.R, c.G, c.B)
+	if c.A != 255 {
+		stroke += fmt.Sprintf(` stroke-opacity="%s"`, formatNumber(float64(c.A)/255))
+	}
+	stroke += fmt.Sprintf(` stroke-width="%s" stroke-linecap="%s" stroke-linejoin="%s"`, formatNumber(style.Width), style.Cap, style.Join)
+	if len(style.Dash) > 0 {
+		dash := make([]string, len(style.Dash))
+		for i, length := range style.Dash {
+			dash[i] = formatNumber(length)
+		}
+		stroke += fmt.Sprintf(` stroke-dasharray="%s"`, strings.Join(dash, " "))
+	}
+	return stroke
+}
+
+// svgPoints formats a list of points for a points attribute.
+func svgPoints(points []pointF) string {
+	formatted := make([]string, len(points))
+	for i, p := range points {
+		formatted[i] = formatNumber(p.X) + "," + formatNumber(p.Y)
+	}
+	return strings.Join(formatted, " ")
+}
+
+func escapeXML(text string) string {
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(text))
+	return escaped.String()
+}
@@ -0,0 +1,376 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"regexp"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// wideImage is an opaque black image wider than a PNG can be.
+type wideImage struct{}
+
+func (img wideImage) ColorModel() color.Model { return color.NRGBAModel }
+
+func (img wideImage) Bounds() image.Rectangle { return image.Rect(0, 0, int(^uint(0)>>1), 1) }
+
+func (img wideImage) At(x, y int) color.Color { return color.Black }
+
+// writeSVG writes the canvas to a string, checking the document is well-formed XML.
+func writeSVG(t *testing.T, canvas Canvas) string {
+	var data bytes.Buffer
+	err := canvas.(SVGCanvas).WriteSVG(&data)
+	assert.NoError(t, err)
+	decoder := xml.NewDecoder(bytes.NewReader(data.Bytes()))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			break
+		}
+	}
+	return data.String()
+}
+
+func TestNewSVGCanvas(t *testing.T) {
+	t.Run("invalid size", func(t *testing.T) {
+		canvas, err := NewSVGCanvas(10, -1)
+		assert.Equal(t, SVGCanvas{}, canvas)
+		assert.EqualError(t, err, "invalid height")
+	})
+	t.Run("valid size", func(t *testing.T) {
+		canvas, err := NewSVGCanvas(20, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 20, canvas.GetWidth())
+		assert.Equal(t, 10, canvas.GetHeight())
+		document := writeSVG(t, canvas)
+		assert.Contains(t, document, `width="20" height="10" viewBox="0 0 20 10"`)
+		assert.NotContains(t, document, "<image")
+	})
+	t.Run("from canvas", func(t *testing.T) {
+		base, _ := NewCanvas(8, 4)
+		drawnBase, _ := base.Rectangle(image.ZP, 8, 4, color.White)
+		canvas, err := NewSVGCanvasFrom(drawnBase.SetPPI(16))
+		assert.NoError(t, err)
+		assert.Equal(t, float64(16), canvas.GetPPI())
+		assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, canvas.GetUnderlyingImage().At(3, 3))
+		document := writeSVG(t, canvas)
+		assert.Contains(t, document, `width="0.5in" height="0.25in" viewBox="0 0 8 4"`)
+		assert.Contains(t, document, `<image x="0" y="0" width="8" height="4" xlink:href="data:image/png;base64,`)
+	})
+	t.Run("from invalid canvas", func(t *testing.T) {
+		canvas, err := NewSVGCanvasFrom(ImageCanvas{})
+		assert.Equal(t, SVGCanvas{}, canvas)
+		assert.EqualError(t, err, "invalid width and height")
+	})
+}
+
+func TestSVGCanvasDrawing(t *testing.T) {
+	ttFont, _ := truetype.Parse(goregular.TTF)
+	t.Run("rectangle", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := canvas.Rectangle(image.Pt(1, 2), 3, 4, color.NRGBA{R: 255, G: 16, A: 255})
+		assert.NoError(t, err)
+		assert.Equal(t, color.NRGBA{R: 255, G: 16, A: 255}, modifiedCanvas.GetUnderlyingImage().At(2, 3))
+		assert.Contains(t, writeSVG(t, modifiedCanvas), `<rect x="1" y="2" width="3" height="4" fill="#ff1000"/>`)
+	})
+	t.Run("invalid rectangle", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := canvas.Rectangle(image.ZP, 3, 0, color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid height")
+		assert.NotContains(t, writeSVG(t, modifiedCanvas), "<rect")
+	})
+	t.Run("circle", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := canvas.Circle(image.Pt(5, 5), 4, color.NRGBA{B: 255, A: 51})
+		assert.NoError(t, err)
+		assert.Contains(t, writeSVG(t, modifiedCanvas), `<circle cx="5" cy="5" r="4" fill="#0000ff" fill-opacity="0.2"/>`)
+	})
+	t.Run("ellipse", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(20, 10)
+		modifiedCanvas, err := canvas.Ellipse(image.Pt(10, 5), 8, 4, color.Black)
+		assert.NoError(t, err)
+		assert.Contains(t, writeSVG(t, modifiedCanvas), `<ellipse cx="10" cy="5" rx="8" ry="4" fill="#000000"/>`)
+		modifiedCanvas, err = canvas.Ellipse(image.Pt(10, 5), 0, 0, color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid x and y radius")
+	})
+	t.Run("arc", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(20, 20)
+		modifiedCanvas, err := canvas.Arc(image.Pt(10, 10), 8, 8, 0, 90, ArcChord, color.Black)
+		assert.NoError(t, err)
+		assert.Contains(t, writeSVG(t, modifiedCanvas), `<path d="M 18 10 C 18 14.4183 14.4183 18 10 18 Z" fill="#000000"/>`)
+		modifiedCanvas, err = canvas.Arc(image.Pt(10, 10), 8, 8, 90, 90, ArcChord, color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid arc angles 90 to 90")
+	})
+	t.Run("invalid circle", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := canvas.Circle(image.Pt(5, 5), -3, color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid radius")
+	})
+	t.Run("line", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := canvas.Line(image.Pt(1, 2), image.Pt(7, 2), StrokeStyle{Width: 1.5, Dash: []float64{2, 1}}, color.NRGBA{R: 255, A: 255})
+		assert.NoError(t, err)
+		assert.Contains(t, writeSVG(t, modifiedCanvas), `<polyline points="1.5,2.5 7.5,2.5" fill="none" stroke="#ff0000" stroke-width="1.5" stroke-linecap="butt" stroke-linejoin="miter" stroke-dasharray="2 1"/>`)
+	})
+	t.Run("invalid line", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := canvas.Polyline([]image.Point{{X: 1, Y: 1}}, StrokeStyle{Width: 1}, color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "a line needs at least 2 points, got 1")
+		assert.NotContains(t, writeSVG(t, modifiedCanvas), "<polyline")
+	})
+	t.Run("path", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		path, _ := ParsePath("m 1 1 h 8 l -4 7 z")
+		modifiedCanvas, err := canvas.Path(path, color.NRGBA{G: 255, A: 255}, color.NRGBA{R: 255, A: 255}, StrokeStyle{Width: 2, Join: JoinRound})
+		assert.NoError(t, err)
+		modifiedCanvas, err = modifiedCanvas.Path(path, nil, color.Black, StrokeStyle{Width: 1})
+		assert.NoError(t, err)
+		modifiedCanvas, err = modifiedCanvas.Path(path, color.Black, nil, StrokeStyle{})
+		assert.NoError(t, err)
+		svg := writeSVG(t, modifiedCanvas)
+		assert.Contains(t, svg, `<path d="M 1 1 L 9 1 L 5 8 Z" fill="#00ff00" stroke="#ff0000" stroke-width="2" stroke-linecap="butt" stroke-linejoin="round"/>`)
+		assert.Contains(t, svg, `<path d="M 1 1 L 9 1 L 5 8 Z" fill="none" stroke="#000000" stroke-width="1" stroke-linecap="butt" stroke-linejoin="miter"/>`)
+		assert.Contains(t, svg, `<path d="M 1 1 L 9 1 L 5 8 Z" fill="#000000"/>`)
+	})
+	t.Run("inside stroke", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		square := NewRectanglePath(image.Pt(1, 1), 8, 8, CornerRadii{})
+		modifiedCanvas, err := canvas.Path(square, color.White, color.Black, StrokeStyle{Width: 2, Inside: true})
+		assert.NoError(t, err)
+		modifiedCanvas, err = modifiedCanvas.Path(square, nil, color.Black, StrokeStyle{Width: 1, Inside: true})
+		assert.NoError(t, err)
+		svg := writeSVG(t, modifiedCanvas)
+		assert.Contains(t, svg, `<clipPath id="clip1"><path d="M 1 1 L 9 1 L 9 9 L 1 9 L 1 1 Z"/></clipPath>`+"\n"+`<path d="M 1 1 L 9 1 L 9 9 L 1 9 L 1 1 Z" fill="#ffffff" stroke="#000000" stroke-width="4" stroke-linecap="butt" stroke-linejoin="miter" clip-path="url(#clip1)"/>`)
+		assert.Contains(t, svg, `<clipPath id="clip2">`)
+	})
+	t.Run("invalid path", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := canvas.Path(NewPolygonPath([]image.Point{{X: 1, Y: 1}, {X: 5, Y: 5}}), color.Black, color.Black, StrokeStyle{Width: 1, Join: "sharp"})
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "unsupported line join: sharp")
+		assert.NotContains(t, writeSVG(t, modifiedCanvas), "<path")
+	})
+	t.Run("gradients", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(20, 10)
+		linear := Gradient{Stops: []GradientStop{{Offset: 0, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 1, Colour: color.NRGBA{B: 255, A: 128}}}}
+		radial := Gradient{Type: GradientRadial, Stops: []GradientStop{{Offset: 0.25, Colour: color.NRGBA{R: 255, A: 255}}, {Offset: 0.5, Colour: color.NRGBA{G: 255, A: 255}}, {Offset: 0.4, Colour: color.NRGBA{B: 255, A: 255}}}}
+		modifiedCanvas, err := canvas.Rectangle(image.ZP, 20, 10, linear)
+		assert.NoError(t, err)
+		modifiedCanvas, err = modifiedCanvas.Ellipse(image.Pt(10, 5), 8, 4, radial)
+		assert.NoError(t, err)
+		document := writeSVG(t, modifiedCanvas)
+		assert.Contains(t, document, `<linearGradient id="gradient1" gradientUnits="userSpaceOnUse" x1="0" y1="5" x2="20" y2="5">
+<stop offset="0" stop-color="#ff0000"/>
+<stop offset="1" stop-color="#0000ff" stop-opacity="0.502"/>
+</linearGradient>
+<rect x="0" y="0" width="20" height="10" fill="url(#gradient1)"/>`)
+		assert.Contains(t, document, `<radialGradient id="gradient2" gradientUnits="userSpaceOnUse" cx="10" cy="5" r="11.3137" gradientTransform="matrix(1 0 0 0.5 0 2.5)">
+<stop offset="0.25" stop-color="#ff0000"/>
+<stop offset="0.5" stop-color="#00ff00"/>
+<stop offset="0.5" stop-color="#0000ff"/>
+</radialGradient>
+<ellipse cx="10" cy="5" rx="8" ry="4" fill="url(#gradient2)"/>`)
+	})
+	t.Run("text", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(80, 30)
+		face := NewFontFace(ttFont, &truetype.Options{Size: 10, DPI: 144})
+		modifiedCanvas, err := canvas.Text("a < b", image.Pt(2, 20), face, color.Black, 80)
+		assert.NoError(t, err)
+		document := writeSVG(t, modifiedCanvas)
+		assert.Contains(t, document, `<text x="2" y="20" font-family="Go" font-size="20" fill="#000000" xml:space="preserve">a &lt; b</text>`)
+		assert.NotContains(t, document, "<image")
+	})
+	t.Run("rasterised text", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(60, 30)
+		face := truetype.NewFace(ttFont, &truetype.Options{Size: 10, Hinting: font.HintingFull})
+		modifiedCanvas, err := canvas.Text("tet", image.Pt(2, 20), face, color.Black, 60)
+		assert.NoError(t, err)
+		document := writeSVG(t, modifiedCanvas)
+		assert.NotContains(t, document, "<text")
+		assert.Contains(t, document, "<image")
+	})
+	t.Run("invalid text", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(60, 30)
+		face := NewFontFace(ttFont, &truetype.Options{Size: 10})
+		modifiedCanvas, err := canvas.Text("tet", image.Pt(2, 20), face, color.Black, 0)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid maxWidth")
+		fits, width := canvas.TryText("tet", image.Pt(2, 20), face, color.Black, 30)
+		assert.True(t, fits)
+		assert.Equal(t, 12, width)
+	})
+	t.Run("barcode", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(80, 30)
+		modifiedCanvas, err := canvas.Barcode(BarcodeTypeCode128, []byte("12"), BarcodeExtraData{}, image.Pt(1, 1), 78, 10, color.NRGBA{R: 255, A: 255}, nil)
+		assert.NoError(t, err)
+		document := writeSVG(t, modifiedCanvas)
+		assert.Contains(t, document, "<g transform=\"translate(1 1)\">\n<rect width=\"78\" height=\"10\" fill=\"#ffffff\"/>\n<g fill=\"#ff0000\" shape-rendering=\"crispEdges\">\n")
+		assert.Len(t, regexp.MustCompile(`<rect x="\d+" y="0" width="\d" height="10"/>`).FindAllString(document, -1), 16)
+	})
+	t.Run("invalid barcode", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(30, 30)
+		modifiedCanvas, err := canvas.Barcode(BarcodeTypeCode128, []byte("12"), BarcodeExtraData{}, image.Pt(1, 1), 28, 10, nil, nil)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "can not scale barcode to an image smaller than 57x1")
+	})
+	t.Run("image", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		img := image.NewNRGBA(image.Rect(0, 0, 2, 3))
+		img.Set(1, 1, color.NRGBA{G: 255, A: 100})
+		modifiedCanvas, err := canvas.DrawImage(image.Pt(4, 5), img)
+		assert.NoError(t, err)
+		document := writeSVG(t, modifiedCanvas)
+		match := regexp.MustCompile(`<image x="4" y="5" width="2" height="3" xlink:href="data:image/png;base64,([^"]+)"/>`).FindStringSubmatch(document)
+		if assert.Len(t, match, 2) {
+			raw, err := base64.StdEncoding.DecodeString(match[1])
+			assert.NoError(t, err)
+			decoded, err := png.Decode(bytes.NewReader(raw))
+			assert.NoError(t, err)
+			assert.Equal(t, color.NRGBA{G: 255, A: 100}, decoded.At(1, 1))
+		}
+	})
+	t.Run("image too wide", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		_, err := canvas.DrawImage(image.ZP, wideImage{})
+		assert.EqualError(t, err, fmt.Sprintf("png: invalid format: invalid image size: %dx1", int(^uint(0)>>1)))
+		assert.NotContains(t, writeSVG(t, canvas), "<image")
+	})
+	t.Run("set underlying image", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		drawnCanvas, _ := canvas.Rectangle(image.ZP, 3, 3, color.Black)
+		newImage := image.NewNRGBA(image.Rect(0, 0, 5, 5))
+		newImage.Set(1, 1, color.Black)
+		modifiedCanvas := drawnCanvas.SetUnderlyingImage(newImage)
+		assert.Equal(t, 5, modifiedCanvas.GetWidth())
+		document := writeSVG(t, modifiedCanvas)
+		assert.NotContains(t, document, "<rect")
+		assert.Contains(t, document, `<image x="0" y="0" width="5" height="5"`)
+	})
+	t.Run("layer", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 0.5, BlendMode: BlendScreen}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 2), 3, 4, color.Black)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, color.NRGBA{A: 128}, modifiedCanvas.GetUnderlyingImage().At(2, 3))
+		assert.Contains(t, writeSVG(t, modifiedCanvas), `<g opacity="0.5" style="mix-blend-mode:screen">`+"\n"+`<rect x="1" y="2" width="3" height="4" fill="#000000"/>`+"\n</g>\n")
+		canvas, _ = NewSVGCanvas(10, 10)
+		modifiedCanvas, err = canvas.Layer(LayerStyle{Opacity: 1, BlendMode: BlendOverlay}, func(layer Canvas) (Canvas, error) {
+			return layer, nil
+		})
+		assert.NoError(t, err)
+		assert.NotContains(t, writeSVG(t, modifiedCanvas), "<g", "empty layers should be left out")
+	})
+	t.Run("transformed layer", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 0.5, Transform: &Transform{ScaleX: 2, ScaleY: 1, SkewY: 45, Anchor: &image.Point{X: 1, Y: 2}}}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 2), 3, 4, color.Black)
+		})
+		assert.NoError(t, err)
+		assert.Contains(t, writeSVG(t, modifiedCanvas), `<g transform="matrix(2 2 0 1 -1 -2)" opacity="0.5">`)
+	})
+	t.Run("clipped layer", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Clip: &Clip{Path: NewPolygonPath([]image.Point{{X: 1, Y: 1}, {X: 3, Y: 1}, {X: 3, Y: 3}})}}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 1), 4, 4, color.Black)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, color.NRGBA{}, modifiedCanvas.GetUnderlyingImage().At(4, 4))
+		assert.Contains(t, writeSVG(t, modifiedCanvas), `<clipPath id="clip1"><path d="M 1 1 L 3 1 L 3 3 Z"/></clipPath>`+"\n"+`<g clip-path="url(#clip1)">`+"\n"+`<rect x="1" y="1" width="4" height="4" fill="#000000"/>`+"\n</g>\n")
+	})
+	t.Run("clipped and transformed layer", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 0.5, Transform: &Transform{Rotation: 180, ScaleX: 1, ScaleY: 1}, Clip: &Clip{Path: NewEllipsePath(image.Pt(3, 3), 2, 2)}}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 1), 4, 4, color.Black)
+		})
+		assert.NoError(t, err)
+		assert.Contains(t, writeSVG(t, modifiedCanvas), `<g clip-path="url(#clip1)" opacity="0.5">`+"\n"+`<g transform="matrix(-1 0 0 -1 6 6)">`+"\n"+`<rect x="1" y="1" width="4" height="4" fill="#000000"/>`+"\n</g>\n</g>\n", "the clip should not move with the layer")
+	})
+	t.Run("masked layer", func(t *testing.T) {
+		mask := image.NewAlpha(image.Rect(1, 1, 3, 2))
+		mask.SetAlpha(1, 1, color.Alpha{A: 255})
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Clip: &Clip{Mask: mask}}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 1), 4, 4, color.Black)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, color.NRGBA{A: 255}, modifiedCanvas.GetUnderlyingImage().At(1, 1))
+		assert.Equal(t, color.NRGBA{}, modifiedCanvas.GetUnderlyingImage().At(2, 1))
+		document := writeSVG(t, modifiedCanvas)
+		assert.Contains(t, document, `<mask id="mask1" maskUnits="userSpaceOnUse" x="1" y="1" width="2" height="1">`+"\n"+`<image x="1" y="1" width="2" height="1"`)
+		assert.Contains(t, document, "</mask>\n"+`<g mask="url(#mask1)">`)
+	})
+	t.Run("filtered layer", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		style := LayerStyle{Opacity: 0.5, Transform: &Transform{Rotation: 180, ScaleX: 1, ScaleY: 1}, Clip: &Clip{Path: NewEllipsePath(image.Pt(3, 3), 2, 2)}, Blur: 1.5, Shadow: &Shadow{Offset: image.Pt(2, 3), Blur: 2, Colour: color.NRGBA{R: 255, A: 51}}}
+		modifiedCanvas, err := canvas.Layer(style, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 1), 4, 4, color.Black)
+		})
+		assert.NoError(t, err)
+		document := writeSVG(t, modifiedCanvas)
+		assert.Contains(t, document, `<filter id="filter2" filterUnits="userSpaceOnUse" x="0" y="0" width="10" height="10" color-interpolation-filters="sRGB">`+"\n"+
+			`<feGaussianBlur stdDeviation="1.5" result="blurred"/>`+"\n"+
+			`<feFlood flood-color="#ff0000" flood-opacity="0.2"/>`+"\n"+
+			`<feComposite in2="blurred" operator="in"/>`+"\n"+
+			`<feOffset dx="2" dy="3"/>`+"\n"+
+			`<feGaussianBlur stdDeviation="2"/>`+"\n"+
+			`<feMerge><feMergeNode/><feMergeNode in="blurred"/></feMerge>`+"\n"+
+			"</filter>\n")
+		assert.Contains(t, document, `<g filter="url(#filter2)" opacity="0.5">`+"\n"+`<g clip-path="url(#clip1)">`+"\n"+`<g transform="matrix(-1 0 0 -1 6 6)">`+"\n"+`<rect`)
+		assert.Contains(t, document, "</g>\n</g>\n</g>\n</svg>")
+	})
+	t.Run("shadowed layer", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Shadow: &Shadow{Colour: color.NRGBA{A: 255}}}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 1), 4, 4, color.Black)
+		})
+		assert.NoError(t, err)
+		document := writeSVG(t, modifiedCanvas)
+		assert.Contains(t, document, `<feComposite in2="SourceGraphic" operator="in"/>`+"\n"+`<feOffset dx="0" dy="0"/>`+"\n"+`<feMerge>`)
+		assert.Contains(t, document, `<g filter="url(#filter1)">`)
+	})
+	t.Run("plain layer", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, BlendMode: BlendNormal}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(1, 2), 3, 4, color.Black)
+		})
+		assert.NoError(t, err)
+		document := writeSVG(t, modifiedCanvas)
+		assert.Contains(t, document, `<rect x="1" y="2" width="3" height="4" fill="#000000"/>`)
+		assert.NotContains(t, document, "<g")
+	})
+	t.Run("invalid layer", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 0.5}, func(layer Canvas) (Canvas, error) {
+			drawn, _ := layer.Rectangle(image.Pt(1, 2), 3, 4, color.Black)
+			return drawn.Circle(image.Pt(5, 5), 0, color.Black)
+		})
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid radius")
+		assert.NotContains(t, writeSVG(t, modifiedCanvas), "<rect")
+	})
+	t.Run("writer error", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		err := canvas.WriteSVG(failingWriter{})
+		assert.EqualError(t, err, "disk full")
+	})
+}
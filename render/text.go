@@ -1,15 +1,86 @@
 package render
 
 import (
+	"bytes"
 	"errors"
 	"image"
 	"image/color"
+	"math"
+	"runtime"
+	"sync"
+	"unsafe"
 
+	"github.com/golang/freetype/truetype"
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/fixed"
 )
 
-// Text draws text on the canvas.
+// FontFace is a font.Face which keeps the TrueType font and options it was created from, allowing vector canvases to embed the font itself rather than rasterised glyphs.
+type FontFace struct {
+	font.Face
+	// Font is the TrueType font the face was created from.
+	Font *truetype.Font
+	// Options are the options the face was created with.
+	Options truetype.Options
+}
+
+// NewFontFace creates a new FontFace from a TrueType font and the options to render it with.
+func NewFontFace(ttf *truetype.Font, opts *truetype.Options) FontFace {
+	face := FontFace{Face: truetype.NewFace(ttf, opts), Font: ttf}
+	if opts != nil {
+		face.Options = *opts
+	}
+	return face
+}
+
+// fontPrograms are the TrueType programs fonts were parsed from by ParseFont, keyed by the address of the font so that the map doesn't keep the font alive.
+var fontPrograms = struct {
+	sync.Mutex
+	data map[uintptr][]byte
+}{data: map[uintptr][]byte{}}
+
+// ParseFont parses a TrueType font, keeping the data it was parsed from so that PDF documents can embed the font itself.
+func ParseFont(data []byte) (*truetype.Font, error) {
+	ttf, err := truetype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte("ttcf")) {
+		// PDF documents can only embed a single font, not a collection.
+		return ttf, nil
+	}
+	key := uintptr(unsafe.Pointer(ttf))
+	fontPrograms.Lock()
+	fontPrograms.data[key] = data
+	fontPrograms.Unlock()
+	runtime.SetFinalizer(ttf, func(*truetype.Font) {
+		fontPrograms.Lock()
+		delete(fontPrograms.data, key)
+		fontPrograms.Unlock()
+	})
+	return ttf, nil
+}
+
+// fontProgram returns the data the font was parsed from by ParseFont, or nil if it wasn't.
+func fontProgram(ttf *truetype.Font) []byte {
+	fontPrograms.Lock()
+	defer fontPrograms.Unlock()
+	return fontPrograms.data[uintptr(unsafe.Pointer(ttf))]
+}
+
+// PixelSize returns the height of an em in pixels, applying the same defaults as truetype.NewFace.
+func (face FontFace) PixelSize() float64 {
+	size, dpi := face.Options.Size, face.Options.DPI
+	if size == 0 {
+		size = 12
+	}
+	if dpi == 0 {
+		dpi = 72
+	}
+	return size * dpi / 72
+}
+
+// Text draws text on the canvas. The colour may be a Gradient, which is stretched across the bounding box of the glyphs.
 func (canvas ImageCanvas) Text(text string, start image.Point, typeFace font.Face, colour color.Color, maxWidth int) (Canvas, error) {
 	if maxWidth <= 0 {
 		return canvas, errors.New("invalid maxWidth")
@@ -22,7 +93,7 @@ func (canvas ImageCanvas) Text(text string, start image.Point, typeFace font.Fac
 		Dot:  fixed.Point26_6{X: fixed.I(start.X), Y: fixed.I(start.Y)},
 		Dst:  c.Image,
 		Face: typeFace,
-		Src:  image.NewUniform(colour),
+		Src:  paint(colour, textBounds(text, start, typeFace)),
 	}
 	width := drawer.MeasureString(text).Ceil()
 	if width > maxWidth {
@@ -49,3 +120,102 @@ func (canvas ImageCanvas) TryText(text string, start image.Point, typeFace font.
 	width := drawer.MeasureString(text).Ceil()
 	return width <= maxWidth, width
 }
+
+// textBounds returns the bounding box of the glyphs of text drawn with its dot at start.
+func textBounds(text string, start image.Point, typeFace font.Face) image.Rectangle {
+	bounds, _ := font.BoundString(typeFace, text)
+	return image.Rect(bounds.Min.X.Floor(), bounds.Min.Y.Floor(), bounds.Max.X.Ceil(), bounds.Max.Y.Ceil()).Add(start)
+}
+
+// TextPath returns the outlines of the glyphs of the text where Text would draw them with the face, with the baseline starting at start, so that the text can be outlined.
+func TextPath(text string, start image.Point, face FontFace) Path {
+	scale := fixed.Int26_6(math.Round(face.PixelSize() * 64))
+	glyph := &truetype.GlyphBuf{}
+	var path Path
+	dot := fixed.I(start.X)
+	previous := rune(-1)
+	for _, char := range text {
+		if previous >= 0 {
+			dot += face.Kern(previous, char)
+		}
+		previous = char
+		if err := glyph.Load(face.Font, scale, face.Font.Index(char), face.Options.Hinting); err == nil {
+			// Glyph outlines are in pixels with the Y axis pointing up from the dot.
+			origin := pointF{X: float64(dot) / 64, Y: float64(start.Y)}
+			place := func(p pointF) pointF {
+				return pointF{X: origin.X + p.X, Y: origin.Y - p.Y}
+			}
+			glyphContours(glyph,
+				func(p pointF) {
+					path.segments = append(path.segments, pathSegment{op: pathMove, points: []pointF{place(p)}})
+				},
+				func(p pointF) {
+					path.segments = append(path.segments, pathSegment{op: pathLine, points: []pointF{place(p)}})
+				},
+				func(control, end pointF) {
+					path.segments = append(path.segments, pathSegment{op: pathQuad, points: []pointF{place(control), place(end)}})
+				},
+				func() {
+					path.segments = append(path.segments, pathSegment{op: pathClose})
+				})
+		}
+		advance, _ := face.GlyphAdvance(char)
+		dot += advance
+	}
+	return path
+}
+
+// glyphContours walks each contour of a TrueType glyph outline in turn, starting it with move and ending it with close, with the outline in pixels and the Y axis pointing up. On-curve points implied between two off-curve points are filled in, so every curve is a single quadratic.
+func glyphContours(glyph *truetype.GlyphBuf, move, line func(p pointF), quad func(control, end pointF), close func()) {
+	point := func(p truetype.Point) pointF {
+		return pointF{X: float64(p.X) / 64, Y: float64(p.Y) / 64}
+	}
+	start := 0
+	for _, end := range glyph.Ends {
+		contour := glyph.Points[start:end]
+		start = end
+		if len(contour) == 0 {
+			continue
+		}
+		// Find an on-curve point to start from, synthesising one between two off-curve points if necessary.
+		first := -1
+		for i, p := range contour {
+			if p.Flags&0x01 != 0 {
+				first = i
+				break
+			}
+		}
+		var origin pointF
+		if first >= 0 {
+			origin = point(contour[first])
+			contour = append(append([]truetype.Point{}, contour[first+1:]...), contour[:first+1]...)
+		} else {
+			a, b := point(contour[0]), point(contour[len(contour)-1])
+			origin = pointF{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+			contour = append(contour[:len(contour):len(contour)], contour[0])
+		}
+		move(origin)
+		offCurve := false
+		var control pointF
+		for _, p := range contour {
+			current := point(p)
+			switch {
+			case p.Flags&0x01 != 0 && offCurve:
+				quad(control, current)
+				offCurve = false
+			case p.Flags&0x01 != 0:
+				line(current)
+			case offCurve:
+				quad(control, pointF{X: (control.X + current.X) / 2, Y: (control.Y + current.Y) / 2})
+				control = current
+			default:
+				control = current
+				offCurve = true
+			}
+		}
+		if offCurve {
+			quad(control, origin)
+		}
+		close()
+	}
+}
@@ -0,0 +1,90 @@
+package render
+
+import (
+	"image"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// Transform is an affine transformation of a layer about an anchor point. The layer is scaled first, then skewed, then rotated, as with the CSS transform "rotate() skew() scale()", and finally moved by the offset.
+type Transform struct {
+	// Rotation is the clockwise rotation in degrees.
+	Rotation float64
+	// ScaleX and ScaleY scale the layer horizontally and vertically, where 1 leaves it unchanged and a negative scale flips it.
+	ScaleX, ScaleY float64
+	// SkewX and SkewY are the angles in degrees to skew the layer along the horizontal and vertical axes.
+	SkewX, SkewY float64
+	// Anchor is the point on the canvas the layer is transformed about, which stays where it is. If nil, the centre of the bounding box of everything drawn on the layer is used.
+	Anchor *image.Point
+	// Offset moves the layer after it has been transformed, in pixels.
+	Offset image.Point
+}
+
+// matrix returns the transformation as a matrix, with content being the bounds of everything drawn on the layer.
+func (transform Transform) matrix(content image.Rectangle) affine {
+	anchor := pointF{X: float64(content.Min.X+content.Max.X) / 2, Y: float64(content.Min.Y+content.Max.Y) / 2}
+	if transform.Anchor != nil {
+		anchor = pointF{X: float64(transform.Anchor.X), Y: float64(transform.Anchor.Y)}
+	}
+	radians := func(degrees float64) float64 {
+		return degrees * math.Pi / 180
+	}
+	sin, cos := math.Sincos(radians(transform.Rotation))
+	return affine{a: 1, d: 1, e: -anchor.X, f: -anchor.Y}.
+		then(affine{a: transform.ScaleX, d: transform.ScaleY}).
+		then(affine{a: 1, b: math.Tan(radians(transform.SkewY)), c: math.Tan(radians(transform.SkewX)), d: 1}).
+		then(affine{a: cos, b: sin, c: -sin, d: cos}).
+		then(affine{a: 1, d: 1, e: anchor.X + float64(transform.Offset.X), f: anchor.Y + float64(transform.Offset.Y)})
+}
+
+// affine is an affine transformation mapping the point (x, y) to (a*x + c*y + e, b*x + d*y + f), as in SVG and PDF.
+type affine struct {
+	a, b, c, d, e, f float64
+}
+
+// then returns the transformation applying this one followed by the next.
+func (m affine) then(next affine) affine {
+	return affine{
+		a: next.a*m.a + next.c*m.b,
+		b: next.b*m.a + next.d*m.b,
+		c: next.a*m.c + next.c*m.d,
+		d: next.b*m.c + next.d*m.d,
+		e: next.a*m.e + next.c*m.f + next.e,
+		f: next.b*m.e + next.d*m.f + next.f,
+	}
+}
+
+// rightAngled returns whether the transformation only moves whole pixels around, by whole pixel offsets and rotations or flips in right angles, so that it can be applied without resampling.
+func (m affine) rightAngled() bool {
+	for _, value := range []float64{m.a, m.b, m.c, m.d, m.e, m.f} {
+		if math.Abs(value-math.Round(value)) > 1e-9 {
+			return false
+		}
+	}
+	return math.Abs(math.Round(m.a))+math.Abs(math.Round(m.b)) == 1 && math.Abs(math.Round(m.c))+math.Abs(math.Round(m.d)) == 1
+}
+
+// translation returns the whole pixel offset of the transformation, if that is all it does.
+func (m affine) translation() (image.Point, bool) {
+	if !m.rightAngled() || math.Round(m.a) != 1 || math.Round(m.d) != 1 {
+		return image.Point{}, false
+	}
+	return image.Pt(int(math.Round(m.e)), int(math.Round(m.f))), true
+}
+
+// transformImage draws the image through the transformation onto a transparent image with the given bounds, smoothing its edges unless the transformation is right angled.
+func transformImage(img image.Image, m affine, bounds image.Rectangle) *image.RGBA {
+	transformed := image.NewRGBA(bounds)
+	if m.a*m.d-m.b*m.c == 0 {
+		// The layer has been squashed flat, leaving nothing to draw.
+		return transformed
+	}
+	var interpolator xdraw.Interpolator = xdraw.BiLinear
+	if m.rightAngled() {
+		interpolator = xdraw.NearestNeighbor
+	}
+	interpolator.Transform(transformed, f64.Aff3{m.a, m.c, m.e, m.b, m.d, m.f}, img, img.Bounds(), xdraw.Src, nil)
+	return transformed
+}
@@ -0,0 +1,79 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformMatrix(t *testing.T) {
+	round := func(m affine) [6]string {
+		return [6]string{formatNumber(m.a), formatNumber(m.b), formatNumber(m.c), formatNumber(m.d), formatNumber(m.e), formatNumber(m.f)}
+	}
+	content := image.Rect(0, 0, 10, 4)
+	t.Run("identity", func(t *testing.T) {
+		assert.Equal(t, [6]string{"1", "0", "0", "1", "0", "0"}, round(Transform{ScaleX: 1, ScaleY: 1}.matrix(content)))
+	})
+	t.Run("rotate about the centre", func(t *testing.T) {
+		m := Transform{Rotation: 90, ScaleX: 1, ScaleY: 1}.matrix(content)
+		assert.Equal(t, [6]string{"0", "1", "-1", "0", "7", "-3"}, round(m))
+		assert.True(t, m.rightAngled())
+	})
+	t.Run("rotate about an anchor", func(t *testing.T) {
+		m := Transform{Rotation: 45, ScaleX: 1, ScaleY: 1, Anchor: &image.Point{X: 0, Y: 0}}.matrix(content)
+		assert.Equal(t, [6]string{"0.7071", "0.7071", "-0.7071", "0.7071", "0", "0"}, round(m))
+		assert.False(t, m.rightAngled())
+	})
+	t.Run("offset", func(t *testing.T) {
+		m := Transform{ScaleX: 1, ScaleY: 1, Offset: image.Pt(3, -2)}.matrix(content)
+		assert.Equal(t, [6]string{"1", "0", "0", "1", "3", "-2"}, round(m))
+		offset, isTranslation := m.translation()
+		assert.Equal(t, image.Pt(3, -2), offset)
+		assert.True(t, isTranslation)
+		_, isTranslation = Transform{Rotation: 90, ScaleX: 1, ScaleY: 1, Offset: image.Pt(3, -2)}.matrix(content).translation()
+		assert.False(t, isTranslation)
+		_, isTranslation = Transform{ScaleX: -1, ScaleY: -1}.matrix(content).translation()
+		assert.False(t, isTranslation)
+	})
+	t.Run("scale then skew then rotate", func(t *testing.T) {
+		m := Transform{Rotation: 90, ScaleX: 2, ScaleY: 3, SkewX: 45, Anchor: &image.Point{}}.matrix(content)
+		// Scaling (1, 1) gives (2, 3), skewing gives (5, 3) and rotating gives (-3, 5).
+		assert.Equal(t, [6]string{"0", "2", "-3", "3", "0", "0"}, round(m))
+	})
+}
+
+func TestImageCanvasTransformedLayer(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	drawBar := func(canvas Canvas) (Canvas, error) {
+		return canvas.Rectangle(image.ZP, 2, 1, red)
+	}
+	t.Run("right angle", func(t *testing.T) {
+		canvas, _ := NewCanvas(4, 4)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Transform: &Transform{Rotation: 90, ScaleX: 1, ScaleY: 1, Anchor: &image.Point{X: 2, Y: 2}}}, drawBar)
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, color.NRGBA{}, img.At(0, 0))
+		assert.Equal(t, red, img.At(3, 0), "right angled transforms should keep pixels crisp")
+		assert.Equal(t, red, img.At(3, 1))
+		assert.Equal(t, color.NRGBA{}, img.At(3, 2))
+	})
+	t.Run("scaled", func(t *testing.T) {
+		canvas, _ := NewCanvas(4, 4)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Transform: &Transform{ScaleX: 2, ScaleY: 2, Anchor: &image.Point{}}}, drawBar)
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, red, img.At(1, 0))
+		assert.Equal(t, red, img.At(2, 0))
+		assert.Equal(t, uint8(255), toNRGBA(img.At(1, 1)).R)
+		assert.True(t, toNRGBA(img.At(1, 1)).A < 255, "edges should be smoothed")
+		assert.Equal(t, color.NRGBA{}, img.At(1, 3))
+	})
+	t.Run("flattened", func(t *testing.T) {
+		canvas, _ := NewCanvas(4, 4)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Transform: &Transform{ScaleX: 0, ScaleY: 1}}, drawBar)
+		assert.NoError(t, err)
+		assert.True(t, isTransparent(modifiedCanvas.GetUnderlyingImage()))
+	})
+}
@@ -0,0 +1,167 @@
+package render
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/image/font"
+)
+
+/*
+Translate calls draw with a canvas which draws everything on this canvas moved by the offset, so
+that drawing at the origin draws at the offset, then returns this canvas with the result. Unlike
+moving a layer with a transform, nothing drawn off the edges of the canvas is lost before it is
+moved, and vector canvases keep everything drawn as vector shapes.
+*/
+func Translate(canvas Canvas, offset image.Point, draw func(Canvas) (Canvas, error)) (Canvas, error) {
+	drawn, err := draw(translatedCanvas{canvas: canvas, offset: offset})
+	if err != nil {
+		return canvas, err
+	}
+	if translated, isTranslated := drawn.(translatedCanvas); isTranslated {
+		return translated.canvas, nil
+	}
+	return drawn, nil
+}
+
+// translatedCanvas draws on another canvas with everything moved by the offset.
+type translatedCanvas struct {
+	canvas Canvas
+	offset image.Point
+}
+
+// wrap returns the canvas drawn on by a method of the translated canvas, translated in the same way, or the error.
+func (canvas translatedCanvas) wrap(drawn Canvas, err error) (Canvas, error) {
+	if err != nil {
+		return canvas, err
+	}
+	canvas.canvas = drawn
+	return canvas, nil
+}
+
+// SetUnderlyingImage sets the image of the canvas beneath, which is not moved.
+func (canvas translatedCanvas) SetUnderlyingImage(newImage image.Image) Canvas {
+	canvas.canvas = canvas.canvas.SetUnderlyingImage(newImage)
+	return canvas
+}
+
+// GetUnderlyingImage returns the image of the canvas beneath, which is not moved.
+func (canvas translatedCanvas) GetUnderlyingImage() image.Image {
+	return canvas.canvas.GetUnderlyingImage()
+}
+
+// GetWidth returns the width of the canvas beneath.
+func (canvas translatedCanvas) GetWidth() int {
+	return canvas.canvas.GetWidth()
+}
+
+// GetHeight returns the height of the canvas beneath.
+func (canvas translatedCanvas) GetHeight() int {
+	return canvas.canvas.GetHeight()
+}
+
+// GetPPI returns the pixels per inch of the canvas beneath.
+func (canvas translatedCanvas) GetPPI() float64 {
+	return canvas.canvas.GetPPI()
+}
+
+// SetPPI sets the pixels per inch of the canvas beneath.
+func (canvas translatedCanvas) SetPPI(ppi float64) Canvas {
+	canvas.canvas = canvas.canvas.SetPPI(ppi)
+	return canvas
+}
+
+// Rectangle draws a rectangle moved by the offset.
+func (canvas translatedCanvas) Rectangle(topLeft image.Point, width, height int, colour color.Color) (Canvas, error) {
+	return canvas.wrap(canvas.canvas.Rectangle(topLeft.Add(canvas.offset), width, height, colour))
+}
+
+// Circle draws a circle moved by the offset.
+func (canvas translatedCanvas) Circle(centre image.Point, radius int, colour color.Color) (Canvas, error) {
+	return canvas.wrap(canvas.canvas.Circle(centre.Add(canvas.offset), radius, colour))
+}
+
+// Ellipse draws an ellipse moved by the offset.
+func (canvas translatedCanvas) Ellipse(centre image.Point, radiusX, radiusY int, colour color.Color) (Canvas, error) {
+	return canvas.wrap(canvas.canvas.Ellipse(centre.Add(canvas.offset), radiusX, radiusY, colour))
+}
+
+// Arc draws an arc moved by the offset.
+func (canvas translatedCanvas) Arc(centre image.Point, radiusX, radiusY int, startAngle, endAngle float64, style ArcStyle, colour color.Color) (Canvas, error) {
+	return canvas.wrap(canvas.canvas.Arc(centre.Add(canvas.offset), radiusX, radiusY, startAngle, endAngle, style, colour))
+}
+
+// Line draws a line moved by the offset.
+func (canvas translatedCanvas) Line(start, end image.Point, style StrokeStyle, colour color.Color) (Canvas, error) {
+	return canvas.wrap(canvas.canvas.Line(start.Add(canvas.offset), end.Add(canvas.offset), style, colour))
+}
+
+// Polyline draws a polyline moved by the offset.
+func (canvas translatedCanvas) Polyline(points []image.Point, style StrokeStyle, colour color.Color) (Canvas, error) {
+	moved := make([]image.Point, len(points))
+	for i, p := range points {
+		moved[i] = p.Add(canvas.offset)
+	}
+	return canvas.wrap(canvas.canvas.Polyline(moved, style, colour))
+}
+
+// Path draws a path moved by the offset.
+func (canvas translatedCanvas) Path(path Path, fill, stroke color.Color, style StrokeStyle) (Canvas, error) {
+	return canvas.wrap(canvas.canvas.Path(path.translated(canvas.offset), fill, stroke, style))
+}
+
+// Text draws text moved by the offset.
+func (canvas translatedCanvas) Text(text string, start image.Point, typeFace font.Face, colour color.Color, maxWidth int) (Canvas, error) {
+	return canvas.wrap(canvas.canvas.Text(text, start.Add(canvas.offset), typeFace, colour, maxWidth))
+}
+
+// TryText tries to fit text moved by the offset.
+func (canvas translatedCanvas) TryText(text string, start image.Point, typeFace font.Face, colour color.Color, maxWidth int) (bool, int) {
+	return canvas.canvas.TryText(text, start.Add(canvas.offset), typeFace, colour, maxWidth)
+}
+
+// DrawImage draws an image moved by the offset.
+func (canvas translatedCanvas) DrawImage(start image.Point, subImage image.Image) (Canvas, error) {
+	return canvas.wrap(canvas.canvas.DrawImage(start.Add(canvas.offset), subImage))
+}
+
+// Barcode draws a barcode moved by the offset.
+func (canvas translatedCanvas) Barcode(codeType BarcodeType, content []byte, extra BarcodeExtraData, start image.Point, width, height int, dataColour color.Color, bgColour color.Color) (Canvas, error) {
+	return canvas.wrap(canvas.canvas.Barcode(codeType, content, extra, start.Add(canvas.offset), width, height, dataColour, bgColour))
+}
+
+// Layer draws a layer on the canvas beneath, with the anchor of its transform and its clip moved by the offset along with everything drawn on it.
+func (canvas translatedCanvas) Layer(style LayerStyle, draw func(Canvas) (Canvas, error)) (Canvas, error) {
+	if style.Transform != nil && style.Transform.Anchor != nil {
+		transform := *style.Transform
+		anchor := transform.Anchor.Add(canvas.offset)
+		transform.Anchor = &anchor
+		style.Transform = &transform
+	}
+	if style.Clip != nil {
+		clip := Clip{Path: style.Clip.Path.translated(canvas.offset)}
+		if style.Clip.Mask != nil {
+			clip.Mask = translatedImage{Image: style.Clip.Mask, offset: canvas.offset}
+		}
+		style.Clip = &clip
+	}
+	return canvas.wrap(canvas.canvas.Layer(style, func(layer Canvas) (Canvas, error) {
+		return Translate(layer, canvas.offset, draw)
+	}))
+}
+
+// translatedImage is an image moved by the offset.
+type translatedImage struct {
+	image.Image
+	offset image.Point
+}
+
+// Bounds returns the bounds of the image moved by the offset.
+func (img translatedImage) Bounds() image.Rectangle {
+	return img.Image.Bounds().Add(img.offset)
+}
+
+// At returns the colour of the pixel of the image moved to x, y.
+func (img translatedImage) At(x, y int) color.Color {
+	return img.Image.At(x-img.offset.X, y-img.offset.Y)
+}
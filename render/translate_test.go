@@ -0,0 +1,85 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslate(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	t.Run("drawn from off the canvas", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := Translate(canvas, image.Pt(5, -5), func(c Canvas) (Canvas, error) {
+			c, err := c.Rectangle(image.Pt(-4, 6), 2, 2, red)
+			if err != nil {
+				return c, err
+			}
+			return c.Path(NewPolygonPath([]image.Point{{X: 0, Y: 12}, {X: 2, Y: 12}, {X: 2, Y: 14}, {X: 0, Y: 14}}), red, nil, StrokeStyle{})
+		})
+		assert.NoError(t, err)
+		assert.IsType(t, ImageCanvas{}, modifiedCanvas, "the canvas beneath should be returned")
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, red, img.At(1, 1))
+		assert.Equal(t, red, img.At(2, 2))
+		assert.Equal(t, color.NRGBA{}, img.At(3, 3))
+		assert.Equal(t, red, img.At(5, 7))
+		assert.Equal(t, red, img.At(6, 8))
+	})
+	t.Run("nested", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := Translate(canvas, image.Pt(2, 0), func(c Canvas) (Canvas, error) {
+			return Translate(c, image.Pt(0, 3), func(c Canvas) (Canvas, error) {
+				return c.Rectangle(image.ZP, 1, 1, red)
+			})
+		})
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, red, img.At(2, 3))
+		assert.Equal(t, color.NRGBA{}, img.At(2, 0))
+		assert.Equal(t, color.NRGBA{}, img.At(0, 3))
+	})
+	t.Run("layer", func(t *testing.T) {
+		mask := image.NewAlpha(image.Rect(0, 0, 1, 1))
+		mask.SetAlpha(0, 0, color.Alpha{A: 255})
+		canvas, _ := NewCanvas(10, 10)
+		modifiedCanvas, err := Translate(canvas, image.Pt(4, 4), func(c Canvas) (Canvas, error) {
+			return c.Layer(LayerStyle{Opacity: 1, Clip: &Clip{Mask: mask}}, func(layer Canvas) (Canvas, error) {
+				return layer.Rectangle(image.ZP, 2, 2, red)
+			})
+		})
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, red, img.At(4, 4))
+		assert.Equal(t, color.NRGBA{}, img.At(5, 5), "the mask should move with the layer")
+		assert.Equal(t, color.NRGBA{}, img.At(0, 0))
+	})
+	t.Run("transform anchor", func(t *testing.T) {
+		canvas, _ := NewCanvas(10, 10)
+		style := LayerStyle{Opacity: 1, Transform: &Transform{Rotation: 90, ScaleX: 1, ScaleY: 1, Anchor: &image.Point{X: 0, Y: 0}}}
+		modifiedCanvas, err := Translate(canvas, image.Pt(5, 5), func(c Canvas) (Canvas, error) {
+			return c.Layer(style, func(layer Canvas) (Canvas, error) {
+				return layer.Rectangle(image.ZP, 3, 1, red)
+			})
+		})
+		assert.NoError(t, err)
+		img := modifiedCanvas.GetUnderlyingImage()
+		assert.Equal(t, red, img.At(4, 7), "the layer should turn about the moved anchor")
+		assert.Equal(t, color.NRGBA{}, img.At(7, 5))
+		assert.Equal(t, image.Pt(0, 0), *style.Transform.Anchor, "the original style should not change")
+	})
+	t.Run("vector", func(t *testing.T) {
+		canvas, _ := NewSVGCanvas(10, 10)
+		modifiedCanvas, err := Translate(canvas, image.Pt(-3, 2), func(c Canvas) (Canvas, error) {
+			return c.Rectangle(image.Pt(5, 1), 2, 2, red)
+		})
+		assert.NoError(t, err)
+		var buf bytes.Buffer
+		assert.NoError(t, modifiedCanvas.(SVGCanvas).WriteSVG(&buf))
+		assert.Contains(t, buf.String(), `<rect x="2" y="3" width="2" height="2" fill="#ff0000"/>`)
+		assert.NotContains(t, buf.String(), "<g")
+	})
+}
@@ -0,0 +1,38 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+)
+
+// formatNumber writes a coordinate or colour channel compactly for vector output.
+func formatNumber(value float64) string {
+	rounded := math.Round(value*10000) / 10000
+	if rounded == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
+
+// toNRGBA converts any colour to non-premultiplied RGBA, treating nil as transparent.
+func toNRGBA(colour color.Color) color.NRGBA {
+	if colour == nil {
+		return color.NRGBA{}
+	}
+	return color.NRGBAModel.Convert(colour).(color.NRGBA)
+}
+
+// isTransparent returns whether every pixel of the image is fully transparent, in which case vector canvases need not embed it.
+func isTransparent(img image.Image) bool {
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,475 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/boombuler/barcode/qr"
+	"golang.org/x/image/font"
+)
+
+// ZPLCanvas implements the Canvas interface by recording drawing operations as ZPL II commands for Zebra label printers, with one canvas pixel per printer dot. Every operation is mirrored onto a raster ImageCanvas, which is used to measure text and to provide the underlying image.
+//
+// Labels are monochrome, so colours are printed if they are dark and opaque and left blank otherwise. Barcodes and text use the printer's own barcode generators and scalable font, so their exact dimensions may differ slightly from the raster image.
+type ZPLCanvas struct {
+	// raster mirrors every drawing operation on the canvas.
+	raster ImageCanvas
+	// doc is the label being drawn, shared between copies of the canvas in the same way as the underlying image of an ImageCanvas.
+	doc *zplDocument
+}
+
+// zplDocument holds the field commands drawn on a ZPLCanvas.
+type zplDocument struct {
+	fields bytes.Buffer
+	// home is the label home set for the fields currently being drawn, moving them all.
+	home image.Point
+}
+
+// NewZPLCanvas generates a new ZPL canvas of the given width and height in dots.
+func NewZPLCanvas(width, height int) (ZPLCanvas, error) {
+	raster, err := NewCanvas(width, height)
+	if err != nil {
+		return ZPLCanvas{}, err
+	}
+	return ZPLCanvas{raster: raster, doc: &zplDocument{}}, nil
+}
+
+// NewZPLCanvasFrom generates a new ZPL canvas with the size and PPI of an existing canvas, embedding any dark pixels of that canvas as a graphic field.
+func NewZPLCanvasFrom(base Canvas) (ZPLCanvas, error) {
+	canvas, err := NewZPLCanvas(base.GetWidth(), base.GetHeight())
+	if err != nil {
+		return ZPLCanvas{}, err
+	}
+	canvas = canvas.SetPPI(base.GetPPI()).(ZPLCanvas)
+	drawn, err := canvas.DrawImage(image.ZP, base.GetUnderlyingImage())
+	if err != nil {
+		return ZPLCanvas{}, err
+	}
+	return drawn.(ZPLCanvas), nil
+}
+
+// SetUnderlyingImage replaces the contents of the canvas with the given image.
+func (canvas ZPLCanvas) SetUnderlyingImage(newImage image.Image) Canvas {
+	canvas.raster = canvas.raster.SetUnderlyingImage(newImage).(ImageCanvas)
+	canvas.doc = &zplDocument{}
+	if newImage != nil {
+		canvas.doc.graphic(image.ZP, newImage)
+	}
+	return canvas
+}
+
+// GetUnderlyingImage gets the raster image mirroring the label contents.
+func (canvas ZPLCanvas) GetUnderlyingImage() image.Image {
+	return canvas.raster.GetUnderlyingImage()
+}
+
+// GetWidth returns the width of the canvas in dots.
+func (canvas ZPLCanvas) GetWidth() int {
+	return canvas.raster.GetWidth()
+}
+
+// GetHeight returns the height of the canvas in dots.
+func (canvas ZPLCanvas) GetHeight() int {
+	return canvas.raster.GetHeight()
+}
+
+// SetPPI sets the pixels per inch of the canvas, which should match the printer's resolution.
+func (canvas ZPLCanvas) SetPPI(ppi float64) Canvas {
+	canvas.raster = canvas.raster.SetPPI(ppi).(ImageCanvas)
+	return canvas
+}
+
+// GetPPI returns the pixels per inch of the canvas.
+func (canvas ZPLCanvas) GetPPI() float64 {
+	return canvas.raster.GetPPI()
+}
+
+// Rectangle draws a filled graphic box on the canvas.
+func (canvas ZPLCanvas) Rectangle(topLeft image.Point, width, height int, colour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Rectangle(topLeft, width, height, colour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	if lineColour, visible := zplColour(colour); visible {
+		thickness := width
+		if height < thickness {
+			thickness = height
+		}
+		fmt.Fprintf(&canvas.doc.fields, "^FO%d,%d^GB%d,%d,%d,%s,0^FS\n", topLeft.X, topLeft.Y, width, height, thickness, lineColour)
+	}
+	return canvas, nil
+}
+
+// Circle draws a filled graphic circle on the canvas.
+func (canvas ZPLCanvas) Circle(centre image.Point, radius int, colour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Circle(centre, radius, colour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	if lineColour, visible := zplColour(colour); visible {
+		fmt.Fprintf(&canvas.doc.fields, "^FO%d,%d^GC%d,%d,%s^FS\n", centre.X-radius, centre.Y-radius, radius*2, radius, lineColour)
+	}
+	return canvas, nil
+}
+
+// Ellipse draws a filled graphic ellipse on the canvas.
+func (canvas ZPLCanvas) Ellipse(centre image.Point, radiusX, radiusY int, colour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Ellipse(centre, radiusX, radiusY, colour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	if lineColour, visible := zplColour(colour); visible {
+		// A border at least as thick as the smaller radius fills the ellipse.
+		thickness := radiusX
+		if radiusY < thickness {
+			thickness = radiusY
+		}
+		fmt.Fprintf(&canvas.doc.fields, "^FO%d,%d^GE%d,%d,%d,%s^FS\n", centre.X-radiusX, centre.Y-radiusY, radiusX*2, radiusY*2, thickness, lineColour)
+	}
+	return canvas, nil
+}
+
+// Arc draws a pie slice or chord segment of an ellipse on the canvas as a graphic field, since ZPL has no native arcs.
+func (canvas ZPLCanvas) Arc(centre image.Point, radiusX, radiusY int, startAngle, endAngle float64, style ArcStyle, colour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Arc(centre, radiusX, radiusY, startAngle, endAngle, style, colour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	path, _ := NewArcPath(centre, radiusX, radiusY, startAngle, endAngle, style)
+	canvas.doc.graphic(rasterisePath(path, colour, nil, StrokeStyle{}))
+	return canvas, nil
+}
+
+// Line draws a straight line of a specific colour and style on the canvas, as a graphic box if possible.
+func (canvas ZPLCanvas) Line(start, end image.Point, style StrokeStyle, colour color.Color) (Canvas, error) {
+	return canvas.Polyline([]image.Point{start, end}, style, colour)
+}
+
+// Polyline draws a line of a specific colour and style through each of the points in turn on the canvas. Single horizontal or vertical solid lines without round caps are drawn as graphic boxes, and anything else as a graphic field.
+func (canvas ZPLCanvas) Polyline(points []image.Point, style StrokeStyle, colour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Polyline(points, style, colour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	lineColour, visible := zplColour(colour)
+	if !visible {
+		return canvas, nil
+	}
+	style, _ = style.normalise()
+	if box, isBox := zplLineBox(points, style); isBox {
+		thickness := box.Dx()
+		if box.Dy() < thickness {
+			thickness = box.Dy()
+		}
+		fmt.Fprintf(&canvas.doc.fields, "^FO%d,%d^GB%d,%d,%d,%s,0^FS\n", box.Min.X, box.Min.Y, box.Dx(), box.Dy(), thickness, lineColour)
+		return canvas, nil
+	}
+	canvas.doc.graphic(rasteriseStroke(points, style, colour))
+	return canvas, nil
+}
+
+// zplLineBox returns the box covered by a line, if it is a single horizontal or vertical solid line with square corners.
+func zplLineBox(points []image.Point, style StrokeStyle) (image.Rectangle, bool) {
+	if len(points) != 2 || len(style.Dash) > 0 || style.Cap == CapRound {
+		return image.Rectangle{}, false
+	}
+	start, end := points[0], points[1]
+	if start.X != end.X && start.Y != end.Y {
+		return image.Rectangle{}, false
+	}
+	// Round the edges of the line to the nearest dot, since boxes can't be anti-aliased.
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, polygon := range strokePolygons(pixelCentres(points), false, style) {
+		for _, p := range polygon {
+			minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+			maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+		}
+	}
+	box := image.Rect(int(math.Round(minX)), int(math.Round(minY)), int(math.Round(maxX)), int(math.Round(maxY)))
+	if minX > maxX || box.Empty() {
+		return image.Rectangle{}, false
+	}
+	return box, true
+}
+
+// Path draws a filled and outlined path on the canvas as a graphic field.
+func (canvas ZPLCanvas) Path(path Path, fill, stroke color.Color, style StrokeStyle) (Canvas, error) {
+	raster, err := canvas.raster.Path(path, fill, stroke, style)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	style, _ = checkPath(path, style)
+	canvas.doc.graphic(rasterisePath(path, fill, stroke, style))
+	return canvas, nil
+}
+
+// Text draws a text field on the canvas using the printer's scalable font, sized to match the face.
+func (canvas ZPLCanvas) Text(text string, start image.Point, typeFace font.Face, colour color.Color, maxWidth int) (Canvas, error) {
+	raster, err := canvas.raster.Text(text, start, typeFace, colour, maxWidth)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	lineColour, visible := zplColour(colour)
+	if !visible {
+		return canvas, nil
+	}
+	// Light text can't be printed as it is, so it is reversed out of whatever is under it, which leaves it blank on a blank label.
+	reverse := ""
+	if lineColour == "W" {
+		reverse = "^FR"
+	}
+	var size int
+	if face, isFontFace := toFontFace(typeFace); isFontFace {
+		size = int(face.PixelSize() + 0.5)
+	} else {
+		metrics := typeFace.Metrics()
+		size = (metrics.Ascent + metrics.Descent).Ceil()
+	}
+	fmt.Fprintf(&canvas.doc.fields, "^FT%d,%d%s^A0N,%d^FH_^FD%s^FS\n", start.X, start.Y, reverse, size, zplEscape(text))
+	return canvas, nil
+}
+
+// TryText returns whether the text would fit on the canvas, and the width the text would currently use up.
+func (canvas ZPLCanvas) TryText(text string, start image.Point, typeFace font.Face, colour color.Color, maxWidth int) (bool, int) {
+	return canvas.raster.TryText(text, start, typeFace, colour, maxWidth)
+}
+
+// DrawImage draws the dark pixels of another image on the canvas as a graphic field.
+func (canvas ZPLCanvas) DrawImage(start image.Point, subImage image.Image) (Canvas, error) {
+	raster, err := canvas.raster.DrawImage(start, subImage)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	canvas.doc.graphic(start, subImage)
+	return canvas, nil
+}
+
+// Barcode draws a barcode on the canvas using the printer's native barcode commands, with the module width chosen so the barcode fills the width as closely as possible.
+func (canvas ZPLCanvas) Barcode(codeType BarcodeType, content []byte, extra BarcodeExtraData, start image.Point, width, height int, dataColour color.Color, backgroundColour color.Color) (Canvas, error) {
+	raster, err := canvas.raster.Barcode(codeType, content, extra, start, width, height, dataColour, backgroundColour)
+	if err != nil {
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	if dataColour == nil {
+		dataColour = color.Black
+	}
+	if backgroundColour == nil {
+		backgroundColour = color.White
+	}
+	rawBarcode, _ := encodeRawBarcode(codeType, content, extra)
+	rawBounds := rawBarcode.Bounds()
+	moduleWidth := clampInt(width/rawBounds.Dx(), 1, 10)
+	moduleHeight := clampInt(height/rawBounds.Dy(), 1, 10)
+	moduleSize := moduleWidth
+	if moduleHeight < moduleSize {
+		moduleSize = moduleHeight
+	}
+	fields := &canvas.doc.fields
+	// Labels are blank by default, so the background only needs drawing if it is dark, in which case the barcode is reversed out of it.
+	reverse := ""
+	if backgroundLine, visible := zplColour(backgroundColour); visible && backgroundLine == "B" {
+		fmt.Fprintf(fields, "^FO%d,%d^GB%d,%d,%d,B,0^FS\n", start.X, start.Y, width, height, clampInt(width, 0, height))
+		reverse = "^FR"
+	}
+	data := string(content)
+	var command string
+	switch codeType {
+	case BarcodeTypeAztec:
+		symbolSize := 0
+		switch {
+		case extra.AztecUserSpecifiedLayers > 0:
+			symbolSize = 200 + extra.AztecUserSpecifiedLayers
+		case extra.AztecUserSpecifiedLayers < 0:
+			symbolSize = 100 - extra.AztecUserSpecifiedLayers
+		case extra.AztecMinECCPercent > 0:
+			symbolSize = extra.AztecMinECCPercent
+		}
+		command = fmt.Sprintf("^B0N,%d,N,%d", moduleSize, symbolSize)
+	case BarcodeTypeCodabar:
+		startChar, stopChar := "A", "A"
+		if len(data) >= 2 && strings.ContainsAny(data[:1], "ABCD") && strings.ContainsAny(data[len(data)-1:], "ABCD") {
+			startChar, stopChar, data = data[:1], data[len(data)-1:], data[1:len(data)-1]
+		}
+		command = fmt.Sprintf("^BY%d,3^BKN,N,%d,N,N,%s,%s", moduleWidth, height, startChar, stopChar)
+	case BarcodeTypeCode128:
+		command = fmt.Sprintf("^BY%d^BCN,%d,N,N,N,A", moduleWidth, height)
+	case BarcodeTypeCode39:
+		command = fmt.Sprintf("^BY%d,3^B3N,%s,%d,N,N", moduleWidth, zplFlag(extra.Code39IncludeChecksum), height)
+	case BarcodeTypeCode93:
+		command = fmt.Sprintf("^BY%d^BAN,%d,N,N,N", moduleWidth, height)
+	case BarcodeTypeDataMatrix:
+		command = fmt.Sprintf("^BXN,%d,200,%d,%d", moduleSize, rawBounds.Dx(), rawBounds.Dy())
+	case BarcodeTypeEAN8:
+		// The printer calculates the check digit itself.
+		command, data = fmt.Sprintf("^BY%d^B8N,%d,N,N", moduleWidth, height), data[:7]
+	case BarcodeTypeEAN13:
+		command, data = fmt.Sprintf("^BY%d^BEN,%d,N,N", moduleWidth, height), data[:12]
+	case BarcodeTypePDF:
+		// PDF417 codewords are 17 modules wide with 69 modules of start, stop and row indicator patterns, and each row is 2 modules high.
+		rows := rawBounds.Dy() / 2
+		columns := (rawBounds.Dx() - 69) / 17
+		command = fmt.Sprintf("^BY%d^B7N,%d,%d,%d,%d,N", moduleWidth, clampInt(height/rows, 1, height), extra.PDFSecurityLevel, columns, rows)
+	case BarcodeTypeQR:
+		command, data = fmt.Sprintf("^BQN,2,%d", moduleSize), zplQRLevel(extra.QRLevel)+"A,"+data
+	case BarcodeType2of5:
+		command = fmt.Sprintf("^BY%d,3^BJN,%d,N,N", moduleWidth, height)
+	case BarcodeType2of5Interleaved:
+		command = fmt.Sprintf("^BY%d,3^B2N,%d,N,N,N", moduleWidth, height)
+	}
+	fmt.Fprintf(fields, "^FO%d,%d%s%s^FH_^FD%s^FS\n", start.X, start.Y, reverse, command, zplEscape(data))
+	return canvas, nil
+}
+
+// Layer draws on a transparent layer composited with the style. Labels are monochrome, so blend modes are ignored. A layer which is only moved by whole pixels keeps its fields, by moving the label home while they're printed. Any other translucent, transformed, clipped, blurred or shadowed layer is printed as a graphic field of the pixels left dark and opaque enough after styling and fading it.
+func (canvas ZPLCanvas) Layer(style LayerStyle, draw func(Canvas) (Canvas, error)) (Canvas, error) {
+	if style.plain() {
+		return draw(canvas)
+	}
+	doc := canvas.doc
+	start := doc.fields.Len()
+	home := doc.home
+	offset, isMoved := zplOffset(style)
+	// The label home can't be moved above or left of the label.
+	if isMoved = isMoved && home.X+offset.X >= 0 && home.Y+offset.Y >= 0; isMoved {
+		doc.home = home.Add(offset)
+		fmt.Fprintf(&doc.fields, "^LH%d,%d\n", doc.home.X, doc.home.Y)
+	}
+	fieldsStart := doc.fields.Len()
+	var drawn Canvas
+	raster, err := canvas.raster.Layer(style, func(layer Canvas) (Canvas, error) {
+		var err error
+		drawn, err = draw(ZPLCanvas{raster: layer.(ImageCanvas), doc: doc})
+		return drawn, err
+	})
+	doc.home = home
+	if err != nil {
+		doc.fields.Truncate(start)
+		return canvas, err
+	}
+	canvas.raster = raster.(ImageCanvas)
+	if isMoved {
+		if doc.fields.Len() == fieldsStart {
+			// Nothing was drawn, so there's nothing to move.
+			doc.fields.Truncate(start)
+		} else {
+			fmt.Fprintf(&doc.fields, "^LH%d,%d\n", home.X, home.Y)
+		}
+		return canvas, nil
+	}
+	if style.Opacity >= 1 && style.Transform == nil && style.Clip == nil && style.Blur <= 0 && style.Shadow == nil {
+		return canvas, nil
+	}
+	// Replace the fields drawn on the layer with an image of the styled and faded layer.
+	doc.fields.Truncate(start)
+	faded := fade(style.styled(drawn.GetUnderlyingImage()), style.Opacity)
+	doc.graphic(faded.Bounds().Min, faded)
+	return canvas, nil
+}
+
+// zplOffset returns the whole pixel offset of a layer, if moving it is all the style does apart from blending.
+func zplOffset(style LayerStyle) (image.Point, bool) {
+	if style.Opacity < 1 || style.Transform == nil || style.Clip != nil || style.Blur > 0 || style.Shadow != nil {
+		return image.Point{}, false
+	}
+	// Moving a layer doesn't depend on where its content is.
+	return style.Transform.matrix(image.Rectangle{}).translation()
+}
+
+// WriteZPL writes the canvas to a ZPL II label format.
+func (canvas ZPLCanvas) WriteZPL(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "^XA\n^CI28\n^PW%d\n^LL%d\n^LH0,0\n", canvas.GetWidth(), canvas.GetHeight())
+	if err != nil {
+		return err
+	}
+	if canvas.doc != nil {
+		_, err = w.Write(canvas.doc.fields.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, "^XZ\n")
+	return err
+}
+
+// graphic draws the dark pixels of an image as an ASCII hex graphic field with its top-left corner at start. Images with no dark pixels are skipped.
+func (doc *zplDocument) graphic(start image.Point, img image.Image) {
+	bounds := img.Bounds()
+	rowBytes := (bounds.Dx() + 7) / 8
+	data := make([]byte, rowBytes*bounds.Dy())
+	empty := true
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if lineColour, visible := zplColour(img.At(x, y)); visible && lineColour == "B" {
+				dx := x - bounds.Min.X
+				data[(y-bounds.Min.Y)*rowBytes+dx/8] |= 0x80 >> uint(dx%8)
+				empty = false
+			}
+		}
+	}
+	if empty {
+		return
+	}
+	fmt.Fprintf(&doc.fields, "^FO%d,%d^GFA,%d,%d,%d,%X^FS\n", start.X, start.Y, len(data), len(data), rowBytes, data)
+}
+
+// zplColour reduces a colour to the black or white line colour of a ZPL graphic, and whether it is opaque enough to print at all.
+func zplColour(colour color.Color) (string, bool) {
+	c := toNRGBA(colour)
+	if c.A < 128 {
+		return "", false
+	}
+	if color.GrayModel.Convert(color.NRGBA{R: c.R, G: c.G, B: c.B, A: 255}).(color.Gray).Y < 128 {
+		return "B", true
+	}
+	return "W", true
+}
+
+// zplEscape escapes the command prefixes and the hex indicator in field data, for use with ^FH_.
+func zplEscape(text string) string {
+	return strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E").Replace(text)
+}
+
+func zplFlag(value bool) string {
+	if value {
+		return "Y"
+	}
+	return "N"
+}
+
+func zplQRLevel(level qr.ErrorCorrectionLevel) string {
+	switch level {
+	case qr.M:
+		return "M"
+	case qr.Q:
+		return "Q"
+	case qr.H:
+		return "H"
+	default:
+		return "L"
+	}
+}
+
+func clampInt(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
@@ -0,0 +1,329 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/boombuler/barcode/qr"
+	"github.com/golang/freetype/truetype"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func writeZPL(t *testing.T, canvas Canvas) string {
+	var data bytes.Buffer
+	err := canvas.(ZPLCanvas).WriteZPL(&data)
+	assert.NoError(t, err)
+	return data.String()
+}
+
+func TestNewZPLCanvas(t *testing.T) {
+	t.Run("invalid size", func(t *testing.T) {
+		canvas, err := NewZPLCanvas(-1, -1)
+		assert.Equal(t, ZPLCanvas{}, canvas)
+		assert.EqualError(t, err, "invalid width and height")
+	})
+	t.Run("valid size", func(t *testing.T) {
+		canvas, err := NewZPLCanvas(400, 200)
+		assert.NoError(t, err)
+		assert.Equal(t, 400, canvas.GetWidth())
+		assert.Equal(t, 200, canvas.GetHeight())
+		assert.Equal(t, "^XA\n^CI28\n^PW400\n^LL200\n^LH0,0\n^XZ\n", writeZPL(t, canvas))
+	})
+	t.Run("from canvas", func(t *testing.T) {
+		base, _ := NewCanvas(10, 2)
+		whiteBase, _ := base.Rectangle(image.ZP, 10, 2, color.White)
+		drawnBase, _ := whiteBase.Rectangle(image.Pt(1, 1), 8, 1, color.Black)
+		canvas, err := NewZPLCanvasFrom(drawnBase.SetPPI(203))
+		assert.NoError(t, err)
+		assert.Equal(t, float64(203), canvas.GetPPI())
+		assert.Equal(t, "^XA\n^CI28\n^PW10\n^LL2\n^LH0,0\n^FO0,0^GFA,4,4,2,00007F80^FS\n^XZ\n", writeZPL(t, canvas))
+	})
+	t.Run("from blank canvas", func(t *testing.T) {
+		base, _ := NewCanvas(10, 2)
+		whiteBase, _ := base.Rectangle(image.ZP, 10, 2, color.White)
+		canvas, err := NewZPLCanvasFrom(whiteBase)
+		assert.NoError(t, err)
+		assert.NotContains(t, writeZPL(t, canvas), "^GF")
+	})
+	t.Run("from invalid canvas", func(t *testing.T) {
+		canvas, err := NewZPLCanvasFrom(ImageCanvas{})
+		assert.Equal(t, ZPLCanvas{}, canvas)
+		assert.EqualError(t, err, "invalid width and height")
+	})
+}
+
+func TestZPLCanvasDrawing(t *testing.T) {
+	ttFont, _ := truetype.Parse(goregular.TTF)
+	t.Run("rectangles", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(100, 100)
+		modifiedCanvas, err := canvas.Rectangle(image.Pt(1, 2), 30, 40, color.Black)
+		assert.NoError(t, err)
+		modifiedCanvas, err = modifiedCanvas.Rectangle(image.Pt(5, 5), 10, 5, color.White)
+		assert.NoError(t, err)
+		modifiedCanvas, err = modifiedCanvas.Rectangle(image.Pt(5, 5), 10, 5, color.NRGBA{A: 20})
+		assert.NoError(t, err)
+		assert.Contains(t, writeZPL(t, modifiedCanvas), "^FO1,2^GB30,40,30,B,0^FS\n^FO5,5^GB10,5,5,W,0^FS\n^XZ\n")
+	})
+	t.Run("invalid rectangle", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(100, 100)
+		modifiedCanvas, err := canvas.Rectangle(image.ZP, -1, 10, color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid width")
+	})
+	t.Run("circle", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(100, 100)
+		modifiedCanvas, err := canvas.Circle(image.Pt(50, 40), 20, color.NRGBA{R: 50, G: 50, B: 50, A: 255})
+		assert.NoError(t, err)
+		assert.Contains(t, writeZPL(t, modifiedCanvas), "^FO30,20^GC40,20,B^FS\n")
+	})
+	t.Run("ellipse", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(100, 100)
+		modifiedCanvas, err := canvas.Ellipse(image.Pt(50, 40), 20, 10, color.Black)
+		assert.NoError(t, err)
+		assert.Contains(t, writeZPL(t, modifiedCanvas), "^FO30,30^GE40,20,10,B^FS\n")
+		modifiedCanvas, err = canvas.Ellipse(image.Pt(50, 40), -20, 10, color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid x radius")
+	})
+	t.Run("arc", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(100, 100)
+		modifiedCanvas, err := canvas.Arc(image.Pt(50, 40), 8, 8, 0, 90, ArcPie, color.Black)
+		assert.NoError(t, err)
+		assert.Contains(t, writeZPL(t, modifiedCanvas), "^FO50,40^GFA,")
+		modifiedCanvas, err = canvas.Arc(image.Pt(50, 40), 8, 8, 0, 90, "", color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "unsupported arc style: ")
+	})
+	t.Run("invalid circle", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(100, 100)
+		modifiedCanvas, err := canvas.Circle(image.Pt(50, 40), 0, color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid radius")
+	})
+	t.Run("lines", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(100, 100)
+		modifiedCanvas, err := canvas.Line(image.Pt(10, 20), image.Pt(50, 20), StrokeStyle{Width: 3}, color.Black)
+		assert.NoError(t, err)
+		modifiedCanvas, err = modifiedCanvas.Line(image.Pt(5, 60), image.Pt(5, 30), StrokeStyle{Width: 4, Cap: CapSquare}, color.White)
+		assert.NoError(t, err)
+		modifiedCanvas, err = modifiedCanvas.Line(image.Pt(5, 5), image.Pt(90, 90), StrokeStyle{Width: 4}, color.NRGBA{A: 20})
+		assert.NoError(t, err)
+		assert.Contains(t, writeZPL(t, modifiedCanvas), "^FO11,19^GB40,3,3,B,0^FS\n^FO4,29^GB4,34,4,W,0^FS\n^XZ\n")
+	})
+	t.Run("diagonal line", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(100, 100)
+		modifiedCanvas, err := canvas.Polyline([]image.Point{{X: 10, Y: 10}, {X: 20, Y: 20}, {X: 30, Y: 10}}, StrokeStyle{Width: 2, Join: JoinRound}, color.Black)
+		assert.NoError(t, err)
+		assert.Regexp(t, `\^FO9,9\^GFA,\d+,\d+,3,[0-9A-F]+\^FS\n\^XZ\n$`, writeZPL(t, modifiedCanvas))
+	})
+	t.Run("invalid line", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(100, 100)
+		modifiedCanvas, err := canvas.Line(image.ZP, image.Pt(5, 5), StrokeStyle{Width: 1, Cap: "pointy"}, color.Black)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "unsupported line cap: pointy")
+	})
+	t.Run("path", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(100, 100)
+		modifiedCanvas, err := canvas.Path(NewPolygonPath([]image.Point{{X: 8, Y: 4}, {X: 16, Y: 4}, {X: 16, Y: 6}, {X: 8, Y: 6}}), color.Black, nil, StrokeStyle{})
+		assert.NoError(t, err)
+		modifiedCanvas, err = modifiedCanvas.Path(NewPolygonPath([]image.Point{{X: 0, Y: 0}, {X: 5, Y: 5}, {X: 0, Y: 5}}), color.White, nil, StrokeStyle{})
+		assert.NoError(t, err)
+		assert.Contains(t, writeZPL(t, modifiedCanvas), "^LH0,0\n^FO8,4^GFA,2,2,1,FFFF^FS\n^XZ\n")
+	})
+	t.Run("invalid path", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(100, 100)
+		modifiedCanvas, err := canvas.Path(Path{}, color.Black, nil, StrokeStyle{})
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "path has no segments")
+	})
+	t.Run("text", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(300, 100)
+		face := NewFontFace(ttFont, &truetype.Options{Size: 12, DPI: 203})
+		modifiedCanvas, err := canvas.Text("50% off ^_~ ✓", image.Pt(10, 60), face, color.Black, 300)
+		assert.NoError(t, err)
+		assert.Contains(t, writeZPL(t, modifiedCanvas), "^FT10,60^A0N,34^FH_^FD50% off _5E_5F_7E ✓^FS\n")
+	})
+	t.Run("plain face text", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(300, 100)
+		face := truetype.NewFace(ttFont, &truetype.Options{Size: 20, Hinting: font.HintingFull})
+		modifiedCanvas, err := canvas.Text("label", image.Pt(10, 60), face, color.Black, 300)
+		assert.NoError(t, err)
+		assert.Contains(t, writeZPL(t, modifiedCanvas), "^FT10,60^A0N,24^FH_^FDlabel^FS\n")
+	})
+	t.Run("white text", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(300, 100)
+		face := NewFontFace(ttFont, &truetype.Options{Size: 12, DPI: 203})
+		boxedCanvas, _ := canvas.Rectangle(image.Pt(0, 20), 300, 60, color.Black)
+		modifiedCanvas, err := boxedCanvas.Text("label", image.Pt(10, 60), face, color.White, 300)
+		assert.NoError(t, err)
+		assert.Contains(t, writeZPL(t, modifiedCanvas), "^FO0,20^GB300,60,60,B,0^FS\n^FT10,60^FR^A0N,34^FH_^FDlabel^FS\n", "white text should be reversed out of the box rather than printed black")
+	})
+	t.Run("invisible text", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(300, 100)
+		face := NewFontFace(ttFont, &truetype.Options{Size: 12})
+		modifiedCanvas, err := canvas.Text("hidden", image.Pt(10, 60), face, color.Transparent, 300)
+		assert.NoError(t, err)
+		assert.NotContains(t, writeZPL(t, modifiedCanvas), "^FD")
+	})
+	t.Run("invalid text", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(60, 30)
+		face := NewFontFace(ttFont, &truetype.Options{Size: 10})
+		modifiedCanvas, err := canvas.Text("tet", image.Pt(2, 20), face, color.Black, 2)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "resultant drawn text was longer than maxWidth")
+		fits, width := canvas.TryText("tet", image.Pt(2, 20), face, color.Black, 2)
+		assert.False(t, fits)
+		assert.Equal(t, 12, width)
+	})
+	t.Run("image", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(100, 100)
+		img := image.NewNRGBA(image.Rect(0, 0, 9, 2))
+		img.Set(0, 0, color.Black)
+		img.Set(8, 1, color.Black)
+		img.Set(4, 1, color.NRGBA{R: 200, G: 200, B: 200, A: 255})
+		modifiedCanvas, err := canvas.DrawImage(image.Pt(3, 4), img)
+		assert.NoError(t, err)
+		assert.Contains(t, writeZPL(t, modifiedCanvas), "^FO3,4^GFA,4,4,2,80000080^FS\n")
+	})
+	t.Run("set underlying image", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(10, 10)
+		drawnCanvas, _ := canvas.Rectangle(image.ZP, 3, 3, color.Black)
+		newImage := image.NewNRGBA(image.Rect(0, 0, 8, 1))
+		newImage.Set(7, 0, color.Black)
+		modifiedCanvas := drawnCanvas.SetUnderlyingImage(newImage)
+		assert.Equal(t, "^XA\n^CI28\n^PW8\n^LL1\n^LH0,0\n^FO0,0^GFA,1,1,1,01^FS\n^XZ\n", writeZPL(t, modifiedCanvas))
+	})
+	t.Run("layer", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(8, 1)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 0.6, BlendMode: BlendMultiply}, func(layer Canvas) (Canvas, error) {
+			drawn, _ := layer.Rectangle(image.ZP, 2, 1, color.Black)
+			return drawn.Rectangle(image.Pt(4, 0), 2, 1, color.NRGBA{A: 200})
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "^XA\n^CI28\n^PW8\n^LL1\n^LH0,0\n^FO0,0^GFA,1,1,1,C0^FS\n^XZ\n", writeZPL(t, modifiedCanvas), "only pixels still dark and opaque enough should be printed")
+		canvas, _ = NewZPLCanvas(8, 1)
+		modifiedCanvas, err = canvas.Layer(LayerStyle{Opacity: 1, BlendMode: BlendMultiply}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.ZP, 2, 1, color.Black)
+		})
+		assert.NoError(t, err)
+		assert.Contains(t, writeZPL(t, modifiedCanvas), "^FO0,0^GB2,1,1,B,0^FS\n", "blend modes should be ignored")
+	})
+	t.Run("transformed layer", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(8, 2)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Transform: &Transform{Rotation: 90, ScaleX: 1, ScaleY: 1, Anchor: &image.Point{X: 1, Y: 1}}}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.ZP, 2, 1, color.Black)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "^XA\n^CI28\n^PW8\n^LL2\n^LH0,0\n^FO0,0^GFA,2,2,1,4040^FS\n^XZ\n", writeZPL(t, modifiedCanvas))
+	})
+	t.Run("clipped layer", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(8, 1)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Clip: &Clip{Path: NewRectanglePath(image.Pt(1, 0), 2, 1, CornerRadii{})}}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.ZP, 4, 1, color.Black)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "^XA\n^CI28\n^PW8\n^LL1\n^LH0,0\n^FO0,0^GFA,1,1,1,60^FS\n^XZ\n", writeZPL(t, modifiedCanvas))
+	})
+	t.Run("shadowed layer", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(8, 1)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 1, Shadow: &Shadow{Offset: image.Pt(2, 0), Colour: color.NRGBA{A: 255}}}, func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.ZP, 2, 1, color.Black)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "^XA\n^CI28\n^PW8\n^LL1\n^LH0,0\n^FO0,0^GFA,1,1,1,F0^FS\n^XZ\n", writeZPL(t, modifiedCanvas))
+	})
+	t.Run("moved layer", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(10, 10)
+		moved := func(x, y int) LayerStyle {
+			return LayerStyle{Opacity: 1, Transform: &Transform{ScaleX: 1, ScaleY: 1, Offset: image.Pt(x, y)}}
+		}
+		modifiedCanvas, err := canvas.Layer(moved(3, 4), func(layer Canvas) (Canvas, error) {
+			drawn, _ := layer.Rectangle(image.ZP, 2, 1, color.Black)
+			return drawn.Layer(moved(1, 1), func(inner Canvas) (Canvas, error) {
+				return inner.Rectangle(image.ZP, 1, 1, color.Black)
+			})
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, color.NRGBA{A: 255}, modifiedCanvas.GetUnderlyingImage().At(4, 5))
+		assert.Equal(t, "^XA\n^CI28\n^PW10\n^LL10\n^LH0,0\n^LH3,4\n^FO0,0^GB2,1,1,B,0^FS\n^LH4,5\n^FO0,0^GB1,1,1,B,0^FS\n^LH3,4\n^LH0,0\n^XZ\n", writeZPL(t, modifiedCanvas), "moved layers should keep their fields")
+		canvas, _ = NewZPLCanvas(10, 10)
+		modifiedCanvas, err = canvas.Layer(moved(3, 4), func(layer Canvas) (Canvas, error) {
+			return layer, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "^XA\n^CI28\n^PW10\n^LL10\n^LH0,0\n^XZ\n", writeZPL(t, modifiedCanvas), "empty layers should be left out")
+		canvas, _ = NewZPLCanvas(8, 1)
+		modifiedCanvas, err = canvas.Layer(moved(-1, 0), func(layer Canvas) (Canvas, error) {
+			return layer.Rectangle(image.Pt(2, 0), 2, 1, color.Black)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "^XA\n^CI28\n^PW8\n^LL1\n^LH0,0\n^FO0,0^GFA,1,1,1,60^FS\n^XZ\n", writeZPL(t, modifiedCanvas), "layers moved off the label should be printed as a graphic")
+	})
+	t.Run("invalid layer", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(10, 10)
+		modifiedCanvas, err := canvas.Layer(LayerStyle{Opacity: 0.5}, func(layer Canvas) (Canvas, error) {
+			drawn, _ := layer.Rectangle(image.ZP, 3, 3, color.Black)
+			return drawn.Circle(image.Pt(5, 5), 0, color.Black)
+		})
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "invalid radius")
+		assert.NotContains(t, writeZPL(t, modifiedCanvas), "^FO")
+	})
+	t.Run("writer error", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(10, 10)
+		err := canvas.WriteZPL(failingWriter{})
+		assert.EqualError(t, err, "disk full")
+	})
+}
+
+func TestZPLBarcodes(t *testing.T) {
+	type barcodeTest struct {
+		codeType BarcodeType
+		content  string
+		extra    BarcodeExtraData
+		width    int
+		height   int
+		expected string
+	}
+	tests := map[string]barcodeTest{
+		"aztec":          {codeType: BarcodeTypeAztec, content: "hello", extra: BarcodeExtraData{AztecMinECCPercent: 33}, width: 60, height: 60, expected: "^FO5,6^B0N,4,N,33^FH_^FDhello^FS\n"},
+		"aztec compact":  {codeType: BarcodeTypeAztec, content: "hello", extra: BarcodeExtraData{AztecUserSpecifiedLayers: -2}, width: 60, height: 60, expected: "^FO5,6^B0N,3,N,102^FH_^FDhello^FS\n"},
+		"aztec layers":   {codeType: BarcodeTypeAztec, content: "hello", extra: BarcodeExtraData{AztecUserSpecifiedLayers: 4}, width: 100, height: 100, expected: "^FO5,6^B0N,3,N,204^FH_^FDhello^FS\n"},
+		"codabar":        {codeType: BarcodeTypeCodabar, content: "B1234D", width: 200, height: 50, expected: "^FO5,6^BY3,3^BKN,N,50,N,N,B,D^FH_^FD1234^FS\n"},
+		"code128":        {codeType: BarcodeTypeCode128, content: "ABC_123", width: 200, height: 50, expected: "^FO5,6^BY1^BCN,50,N,N,N,A^FH_^FDABC_5F123^FS\n"},
+		"code39":         {codeType: BarcodeTypeCode39, content: "ABC", extra: BarcodeExtraData{Code39IncludeChecksum: true}, width: 200, height: 50, expected: "^FO5,6^BY2,3^B3N,Y,50,N,N^FH_^FDABC^FS\n"},
+		"code93":         {codeType: BarcodeTypeCode93, content: "ABC", width: 200, height: 50, expected: "^FO5,6^BY3^BAN,50,N,N,N^FH_^FDABC^FS\n"},
+		"datamatrix":     {codeType: BarcodeTypeDataMatrix, content: "hello", width: 60, height: 60, expected: "^FO5,6^BXN,5,200,12,12^FH_^FDhello^FS\n"},
+		"ean8":           {codeType: BarcodeTypeEAN8, content: "96385074", width: 200, height: 50, expected: "^FO5,6^BY2^B8N,50,N,N^FH_^FD9638507^FS\n"},
+		"ean13":          {codeType: BarcodeTypeEAN13, content: "5901234123457", width: 200, height: 50, expected: "^FO5,6^BY2^BEN,50,N,N^FH_^FD590123412345^FS\n"},
+		"pdf417":         {codeType: BarcodeTypePDF, content: "hello", extra: BarcodeExtraData{PDFSecurityLevel: 2}, width: 300, height: 60, expected: "^FO5,6^BY2^B7N,15,2,3,4,N^FH_^FDhello^FS\n"},
+		"qr":             {codeType: BarcodeTypeQR, content: "hello", extra: BarcodeExtraData{QRLevel: qr.H}, width: 100, height: 100, expected: "^FO5,6^BQN,2,4^FH_^FDHA,hello^FS\n"},
+		"qr default":     {codeType: BarcodeTypeQR, content: "hello", width: 100, height: 100, expected: "^FO5,6^BQN,2,4^FH_^FDLA,hello^FS\n"},
+		"2of5":           {codeType: BarcodeType2of5, content: "1234", width: 200, height: 50, expected: "^FO5,6^BY2,3^BJN,50,N,N^FH_^FD1234^FS\n"},
+		"2of5interleave": {codeType: BarcodeType2of5Interleaved, content: "1234", width: 200, height: 50, expected: "^FO5,6^BY4,3^B2N,50,N,N,N^FH_^FD1234^FS\n"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			canvas, _ := NewZPLCanvas(400, 400)
+			modifiedCanvas, err := canvas.Barcode(test.codeType, []byte(test.content), test.extra, image.Pt(5, 6), test.width, test.height, nil, nil)
+			assert.NoError(t, err)
+			assert.Contains(t, writeZPL(t, modifiedCanvas), "^LH0,0\n"+test.expected+"^XZ\n")
+		})
+	}
+	t.Run("reversed", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(400, 400)
+		modifiedCanvas, err := canvas.Barcode(BarcodeTypeCode128, []byte("123"), BarcodeExtraData{}, image.Pt(5, 6), 200, 50, color.White, color.Black)
+		assert.NoError(t, err)
+		assert.Contains(t, writeZPL(t, modifiedCanvas), "^FO5,6^GB200,50,50,B,0^FS\n^FO5,6^FR^BY2^BCN,50,N,N,N,A^FH_^FD123^FS\n")
+	})
+	t.Run("invalid barcode", func(t *testing.T) {
+		canvas, _ := NewZPLCanvas(30, 30)
+		modifiedCanvas, err := canvas.Barcode(BarcodeTypeEAN8, []byte("12"), BarcodeExtraData{}, image.Pt(1, 1), 28, 10, nil, nil)
+		assert.Equal(t, canvas, modifiedCanvas)
+		assert.EqualError(t, err, "EAN8 Barcode requires 8 characters")
+	})
+}
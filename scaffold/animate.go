@@ -0,0 +1,320 @@
+package scaffold
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"math"
+	"sort"
+)
+
+// checkFrames validates a sequence of frames and their delays, returning the delay for each frame.
+func checkFrames(frames []image.Image, delays []int) ([]int, error) {
+	if len(frames) == 0 {
+		return nil, errors.New("no frames to animate")
+	}
+	if len(delays) == 1 {
+		shared := delays[0]
+		delays = make([]int, len(frames))
+		for i := range delays {
+			delays[i] = shared
+		}
+	}
+	if len(delays) != len(frames) {
+		return nil, fmt.Errorf("expected %d delays, got %d", len(frames), len(delays))
+	}
+	size := frames[0].Bounds().Size()
+	for i, frame := range frames {
+		if frame.Bounds().Size() != size {
+			return nil, fmt.Errorf("frame %d is %v, expected %v", i, frame.Bounds().Size(), size)
+		}
+		// Both GIF and APNG store delays as 16-bit values
+		if delays[i] < 0 || delays[i] > math.MaxUint16 {
+			return nil, fmt.Errorf("invalid delay %d for frame %d", delays[i], i)
+		}
+	}
+	return delays, nil
+}
+
+// WriteAnimatedGIF encodes the frames as a looping animated GIF, showing each frame for the matching delay in hundredths of a second. A single delay applies to every frame. All frames share one palette, so colours stay stable from frame to frame.
+func WriteAnimatedGIF(w io.Writer, frames []image.Image, delays []int) error {
+	delays, err := checkFrames(frames, delays)
+	if err != nil {
+		return err
+	}
+	palette, exact := sharedPalette(frames, 256)
+	var disposal byte = gif.DisposalNone
+	for _, c := range palette {
+		if _, _, _, a := c.RGBA(); a == 0 {
+			// Transparent areas must be cleared between frames, or the previous frame shows through.
+			disposal = gif.DisposalBackground
+		}
+	}
+	size := frames[0].Bounds().Size()
+	animation := &gif.GIF{
+		Config: image.Config{ColorModel: palette, Width: size.X, Height: size.Y},
+	}
+	var drawer draw.Drawer = draw.FloydSteinberg
+	if exact {
+		drawer = draw.Src
+	}
+	for i, frame := range frames {
+		bounds := frame.Bounds()
+		paletted := image.NewPaletted(image.Rect(0, 0, size.X, size.Y), palette)
+		drawer.Draw(paletted, paletted.Rect, frame, bounds.Min)
+		animation.Image = append(animation.Image, paletted)
+		animation.Delay = append(animation.Delay, delays[i])
+		animation.Disposal = append(animation.Disposal, disposal)
+	}
+	return gif.EncodeAll(w, animation)
+}
+
+// WriteAPNG encodes the frames as a looping animated PNG in full colour, showing each frame for the matching delay in hundredths of a second. A single delay applies to every frame. Viewers without APNG support show the first frame.
+func WriteAPNG(w io.Writer, frames []image.Image, delays []int) error {
+	delays, err := checkFrames(frames, delays)
+	if err != nil {
+		return err
+	}
+	size := frames[0].Bounds().Size()
+	pw := &pngWriter{w: w}
+	pw.write([]byte("\x89PNG\r\n\x1a\n"))
+	header := make([]byte, 13)
+	binary.BigEndian.PutUint32(header[0:], uint32(size.X))
+	binary.BigEndian.PutUint32(header[4:], uint32(size.Y))
+	// 8 bits per channel, RGBA, default compression, filtering and no interlacing.
+	header[8], header[9] = 8, 6
+	pw.chunk("IHDR", header)
+	control := make([]byte, 8)
+	binary.BigEndian.PutUint32(control[0:], uint32(len(frames)))
+	pw.chunk("acTL", control)
+	var sequence uint32
+	for i, frame := range frames {
+		frameControl := make([]byte, 26)
+		binary.BigEndian.PutUint32(frameControl[0:], sequence)
+		binary.BigEndian.PutUint32(frameControl[4:], uint32(size.X))
+		binary.BigEndian.PutUint32(frameControl[8:], uint32(size.Y))
+		binary.BigEndian.PutUint16(frameControl[20:], uint16(delays[i]))
+		binary.BigEndian.PutUint16(frameControl[22:], 100)
+		// Each frame replaces the whole canvas, including any transparent areas.
+		frameControl[24], frameControl[25] = 0, 0
+		pw.chunk("fcTL", frameControl)
+		sequence++
+		data := apngFrameData(frame)
+		if i == 0 {
+			pw.chunk("IDAT", data)
+			continue
+		}
+		frameData := make([]byte, 4, 4+len(data))
+		binary.BigEndian.PutUint32(frameData, sequence)
+		pw.chunk("fdAT", append(frameData, data...))
+		sequence++
+	}
+	pw.chunk("IEND", nil)
+	return pw.err
+}
+
+// apngFrameData compresses the unfiltered RGBA rows of a frame.
+func apngFrameData(frame image.Image) []byte {
+	bounds := frame.Bounds()
+	var buf bytes.Buffer
+	z := zlib.NewWriter(&buf)
+	row := make([]byte, 1+4*bounds.Dx())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(frame.At(x, y)).(color.NRGBA)
+			i := 1 + 4*(x-bounds.Min.X)
+			row[i], row[i+1], row[i+2], row[i+3] = c.R, c.G, c.B, c.A
+		}
+		z.Write(row)
+	}
+	z.Close()
+	return buf.Bytes()
+}
+
+// pngWriter writes PNG chunks, keeping the first error encountered.
+type pngWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (pw *pngWriter) write(data []byte) {
+	if pw.err == nil {
+		_, pw.err = pw.w.Write(data)
+	}
+}
+
+func (pw *pngWriter) chunk(name string, data []byte) {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(len(data)))
+	copy(header[4:], name)
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	footer := make([]byte, 4)
+	binary.BigEndian.PutUint32(footer, crc.Sum32())
+	pw.write(header)
+	pw.write(data)
+	pw.write(footer)
+}
+
+// colourCount is a colour and the number of pixels using it.
+type colourCount struct {
+	colour color.NRGBA
+	count  int
+}
+
+// sharedPalette builds a palette of at most maxColours colours representing every frame, with a transparent entry if any pixel is mostly transparent. If the frames use few enough colours the palette is exact, otherwise colours are chosen by median cut.
+func sharedPalette(frames []image.Image, maxColours int) (color.Palette, bool) {
+	exact := map[color.NRGBA]int{}
+	reduced := map[color.NRGBA]int{}
+	transparent := false
+	for _, frame := range frames {
+		bounds := frame.Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				c := color.NRGBAModel.Convert(frame.At(x, y)).(color.NRGBA)
+				if c.A < 128 {
+					transparent = true
+					continue
+				}
+				c.A = 255
+				if len(exact) <= maxColours {
+					exact[c]++
+				}
+				// Keep 5 bits per channel, so large images with many colours do not need a huge histogram.
+				reduced[color.NRGBA{R: c.R &^ 7, G: c.G &^ 7, B: c.B &^ 7, A: 255}]++
+			}
+		}
+	}
+	available := maxColours
+	var palette color.Palette
+	if transparent {
+		palette = append(palette, color.NRGBA{})
+		available--
+	}
+	if len(exact) <= available {
+		colours := make([]colourCount, 0, len(exact))
+		for c, count := range exact {
+			colours = append(colours, colourCount{colour: c, count: count})
+		}
+		sortColours(colours)
+		for _, c := range colours {
+			palette = append(palette, c.colour)
+		}
+		return palette, true
+	}
+	colours := make([]colourCount, 0, len(reduced))
+	for c, count := range reduced {
+		colours = append(colours, colourCount{colour: c, count: count})
+	}
+	sortColours(colours)
+	for _, box := range medianCut(colours, available) {
+		palette = append(palette, box.average())
+	}
+	return palette, false
+}
+
+// sortColours orders colours by descending popularity, then by value, so palettes are deterministic.
+func sortColours(colours []colourCount) {
+	sort.Slice(colours, func(i, j int) bool {
+		if colours[i].count != colours[j].count {
+			return colours[i].count > colours[j].count
+		}
+		a, b := colours[i].colour, colours[j].colour
+		return uint32(a.R)<<16|uint32(a.G)<<8|uint32(a.B) < uint32(b.R)<<16|uint32(b.G)<<8|uint32(b.B)
+	})
+}
+
+// colourBox is a group of colours being split by median cut.
+type colourBox []colourCount
+
+func (box colourBox) channel(c color.NRGBA, channel int) uint8 {
+	switch channel {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	default:
+		return c.B
+	}
+}
+
+// widest returns the channel with the largest range in the box, and that range.
+func (box colourBox) widest() (int, int) {
+	bestChannel, bestRange := 0, -1
+	for channel := 0; channel < 3; channel++ {
+		min, max := 255, 0
+		for _, c := range box {
+			value := int(box.channel(c.colour, channel))
+			if value < min {
+				min = value
+			}
+			if value > max {
+				max = value
+			}
+		}
+		if max-min > bestRange {
+			bestChannel, bestRange = channel, max-min
+		}
+	}
+	return bestChannel, bestRange
+}
+
+// average returns the mean colour of the box, weighted by popularity.
+func (box colourBox) average() color.NRGBA {
+	var r, g, b, total int
+	for _, c := range box {
+		r += int(c.colour.R) * c.count
+		g += int(c.colour.G) * c.count
+		b += int(c.colour.B) * c.count
+		total += c.count
+	}
+	return color.NRGBA{R: uint8(r / total), G: uint8(g / total), B: uint8(b / total), A: 255}
+}
+
+// medianCut repeatedly splits the box with the widest channel range at its weighted median, until there are as many boxes as requested or no box can be split.
+func medianCut(colours []colourCount, boxes int) []colourBox {
+	result := []colourBox{colours}
+	for len(result) < boxes {
+		split, splitChannel, splitRange := -1, 0, 0
+		for i, box := range result {
+			if len(box) < 2 {
+				continue
+			}
+			channel, channelRange := box.widest()
+			if channelRange > splitRange {
+				split, splitChannel, splitRange = i, channel, channelRange
+			}
+		}
+		if split < 0 {
+			break
+		}
+		box := result[split]
+		sort.SliceStable(box, func(i, j int) bool {
+			return box.channel(box[i].colour, splitChannel) < box.channel(box[j].colour, splitChannel)
+		})
+		total := 0
+		for _, c := range box {
+			total += c.count
+		}
+		median, seen := 1, 0
+		for i, c := range box[:len(box)-1] {
+			seen += c.count
+			if seen*2 >= total {
+				median = i + 1
+				break
+			}
+		}
+		result[split] = box[:median:median]
+		result = append(result, box[median:])
+	}
+	return result
+}
@@ -0,0 +1,251 @@
+package scaffold
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func solidFrame(width, height int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func gradientFrame(offset int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 4), G: uint8(y * 4), B: uint8(offset), A: 255})
+		}
+	}
+	return img
+}
+
+func TestCheckFrames(t *testing.T) {
+	red := solidFrame(4, 4, color.NRGBA{R: 255, A: 255})
+	t.Run("no frames", func(t *testing.T) {
+		delays, err := checkFrames(nil, []int{1})
+		assert.Nil(t, delays)
+		assert.EqualError(t, err, "no frames to animate")
+	})
+	t.Run("shared delay", func(t *testing.T) {
+		delays, err := checkFrames([]image.Image{red, red, red}, []int{7})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{7, 7, 7}, delays)
+	})
+	t.Run("wrong number of delays", func(t *testing.T) {
+		delays, err := checkFrames([]image.Image{red, red}, nil)
+		assert.Nil(t, delays)
+		assert.EqualError(t, err, "expected 2 delays, got 0")
+	})
+	t.Run("negative delay", func(t *testing.T) {
+		delays, err := checkFrames([]image.Image{red, red}, []int{5, -1})
+		assert.Nil(t, delays)
+		assert.EqualError(t, err, "invalid delay -1 for frame 1")
+	})
+	t.Run("delay too long", func(t *testing.T) {
+		delays, err := checkFrames([]image.Image{red, red}, []int{65535, 65536})
+		assert.Nil(t, delays)
+		assert.EqualError(t, err, "invalid delay 65536 for frame 1")
+	})
+	t.Run("mismatched sizes", func(t *testing.T) {
+		delays, err := checkFrames([]image.Image{red, solidFrame(5, 4, color.White)}, []int{5})
+		assert.Nil(t, delays)
+		assert.EqualError(t, err, "frame 1 is (5,4), expected (4,4)")
+	})
+	t.Run("offset bounds", func(t *testing.T) {
+		offset := image.NewNRGBA(image.Rect(10, 10, 14, 14))
+		delays, err := checkFrames([]image.Image{red, offset}, []int{5, 6})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{5, 6}, delays)
+	})
+}
+
+func TestSharedPalette(t *testing.T) {
+	t.Run("exact", func(t *testing.T) {
+		red := solidFrame(4, 4, color.NRGBA{R: 255, A: 255})
+		half := solidFrame(4, 4, color.NRGBA{B: 255, A: 255})
+		for x := 0; x < 4; x++ {
+			half.Set(x, 0, color.NRGBA{R: 255, A: 255})
+		}
+		palette, exact := sharedPalette([]image.Image{red, half}, 256)
+		assert.True(t, exact)
+		// Red is used by more pixels, so it comes first.
+		assert.Equal(t, color.Palette{color.NRGBA{R: 255, A: 255}, color.NRGBA{B: 255, A: 255}}, palette)
+	})
+	t.Run("transparent", func(t *testing.T) {
+		clear := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+		clear.Set(0, 0, color.NRGBA{G: 255, A: 255})
+		palette, exact := sharedPalette([]image.Image{clear}, 256)
+		assert.True(t, exact)
+		assert.Equal(t, color.Palette{color.NRGBA{}, color.NRGBA{G: 255, A: 255}}, palette)
+	})
+	t.Run("median cut", func(t *testing.T) {
+		palette, exact := sharedPalette([]image.Image{gradientFrame(0), gradientFrame(255)}, 16)
+		assert.False(t, exact)
+		assert.Len(t, palette, 16)
+		// Both ends of the blue channel are represented.
+		var minBlue, maxBlue uint8 = 255, 0
+		for _, c := range palette {
+			b := c.(color.NRGBA).B
+			if b < minBlue {
+				minBlue = b
+			}
+			if b > maxBlue {
+				maxBlue = b
+			}
+		}
+		assert.Equal(t, uint8(0), minBlue)
+		assert.True(t, maxBlue > 200, "expected a strongly blue palette entry, got %d", maxBlue)
+	})
+	t.Run("fewer colours than boxes", func(t *testing.T) {
+		boxes := medianCut([]colourCount{{colour: color.NRGBA{A: 255}, count: 3}}, 4)
+		assert.Len(t, boxes, 1)
+	})
+}
+
+func TestWriteAnimatedGIF(t *testing.T) {
+	red := solidFrame(4, 3, color.NRGBA{R: 255, A: 255})
+	blue := solidFrame(4, 3, color.NRGBA{B: 255, A: 255})
+	t.Run("invalid frames", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteAnimatedGIF(&buf, nil, nil)
+		assert.EqualError(t, err, "no frames to animate")
+		assert.Equal(t, 0, buf.Len())
+	})
+	t.Run("exact colours", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteAnimatedGIF(&buf, []image.Image{red, blue, red}, []int{10, 20, 30})
+		assert.NoError(t, err)
+		animation, err := gif.DecodeAll(&buf)
+		if assert.NoError(t, err) {
+			assert.Equal(t, []int{10, 20, 30}, animation.Delay)
+			assert.Equal(t, 0, animation.LoopCount)
+			assert.Equal(t, 4, animation.Config.Width)
+			assert.Equal(t, 3, animation.Config.Height)
+			// Every frame uses the global colour table.
+			global := animation.Config.ColorModel.(color.Palette)
+			assert.Len(t, global, 2)
+			for _, frame := range animation.Image {
+				assert.Equal(t, global, frame.Palette)
+			}
+			assertSameColour(t, color.NRGBA{R: 255, A: 255}, animation.Image[0].At(1, 1))
+			assertSameColour(t, color.NRGBA{B: 255, A: 255}, animation.Image[1].At(1, 1))
+			assert.Equal(t, []byte{gif.DisposalNone, gif.DisposalNone, gif.DisposalNone}, animation.Disposal)
+		}
+	})
+	t.Run("transparent frames", func(t *testing.T) {
+		clear := image.NewNRGBA(image.Rect(0, 0, 4, 3))
+		var buf bytes.Buffer
+		err := WriteAnimatedGIF(&buf, []image.Image{red, clear}, []int{5})
+		assert.NoError(t, err)
+		animation, err := gif.DecodeAll(&buf)
+		if assert.NoError(t, err) {
+			assert.Equal(t, []byte{gif.DisposalBackground, gif.DisposalBackground}, animation.Disposal)
+			_, _, _, a := animation.Image[1].At(0, 0).RGBA()
+			assert.Equal(t, uint32(0), a)
+		}
+	})
+	t.Run("many colours", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteAnimatedGIF(&buf, []image.Image{gradientFrame(0), gradientFrame(128)}, []int{5})
+		assert.NoError(t, err)
+		animation, err := gif.DecodeAll(&buf)
+		if assert.NoError(t, err) {
+			assert.Len(t, animation.Image, 2)
+			assert.Len(t, animation.Config.ColorModel.(color.Palette), 256)
+		}
+	})
+	t.Run("failing writer", func(t *testing.T) {
+		err := WriteAnimatedGIF(failingWriter{}, []image.Image{red}, []int{5})
+		assert.EqualError(t, err, "writer closed")
+	})
+}
+
+func assertSameColour(t *testing.T, expected, actual color.Color) {
+	er, eg, eb, ea := expected.RGBA()
+	ar, ag, ab, aa := actual.RGBA()
+	assert.Equal(t, [4]uint32{er, eg, eb, ea}, [4]uint32{ar, ag, ab, aa})
+}
+
+// apngChunk is a decoded PNG chunk.
+type apngChunk struct {
+	name string
+	data []byte
+}
+
+// readPNGChunks splits PNG data into chunks, checking each CRC.
+func readPNGChunks(t *testing.T, data []byte) []apngChunk {
+	if !assert.True(t, bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")), "missing PNG signature") {
+		return nil
+	}
+	data = data[8:]
+	var chunks []apngChunk
+	for len(data) >= 12 {
+		length := binary.BigEndian.Uint32(data)
+		chunk := apngChunk{name: string(data[4:8]), data: data[8 : 8+length]}
+		assert.Equal(t, crc32.ChecksumIEEE(data[4:8+length]), binary.BigEndian.Uint32(data[8+length:]), "bad CRC for %s", chunk.name)
+		chunks = append(chunks, chunk)
+		data = data[12+length:]
+	}
+	assert.Empty(t, data)
+	return chunks
+}
+
+func TestWriteAPNG(t *testing.T) {
+	red := solidFrame(4, 3, color.NRGBA{R: 255, A: 255})
+	clear := image.NewNRGBA(image.Rect(0, 0, 4, 3))
+	t.Run("invalid frames", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteAPNG(&buf, []image.Image{red}, []int{1, 2})
+		assert.EqualError(t, err, "expected 1 delays, got 2")
+		assert.Equal(t, 0, buf.Len())
+	})
+	t.Run("frames", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteAPNG(&buf, []image.Image{red, clear, red}, []int{10, 20, 30})
+		assert.NoError(t, err)
+		data := buf.Bytes()
+		// The first frame is the default image.
+		img, err := png.Decode(bytes.NewReader(data))
+		if assert.NoError(t, err) {
+			assert.Equal(t, image.Rect(0, 0, 4, 3), img.Bounds())
+			assertSameColour(t, color.NRGBA{R: 255, A: 255}, img.At(2, 2))
+		}
+		var names []string
+		var sequence []uint32
+		var delays []uint16
+		for _, chunk := range readPNGChunks(t, data) {
+			names = append(names, chunk.name)
+			switch chunk.name {
+			case "acTL":
+				assert.Equal(t, []byte{0, 0, 0, 3, 0, 0, 0, 0}, chunk.data)
+			case "fcTL":
+				sequence = append(sequence, binary.BigEndian.Uint32(chunk.data))
+				delays = append(delays, binary.BigEndian.Uint16(chunk.data[20:]))
+				assert.Equal(t, uint16(100), binary.BigEndian.Uint16(chunk.data[22:]))
+			case "fdAT":
+				sequence = append(sequence, binary.BigEndian.Uint32(chunk.data))
+			}
+		}
+		assert.Equal(t, []string{"IHDR", "acTL", "fcTL", "IDAT", "fcTL", "fdAT", "fcTL", "fdAT", "IEND"}, names)
+		assert.Equal(t, []uint32{0, 1, 2, 3, 4}, sequence)
+		assert.Equal(t, []uint16{10, 20, 30}, delays)
+	})
+	t.Run("failing writer", func(t *testing.T) {
+		err := WriteAPNG(failingWriter{}, []image.Image{red}, []int{5})
+		assert.EqualError(t, err, "writer closed")
+	})
+}
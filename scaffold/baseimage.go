@@ -19,7 +19,7 @@ func (builder ImageBuilder) setBackgroundImage(template Template) (b ImageBuilde
 	// Check the state of the optional and required properties
 	dataSet := template.BaseImage.Data != ""
 	fileSet := template.BaseImage.FileName != ""
-	baseColourSet := template.BaseImage.BaseWidth != "" && template.BaseImage.BaseHeight != "" && (template.BaseImage.BaseColour.Red != "" || template.BaseImage.BaseColour.Green != "" || template.BaseImage.BaseColour.Blue != "" || template.BaseImage.BaseColour.Alpha != "")
+	baseColourSet := template.BaseImage.BaseWidth != "" && template.BaseImage.BaseHeight != "" && (template.BaseImage.BaseColour.Red != "" || template.BaseImage.BaseColour.Green != "" || template.BaseImage.BaseColour.Blue != "" || template.BaseImage.BaseColour.Alpha != "" || template.BaseImage.BaseColour.Gradient != nil)
 	oneSet := dataSet || fileSet || baseColourSet
 	if !oneSet {
 		return builder.SetCanvas(builder.GetCanvas()).(ImageBuilder), nil
@@ -43,8 +43,8 @@ func (builder ImageBuilder) setBaseData(template Template) (ImageBuilder, error)
 	// Get image data from string
 	sReader := strings.NewReader(template.BaseImage.Data)
 	imageData := base64.NewDecoder(base64.StdEncoding, sReader)
-	// Decode image data
-	baseImage, _, err := image.Decode(imageData)
+	// Decode image data, rotating photos upright according to their EXIF orientation
+	baseImage, err := imaging.Decode(imageData, imaging.AutoOrientation(true))
 	if err != nil {
 		return builder, err
 	}
@@ -117,8 +117,8 @@ func (builder ImageBuilder) setBaseFile(template Template) (ImageBuilder, error)
 	}
 	defer imgFile.Close()
 	imageData := imgFile
-	// Decode image data
-	baseImage, _, err := image.Decode(imageData)
+	// Decode image data, rotating photos upright according to their EXIF orientation
+	baseImage, err := imaging.Decode(imageData, imaging.AutoOrientation(true))
 	if err != nil {
 		return builder, err
 	}
@@ -137,6 +137,11 @@ func (builder ImageBuilder) setBaseColour(template Template) (b ImageBuilder, er
 		return builder, err
 	}
 	height := int(height64)
+	rectangle := image.Rect(0, 0, width, height)
+	baseImage := image.NewNRGBA(rectangle)
+	if template.BaseImage.BaseColour.Gradient != nil {
+		return b.setBaseGradient(template, baseImage)
+	}
 	red64, err := strconv.ParseUint(template.BaseImage.BaseColour.Red, 0, 8)
 	if err != nil {
 		return builder, err
@@ -157,9 +162,24 @@ func (builder ImageBuilder) setBaseColour(template Template) (b ImageBuilder, er
 		return builder, err
 	}
 	alpha := uint8(alpha64)
-	rectangle := image.Rect(0, 0, width, height)
-	baseImage := image.NewNRGBA(rectangle)
 	colourPlane := image.NewUniform(color.NRGBA{R: red, G: green, B: blue, A: alpha})
 	draw.Draw(baseImage, rectangle, colourPlane, image.Point{X: 0, Y: 0}, draw.Over)
 	return b.baseConvertAndResize(baseImage, template)
 }
+
+func (builder ImageBuilder) setBaseGradient(template Template, baseImage *image.NRGBA) (ImageBuilder, error) {
+	baseColour := template.BaseImage.BaseColour
+	if baseColour.Red != "" || baseColour.Green != "" || baseColour.Blue != "" || baseColour.Alpha != "" {
+		return builder, fmt.Errorf("a base colour takes either a colour or a gradient, not both")
+	}
+	gradient, props, err := cutils.ParseGradient(*baseColour.Gradient, "gradient", map[string][]string{})
+	if err != nil {
+		return builder, err
+	}
+	// The base image is drawn before any named properties are set, so they can't be used here
+	if len(props) != 0 {
+		return builder, fmt.Errorf("a base gradient cannot use named properties: %v", props)
+	}
+	draw.Draw(baseImage, baseImage.Rect, gradient.Image(baseImage.Rect), image.Point{X: 0, Y: 0}, draw.Over)
+	return builder.baseConvertAndResize(baseImage, template)
+}
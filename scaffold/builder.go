@@ -2,26 +2,32 @@
 package scaffold
 
 import (
-	"bytes"
 	"encoding/json"
+	_ "image/gif"  // gif imported for image decoding
 	_ "image/jpeg" // jpeg imported for image decoding
 	_ "image/png"  // png imported for image decoding
+	"io"
 	"io/ioutil"
 
 	_ "github.com/LLKennedy/imagetemplate/v3/components/barcode"   // add barcode component to registry by default
 	_ "github.com/LLKennedy/imagetemplate/v3/components/circle"    // add circle component to registry by default
 	_ "github.com/LLKennedy/imagetemplate/v3/components/datetime"  // add datetime component to registry by default
+	_ "github.com/LLKennedy/imagetemplate/v3/components/ellipse"   // add ellipse component to registry by default
 	_ "github.com/LLKennedy/imagetemplate/v3/components/image"     // add image component to registry by default
+	_ "github.com/LLKennedy/imagetemplate/v3/components/line"      // add line component to registry by default
+	_ "github.com/LLKennedy/imagetemplate/v3/components/path"      // add path component to registry by default
 	_ "github.com/LLKennedy/imagetemplate/v3/components/rectangle" // add rectangle component to registry by default
 	_ "github.com/LLKennedy/imagetemplate/v3/components/text"      // add text component to registry by default
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
 	"github.com/LLKennedy/imagetemplate/v3/render"
 
-	"golang.org/x/image/bmp"
+	_ "golang.org/x/image/bmp"  // bmp imported for image decoding
 	_ "golang.org/x/image/tiff" // tiff imported for image decoding
+	_ "golang.org/x/image/webp" // webp imported for image decoding
 	"golang.org/x/tools/godoc/vfs"
 )
 
-// Builder manipulates Canvas objects and outputs to a bitmap.
+// Builder manipulates Canvas objects and outputs to a bitmap or other image formats.
 type Builder interface {
 	GetCanvas() render.Canvas
 	SetCanvas(newCanvas render.Canvas) Builder
@@ -33,6 +39,17 @@ type Builder interface {
 	LoadComponentsFile(fileName string) (Builder, error)
 	LoadComponentsData(fileData []byte) (Builder, error)
 	WriteToBMP() ([]byte, error)
+	WriteToPNG() ([]byte, error)
+	WriteToJPEG(quality int) ([]byte, error)
+	WriteToGIF() ([]byte, error)
+	WriteToTIFF() ([]byte, error)
+	WriteToFormat(format ImageFormat, opts *EncodeOptions) ([]byte, error)
+	WriteFormatTo(w io.Writer, format ImageFormat, opts *EncodeOptions) error
+	WriteToPDF() ([]byte, error)
+	WriteToSVG() ([]byte, error)
+	WriteToZPL() ([]byte, error)
+	WriteToESCPOS(dotWidth int) ([]byte, error)
+	WriteToDitheredPNG(opts DitherOptions) ([]byte, error)
 }
 
 // Template is the format of the JSON file used as a template for building images. See samples.json for examples, each element in the samples array is a complete and valid template object.
@@ -49,7 +66,7 @@ type BaseImage struct {
 	FileName string `json:"fileName"`
 	// Data is the base64-encoded data to load the base image from.
 	Data string `json:"data"`
-	// BaseColour is the pure colour to use as a base image.
+	// BaseColour is the pure colour or gradient to use as a base image.
 	BaseColour BaseColour `json:"baseColour"`
 	// BaseWidth is the width to use for pure colour.
 	BaseWidth string `json:"width"`
@@ -69,6 +86,8 @@ type BaseColour struct {
 	Blue string `json:"B"`
 	// Alpha is the alpha channel.
 	Alpha string `json:"A"`
+	// Gradient is the gradient to use instead of a pure colour.
+	Gradient *cutils.GradientStrings `json:"gradient"`
 }
 
 // ComponentTemplate is a partial unmarshalled Component, with its properties left in raw form to be handled by each known type of Component.
@@ -79,6 +98,87 @@ type ComponentTemplate struct {
 	Conditional render.ComponentConditional `json:"conditional"`
 	// Properties are the raw, unprocessed JSON data for the component to parse
 	Properties json.RawMessage `json:"properties"`
+	// Opacity is the opacity of the whole component, from 0 to 1.
+	Opacity string `json:"opacity"`
+	// BlendMode is how the colours of the component are mixed with the canvas beneath it.
+	BlendMode string `json:"blendMode"`
+	// Transform rotates, scales and skews the component.
+	Transform *TransformTemplate `json:"transform"`
+	// Clip is the shape the component is clipped to.
+	Clip *ClipTemplate `json:"clip"`
+	// Blur is the standard deviation in pixels of a Gaussian blur softening the component.
+	Blur string `json:"blur"`
+	// Shadow is a shadow or glow drawn beneath the component.
+	Shadow *ShadowTemplate `json:"shadow"`
+}
+
+// TransformTemplate is the template format of a component's transform.
+type TransformTemplate struct {
+	// Rotate is the clockwise rotation in degrees.
+	Rotate string `json:"rotate"`
+	// Scale scales the component by the same amount in both directions.
+	Scale string `json:"scale"`
+	// ScaleX is the horizontal scale.
+	ScaleX string `json:"scaleX"`
+	// ScaleY is the vertical scale.
+	ScaleY string `json:"scaleY"`
+	// SkewX is the angle in degrees to skew the component along the horizontal axis.
+	SkewX string `json:"skewX"`
+	// SkewY is the angle in degrees to skew the component along the vertical axis.
+	SkewY string `json:"skewY"`
+	// AnchorX is the horizontal position of the point to transform the component about.
+	AnchorX string `json:"anchorX"`
+	// AnchorY is the vertical position of the point to transform the component about.
+	AnchorY string `json:"anchorY"`
+}
+
+// ClipTemplate is the template format of the shape a component is clipped to. Which properties are used depends on the shape.
+type ClipTemplate struct {
+	// Shape is the kind of shape, one of circle, rectangle, path or image.
+	Shape string `json:"shape"`
+	// CentreX is the horizontal position of the centre of a circle.
+	CentreX string `json:"centreX"`
+	// CentreY is the vertical position of the centre of a circle.
+	CentreY string `json:"centreY"`
+	// Radius is the radius of a circle.
+	Radius string `json:"radius"`
+	// TopLeftX is the horizontal position of the top-left corner of a rectangle or image.
+	TopLeftX string `json:"topLeftX"`
+	// TopLeftY is the vertical position of the top-left corner of a rectangle or image.
+	TopLeftY string `json:"topLeftY"`
+	// Width is the width of a rectangle.
+	Width string `json:"width"`
+	// Height is the height of a rectangle.
+	Height string `json:"height"`
+	// CornerRadius is the radius of the rounded corners of a rectangle.
+	CornerRadius string `json:"cornerRadius"`
+	// Path is the SVG path data of a path.
+	Path string `json:"path"`
+	// FileName is the file to load an image from.
+	FileName string `json:"fileName"`
+	// Data is the base64-encoded data to load an image from.
+	Data string `json:"data"`
+}
+
+// ShadowTemplate is the template format of a component's shadow.
+type ShadowTemplate struct {
+	// OffsetX is the horizontal distance to move the shadow from the component.
+	OffsetX string `json:"offsetX"`
+	// OffsetY is the vertical distance to move the shadow from the component.
+	OffsetY string `json:"offsetY"`
+	// Blur is the standard deviation in pixels of a Gaussian blur softening the shadow.
+	Blur string `json:"blur"`
+	// Colour is the colour of the shadow, defaulting to translucent black.
+	Colour *struct {
+		// Red is the red channel.
+		Red string `json:"R"`
+		// Green is the green channel.
+		Green string `json:"G"`
+		// Blue is the blue channel.
+		Blue string `json:"B"`
+		// Alpha is the alpha channel.
+		Alpha string `json:"A"`
+	} `json:"colour"`
 }
 
 // ToggleableComponent is a component with its conditional.
@@ -87,6 +187,8 @@ type ToggleableComponent struct {
 	Conditional render.ComponentConditional
 	// Component is the component to render.
 	Component render.Component
+	// Layer is the layer the component is drawn on, or nil to draw it directly on the canvas.
+	Layer *ComponentLayer
 }
 
 // ImageBuilder uses golang's native Image package to implement the Builder interface.
@@ -97,6 +199,10 @@ type ImageBuilder struct {
 	Components []ToggleableComponent
 	// NamedProperties are the user/application defined variables
 	NamedProperties render.NamedProperties
+	// templateHash is the hash of the template data the components were loaded from
+	templateHash string
+	// propertiesHash is the hash of the named properties last set
+	propertiesHash string
 	// fs is the file system
 	fs vfs.FileSystem
 }
@@ -111,12 +217,7 @@ func NewBuilder(fs vfs.FileSystem) Builder {
 
 // WriteToBMP outputs the contents of the builder to a BMP byte array.
 func (builder ImageBuilder) WriteToBMP() ([]byte, error) {
-	var buf bytes.Buffer
-	err := bmp.Encode(&buf, builder.GetCanvas().GetUnderlyingImage())
-	if err != nil {
-		return nil, err
-	}
-	return buf.Bytes(), nil
+	return builder.WriteToFormat(FormatBMP, nil)
 }
 
 // LoadComponentsFile sets the internal Component array based on the contents of the specified JSON file.
@@ -155,6 +256,7 @@ func (builder ImageBuilder) LoadComponentsData(fileData []byte) (Builder, error)
 	if err != nil {
 		return builder, err
 	}
+	b.templateHash = HashTemplate(fileData)
 
 	return b, nil
 }
@@ -191,6 +293,14 @@ func parseComponents(templates []ComponentTemplate) ([]ToggleableComponent, rend
 		for key, value := range compNamedProps {
 			tempProperties[key] = value
 		}
+		var layerNamedProps render.NamedProperties
+		result.Layer, layerNamedProps, err = parseLayer(template)
+		if err != nil {
+			return results, namedProperties, err
+		}
+		for key, value := range layerNamedProps {
+			tempProperties[key] = value
+		}
 		result.Component = newComponent
 		results = append(results, result)
 		namedProperties = tempProperties
@@ -238,46 +348,63 @@ func (builder ImageBuilder) GetNamedPropertiesList() render.NamedProperties {
 // SetNamedProperties sets the values of names properties in all components and conditionals in the builder.
 func (builder ImageBuilder) SetNamedProperties(properties render.NamedProperties) (Builder, error) {
 	b := builder
-	b.Components = builder.Components[:]
-	for tIndex, tComponent := range b.Components {
+	// The components are copied so that the builder can have its properties set again from the same starting point
+	b.Components = append([]ToggleableComponent(nil), builder.Components...)
+	err := setComponentsNamedProperties(b.Components, properties)
+	if err != nil {
+		return builder, err
+	}
+	b.propertiesHash = HashProperties(properties)
+	return b, nil
+}
+
+// setComponentsNamedProperties sets the values of named properties in each of the components, their layers and their conditionals in place.
+func setComponentsNamedProperties(components []ToggleableComponent, properties render.NamedProperties) error {
+	for tIndex, tComponent := range components {
 		var err error
 		tComponent.Component, err = tComponent.Component.SetNamedProperties(properties)
 		if err != nil {
-			return builder, err
+			return err
+		}
+		if tComponent.Layer != nil {
+			tComponent.Layer, err = tComponent.Layer.SetNamedProperties(properties)
+			if err != nil {
+				return err
+			}
 		}
 		for key, value := range properties {
 			tComponent.Conditional, err = tComponent.Conditional.SetValue(key, value)
 			if err != nil {
-				return builder, err
+				return err
 			}
 		}
-		b.Components[tIndex] = tComponent
+		components[tIndex] = tComponent
 	}
-	return b, nil
+	return nil
 }
 
 // ApplyComponents iterates over the internal Component array, applying each in turn to the Canvas.
 func (builder ImageBuilder) ApplyComponents() (Builder, error) {
 	b := builder
 	for _, tComponent := range b.Components {
-		if tComponent.Conditional.Name == "" {
-			var err error
-			b.Canvas, err = tComponent.Component.Write(b.GetCanvas())
-			if err != nil {
-				return builder, err
-			}
-		} else {
-			valid, err := tComponent.Conditional.Validate()
+		enabled, err := tComponent.enabled()
+		if err != nil {
+			return builder, err
+		}
+		if enabled {
+			b.Canvas, err = tComponent.write(b.GetCanvas())
 			if err != nil {
 				return builder, err
 			}
-			if valid {
-				b.Canvas, err = tComponent.Component.Write(b.GetCanvas())
-				if err != nil {
-					return builder, err
-				}
-			}
 		}
 	}
 	return b, nil
 }
+
+// enabled reports whether the component should be drawn, which it always should be without a conditional.
+func (tComponent ToggleableComponent) enabled() (bool, error) {
+	if tComponent.Conditional.Name == "" {
+		return true, nil
+	}
+	return tComponent.Conditional.Validate()
+}
@@ -1,12 +1,17 @@
 package scaffold
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
 	"image"
 	"image/color"
+	"image/gif"
 	"testing"
 
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
 	fs "github.com/LLKennedy/imagetemplate/v3/internal/filesystem"
+	"github.com/LLKennedy/imagetemplate/v3/internal/testimage"
 	"github.com/LLKennedy/imagetemplate/v3/render"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/tools/godoc/vfs"
@@ -50,6 +55,7 @@ func TestWriteToBMP(t *testing.T) {
 		img := fakeImage{bounds: image.Rectangle{Min: image.Pt(0, 0), Max: image.Pt(-1, -1)}}
 		canvas := new(render.MockCanvas)
 		canvas.On("GetUnderlyingImage").Return(&img)
+		canvas.On("GetPPI").Return(float64(0))
 		newBuilder = newBuilder.SetCanvas(canvas)
 		data, err := newBuilder.WriteToBMP()
 		assert.EqualError(t, err, "bmp: negative bounds")
@@ -193,6 +199,26 @@ func TestSetBackgroundImageData(t *testing.T) {
 			template: Template{BaseImage: BaseImage{BaseWidth: "2", BaseHeight: "2", BaseColour: BaseColour{Red: "1", Green: "1", Blue: "1", Alpha: "a"}}},
 			err:      fmt.Errorf("strconv.ParseUint: parsing \"a\": invalid syntax"),
 		},
+		{
+			name:     "valid base gradient",
+			template: Template{BaseImage: BaseImage{BaseWidth: "2", BaseHeight: "1", BaseColour: BaseColour{Gradient: &cutils.GradientStrings{Stops: []cutils.GradientStopStrings{{Colour: cutils.ColourStrings{R: "255", G: "0", B: "0", A: "255"}}, {Colour: cutils.ColourStrings{R: "0", G: "0", B: "255", A: "255"}}}}}}},
+			result:   ImageBuilder{}.SetCanvas(render.ImageCanvas{}.SetPPI(72).SetUnderlyingImage(&image.NRGBA{Pix: []uint8{191, 0, 64, 255, 64, 0, 191, 255}, Rect: image.Rect(0, 0, 2, 1), Stride: 8})).(ImageBuilder),
+		},
+		{
+			name:     "base gradient and colour",
+			template: Template{BaseImage: BaseImage{BaseWidth: "2", BaseHeight: "1", BaseColour: BaseColour{Red: "1", Gradient: &cutils.GradientStrings{}}}},
+			err:      fmt.Errorf("a base colour takes either a colour or a gradient, not both"),
+		},
+		{
+			name:     "base gradient invalid",
+			template: Template{BaseImage: BaseImage{BaseWidth: "2", BaseHeight: "1", BaseColour: BaseColour{Gradient: &cutils.GradientStrings{}}}},
+			err:      fmt.Errorf("a gradient needs at least 2 colour stops, got 0"),
+		},
+		{
+			name:     "base gradient named property",
+			template: Template{BaseImage: BaseImage{BaseWidth: "2", BaseHeight: "1", BaseColour: BaseColour{Gradient: &cutils.GradientStrings{Stops: []cutils.GradientStopStrings{{Colour: cutils.ColourStrings{R: "$red$", G: "0", B: "0", A: "255"}}, {Colour: cutils.ColourStrings{R: "0", G: "0", B: "255", A: "255"}}}}}}},
+			err:      fmt.Errorf("a base gradient cannot use named properties: map[red:[gradientStop0R]]"),
+		},
 		{
 			name:     "invalid base64",
 			template: Template{BaseImage: BaseImage{Data: "a"}},
@@ -581,7 +607,9 @@ func TestSetNamedProperties(t *testing.T) {
 	t.Run("empty components list", func(t *testing.T) {
 		b := ImageBuilder{}
 		m, err := b.SetNamedProperties(render.NamedProperties{})
-		assert.Equal(t, b, m)
+		expected := b
+		expected.propertiesHash = HashProperties(render.NamedProperties{})
+		assert.Equal(t, expected, m)
 		assert.NoError(t, err)
 	})
 	t.Run("error setting component properties", func(t *testing.T) {
@@ -597,8 +625,12 @@ func TestSetNamedProperties(t *testing.T) {
 			{Component: &workingComponent{}},
 		}}
 		m, err := b.SetNamedProperties(render.NamedProperties{})
-		assert.Equal(t, b, m)
+		expected := b
+		expected.Components = []ToggleableComponent{{Component: &workingComponent{someVar: 1}}}
+		expected.propertiesHash = HashProperties(render.NamedProperties{})
+		assert.Equal(t, expected, m)
 		assert.NoError(t, err)
+		assert.Equal(t, []ToggleableComponent{{Component: &workingComponent{}}}, b.Components, "the original components should not change")
 	})
 	t.Run("property error", func(t *testing.T) {
 		b := ImageBuilder{Components: []ToggleableComponent{
@@ -659,3 +691,56 @@ func TestApplyComponents(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestSetBackgroundImageFormats(t *testing.T) {
+	isRed := func(c color.Color) bool {
+		r, g, b, _ := c.RGBA()
+		return r > 0xc000 && g < 0x4000 && b < 0x4000
+	}
+	load := func(t *testing.T, data []byte) render.Canvas {
+		template := Template{BaseImage: BaseImage{Data: base64.StdEncoding.EncodeToString(data)}}
+		result, err := ImageBuilder{}.setBackgroundImage(template)
+		assert.NoError(t, err)
+		return result.GetCanvas()
+	}
+	t.Run("jpeg without orientation", func(t *testing.T) {
+		canvas := load(t, testimage.OrientedJPEG(1))
+		assert.Equal(t, image.Rect(0, 0, 4, 2), canvas.GetUnderlyingImage().Bounds())
+		assert.True(t, isRed(canvas.GetUnderlyingImage().At(0, 1)))
+	})
+	t.Run("jpeg rotated clockwise", func(t *testing.T) {
+		canvas := load(t, testimage.OrientedJPEG(6))
+		img := canvas.GetUnderlyingImage()
+		assert.Equal(t, image.Rect(0, 0, 2, 4), img.Bounds())
+		// The left of the stored image becomes the top once upright.
+		assert.True(t, isRed(img.At(1, 0)))
+		assert.False(t, isRed(img.At(1, 3)))
+	})
+	t.Run("jpeg from file", func(t *testing.T) {
+		mfs := fs.NewMockFileSystem()
+		mfs.On("Open", "phone.jpg").Return(fs.NewMockFile("", testimage.OrientedJPEG(8)), nil)
+		result, err := ImageBuilder{fs: mfs}.setBackgroundImage(Template{BaseImage: BaseImage{FileName: "phone.jpg"}})
+		assert.NoError(t, err)
+		img := result.GetCanvas().GetUnderlyingImage()
+		assert.Equal(t, image.Rect(0, 0, 2, 4), img.Bounds())
+		// Rotated anticlockwise, so the left of the stored image becomes the bottom.
+		assert.True(t, isRed(img.At(1, 3)))
+		assert.False(t, isRed(img.At(1, 0)))
+	})
+	t.Run("webp", func(t *testing.T) {
+		data, err := base64.StdEncoding.DecodeString("UklGRiIAAABXRUJQVlA4IBYAAAAwAQCdASoBAAEADsD+JaQAA3AAAAAA")
+		assert.NoError(t, err)
+		canvas := load(t, data)
+		assert.Equal(t, image.Rect(0, 0, 1, 1), canvas.GetUnderlyingImage().Bounds())
+		assert.Equal(t, color.NRGBA{R: 128, G: 128, B: 128, A: 255}, canvas.GetUnderlyingImage().At(0, 0))
+	})
+	t.Run("gif", func(t *testing.T) {
+		img := image.NewPaletted(image.Rect(0, 0, 3, 2), color.Palette{color.Black, color.White})
+		img.SetColorIndex(2, 1, 1)
+		var buf bytes.Buffer
+		assert.NoError(t, gif.Encode(&buf, img, nil))
+		canvas := load(t, buf.Bytes())
+		assert.Equal(t, image.Rect(0, 0, 3, 2), canvas.GetUnderlyingImage().Bounds())
+		assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, canvas.GetUnderlyingImage().At(2, 1))
+	})
+}
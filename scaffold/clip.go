@@ -0,0 +1,179 @@
+package scaffold
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"strings"
+
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/disintegration/imaging"
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// ClipShape is the kind of shape a component is clipped to.
+type ClipShape string
+
+const (
+	// ClipCircle clips to a circle
+	ClipCircle ClipShape = "circle"
+	// ClipRectangle clips to a rectangle, optionally with rounded corners
+	ClipRectangle ClipShape = "rectangle"
+	// ClipPath clips to the area inside an SVG path
+	ClipPath ClipShape = "path"
+	// ClipImage clips to the alpha of an image, so that the component shows through wherever the image is opaque
+	ClipImage ClipShape = "image"
+)
+
+// ComponentClip is the shape a component is clipped to. The shape is kept as its dimensions and only built when the component is drawn, so that any of them can be named properties.
+type ComponentClip struct {
+	// Shape is the kind of shape, which decides the other properties used.
+	Shape ClipShape
+	// Centre is the centre of a circle.
+	Centre image.Point
+	// Radius is the radius of a circle.
+	Radius int
+	// TopLeft is the top-left corner of a rectangle or image.
+	TopLeft image.Point
+	// Width and Height are the size of a rectangle.
+	Width, Height int
+	// CornerRadius is the radius of the rounded corners of a rectangle.
+	CornerRadius float64
+	// Path is the path to clip to.
+	Path render.Path
+	// Mask is the image to clip to, placed with its top-left corner at TopLeft.
+	Mask image.Image
+}
+
+// parseClip reads the shape of a clip template, returning the properties it needs to be extracted.
+func parseClip(template ClipTemplate) (*ComponentClip, []layerProperty, error) {
+	clip := &ComponentClip{Shape: ClipShape(template.Shape)}
+	var required, optional []layerProperty
+	switch clip.Shape {
+	case ClipCircle:
+		required = []layerProperty{
+			{raw: template.CentreX, name: "centreX", typeName: render.IntType},
+			{raw: template.CentreY, name: "centreY", typeName: render.IntType},
+			{raw: template.Radius, name: "radius", typeName: render.IntType},
+		}
+	case ClipRectangle:
+		required = []layerProperty{
+			{raw: template.TopLeftX, name: "topLeftX", typeName: render.IntType},
+			{raw: template.TopLeftY, name: "topLeftY", typeName: render.IntType},
+			{raw: template.Width, name: "width", typeName: render.IntType},
+			{raw: template.Height, name: "height", typeName: render.IntType},
+		}
+		optional = []layerProperty{{raw: template.CornerRadius, name: "cornerRadius", typeName: render.Float64Type}}
+	case ClipPath:
+		required = []layerProperty{{raw: template.Path, name: "path", typeName: render.StringType}}
+	case ClipImage:
+		if (template.FileName == "") == (template.Data == "") {
+			return nil, nil, fmt.Errorf("an image clip needs exactly one of fileName and data")
+		}
+		optional = []layerProperty{
+			{raw: template.FileName, name: "fileName", typeName: render.StringType},
+			{raw: template.Data, name: "data", typeName: render.StringType},
+			{raw: template.TopLeftX, name: "topLeftX", typeName: render.IntType},
+			{raw: template.TopLeftY, name: "topLeftY", typeName: render.IntType},
+		}
+	default:
+		return nil, nil, fmt.Errorf("clip shape %v does not match defined constants", template.Shape)
+	}
+	for _, property := range required {
+		if property.raw == "" {
+			return nil, nil, fmt.Errorf("a %v clip needs %v", clip.Shape, property.name)
+		}
+	}
+	return clip, append(required, optional...), nil
+}
+
+func setClipProperty(clip *ComponentClip, name string, value interface{}) (err error) {
+	switch name {
+	case "centreX":
+		clip.Centre.X, err = cutils.SetInt(value)
+	case "centreY":
+		clip.Centre.Y, err = cutils.SetInt(value)
+	case "radius":
+		clip.Radius, err = cutils.SetInt(value)
+	case "topLeftX":
+		clip.TopLeft.X, err = cutils.SetInt(value)
+	case "topLeftY":
+		clip.TopLeft.Y, err = cutils.SetInt(value)
+	case "width":
+		clip.Width, err = cutils.SetInt(value)
+	case "height":
+		clip.Height, err = cutils.SetInt(value)
+	case "cornerRadius":
+		clip.CornerRadius, err = cutils.SetFloat64(value)
+	case "path":
+		var data string
+		data, err = cutils.SetString(value)
+		if err != nil {
+			return err
+		}
+		clip.Path, err = render.ParsePath(data)
+	case "fileName":
+		var fileName string
+		fileName, err = cutils.SetString(value)
+		if err != nil {
+			return err
+		}
+		clip.Mask, err = loadClipFile(fileName)
+	case "data":
+		clip.Mask, err = decodeClipData(value)
+	default:
+		err = fmt.Errorf("invalid component property in named property map: %v", name)
+	}
+	return err
+}
+
+func loadClipFile(fileName string) (image.Image, error) {
+	file, err := vfs.OS(".").Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return imaging.Decode(file, imaging.AutoOrientation(true))
+}
+
+func decodeClipData(value interface{}) (image.Image, error) {
+	var reader io.Reader
+	switch data := value.(type) {
+	case image.Image:
+		return data, nil
+	case []byte:
+		reader = bytes.NewReader(data)
+	case string:
+		reader = base64.NewDecoder(base64.StdEncoding, strings.NewReader(data))
+	case io.Reader:
+		reader = data
+	default:
+		return nil, fmt.Errorf("error converting %v to image.Image, []byte, string or io.Reader", value)
+	}
+	return imaging.Decode(reader, imaging.AutoOrientation(true))
+}
+
+// toClip builds the shape of the clip.
+func (clip ComponentClip) toClip() *render.Clip {
+	switch clip.Shape {
+	case ClipCircle:
+		return &render.Clip{Path: render.NewEllipsePath(clip.Centre, clip.Radius, clip.Radius)}
+	case ClipRectangle:
+		radius := clip.CornerRadius
+		return &render.Clip{Path: render.NewRectanglePath(clip.TopLeft, clip.Width, clip.Height, render.CornerRadii{TopLeft: radius, TopRight: radius, BottomRight: radius, BottomLeft: radius})}
+	case ClipPath:
+		return &render.Clip{Path: clip.Path}
+	}
+	if clip.Mask == nil {
+		// Nothing to show through.
+		return &render.Clip{}
+	}
+	bounds := clip.Mask.Bounds()
+	mask := image.NewNRGBA(bounds.Sub(bounds.Min).Add(clip.TopLeft))
+	draw.Draw(mask, mask.Bounds(), clip.Mask, bounds.Min, draw.Src)
+	return &render.Clip{Mask: mask}
+}
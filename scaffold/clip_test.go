@@ -0,0 +1,165 @@
+package scaffold
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseClip(t *testing.T) {
+	placeholder := struct{ Message string }{Message: "Please replace me with real data"}
+	normal := render.LayerStyle{Opacity: 1, BlendMode: render.BlendNormal}
+	path, _ := render.ParsePath("M 0 0 L 4 0 L 0 4 Z")
+	type testSet struct {
+		name     string
+		template ClipTemplate
+		layer    *ComponentLayer
+		props    render.NamedProperties
+		err      string
+	}
+	tests := []testSet{
+		{
+			name:     "circle",
+			template: ClipTemplate{Shape: "circle", CentreX: "5", CentreY: "$y$", Radius: "4", Width: "ignored"},
+			layer: &ComponentLayer{
+				NamedPropertiesMap: map[string][]string{"y": {"centreY"}},
+				Style:              normal,
+				Clip:               &ComponentClip{Shape: ClipCircle, Centre: image.Point{X: 5}, Radius: 4},
+			},
+			props: render.NamedProperties{"y": placeholder},
+		},
+		{
+			name:     "rectangle",
+			template: ClipTemplate{Shape: "rectangle", TopLeftX: "1", TopLeftY: "2", Width: "3", Height: "4", CornerRadius: "1.5"},
+			layer: &ComponentLayer{
+				NamedPropertiesMap: map[string][]string{},
+				Style:              normal,
+				Clip:               &ComponentClip{Shape: ClipRectangle, TopLeft: image.Pt(1, 2), Width: 3, Height: 4, CornerRadius: 1.5},
+			},
+			props: render.NamedProperties{},
+		},
+		{
+			name:     "path",
+			template: ClipTemplate{Shape: "path", Path: "M 0 0 L 4 0 L 0 4 Z"},
+			layer:    &ComponentLayer{NamedPropertiesMap: map[string][]string{}, Style: normal, Clip: &ComponentClip{Shape: ClipPath, Path: path}},
+			props:    render.NamedProperties{},
+		},
+		{
+			name:     "image",
+			template: ClipTemplate{Shape: "image", FileName: "$mask$", TopLeftX: "3"},
+			layer: &ComponentLayer{
+				NamedPropertiesMap: map[string][]string{"mask": {"fileName"}},
+				Style:              normal,
+				Clip:               &ComponentClip{Shape: ClipImage, TopLeft: image.Point{X: 3}},
+			},
+			props: render.NamedProperties{"mask": placeholder},
+		},
+		{
+			name:     "image with both sources",
+			template: ClipTemplate{Shape: "image", FileName: "mask.png", Data: "abcd"},
+			props:    render.NamedProperties{},
+			err:      "an image clip needs exactly one of fileName and data",
+		},
+		{
+			name:     "missing property",
+			template: ClipTemplate{Shape: "rectangle", TopLeftX: "1", TopLeftY: "2", Width: "3"},
+			props:    render.NamedProperties{},
+			err:      "a rectangle clip needs height",
+		},
+		{
+			name:     "invalid shape",
+			template: ClipTemplate{Shape: "star"},
+			props:    render.NamedProperties{},
+			err:      "clip shape star does not match defined constants",
+		},
+		{
+			name:     "invalid path",
+			template: ClipTemplate{Shape: "path", Path: "M 0"},
+			props:    render.NamedProperties{},
+			err:      "expected a number in path data at offset 3",
+		},
+		{
+			name:     "invalid data",
+			template: ClipTemplate{Shape: "image", Data: "abcd"},
+			props:    render.NamedProperties{},
+			err:      "image: unknown format",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			layer, props, err := parseLayer(ComponentTemplate{Clip: &test.template})
+			assert.Equal(t, test.layer, layer)
+			assert.Equal(t, test.props, props)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestComponentClipSetNamedProperties(t *testing.T) {
+	mask := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	mask.Set(0, 0, color.Black)
+	var encoded bytes.Buffer
+	png.Encode(&encoded, mask)
+	newLayer := func() ComponentLayer {
+		return ComponentLayer{
+			NamedPropertiesMap: map[string][]string{"left": {"topLeftX"}, "top": {"topLeftY"}, "mask": {"data"}},
+			Style:              render.LayerStyle{Opacity: 1},
+			Clip:               &ComponentClip{Shape: ClipImage},
+		}
+	}
+	t.Run("valid", func(t *testing.T) {
+		for _, data := range []interface{}{base64.StdEncoding.EncodeToString(encoded.Bytes()), encoded.Bytes(), bytes.NewReader(encoded.Bytes()), mask} {
+			original := newLayer()
+			layer, err := original.SetNamedProperties(render.NamedProperties{"left": 3, "top": 4, "mask": data})
+			if !assert.NoError(t, err) {
+				continue
+			}
+			assert.Equal(t, image.Pt(3, 4), layer.Clip.TopLeft)
+			assert.Equal(t, image.Rect(0, 0, 2, 1), layer.Clip.Mask.Bounds())
+			assert.Equal(t, &ComponentClip{Shape: ClipImage}, original.Clip, "the original clip should be unchanged")
+		}
+	})
+	t.Run("wrong type", func(t *testing.T) {
+		_, err := newLayer().SetNamedProperties(render.NamedProperties{"mask": 3})
+		assert.EqualError(t, err, "error converting 3 to image.Image, []byte, string or io.Reader")
+	})
+	t.Run("missing file", func(t *testing.T) {
+		layer := ComponentLayer{NamedPropertiesMap: map[string][]string{"mask": {"fileName"}}, Clip: &ComponentClip{Shape: ClipImage}}
+		_, err := layer.SetNamedProperties(render.NamedProperties{"mask": "no such file.png"})
+		assert.Error(t, err)
+	})
+	t.Run("no clip", func(t *testing.T) {
+		layer := ComponentLayer{NamedPropertiesMap: map[string][]string{"size": {"radius"}}}
+		_, err := layer.SetNamedProperties(render.NamedProperties{"size": 3})
+		assert.EqualError(t, err, "invalid component property in named property map: radius")
+	})
+}
+
+func TestComponentClipToClip(t *testing.T) {
+	t.Run("shapes", func(t *testing.T) {
+		path, _ := render.ParsePath("M 0 0 L 4 0 L 0 4 Z")
+		assert.Equal(t, &render.Clip{Path: render.NewEllipsePath(image.Pt(1, 2), 3, 3)}, ComponentClip{Shape: ClipCircle, Centre: image.Pt(1, 2), Radius: 3}.toClip())
+		assert.Equal(t, &render.Clip{Path: render.NewRectanglePath(image.Pt(1, 2), 3, 4, render.CornerRadii{TopLeft: 1, TopRight: 1, BottomRight: 1, BottomLeft: 1})},
+			ComponentClip{Shape: ClipRectangle, TopLeft: image.Pt(1, 2), Width: 3, Height: 4, CornerRadius: 1}.toClip())
+		assert.Equal(t, &render.Clip{Path: path}, ComponentClip{Shape: ClipPath, Path: path}.toClip())
+		assert.Equal(t, &render.Clip{}, ComponentClip{Shape: ClipImage}.toClip())
+	})
+	t.Run("image", func(t *testing.T) {
+		mask := image.NewAlpha(image.Rect(5, 5, 7, 6))
+		mask.SetAlpha(5, 5, color.Alpha{A: 255})
+		clip := ComponentClip{Shape: ClipImage, TopLeft: image.Pt(1, 2), Mask: mask}.toClip()
+		assert.Equal(t, image.Rect(1, 2, 3, 3), clip.Mask.Bounds(), "the mask should be moved to the top-left corner")
+		assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, clip.Mask.At(1, 2))
+		assert.Equal(t, color.NRGBA{}, clip.Mask.At(2, 2))
+	})
+}
@@ -0,0 +1,135 @@
+package scaffold
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// DitherMethod is an algorithm for reducing an image to a limited number of grey levels.
+type DitherMethod string
+
+const (
+	// DitherThreshold rounds each pixel to the nearest level, with no dithering
+	DitherThreshold DitherMethod = "threshold"
+	// DitherFloydSteinberg diffuses the full error of each pixel over four neighbours
+	DitherFloydSteinberg DitherMethod = "floyd-steinberg"
+	// DitherAtkinson diffuses three quarters of the error of each pixel over six neighbours, giving higher contrast
+	DitherAtkinson DitherMethod = "atkinson"
+	// DitherBayer applies an 8x8 ordered dithering matrix, giving a regular pattern
+	DitherBayer DitherMethod = "bayer"
+)
+
+// ToDitherMethod attempts to convert a dither method name to a defined DitherMethod constant.
+func ToDitherMethod(raw string) (DitherMethod, error) {
+	switch raw {
+	case string(DitherThreshold):
+		return DitherThreshold, nil
+	case string(DitherFloydSteinberg):
+		return DitherFloydSteinberg, nil
+	case string(DitherAtkinson):
+		return DitherAtkinson, nil
+	case string(DitherBayer):
+		return DitherBayer, nil
+	default:
+		return DitherMethod(""), fmt.Errorf("dither method %v does not match defined constants", raw)
+	}
+}
+
+// DitherOptions chooses how an image is reduced to greyscale.
+type DitherOptions struct {
+	// Method is the dithering algorithm to use.
+	Method DitherMethod
+	// Levels is the number of evenly spaced grey levels from 2 (1-bit black and white) to 256. Zero uses the default of 2.
+	Levels int
+}
+
+// diffusion is a share of the quantisation error passed to a neighbouring pixel.
+type diffusion struct {
+	dx, dy int
+	weight float64
+}
+
+var (
+	floydSteinbergDiffusion = []diffusion{{1, 0, 7.0 / 16}, {-1, 1, 3.0 / 16}, {0, 1, 5.0 / 16}, {1, 1, 1.0 / 16}}
+	atkinsonDiffusion       = []diffusion{{1, 0, 1.0 / 8}, {2, 0, 1.0 / 8}, {-1, 1, 1.0 / 8}, {0, 1, 1.0 / 8}, {1, 1, 1.0 / 8}, {0, 2, 1.0 / 8}}
+	bayerMatrix             = [8][8]float64{
+		{0, 32, 8, 40, 2, 34, 10, 42},
+		{48, 16, 56, 24, 50, 18, 58, 26},
+		{12, 44, 4, 36, 14, 46, 6, 38},
+		{60, 28, 52, 20, 62, 30, 54, 22},
+		{3, 35, 11, 43, 1, 33, 9, 41},
+		{51, 19, 59, 27, 49, 17, 57, 25},
+		{15, 47, 7, 39, 13, 45, 5, 37},
+		{63, 31, 55, 23, 61, 29, 53, 21},
+	}
+)
+
+// Dither reduces an image to evenly spaced grey levels, flattening any transparency onto white. The result is paletted from black to white, so PNG output uses the smallest possible bit depth.
+func Dither(img image.Image, opts DitherOptions) (*image.Paletted, error) {
+	levels := opts.Levels
+	if levels == 0 {
+		levels = 2
+	}
+	if levels < 2 || levels > 256 {
+		return nil, fmt.Errorf("invalid dither levels %d, must be between 2 and 256", levels)
+	}
+	var diffusions []diffusion
+	switch opts.Method {
+	case DitherThreshold, DitherBayer:
+	case DitherFloydSteinberg:
+		diffusions = floydSteinbergDiffusion
+	case DitherAtkinson:
+		diffusions = atkinsonDiffusion
+	default:
+		return nil, fmt.Errorf("unsupported dither method: %v", opts.Method)
+	}
+	palette := make(color.Palette, levels)
+	step := 255 / float64(levels-1)
+	for i := range palette {
+		palette[i] = color.Gray{Y: uint8(math.Round(float64(i) * step))}
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	values := greyLevels(img)
+	result := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			value := values[y*width+x]
+			if opts.Method == DitherBayer {
+				value += ((bayerMatrix[y%8][x%8]+0.5)/64 - 0.5) * step
+			}
+			index := int(math.Round(value / step))
+			if index < 0 {
+				index = 0
+			} else if index >= levels {
+				index = levels - 1
+			}
+			result.Pix[y*result.Stride+x] = uint8(index)
+			quantError := values[y*width+x] - float64(index)*step
+			for _, d := range diffusions {
+				nx, ny := x+d.dx, y+d.dy
+				if nx >= 0 && nx < width && ny < height {
+					values[ny*width+nx] += quantError * d.weight
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// greyLevels flattens an image onto a white background and returns the luminance of each pixel from 0 to 255, row by row.
+func greyLevels(img image.Image) []float64 {
+	bounds := img.Bounds()
+	levels := make([]float64, 0, bounds.Dx()*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			grey := float64(color.GrayModel.Convert(color.NRGBA{R: c.R, G: c.G, B: c.B, A: 255}).(color.Gray).Y)
+			alpha := float64(c.A) / 255
+			levels = append(levels, grey*alpha+255*(1-alpha))
+		}
+	}
+	return levels
+}
@@ -0,0 +1,130 @@
+package scaffold
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDitherMethod(t *testing.T) {
+	tests := map[string]DitherMethod{
+		"threshold":       DitherThreshold,
+		"floyd-steinberg": DitherFloydSteinberg,
+		"atkinson":        DitherAtkinson,
+		"bayer":           DitherBayer,
+		"Bayer":           "",
+		"random":          "",
+	}
+	for raw, expected := range tests {
+		t.Run(raw, func(t *testing.T) {
+			method, err := ToDitherMethod(raw)
+			assert.Equal(t, expected, method)
+			if expected == "" {
+				assert.EqualError(t, err, "dither method "+raw+" does not match defined constants")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDither(t *testing.T) {
+	countIndex := func(img *image.Paletted, index uint8) int {
+		count := 0
+		for _, value := range img.Pix {
+			if value == index {
+				count++
+			}
+		}
+		return count
+	}
+	greyImage := func(grey uint8) *image.Gray {
+		img := image.NewGray(image.Rect(5, 5, 25, 25))
+		for i := range img.Pix {
+			img.Pix[i] = grey
+		}
+		return img
+	}
+	t.Run("invalid method", func(t *testing.T) {
+		dithered, err := Dither(greyImage(0), DitherOptions{Method: "sketchy"})
+		assert.Nil(t, dithered)
+		assert.EqualError(t, err, "unsupported dither method: sketchy")
+	})
+	t.Run("invalid levels", func(t *testing.T) {
+		dithered, err := Dither(greyImage(0), DitherOptions{Method: DitherThreshold, Levels: 1})
+		assert.Nil(t, dithered)
+		assert.EqualError(t, err, "invalid dither levels 1, must be between 2 and 256")
+		dithered, err = Dither(greyImage(0), DitherOptions{Method: DitherThreshold, Levels: 257})
+		assert.Nil(t, dithered)
+		assert.EqualError(t, err, "invalid dither levels 257, must be between 2 and 256")
+	})
+	t.Run("transparent", func(t *testing.T) {
+		dithered, err := Dither(image.NewNRGBA(image.Rect(0, 0, 10, 10)), DitherOptions{Method: DitherFloydSteinberg})
+		assert.NoError(t, err)
+		assert.Equal(t, 100, countIndex(dithered, 1))
+	})
+	t.Run("palette", func(t *testing.T) {
+		dithered, err := Dither(greyImage(0), DitherOptions{Method: DitherThreshold})
+		assert.NoError(t, err)
+		assert.Equal(t, color.Palette{color.Gray{Y: 0}, color.Gray{Y: 255}}, dithered.Palette)
+		assert.Equal(t, image.Rect(0, 0, 20, 20), dithered.Bounds())
+		dithered, err = Dither(greyImage(0), DitherOptions{Method: DitherThreshold, Levels: 4})
+		assert.NoError(t, err)
+		assert.Equal(t, color.Palette{color.Gray{Y: 0}, color.Gray{Y: 85}, color.Gray{Y: 170}, color.Gray{Y: 255}}, dithered.Palette)
+	})
+	t.Run("threshold", func(t *testing.T) {
+		dithered, err := Dither(greyImage(127), DitherOptions{Method: DitherThreshold})
+		assert.NoError(t, err)
+		assert.Equal(t, 400, countIndex(dithered, 0))
+		dithered, err = Dither(greyImage(128), DitherOptions{Method: DitherThreshold})
+		assert.NoError(t, err)
+		assert.Equal(t, 400, countIndex(dithered, 1))
+		dithered, err = Dither(greyImage(100), DitherOptions{Method: DitherThreshold, Levels: 4})
+		assert.NoError(t, err)
+		assert.Equal(t, 400, countIndex(dithered, 1))
+	})
+	t.Run("floyd-steinberg", func(t *testing.T) {
+		dithered, err := Dither(greyImage(127), DitherOptions{Method: DitherFloydSteinberg})
+		assert.NoError(t, err)
+		assert.InDelta(t, 200, countIndex(dithered, 0), 10)
+	})
+	t.Run("atkinson", func(t *testing.T) {
+		// Atkinson only diffuses three quarters of the error, so dark greys are darker than with full diffusion and light greys wash out to white.
+		dithered, err := Dither(greyImage(64), DitherOptions{Method: DitherAtkinson})
+		assert.NoError(t, err)
+		black := countIndex(dithered, 0)
+		assert.True(t, black > 300 && black < 350, "expected between 300 and 350 black pixels, got %d", black)
+		dithered, err = Dither(greyImage(240), DitherOptions{Method: DitherAtkinson})
+		assert.NoError(t, err)
+		assert.Equal(t, 400, countIndex(dithered, 1))
+	})
+	t.Run("bayer", func(t *testing.T) {
+		dithered, err := Dither(greyImage(128), DitherOptions{Method: DitherBayer})
+		assert.NoError(t, err)
+		assert.Equal(t, 200, countIndex(dithered, 0))
+		// The ordered pattern repeats every 8 pixels.
+		for y := 0; y < 20; y++ {
+			for x := 0; x < 12; x++ {
+				assert.Equal(t, dithered.Pix[y*20+x], dithered.Pix[y*20+x+8])
+			}
+		}
+	})
+	t.Run("greyscale levels", func(t *testing.T) {
+		dithered, err := Dither(greyImage(42), DitherOptions{Method: DitherFloydSteinberg, Levels: 4})
+		assert.NoError(t, err)
+		// 42 is halfway between the first two levels, so only those are used.
+		assert.InDelta(t, 200, countIndex(dithered, 0), 10)
+		assert.InDelta(t, 200, countIndex(dithered, 1), 10)
+	})
+	t.Run("semi-transparent black", func(t *testing.T) {
+		img := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 64
+		}
+		dithered, err := Dither(img, DitherOptions{Method: DitherFloydSteinberg})
+		assert.NoError(t, err)
+		assert.InDelta(t, 100, countIndex(dithered, 0), 10)
+	})
+}
@@ -0,0 +1,152 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+
+	"github.com/disintegration/imaging"
+)
+
+// ImageFormat is an encoded image file format the builder can write to.
+type ImageFormat string
+
+const (
+	// FormatBMP is a bitmap file
+	FormatBMP ImageFormat = "bmp"
+	// FormatPNG is a png file
+	FormatPNG ImageFormat = "png"
+	// FormatJPEG is a jpeg file
+	FormatJPEG ImageFormat = "jpeg"
+	// FormatGIF is a single-frame gif file
+	FormatGIF ImageFormat = "gif"
+	// FormatTIFF is a tiff file
+	FormatTIFF ImageFormat = "tiff"
+)
+
+// ToImageFormat attempts to convert a format name or file extension to a defined ImageFormat constant.
+func ToImageFormat(raw string) (ImageFormat, error) {
+	switch raw {
+	case "bmp", "BMP", ".bmp":
+		return FormatBMP, nil
+	case "png", "PNG", ".png":
+		return FormatPNG, nil
+	case "jpeg", "JPEG", "jpg", "JPG", ".jpeg", ".jpg":
+		return FormatJPEG, nil
+	case "gif", "GIF", ".gif":
+		return FormatGIF, nil
+	case "tiff", "TIFF", "tif", "TIF", ".tiff", ".tif":
+		return FormatTIFF, nil
+	default:
+		return ImageFormat(""), fmt.Errorf("image format %v does not match defined constants", raw)
+	}
+}
+
+// EncodeOptions contains optional settings for encoding, leave any fields not relevant to the format in use alone.
+type EncodeOptions struct {
+	// JPEGQuality is the jpeg quality from 1 to 100, higher is better. Zero uses the default of 95.
+	JPEGQuality int
+	// GIFNumColours is the maximum number of colours in the gif palette from 1 to 256. Zero uses the default of 256.
+	GIFNumColours int
+	// Dither reduces the image to greyscale before encoding if set, for monochrome displays and printers.
+	Dither *DitherOptions
+	// Provenance records the hashes of the template and named properties as PNG text chunks or JPEG comments, so output can be traced back to its inputs.
+	Provenance bool
+}
+
+func (opts *EncodeOptions) imagingOptions() []imaging.EncodeOption {
+	if opts == nil {
+		return nil
+	}
+	var result []imaging.EncodeOption
+	if opts.JPEGQuality != 0 {
+		result = append(result, imaging.JPEGQuality(opts.JPEGQuality))
+	}
+	if opts.GIFNumColours != 0 {
+		result = append(result, imaging.GIFNumColors(opts.GIFNumColours))
+	}
+	return result
+}
+
+func (f ImageFormat) imagingFormat() (imaging.Format, error) {
+	switch f {
+	case FormatBMP:
+		return imaging.BMP, nil
+	case FormatPNG:
+		return imaging.PNG, nil
+	case FormatJPEG:
+		return imaging.JPEG, nil
+	case FormatGIF:
+		return imaging.GIF, nil
+	case FormatTIFF:
+		return imaging.TIFF, nil
+	default:
+		return imaging.Format(-1), fmt.Errorf("unsupported image format: %v", f)
+	}
+}
+
+// WriteFormatTo encodes the contents of the builder in the specified format directly to the provided writer, without buffering the whole file in memory. PNG, JPEG and BMP output records the canvas PPI as the image resolution.
+func (builder ImageBuilder) WriteFormatTo(w io.Writer, format ImageFormat, opts *EncodeOptions) error {
+	imagingFormat, err := format.imagingFormat()
+	if err != nil {
+		return err
+	}
+	var img image.Image = builder.GetCanvas().GetUnderlyingImage()
+	if opts != nil && opts.Dither != nil {
+		img, err = Dither(img, *opts.Dither)
+		if err != nil {
+			return err
+		}
+	}
+	var text []textField
+	if opts != nil && opts.Provenance {
+		if builder.templateHash != "" {
+			text = append(text, textField{key: TemplateHashKey, value: builder.templateHash})
+		}
+		if builder.propertiesHash != "" {
+			text = append(text, textField{key: PropertiesHashKey, value: builder.propertiesHash})
+		}
+	}
+	mw := metadataWriter(w, format, builder.GetCanvas().GetPPI(), text)
+	err = imaging.Encode(mw, img, imagingFormat, opts.imagingOptions()...)
+	if err != nil {
+		return err
+	}
+	return mw.flush()
+}
+
+// WriteToFormat outputs the contents of the builder to a byte array in the specified format.
+func (builder ImageBuilder) WriteToFormat(format ImageFormat, opts *EncodeOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	err := builder.WriteFormatTo(&buf, format, opts)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteToPNG outputs the contents of the builder to a PNG byte array.
+func (builder ImageBuilder) WriteToPNG() ([]byte, error) {
+	return builder.WriteToFormat(FormatPNG, nil)
+}
+
+// WriteToJPEG outputs the contents of the builder to a JPEG byte array at the specified quality.
+func (builder ImageBuilder) WriteToJPEG(quality int) ([]byte, error) {
+	return builder.WriteToFormat(FormatJPEG, &EncodeOptions{JPEGQuality: quality})
+}
+
+// WriteToGIF outputs the contents of the builder to a GIF byte array.
+func (builder ImageBuilder) WriteToGIF() ([]byte, error) {
+	return builder.WriteToFormat(FormatGIF, nil)
+}
+
+// WriteToTIFF outputs the contents of the builder to a TIFF byte array.
+func (builder ImageBuilder) WriteToTIFF() ([]byte, error) {
+	return builder.WriteToFormat(FormatTIFF, nil)
+}
+
+// WriteToDitheredPNG outputs the contents of the builder to a PNG byte array, reduced to greyscale using the dither options.
+func (builder ImageBuilder) WriteToDitheredPNG(opts DitherOptions) ([]byte, error) {
+	return builder.WriteToFormat(FormatPNG, &EncodeOptions{Dither: &opts})
+}
@@ -0,0 +1,167 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/tiff"
+)
+
+func TestToImageFormat(t *testing.T) {
+	tests := map[string]ImageFormat{
+		"bmp":    FormatBMP,
+		"bitmap": "",
+		"PNG":    FormatPNG,
+		".png":   FormatPNG,
+		"jpg":    FormatJPEG,
+		"JPEG":   FormatJPEG,
+		".jpeg":  FormatJPEG,
+		"gif":    FormatGIF,
+		"tif":    FormatTIFF,
+		".tiff":  FormatTIFF,
+		"webp":   "",
+	}
+	for raw, expected := range tests {
+		t.Run(raw, func(t *testing.T) {
+			format, err := ToImageFormat(raw)
+			assert.Equal(t, expected, format)
+			if expected == "" {
+				assert.EqualError(t, err, "image format "+raw+" does not match defined constants")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestWriteToFormat(t *testing.T) {
+	canvas, err := render.NewCanvas(4, 3)
+	assert.NoError(t, err)
+	colour := color.NRGBA{R: 200, G: 100, B: 50, A: 255}
+	drawnCanvas, err := canvas.Rectangle(image.ZP, 4, 3, colour)
+	assert.NoError(t, err)
+	builder := ImageBuilder{Canvas: drawnCanvas}
+	t.Run("unsupported format", func(t *testing.T) {
+		data, err := builder.WriteToFormat(ImageFormat("webp"), nil)
+		assert.Nil(t, data)
+		assert.EqualError(t, err, "unsupported image format: webp")
+	})
+	t.Run("bad image", func(t *testing.T) {
+		img := fakeImage{bounds: image.Rectangle{Min: image.Pt(0, 0), Max: image.Pt(-1, -1)}}
+		badCanvas := new(render.MockCanvas)
+		badCanvas.On("GetUnderlyingImage").Return(&img)
+		badCanvas.On("GetPPI").Return(float64(0))
+		data, err := ImageBuilder{Canvas: badCanvas}.WriteToFormat(FormatBMP, nil)
+		assert.EqualError(t, err, "bmp: negative bounds")
+		assert.Nil(t, data)
+		badCanvas.AssertExpectations(t)
+	})
+	t.Run("png", func(t *testing.T) {
+		data, err := builder.WriteToPNG()
+		assert.NoError(t, err)
+		decoded, err := png.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 4, 3), decoded.Bounds())
+		assert.Equal(t, colour, color.NRGBAModel.Convert(decoded.At(2, 1)))
+	})
+	t.Run("jpeg", func(t *testing.T) {
+		low, err := builder.WriteToJPEG(1)
+		assert.NoError(t, err)
+		high, err := builder.WriteToJPEG(100)
+		assert.NoError(t, err)
+		assert.NotEqual(t, low, high)
+		decoded, err := jpeg.Decode(bytes.NewReader(high))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 4, 3), decoded.Bounds())
+		defaulted, err := builder.WriteToFormat(FormatJPEG, &EncodeOptions{})
+		assert.NoError(t, err)
+		ninetyFive, err := builder.WriteToJPEG(95)
+		assert.NoError(t, err)
+		assert.Equal(t, ninetyFive, defaulted)
+	})
+	t.Run("gif", func(t *testing.T) {
+		data, err := builder.WriteToGIF()
+		assert.NoError(t, err)
+		decoded, err := gif.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 4, 3), decoded.Bounds())
+		limited, err := builder.WriteToFormat(FormatGIF, &EncodeOptions{GIFNumColours: 2})
+		assert.NoError(t, err)
+		decoded, err = gif.Decode(bytes.NewReader(limited))
+		assert.NoError(t, err)
+		assert.Len(t, decoded.(*image.Paletted).Palette, 2)
+	})
+	t.Run("tiff", func(t *testing.T) {
+		data, err := builder.WriteToTIFF()
+		assert.NoError(t, err)
+		decoded, err := tiff.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, colour, color.NRGBAModel.Convert(decoded.At(3, 2)))
+	})
+	t.Run("dithered png", func(t *testing.T) {
+		data, err := builder.WriteToDitheredPNG(DitherOptions{Method: DitherBayer})
+		assert.NoError(t, err)
+		decoded, err := png.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+		if assert.IsType(t, &image.Paletted{}, decoded) {
+			assert.Len(t, decoded.(*image.Paletted).Palette, 2)
+		}
+		data, err = builder.WriteToFormat(FormatPNG, &EncodeOptions{Dither: &DitherOptions{Method: DitherThreshold, Levels: 16}})
+		assert.NoError(t, err)
+		decoded, err = png.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, color.RGBA{R: 119, G: 119, B: 119, A: 255}, decoded.At(1, 1))
+		data, err = builder.WriteToFormat(FormatPNG, &EncodeOptions{Dither: &DitherOptions{Method: "sketchy"}})
+		assert.Nil(t, data)
+		assert.EqualError(t, err, "unsupported dither method: sketchy")
+	})
+	t.Run("bmp", func(t *testing.T) {
+		data, err := builder.WriteToBMP()
+		assert.NoError(t, err)
+		formatted, err := builder.WriteToFormat(FormatBMP, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, data, formatted)
+	})
+}
+
+type failingWriter struct{}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, fmt.Errorf("writer closed")
+}
+
+func TestWriteFormatTo(t *testing.T) {
+	canvas, err := render.NewCanvas(5, 5)
+	assert.NoError(t, err)
+	builder := ImageBuilder{Canvas: canvas}
+	t.Run("unsupported format", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := builder.WriteFormatTo(&buf, ImageFormat("svg"), nil)
+		assert.EqualError(t, err, "unsupported image format: svg")
+		assert.Equal(t, 0, buf.Len())
+	})
+	t.Run("writer error", func(t *testing.T) {
+		err := builder.WriteFormatTo(failingWriter{}, FormatPNG, nil)
+		assert.EqualError(t, err, "writer closed")
+	})
+	t.Run("matches buffered output", func(t *testing.T) {
+		for _, format := range []ImageFormat{FormatBMP, FormatPNG, FormatJPEG, FormatGIF, FormatTIFF} {
+			t.Run(string(format), func(t *testing.T) {
+				var buf bytes.Buffer
+				err := builder.WriteFormatTo(&buf, format, nil)
+				assert.NoError(t, err)
+				buffered, err := builder.WriteToFormat(format, nil)
+				assert.NoError(t, err)
+				assert.Equal(t, buffered, buf.Bytes())
+			})
+		}
+	})
+}
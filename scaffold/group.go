@@ -0,0 +1,147 @@
+package scaffold
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// GroupComponent is a list of components drawn together, so that they can be moved, clipped, faded or hidden as one.
+type GroupComponent struct {
+	/*
+		NamedPropertiesMap maps user/application variables to properties of the group itself.
+		The named properties of the components in the group are kept by each of them.
+	*/
+	NamedPropertiesMap map[string][]string
+	/*
+		Offset is the position of the top-left corner of the group relative to the top-left
+		corner of the canvas. The coordinates of the components in the group are relative to it.
+	*/
+	Offset image.Point
+	// Components are the components in the group, drawn in order.
+	Components []ToggleableComponent
+}
+
+type groupFormat struct {
+	OffsetX    string              `json:"offsetX"`
+	OffsetY    string              `json:"offsetY"`
+	Components []ComponentTemplate `json:"components"`
+}
+
+// Write draws the components of the group on the canvas, moved by the offset.
+func (component GroupComponent) Write(canvas render.Canvas) (render.Canvas, error) {
+	if len(component.NamedPropertiesMap) != 0 {
+		return canvas, fmt.Errorf("cannot draw group, not all named properties are set: %v", component.NamedPropertiesMap)
+	}
+	if component.Offset == image.ZP {
+		return component.writeComponents(canvas)
+	}
+	return render.Translate(canvas, component.Offset, component.writeComponents)
+}
+
+func (component GroupComponent) writeComponents(canvas render.Canvas) (render.Canvas, error) {
+	for _, tComponent := range component.Components {
+		enabled, err := tComponent.enabled()
+		if err != nil {
+			return canvas, err
+		}
+		if enabled {
+			canvas, err = tComponent.write(canvas)
+			if err != nil {
+				return canvas, err
+			}
+		}
+	}
+	return canvas, nil
+}
+
+// SetNamedProperties processes the named properties and sets them into the group properties and the components in it.
+func (component GroupComponent) SetNamedProperties(properties render.NamedProperties) (render.Component, error) {
+	c := component
+	var err error
+	c.NamedPropertiesMap, err = render.StandardSetNamedProperties(properties, component.NamedPropertiesMap, (&c).delegatedSetProperties)
+	if err != nil {
+		return component, err
+	}
+	c.Components = make([]ToggleableComponent, len(component.Components))
+	copy(c.Components, component.Components)
+	err = setComponentsNamedProperties(c.Components, properties)
+	if err != nil {
+		return component, err
+	}
+	return c, nil
+}
+
+func (component *GroupComponent) delegatedSetProperties(name string, value interface{}) (err error) {
+	switch name {
+	case "offsetX":
+		component.Offset.X, err = cutils.SetInt(value)
+	case "offsetY":
+		component.Offset.Y, err = cutils.SetInt(value)
+	default:
+		err = fmt.Errorf("invalid component property in named property map: %v", name)
+	}
+	return err
+}
+
+// GetJSONFormat returns the JSON structure of a group component.
+func (component GroupComponent) GetJSONFormat() interface{} {
+	return &groupFormat{}
+}
+
+// VerifyAndSetJSONData processes the data parsed from JSON and uses it to set group properties, parse the components in it and fill the named properties map.
+func (component GroupComponent) VerifyAndSetJSONData(data interface{}) (render.Component, render.NamedProperties, error) {
+	c := component
+	props := make(render.NamedProperties)
+	stringStruct, ok := data.(*groupFormat)
+	if !ok {
+		return component, props, fmt.Errorf("failed to convert returned data to component properties")
+	}
+	offsets := []struct {
+		raw  string
+		name string
+	}{
+		{raw: stringStruct.OffsetX, name: "offsetX"},
+		{raw: stringStruct.OffsetY, name: "offsetY"},
+	}
+	for _, offset := range offsets {
+		if offset.raw == "" {
+			continue
+		}
+		var newVal interface{}
+		var err error
+		c.NamedPropertiesMap, newVal, err = render.ExtractSingleProp(offset.raw, offset.name, render.IntType, c.NamedPropertiesMap)
+		if err != nil {
+			return component, props, err
+		}
+		if newVal != nil {
+			err = (&c).delegatedSetProperties(offset.name, newVal)
+			if err != nil {
+				return component, props, err
+			}
+		}
+	}
+	components, componentProps, err := parseComponents(stringStruct.Components)
+	if err != nil {
+		return component, props, err
+	}
+	c.Components = components
+	for key, value := range componentProps {
+		props[key] = value
+	}
+	for key := range c.NamedPropertiesMap {
+		props[key] = struct {
+			Message string
+		}{Message: "Please replace me with real data"}
+	}
+	return c, props, nil
+}
+
+func init() {
+	for _, name := range []string{"group", "Group", "GROUP"} {
+		render.RegisterComponent(name, func(vfs.FileSystem) render.Component { return GroupComponent{} })
+	}
+}
@@ -0,0 +1,145 @@
+package scaffold
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupComponent(t *testing.T) {
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	black := color.NRGBA{A: 255}
+	load := func(t *testing.T, group string) Builder {
+		var b Builder = ImageBuilder{}
+		b, err := b.LoadComponentsData([]byte(`{
+			"baseImage": {"baseColour": {"R": "255", "G": "255", "B": "255", "A": "255"}, "width": "10", "height": "10"},
+			"components": [` + group + `]
+		}`))
+		assert.NoError(t, err)
+		return b
+	}
+	square := `{"type": "rectangle", "properties": {"topLeftX": "0", "topLeftY": "0", "width": "2", "height": "2", "colour": {"R": "0", "G": "0", "B": "0", "A": "255"}}}`
+	t.Run("offset", func(t *testing.T) {
+		b := load(t, `{"type": "group", "properties": {"offsetX": "3", "offsetY": "$y$", "components": [`+square+`]}}`)
+		assert.Equal(t, render.NamedProperties{"y": struct{ Message string }{Message: "Please replace me with real data"}}, b.GetNamedPropertiesList())
+		b, err := b.SetNamedProperties(render.NamedProperties{"y": 4})
+		assert.NoError(t, err)
+		b, err = b.ApplyComponents()
+		assert.NoError(t, err)
+		img := b.GetCanvas().GetUnderlyingImage()
+		assert.Equal(t, white, img.At(0, 0))
+		assert.Equal(t, black, img.At(3, 4))
+		assert.Equal(t, black, img.At(4, 5))
+		assert.Equal(t, white, img.At(5, 6))
+	})
+	t.Run("children outside the canvas before the offset", func(t *testing.T) {
+		for _, test := range []struct {
+			name   string
+			offset string
+			x      string
+			drawn  []image.Point
+		}{
+			{name: "negative child", offset: "5", x: "-4", drawn: []image.Point{{1, 0}, {2, 0}, {1, 1}, {2, 1}}},
+			{name: "negative offset", offset: "-5", x: "12", drawn: []image.Point{{7, 0}, {8, 0}, {7, 1}, {8, 1}}},
+			{name: "moved partly off the canvas", offset: "-2", x: "1", drawn: []image.Point{{0, 0}, {0, 1}}},
+		} {
+			t.Run(test.name, func(t *testing.T) {
+				child := `{"type": "rectangle", "properties": {"topLeftX": "` + test.x + `", "topLeftY": "0", "width": "2", "height": "2", "colour": {"R": "0", "G": "0", "B": "0", "A": "255"}}}`
+				b := load(t, `{"type": "group", "properties": {"offsetX": "`+test.offset+`", "components": [`+child+`]}}`)
+				b, err := b.ApplyComponents()
+				assert.NoError(t, err)
+				img := b.GetCanvas().GetUnderlyingImage()
+				var drawn []image.Point
+				for y := 0; y < 10; y++ {
+					for x := 0; x < 10; x++ {
+						if img.At(x, y) == black {
+							drawn = append(drawn, image.Pt(x, y))
+						}
+					}
+				}
+				assert.ElementsMatch(t, test.drawn, drawn)
+			})
+		}
+	})
+	t.Run("nested", func(t *testing.T) {
+		inner := `{"type": "group", "properties": {"offsetX": "2", "components": [` + square + `]}}`
+		b := load(t, `{"type": "group", "properties": {"offsetY": "1", "components": [`+inner+`]}}`)
+		b, err := b.ApplyComponents()
+		assert.NoError(t, err)
+		img := b.GetCanvas().GetUnderlyingImage()
+		assert.Equal(t, black, img.At(2, 1))
+		assert.Equal(t, white, img.At(0, 0))
+	})
+	t.Run("conditional", func(t *testing.T) {
+		child := `{"type": "rectangle", "conditional": {"name": "show", "operator": "equals", "value": "yes"}, "properties": {"topLeftX": "$x$", "topLeftY": "0", "width": "2", "height": "2", "colour": {"R": "0", "G": "0", "B": "0", "A": "255"}}}`
+		group := `{"type": "group", "conditional": {"name": "block", "operator": "equals", "value": "on"}, "properties": {"components": [` + child + `]}}`
+		for _, test := range []struct {
+			name   string
+			block  string
+			show   string
+			colour color.NRGBA
+		}{
+			{name: "shown", block: "on", show: "yes", colour: black},
+			{name: "child hidden", block: "on", show: "no", colour: white},
+			{name: "group hidden", block: "off", show: "yes", colour: white},
+		} {
+			t.Run(test.name, func(t *testing.T) {
+				b := load(t, group)
+				b, err := b.SetNamedProperties(render.NamedProperties{"block": test.block, "show": test.show, "x": 1})
+				assert.NoError(t, err)
+				b, err = b.ApplyComponents()
+				assert.NoError(t, err)
+				assert.Equal(t, test.colour, b.GetCanvas().GetUnderlyingImage().At(1, 1))
+			})
+		}
+	})
+	t.Run("unset properties", func(t *testing.T) {
+		b := load(t, `{"type": "group", "properties": {"offsetX": "$x$", "components": [`+square+`]}}`)
+		_, err := b.ApplyComponents()
+		assert.EqualError(t, err, "cannot draw group, not all named properties are set: map[x:[offsetX]]")
+	})
+	t.Run("unchanged original", func(t *testing.T) {
+		group := GroupComponent{NamedPropertiesMap: map[string][]string{}, Components: []ToggleableComponent{{Component: &workingComponent{}}}}
+		result, err := group.SetNamedProperties(render.NamedProperties{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.(GroupComponent).Components[0].Component.(*workingComponent).someVar)
+		assert.Equal(t, 0, group.Components[0].Component.(*workingComponent).someVar)
+	})
+	t.Run("invalid child", func(t *testing.T) {
+		var b Builder = ImageBuilder{}
+		_, err := b.LoadComponentsData([]byte(`{
+			"baseImage": {"width": "10", "height": "10"},
+			"components": [{"type": "group", "properties": {"components": [{"type": "star", "properties": {}}]}}]
+		}`))
+		assert.EqualError(t, err, "component error: no component registered for name star")
+	})
+	t.Run("invalid offset", func(t *testing.T) {
+		_, _, err := GroupComponent{}.VerifyAndSetJSONData(&groupFormat{OffsetX: "left"})
+		assert.EqualError(t, err, "failed to convert property offsetX to integer: strconv.ParseInt: parsing \"left\": invalid syntax")
+	})
+	t.Run("wrong format", func(t *testing.T) {
+		_, _, err := GroupComponent{}.VerifyAndSetJSONData(struct{}{})
+		assert.EqualError(t, err, "failed to convert returned data to component properties")
+	})
+	t.Run("zpl", func(t *testing.T) {
+		canvas, _ := render.NewZPLCanvas(10, 10)
+		group := GroupComponent{Offset: image.Pt(3, 2), Components: []ToggleableComponent{{Component: &rectangleWriter{}}}}
+		drawnCanvas, err := group.Write(canvas)
+		assert.NoError(t, err)
+		data, err := ImageBuilder{Canvas: drawnCanvas}.WriteToZPL()
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "^LH0,0\n^FO3,2^GB2,2,2,B,0^FS\n", "the group should stay as native fields")
+	})
+}
+
+// rectangleWriter draws a black 2x2 square at the origin.
+type rectangleWriter struct {
+	workingComponent
+}
+
+func (rectangleWriter) Write(canvas render.Canvas) (render.Canvas, error) {
+	return canvas.Rectangle(image.ZP, 2, 2, color.Black)
+}
@@ -0,0 +1,252 @@
+package scaffold
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/LLKennedy/imagetemplate/v3/cutils"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+)
+
+// ComponentLayer is a layer of its own for a component to be drawn on, composited onto the canvas with a style.
+type ComponentLayer struct {
+	// NamedPropertiesMap maps user/application variables to properties of the layer, in the same way as the named properties map of a component.
+	NamedPropertiesMap map[string][]string
+	// Style is the style the layer is composited with.
+	Style render.LayerStyle
+	// Clip is the shape the layer is clipped to, or nil to leave it unclipped. It takes the place of the clip of the style.
+	Clip *ComponentClip
+}
+
+// layerProperty is a raw layer property from a component template.
+type layerProperty struct {
+	raw      string
+	name     string
+	typeName render.PropType
+}
+
+// parseLayer reads the layer properties of a component template, returning a nil layer if the component has none.
+func parseLayer(template ComponentTemplate) (*ComponentLayer, render.NamedProperties, error) {
+	props := render.NamedProperties{}
+	if template.Opacity == "" && template.BlendMode == "" && template.Transform == nil && template.Clip == nil && template.Blur == "" && template.Shadow == nil {
+		return nil, props, nil
+	}
+	layer := &ComponentLayer{Style: render.LayerStyle{Opacity: 1, BlendMode: render.BlendNormal}}
+	properties := []layerProperty{
+		{raw: template.Opacity, name: "opacity", typeName: render.Float64Type},
+		{raw: template.BlendMode, name: "blendMode", typeName: render.StringType},
+		{raw: template.Blur, name: "blur", typeName: render.Float64Type},
+	}
+	if transform := template.Transform; transform != nil {
+		if transform.Scale != "" && (transform.ScaleX != "" || transform.ScaleY != "") {
+			return nil, props, fmt.Errorf("a transform takes either scale or scaleX and scaleY, not both")
+		}
+		layer.Style.Transform = &render.Transform{ScaleX: 1, ScaleY: 1}
+		if transform.AnchorX != "" || transform.AnchorY != "" {
+			if transform.AnchorX == "" || transform.AnchorY == "" {
+				return nil, props, fmt.Errorf("a transform anchor needs both anchorX and anchorY")
+			}
+			layer.Style.Transform.Anchor = &image.Point{}
+		}
+		properties = append(properties, []layerProperty{
+			{raw: transform.Rotate, name: "rotate", typeName: render.Float64Type},
+			{raw: transform.Scale, name: "scale", typeName: render.Float64Type},
+			{raw: transform.ScaleX, name: "scaleX", typeName: render.Float64Type},
+			{raw: transform.ScaleY, name: "scaleY", typeName: render.Float64Type},
+			{raw: transform.SkewX, name: "skewX", typeName: render.Float64Type},
+			{raw: transform.SkewY, name: "skewY", typeName: render.Float64Type},
+			{raw: transform.AnchorX, name: "anchorX", typeName: render.IntType},
+			{raw: transform.AnchorY, name: "anchorY", typeName: render.IntType},
+		}...)
+	}
+	if template.Clip != nil {
+		var clipProperties []layerProperty
+		var err error
+		layer.Clip, clipProperties, err = parseClip(*template.Clip)
+		if err != nil {
+			return nil, props, err
+		}
+		properties = append(properties, clipProperties...)
+	}
+	if shadow := template.Shadow; shadow != nil {
+		layer.Style.Shadow = &render.Shadow{Colour: color.NRGBA{A: 128}}
+		properties = append(properties, []layerProperty{
+			{raw: shadow.OffsetX, name: "shadowOffsetX", typeName: render.IntType},
+			{raw: shadow.OffsetY, name: "shadowOffsetY", typeName: render.IntType},
+			{raw: shadow.Blur, name: "shadowBlur", typeName: render.Float64Type},
+		}...)
+		if colour := shadow.Colour; colour != nil {
+			layer.Style.Shadow.Colour = color.NRGBA{}
+			properties = append(properties, []layerProperty{
+				{raw: colour.Red, name: "shadowR", typeName: render.Uint8Type},
+				{raw: colour.Green, name: "shadowG", typeName: render.Uint8Type},
+				{raw: colour.Blue, name: "shadowB", typeName: render.Uint8Type},
+				{raw: colour.Alpha, name: "shadowA", typeName: render.Uint8Type},
+			}...)
+		}
+	}
+	for _, property := range properties {
+		if property.raw == "" {
+			continue
+		}
+		var newVal interface{}
+		var err error
+		layer.NamedPropertiesMap, newVal, err = render.ExtractSingleProp(property.raw, property.name, property.typeName, layer.NamedPropertiesMap)
+		if err != nil {
+			return nil, props, err
+		}
+		if newVal != nil {
+			err = layer.delegatedSetProperties(property.name, newVal)
+			if err != nil {
+				return nil, props, err
+			}
+		}
+	}
+	for key := range layer.NamedPropertiesMap {
+		props[key] = struct {
+			Message string
+		}{Message: "Please replace me with real data"}
+	}
+	return layer, props, nil
+}
+
+// SetNamedProperties processes the named properties and sets them into the layer properties.
+func (layer ComponentLayer) SetNamedProperties(properties render.NamedProperties) (*ComponentLayer, error) {
+	l := layer
+	var err error
+	l.NamedPropertiesMap, err = render.StandardSetNamedProperties(properties, layer.NamedPropertiesMap, (&l).delegatedSetProperties)
+	if err != nil {
+		return &layer, err
+	}
+	return &l, nil
+}
+
+func (layer *ComponentLayer) delegatedSetProperties(name string, value interface{}) (err error) {
+	switch name {
+	case "opacity":
+		var opacity float64
+		opacity, err = cutils.SetFloat64(value)
+		if err != nil {
+			return err
+		}
+		if opacity < 0 || opacity > 1 {
+			return fmt.Errorf("opacity must be between 0 and 1, got %v", opacity)
+		}
+		layer.Style.Opacity = opacity
+	case "blendMode":
+		var mode string
+		mode, err = cutils.SetString(value)
+		if err != nil {
+			return err
+		}
+		layer.Style.BlendMode, err = render.ToBlendMode(mode)
+	case "blur":
+		var blur float64
+		blur, err = cutils.SetFloat64(value)
+		if err != nil {
+			return err
+		}
+		if blur < 0 {
+			return fmt.Errorf("blur must not be negative, got %v", blur)
+		}
+		layer.Style.Blur = blur
+	case "shadowOffsetX", "shadowOffsetY", "shadowBlur", "shadowR", "shadowG", "shadowB", "shadowA":
+		if layer.Style.Shadow == nil {
+			return fmt.Errorf("invalid component property in named property map: %v", name)
+		}
+		// As with the transform, the shadow must not be shared with the original layer
+		shadow := *layer.Style.Shadow
+		err = setShadowProperty(&shadow, name, value)
+		layer.Style.Shadow = &shadow
+	case "centreX", "centreY", "radius", "topLeftX", "topLeftY", "width", "height", "cornerRadius", "path", "fileName", "data":
+		if layer.Clip == nil {
+			return fmt.Errorf("invalid component property in named property map: %v", name)
+		}
+		// As with the transform, the clip must not be shared with the original layer
+		clip := *layer.Clip
+		err = setClipProperty(&clip, name, value)
+		layer.Clip = &clip
+	default:
+		if layer.Style.Transform == nil {
+			return fmt.Errorf("invalid component property in named property map: %v", name)
+		}
+		// Layers are copied before their properties are set, so the transform must not be shared with the original
+		transform := *layer.Style.Transform
+		err = setTransformProperty(&transform, name, value)
+		layer.Style.Transform = &transform
+	}
+	return err
+}
+
+func setTransformProperty(transform *render.Transform, name string, value interface{}) (err error) {
+	switch name {
+	case "rotate":
+		transform.Rotation, err = cutils.SetFloat64(value)
+	case "scale":
+		transform.ScaleX, err = cutils.SetFloat64(value)
+		transform.ScaleY = transform.ScaleX
+	case "scaleX":
+		transform.ScaleX, err = cutils.SetFloat64(value)
+	case "scaleY":
+		transform.ScaleY, err = cutils.SetFloat64(value)
+	case "skewX":
+		transform.SkewX, err = cutils.SetFloat64(value)
+	case "skewY":
+		transform.SkewY, err = cutils.SetFloat64(value)
+	case "anchorX", "anchorY":
+		if transform.Anchor == nil {
+			return fmt.Errorf("invalid component property in named property map: %v", name)
+		}
+		anchor := *transform.Anchor
+		if name == "anchorX" {
+			anchor.X, err = cutils.SetInt(value)
+		} else {
+			anchor.Y, err = cutils.SetInt(value)
+		}
+		transform.Anchor = &anchor
+	default:
+		err = fmt.Errorf("invalid component property in named property map: %v", name)
+	}
+	return err
+}
+
+func setShadowProperty(shadow *render.Shadow, name string, value interface{}) (err error) {
+	switch name {
+	case "shadowOffsetX":
+		shadow.Offset.X, err = cutils.SetInt(value)
+	case "shadowOffsetY":
+		shadow.Offset.Y, err = cutils.SetInt(value)
+	case "shadowBlur":
+		shadow.Blur, err = cutils.SetFloat64(value)
+		if err == nil && shadow.Blur < 0 {
+			err = fmt.Errorf("shadow blur must not be negative, got %v", shadow.Blur)
+		}
+	case "shadowR":
+		shadow.Colour.R, err = cutils.SetUint8(value)
+	case "shadowG":
+		shadow.Colour.G, err = cutils.SetUint8(value)
+	case "shadowB":
+		shadow.Colour.B, err = cutils.SetUint8(value)
+	case "shadowA":
+		shadow.Colour.A, err = cutils.SetUint8(value)
+	default:
+		err = fmt.Errorf("invalid component property in named property map: %v", name)
+	}
+	return err
+}
+
+// write draws the component, on its own layer if it has one.
+func (tComponent ToggleableComponent) write(canvas render.Canvas) (render.Canvas, error) {
+	if tComponent.Layer == nil {
+		return tComponent.Component.Write(canvas)
+	}
+	if len(tComponent.Layer.NamedPropertiesMap) != 0 {
+		return canvas, fmt.Errorf("cannot draw layer, not all named properties are set: %v", tComponent.Layer.NamedPropertiesMap)
+	}
+	style := tComponent.Layer.Style
+	if tComponent.Layer.Clip != nil {
+		style.Clip = tComponent.Layer.Clip.toClip()
+	}
+	return canvas.Layer(style, tComponent.Component.Write)
+}
@@ -0,0 +1,325 @@
+package scaffold
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/LLKennedy/imagetemplate/v3/components/rectangle"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLayer(t *testing.T) {
+	placeholder := struct{ Message string }{Message: "Please replace me with real data"}
+	type testSet struct {
+		name     string
+		template ComponentTemplate
+		layer    *ComponentLayer
+		props    render.NamedProperties
+		err      string
+	}
+	tests := []testSet{
+		{
+			name:  "no layer",
+			props: render.NamedProperties{},
+		},
+		{
+			name:     "opacity",
+			template: ComponentTemplate{Opacity: "0.25"},
+			layer:    &ComponentLayer{NamedPropertiesMap: map[string][]string{}, Style: render.LayerStyle{Opacity: 0.25, BlendMode: render.BlendNormal}},
+			props:    render.NamedProperties{},
+		},
+		{
+			name:     "blend mode",
+			template: ComponentTemplate{BlendMode: "multiply"},
+			layer:    &ComponentLayer{NamedPropertiesMap: map[string][]string{}, Style: render.LayerStyle{Opacity: 1, BlendMode: render.BlendMultiply}},
+			props:    render.NamedProperties{},
+		},
+		{
+			name:     "named properties",
+			template: ComponentTemplate{Opacity: "$fade$", BlendMode: "$mode$"},
+			layer: &ComponentLayer{
+				NamedPropertiesMap: map[string][]string{"fade": {"opacity"}, "mode": {"blendMode"}},
+				Style:              render.LayerStyle{Opacity: 1, BlendMode: render.BlendNormal},
+			},
+			props: render.NamedProperties{"fade": placeholder, "mode": placeholder},
+		},
+		{
+			name:     "transform",
+			template: ComponentTemplate{Transform: &TransformTemplate{Rotate: "90", Scale: "2", SkewX: "10", SkewY: "-10", AnchorX: "4", AnchorY: "$top$"}},
+			layer: &ComponentLayer{
+				NamedPropertiesMap: map[string][]string{"top": {"anchorY"}},
+				Style: render.LayerStyle{Opacity: 1, BlendMode: render.BlendNormal, Transform: &render.Transform{
+					Rotation: 90, ScaleX: 2, ScaleY: 2, SkewX: 10, SkewY: -10, Anchor: &image.Point{X: 4},
+				}},
+			},
+			props: render.NamedProperties{"top": placeholder},
+		},
+		{
+			name:     "transform without anchor",
+			template: ComponentTemplate{Transform: &TransformTemplate{ScaleX: "-1"}},
+			layer: &ComponentLayer{
+				NamedPropertiesMap: map[string][]string{},
+				Style:              render.LayerStyle{Opacity: 1, BlendMode: render.BlendNormal, Transform: &render.Transform{ScaleX: -1, ScaleY: 1}},
+			},
+			props: render.NamedProperties{},
+		},
+		{
+			name:     "blur and shadow",
+			template: ComponentTemplate{Blur: "1.5", Shadow: &ShadowTemplate{OffsetX: "2", OffsetY: "$drop$", Blur: "3"}},
+			layer: &ComponentLayer{
+				NamedPropertiesMap: map[string][]string{"drop": {"shadowOffsetY"}},
+				Style:              render.LayerStyle{Opacity: 1, BlendMode: render.BlendNormal, Blur: 1.5, Shadow: &render.Shadow{Offset: image.Point{X: 2}, Blur: 3, Colour: color.NRGBA{A: 128}}},
+			},
+			props: render.NamedProperties{"drop": placeholder},
+		},
+		{
+			name: "shadow colour",
+			template: func() ComponentTemplate {
+				var template ComponentTemplate
+				json.Unmarshal([]byte(`{"shadow": {"colour": {"R": "255", "G": "$green$", "A": "100"}}}`), &template)
+				return template
+			}(),
+			layer: &ComponentLayer{
+				NamedPropertiesMap: map[string][]string{"green": {"shadowG"}},
+				Style:              render.LayerStyle{Opacity: 1, BlendMode: render.BlendNormal, Shadow: &render.Shadow{Colour: color.NRGBA{R: 255, A: 100}}},
+			},
+			props: render.NamedProperties{"green": placeholder},
+		},
+		{
+			name:     "negative blur",
+			template: ComponentTemplate{Blur: "-1"},
+			props:    render.NamedProperties{},
+			err:      "blur must not be negative, got -1",
+		},
+		{
+			name:     "negative shadow blur",
+			template: ComponentTemplate{Shadow: &ShadowTemplate{Blur: "-2"}},
+			props:    render.NamedProperties{},
+			err:      "shadow blur must not be negative, got -2",
+		},
+		{
+			name:     "transform with both scales",
+			template: ComponentTemplate{Transform: &TransformTemplate{Scale: "2", ScaleY: "3"}},
+			props:    render.NamedProperties{},
+			err:      "a transform takes either scale or scaleX and scaleY, not both",
+		},
+		{
+			name:     "transform with half an anchor",
+			template: ComponentTemplate{Transform: &TransformTemplate{AnchorX: "3"}},
+			props:    render.NamedProperties{},
+			err:      "a transform anchor needs both anchorX and anchorY",
+		},
+		{
+			name:     "invalid transform",
+			template: ComponentTemplate{Transform: &TransformTemplate{AnchorX: "3", AnchorY: "3.5"}},
+			props:    render.NamedProperties{},
+			err:      "failed to convert property anchorY to integer: strconv.ParseInt: parsing \"3.5\": invalid syntax",
+		},
+		{
+			name:     "invalid opacity",
+			template: ComponentTemplate{Opacity: "half"},
+			props:    render.NamedProperties{},
+			err:      "failed to convert property opacity to float64: strconv.ParseFloat: parsing \"half\": invalid syntax",
+		},
+		{
+			name:     "opacity out of range",
+			template: ComponentTemplate{Opacity: "1.5"},
+			props:    render.NamedProperties{},
+			err:      "opacity must be between 0 and 1, got 1.5",
+		},
+		{
+			name:     "invalid blend mode",
+			template: ComponentTemplate{BlendMode: "dodge"},
+			props:    render.NamedProperties{},
+			err:      "blend mode dodge does not match defined constants",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			layer, props, err := parseLayer(test.template)
+			assert.Equal(t, test.layer, layer)
+			assert.Equal(t, test.props, props)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestComponentLayerSetNamedProperties(t *testing.T) {
+	newLayer := func() ComponentLayer {
+		return ComponentLayer{
+			NamedPropertiesMap: map[string][]string{"fade": {"opacity"}, "mode": {"blendMode"}},
+			Style:              render.LayerStyle{Opacity: 1, BlendMode: render.BlendNormal},
+		}
+	}
+	t.Run("valid", func(t *testing.T) {
+		layer, err := newLayer().SetNamedProperties(render.NamedProperties{"fade": 0.5, "mode": "screen"})
+		assert.Equal(t, &ComponentLayer{NamedPropertiesMap: map[string][]string{}, Style: render.LayerStyle{Opacity: 0.5, BlendMode: render.BlendScreen}}, layer)
+		assert.NoError(t, err)
+	})
+	t.Run("out of range", func(t *testing.T) {
+		original := newLayer()
+		layer, err := original.SetNamedProperties(render.NamedProperties{"fade": -0.5})
+		assert.Equal(t, &original, layer)
+		assert.EqualError(t, err, "opacity must be between 0 and 1, got -0.5")
+	})
+	t.Run("wrong type", func(t *testing.T) {
+		original := newLayer()
+		layer, err := original.SetNamedProperties(render.NamedProperties{"mode": 3})
+		assert.Equal(t, &original, layer)
+		assert.EqualError(t, err, "error converting 3 to string")
+	})
+	t.Run("transform", func(t *testing.T) {
+		original := ComponentLayer{
+			NamedPropertiesMap: map[string][]string{"angle": {"rotate"}, "size": {"scale"}, "left": {"anchorX"}, "top": {"anchorY"}, "skew": {"skewX", "skewY"}},
+			Style:              render.LayerStyle{Opacity: 1, Transform: &render.Transform{ScaleX: 1, ScaleY: 1, Anchor: &image.Point{}}},
+		}
+		layer, err := original.SetNamedProperties(render.NamedProperties{"angle": 45.0, "size": 0.5, "left": 3, "top": 4, "skew": 5.0})
+		assert.NoError(t, err)
+		assert.Equal(t, &render.Transform{Rotation: 45, ScaleX: 0.5, ScaleY: 0.5, SkewX: 5, SkewY: 5, Anchor: &image.Point{X: 3, Y: 4}}, layer.Style.Transform)
+		assert.Equal(t, &render.Transform{ScaleX: 1, ScaleY: 1, Anchor: &image.Point{}}, original.Style.Transform, "the original transform should be unchanged")
+	})
+	t.Run("shadow", func(t *testing.T) {
+		original := ComponentLayer{
+			NamedPropertiesMap: map[string][]string{"soft": {"blur", "shadowBlur"}, "left": {"shadowOffsetX"}, "top": {"shadowOffsetY"}, "red": {"shadowR"}, "green": {"shadowG"}, "blue": {"shadowB"}, "alpha": {"shadowA"}},
+			Style:              render.LayerStyle{Opacity: 1, Shadow: &render.Shadow{}},
+		}
+		layer, err := original.SetNamedProperties(render.NamedProperties{"soft": 2.5, "left": 1, "top": -1, "red": uint8(1), "green": uint8(2), "blue": uint8(3), "alpha": uint8(4)})
+		assert.NoError(t, err)
+		assert.Equal(t, 2.5, layer.Style.Blur)
+		assert.Equal(t, &render.Shadow{Offset: image.Pt(1, -1), Blur: 2.5, Colour: color.NRGBA{R: 1, G: 2, B: 3, A: 4}}, layer.Style.Shadow)
+		assert.Equal(t, &render.Shadow{}, original.Style.Shadow, "the original shadow should be unchanged")
+	})
+	t.Run("no shadow", func(t *testing.T) {
+		original := ComponentLayer{NamedPropertiesMap: map[string][]string{"left": {"shadowOffsetX"}}}
+		_, err := original.SetNamedProperties(render.NamedProperties{"left": 3})
+		assert.EqualError(t, err, "invalid component property in named property map: shadowOffsetX")
+	})
+	t.Run("negative shadow blur", func(t *testing.T) {
+		original := ComponentLayer{NamedPropertiesMap: map[string][]string{"soft": {"shadowBlur"}}, Style: render.LayerStyle{Shadow: &render.Shadow{}}}
+		_, err := original.SetNamedProperties(render.NamedProperties{"soft": -1.0})
+		assert.EqualError(t, err, "shadow blur must not be negative, got -1")
+	})
+	t.Run("transform without anchor", func(t *testing.T) {
+		original := ComponentLayer{NamedPropertiesMap: map[string][]string{"left": {"anchorX"}}, Style: render.LayerStyle{Transform: &render.Transform{}}}
+		_, err := original.SetNamedProperties(render.NamedProperties{"left": 3})
+		assert.EqualError(t, err, "invalid component property in named property map: anchorX")
+	})
+	t.Run("no transform", func(t *testing.T) {
+		original := ComponentLayer{NamedPropertiesMap: map[string][]string{"angle": {"rotate"}}}
+		_, err := original.SetNamedProperties(render.NamedProperties{"angle": 3.0})
+		assert.EqualError(t, err, "invalid component property in named property map: rotate")
+	})
+	t.Run("invalid property", func(t *testing.T) {
+		original := ComponentLayer{NamedPropertiesMap: map[string][]string{"fade": {"alpha"}}, Style: render.LayerStyle{Transform: &render.Transform{}}}
+		layer, err := original.SetNamedProperties(render.NamedProperties{"fade": 0.5})
+		assert.Equal(t, &original, layer)
+		assert.EqualError(t, err, "invalid component property in named property map: alpha")
+	})
+}
+
+func TestLayeredComponents(t *testing.T) {
+	square := rectangle.Component{Width: 1, Height: 1, Colour: color.NRGBA{A: 255}}
+	newCanvas := func() render.Canvas {
+		canvas, _ := render.NewCanvas(2, 1)
+		drawn, _ := canvas.Rectangle(image.ZP, 2, 1, color.White)
+		return drawn
+	}
+	t.Run("unset named properties", func(t *testing.T) {
+		canvas := newCanvas()
+		b := ImageBuilder{Canvas: canvas, Components: []ToggleableComponent{
+			{Component: square, Layer: &ComponentLayer{NamedPropertiesMap: map[string][]string{"fade": {"opacity"}}}},
+		}}
+		m, err := b.ApplyComponents()
+		assert.Equal(t, b, m)
+		assert.EqualError(t, err, "cannot draw layer, not all named properties are set: map[fade:[opacity]]")
+	})
+	t.Run("layer error", func(t *testing.T) {
+		b := ImageBuilder{Canvas: render.ImageCanvas{}, Components: []ToggleableComponent{
+			{Component: square, Layer: &ComponentLayer{Style: render.LayerStyle{Opacity: 0.5}}},
+		}}
+		m, err := b.ApplyComponents()
+		assert.Equal(t, b, m)
+		assert.EqualError(t, err, "no image set for canvas to draw on")
+	})
+	t.Run("set and applied", func(t *testing.T) {
+		var b Builder = ImageBuilder{}
+		b, err := b.LoadComponentsData([]byte(`{
+			"baseImage": {"baseColour": {"R": "255", "G": "255", "B": "255", "A": "255"}, "width": "2", "height": "1"},
+			"components": [{"type": "rectangle", "opacity": "$fade$", "properties": {"topLeftX": "0", "topLeftY": "0", "width": "1", "height": "1", "colour": {"R": "0", "G": "0", "B": "0", "A": "255"}}}]
+		}`))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Contains(t, b.GetNamedPropertiesList(), "fade")
+		_, err = b.SetNamedProperties(render.NamedProperties{"fade": 1.5})
+		assert.EqualError(t, err, "opacity must be between 0 and 1, got 1.5")
+		b, err = b.SetNamedProperties(render.NamedProperties{"fade": 0.25})
+		assert.NoError(t, err)
+		b, err = b.ApplyComponents()
+		assert.NoError(t, err)
+		img := b.GetCanvas().GetUnderlyingImage()
+		assert.Equal(t, color.NRGBA{R: 191, G: 191, B: 191, A: 255}, img.At(0, 0))
+		assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, img.At(1, 0))
+	})
+	t.Run("transformed", func(t *testing.T) {
+		var b Builder = ImageBuilder{}
+		b, err := b.LoadComponentsData([]byte(`{
+			"baseImage": {"baseColour": {"R": "255", "G": "255", "B": "255", "A": "255"}, "width": "4", "height": "4"},
+			"components": [{"type": "rectangle", "transform": {"rotate": "90", "anchorX": "2", "anchorY": "2"}, "properties": {"topLeftX": "0", "topLeftY": "0", "width": "2", "height": "1", "colour": {"R": "0", "G": "0", "B": "0", "A": "255"}}}]
+		}`))
+		if !assert.NoError(t, err) {
+			return
+		}
+		b, err = b.ApplyComponents()
+		assert.NoError(t, err)
+		img := b.GetCanvas().GetUnderlyingImage()
+		assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, img.At(0, 0))
+		assert.Equal(t, color.NRGBA{A: 255}, img.At(3, 0))
+		assert.Equal(t, color.NRGBA{A: 255}, img.At(3, 1))
+	})
+	t.Run("clipped", func(t *testing.T) {
+		var b Builder = ImageBuilder{}
+		b, err := b.LoadComponentsData([]byte(`{
+			"baseImage": {"baseColour": {"R": "255", "G": "255", "B": "255", "A": "255"}, "width": "10", "height": "10"},
+			"components": [{"type": "rectangle", "clip": {"shape": "circle", "centreX": "5", "centreY": "5", "radius": "$size$"}, "properties": {"topLeftX": "0", "topLeftY": "0", "width": "10", "height": "10", "colour": {"R": "0", "G": "0", "B": "0", "A": "255"}}}]
+		}`))
+		if !assert.NoError(t, err) {
+			return
+		}
+		b, err = b.SetNamedProperties(render.NamedProperties{"size": 4})
+		assert.NoError(t, err)
+		b, err = b.ApplyComponents()
+		assert.NoError(t, err)
+		img := b.GetCanvas().GetUnderlyingImage()
+		assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, img.At(0, 0))
+		assert.Equal(t, color.NRGBA{A: 255}, img.At(5, 5))
+	})
+	t.Run("shadowed", func(t *testing.T) {
+		var b Builder = ImageBuilder{}
+		b, err := b.LoadComponentsData([]byte(`{
+			"baseImage": {"baseColour": {"R": "255", "G": "255", "B": "255", "A": "255"}, "width": "4", "height": "4"},
+			"components": [{"type": "rectangle", "shadow": {"offsetX": "1", "offsetY": "1", "colour": {"R": "255", "A": "255"}}, "properties": {"topLeftX": "0", "topLeftY": "0", "width": "2", "height": "2", "colour": {"R": "0", "G": "0", "B": "0", "A": "255"}}}]
+		}`))
+		if !assert.NoError(t, err) {
+			return
+		}
+		b, err = b.ApplyComponents()
+		assert.NoError(t, err)
+		img := b.GetCanvas().GetUnderlyingImage()
+		assert.Equal(t, color.NRGBA{A: 255}, img.At(1, 1))
+		assert.Equal(t, color.NRGBA{R: 255, A: 255}, img.At(2, 2))
+		assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, img.At(3, 3))
+	})
+	t.Run("invalid layer", func(t *testing.T) {
+		properties := `{"topLeftX": "0", "topLeftY": "0", "width": "1", "height": "1", "colour": {"R": "0", "G": "0", "B": "0", "A": "255"}}`
+		_, _, err := parseComponents([]ComponentTemplate{{Type: "rectangle", BlendMode: "dodge", Properties: []byte(properties)}})
+		assert.EqualError(t, err, "blend mode dodge does not match defined constants")
+	})
+}
@@ -0,0 +1,166 @@
+package scaffold
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
+)
+
+const (
+	// TemplateHashKey is the metadata key for the template hash written when provenance is enabled.
+	TemplateHashKey = "Template SHA-256"
+	// PropertiesHashKey is the metadata key for the named properties hash written when provenance is enabled.
+	PropertiesHashKey = "Properties SHA-256"
+)
+
+// HashTemplate returns the hex-encoded SHA-256 hash of raw template data, as recorded in output metadata.
+func HashTemplate(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// HashProperties returns the hex-encoded SHA-256 hash of a set of named properties, as recorded in output metadata. Keys are hashed in sorted order, so the hash does not depend on map ordering. Readers are hashed by type only, since reading them would consume the data.
+func HashProperties(props render.NamedProperties) string {
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%q=", key)
+		switch value := props[key].(type) {
+		case []byte:
+			fmt.Fprintf(hash, "[]byte:%x", value)
+		case string:
+			fmt.Fprintf(hash, "string:%q", value)
+		case time.Time:
+			fmt.Fprintf(hash, "time:%s", value.Format(time.RFC3339Nano))
+		case *time.Time:
+			if value != nil {
+				fmt.Fprintf(hash, "time:%s", value.Format(time.RFC3339Nano))
+			}
+		case io.Reader:
+			fmt.Fprintf(hash, "%T", value)
+		default:
+			fmt.Fprintf(hash, "%T:%v", value, value)
+		}
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// textField is a key/value pair written as text metadata.
+type textField struct {
+	key, value string
+}
+
+// headerWriter holds back the first size bytes written, so they can be patched or followed by extra data before being passed on.
+type headerWriter struct {
+	w      io.Writer
+	size   int
+	patch  func(header []byte) []byte
+	header []byte
+	done   bool
+}
+
+func (hw *headerWriter) Write(p []byte) (int, error) {
+	if hw.done {
+		return hw.w.Write(p)
+	}
+	need := hw.size - len(hw.header)
+	if len(p) < need {
+		hw.header = append(hw.header, p...)
+		return len(p), nil
+	}
+	hw.header = append(hw.header, p[:need]...)
+	err := hw.flush()
+	if err != nil {
+		return 0, err
+	}
+	n, err := hw.w.Write(p[need:])
+	return need + n, err
+}
+
+// flush writes the patched header, or whatever was held back if the output was too short to have a header.
+func (hw *headerWriter) flush() error {
+	if hw.done {
+		return nil
+	}
+	hw.done = true
+	header := hw.header
+	if len(header) == hw.size {
+		header = hw.patch(header)
+	}
+	_, err := hw.w.Write(header)
+	return err
+}
+
+// metadataWriter wraps a writer to add the resolution and any text fields to the encoded output, for the formats which support them.
+func metadataWriter(w io.Writer, format ImageFormat, ppi float64, text []textField) *headerWriter {
+	switch format {
+	case FormatPNG:
+		// The signature and IHDR chunk always come first, and metadata chunks may follow them.
+		return &headerWriter{w: w, size: 33, patch: func(header []byte) []byte {
+			var buf bytes.Buffer
+			buf.Write(header)
+			pw := &pngWriter{w: &buf}
+			if ppi > 0 {
+				phys := make([]byte, 9)
+				perMetre := pixelsPerMetre(ppi)
+				binary.BigEndian.PutUint32(phys[0:], perMetre)
+				binary.BigEndian.PutUint32(phys[4:], perMetre)
+				phys[8] = 1
+				pw.chunk("pHYs", phys)
+			}
+			for _, field := range text {
+				pw.chunk("tEXt", []byte(field.key+"\x00"+field.value))
+			}
+			return buf.Bytes()
+		}}
+	case FormatJPEG:
+		// The start of image marker comes first, followed by the JFIF segment and any comments.
+		return &headerWriter{w: w, size: 2, patch: func(header []byte) []byte {
+			var buf bytes.Buffer
+			buf.Write(header)
+			if ppi > 0 {
+				dpi := uint16(math.Min(math.Round(ppi), math.MaxUint16))
+				buf.Write([]byte{0xff, 0xe0, 0, 16, 'J', 'F', 'I', 'F', 0, 1, 1, 1})
+				binary.Write(&buf, binary.BigEndian, []uint16{dpi, dpi})
+				buf.Write([]byte{0, 0})
+			}
+			for _, field := range text {
+				comment := []byte(field.key + ": " + field.value)
+				buf.Write([]byte{0xff, 0xfe})
+				binary.Write(&buf, binary.BigEndian, uint16(len(comment)+2))
+				buf.Write(comment)
+			}
+			return buf.Bytes()
+		}}
+	case FormatBMP:
+		if ppi <= 0 {
+			break
+		}
+		// The resolution is in the info header, which has the same layout up to this point in every version.
+		return &headerWriter{w: w, size: 46, patch: func(header []byte) []byte {
+			perMetre := pixelsPerMetre(ppi)
+			binary.LittleEndian.PutUint32(header[38:], perMetre)
+			binary.LittleEndian.PutUint32(header[42:], perMetre)
+			return header
+		}}
+	}
+	return &headerWriter{w: w, done: true}
+}
+
+// pixelsPerMetre converts a resolution in pixels per inch to pixels per metre.
+func pixelsPerMetre(ppi float64) uint32 {
+	return uint32(math.Round(ppi / 0.0254))
+}
@@ -0,0 +1,180 @@
+package scaffold
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	fs "github.com/LLKennedy/imagetemplate/v3/internal/filesystem"
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/bmp"
+)
+
+func TestHashTemplate(t *testing.T) {
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", HashTemplate(nil))
+	assert.NotEqual(t, HashTemplate([]byte("{}")), HashTemplate([]byte("{ }")))
+}
+
+func TestHashProperties(t *testing.T) {
+	moment := time.Date(2019, 3, 4, 5, 6, 7, 0, time.UTC)
+	props := render.NamedProperties{
+		"name":   "Jane",
+		"count":  3,
+		"photo":  []byte{1, 2, 3},
+		"when":   moment,
+		"reader": strings.NewReader("ignored"),
+	}
+	hash := HashProperties(props)
+	assert.Len(t, hash, 64)
+	t.Run("deterministic", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, hash, HashProperties(props))
+		}
+	})
+	t.Run("values matter", func(t *testing.T) {
+		changed := render.NamedProperties{}
+		for key, value := range props {
+			changed[key] = value
+		}
+		changed["photo"] = []byte{1, 2, 4}
+		assert.NotEqual(t, hash, HashProperties(changed))
+		changed["photo"] = []byte{1, 2, 3}
+		changed["count"] = "3"
+		assert.NotEqual(t, hash, HashProperties(changed))
+		changed["count"] = 3
+		changed["when"] = &moment
+		assert.Equal(t, hash, HashProperties(changed))
+		changed["reader"] = strings.NewReader("different")
+		assert.Equal(t, hash, HashProperties(changed))
+	})
+	t.Run("keys matter", func(t *testing.T) {
+		assert.NotEqual(t, HashProperties(render.NamedProperties{"a": "bc"}), HashProperties(render.NamedProperties{"ab": "c"}))
+		assert.NotEqual(t, HashProperties(nil), HashProperties(render.NamedProperties{"a": nil}))
+	})
+}
+
+func TestHeaderWriter(t *testing.T) {
+	t.Run("short output", func(t *testing.T) {
+		var buf bytes.Buffer
+		hw := &headerWriter{w: &buf, size: 4, patch: func(header []byte) []byte {
+			return []byte("patched")
+		}}
+		n, err := hw.Write([]byte("ab"))
+		assert.Equal(t, 2, n)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, buf.Len())
+		assert.NoError(t, hw.flush())
+		assert.Equal(t, "ab", buf.String())
+	})
+	t.Run("split header", func(t *testing.T) {
+		var buf bytes.Buffer
+		hw := &headerWriter{w: &buf, size: 4, patch: func(header []byte) []byte {
+			return append([]byte("<"), append(header, '>')...)
+		}}
+		hw.Write([]byte("ab"))
+		n, err := hw.Write([]byte("cdef"))
+		assert.Equal(t, 4, n)
+		assert.NoError(t, err)
+		assert.NoError(t, hw.flush())
+		assert.Equal(t, "<abcd>ef", buf.String())
+	})
+	t.Run("failing writer", func(t *testing.T) {
+		hw := &headerWriter{w: failingWriter{}, size: 1, patch: func(header []byte) []byte {
+			return header
+		}}
+		n, err := hw.Write([]byte("ab"))
+		assert.Equal(t, 0, n)
+		assert.EqualError(t, err, "writer closed")
+	})
+}
+
+// pngChunks returns the data of each chunk in a PNG file with the specified name.
+func pngChunks(t *testing.T, data []byte, name string) [][]byte {
+	var result [][]byte
+	for _, chunk := range readPNGChunks(t, data) {
+		if chunk.name == name {
+			result = append(result, chunk.data)
+		}
+	}
+	return result
+}
+
+func TestWriteMetadata(t *testing.T) {
+	canvas, err := render.NewCanvas(4, 3)
+	assert.NoError(t, err)
+	builder := ImageBuilder{Canvas: canvas.SetPPI(300)}
+	provenance := &EncodeOptions{Provenance: true}
+	t.Run("png resolution", func(t *testing.T) {
+		data, err := builder.WriteToPNG()
+		assert.NoError(t, err)
+		_, err = png.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+		phys := pngChunks(t, data, "pHYs")
+		if assert.Len(t, phys, 1) {
+			assert.Equal(t, []byte{0, 0, 0x2e, 0x23, 0, 0, 0x2e, 0x23, 1}, phys[0])
+		}
+		assert.Empty(t, pngChunks(t, data, "tEXt"))
+	})
+	t.Run("no resolution", func(t *testing.T) {
+		data, err := ImageBuilder{Canvas: canvas}.WriteToPNG()
+		assert.NoError(t, err)
+		assert.Empty(t, pngChunks(t, data, "pHYs"))
+	})
+	t.Run("png provenance", func(t *testing.T) {
+		data, err := builder.WriteToFormat(FormatPNG, provenance)
+		assert.NoError(t, err)
+		assert.Empty(t, pngChunks(t, data, "tEXt"), "builders without a template or properties have nothing to record")
+		hashed := builder
+		hashed.templateHash = "abc"
+		hashed.propertiesHash = "def"
+		data, err = hashed.WriteToFormat(FormatPNG, provenance)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]byte{[]byte("Template SHA-256\x00abc"), []byte("Properties SHA-256\x00def")}, pngChunks(t, data, "tEXt"))
+		_, err = png.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+	})
+	t.Run("jpeg", func(t *testing.T) {
+		data, err := builder.WriteToJPEG(90)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0xff, 0xd8, 0xff, 0xe0, 0, 16, 'J', 'F', 'I', 'F', 0, 1, 1, 1, 1, 0x2c, 1, 0x2c, 0, 0}, data[:20])
+		_, err = jpeg.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+		hashed := builder
+		hashed.templateHash = "abc"
+		data, err = hashed.WriteToFormat(FormatJPEG, &EncodeOptions{Provenance: true, JPEGQuality: 90})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("\xff\xfe\x00\x17Template SHA-256: abc"), data[20:45])
+		_, err = jpeg.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+	})
+	t.Run("bmp", func(t *testing.T) {
+		data, err := builder.WriteToBMP()
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(11811), binary.LittleEndian.Uint32(data[38:]))
+		assert.Equal(t, uint32(11811), binary.LittleEndian.Uint32(data[42:]))
+		decoded, err := bmp.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 4, 3), decoded.Bounds())
+	})
+	t.Run("loaded template", func(t *testing.T) {
+		template := []byte(`{"baseImage": {"baseColour": {"R": "255", "G": "255", "B": "255", "A": "255"}, "width": "4", "height": "3", "ppi": "150"}, "components": []}`)
+		loaded, err := NewBuilder(fs.NewMockFileSystem()).LoadComponentsData(template)
+		assert.NoError(t, err)
+		props := render.NamedProperties{"name": "value"}
+		loaded, err = loaded.SetNamedProperties(props)
+		assert.NoError(t, err)
+		data, err := loaded.WriteToFormat(FormatPNG, provenance)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]byte{
+			[]byte("Template SHA-256\x00" + HashTemplate(template)),
+			[]byte("Properties SHA-256\x00" + HashProperties(props)),
+		}, pngChunks(t, data, "tEXt"))
+		assert.Equal(t, [][]byte{{0, 0, 0x17, 0x12, 0, 0, 0x17, 0x12, 1}}, pngChunks(t, data, "pHYs"))
+	})
+}
@@ -0,0 +1,80 @@
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/disintegration/imaging"
+)
+
+// WriteToZPL outputs the contents of the builder as a ZPL II label for Zebra printers. Native fields are preserved if the canvas is a render.ZPLCanvas, any other canvas is printed as a graphic field of its dark pixels.
+func (builder ImageBuilder) WriteToZPL() ([]byte, error) {
+	canvas, isZPL := builder.GetCanvas().(render.ZPLCanvas)
+	if !isZPL {
+		var err error
+		canvas, err = render.NewZPLCanvasFrom(builder.GetCanvas())
+		if err != nil {
+			return nil, err
+		}
+	}
+	var buf bytes.Buffer
+	err := canvas.WriteZPL(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// escposBandHeight is the number of rows sent in each raster command, small enough for the receive buffers of common receipt printers.
+const escposBandHeight = 255
+
+// WriteToESCPOS outputs the contents of the builder as ESC/POS commands for a receipt printer with the dot width of the print head (usually 384 for 58mm paper or 576 for 80mm paper), dithered to black and white. Wider canvases are scaled down to the dot width, while narrower canvases are printed at their own size on the left, padded with white, rather than stretched and blurred.
+func (builder ImageBuilder) WriteToESCPOS(dotWidth int) ([]byte, error) {
+	if dotWidth <= 0 || dotWidth%8 != 0 {
+		return nil, fmt.Errorf("invalid dot width %d, must be a positive multiple of 8", dotWidth)
+	}
+	img := builder.GetCanvas().GetUnderlyingImage()
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return nil, errors.New("no image to print")
+	}
+	if bounds.Dx() > dotWidth {
+		img = imaging.Resize(img, dotWidth, 0, imaging.Lanczos)
+	} else if bounds.Dx() < dotWidth {
+		padded := image.NewNRGBA(image.Rect(0, 0, dotWidth, bounds.Dy()))
+		draw.Draw(padded, padded.Rect, image.White, image.ZP, draw.Src)
+		draw.Draw(padded, padded.Rect, img, bounds.Min, draw.Over)
+		img = padded
+	}
+	dithered, err := Dither(img, DitherOptions{Method: DitherFloydSteinberg})
+	if err != nil {
+		return nil, err
+	}
+	rowBytes := dotWidth / 8
+	height := dithered.Bounds().Dy()
+	var buf bytes.Buffer
+	// ESC @ initialises the printer.
+	buf.Write([]byte{0x1b, 0x40})
+	for bandStart := 0; bandStart < height; bandStart += escposBandHeight {
+		bandRows := height - bandStart
+		if bandRows > escposBandHeight {
+			bandRows = escposBandHeight
+		}
+		// GS v 0 prints a raster bit image in normal mode, with the width in bytes and the height in dots.
+		buf.Write([]byte{0x1d, 0x76, 0x30, 0x00, byte(rowBytes), byte(rowBytes >> 8), byte(bandRows), byte(bandRows >> 8)})
+		for y := bandStart; y < bandStart+bandRows; y++ {
+			row := make([]byte, rowBytes)
+			for x := 0; x < dotWidth; x++ {
+				if dithered.Pix[y*dithered.Stride+x] == 0 {
+					row[x/8] |= 0x80 >> uint(x%8)
+				}
+			}
+			buf.Write(row)
+		}
+	}
+	return buf.Bytes(), nil
+}
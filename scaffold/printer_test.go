@@ -0,0 +1,96 @@
+package scaffold
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteToZPL(t *testing.T) {
+	t.Run("raster canvas", func(t *testing.T) {
+		canvas, _ := render.NewCanvas(16, 2)
+		drawnCanvas, _ := canvas.Rectangle(image.ZP, 4, 1, color.Black)
+		data, err := ImageBuilder{Canvas: drawnCanvas}.WriteToZPL()
+		assert.NoError(t, err)
+		assert.Equal(t, "^XA\n^CI28\n^PW16\n^LL2\n^LH0,0\n^FO0,0^GFA,4,4,2,F0000000^FS\n^XZ\n", string(data))
+	})
+	t.Run("zpl canvas", func(t *testing.T) {
+		canvas, _ := render.NewZPLCanvas(16, 2)
+		drawnCanvas, _ := canvas.Rectangle(image.ZP, 4, 1, color.Black)
+		data, err := ImageBuilder{Canvas: drawnCanvas}.WriteToZPL()
+		assert.NoError(t, err)
+		assert.Equal(t, "^XA\n^CI28\n^PW16\n^LL2\n^LH0,0\n^FO0,0^GB4,1,1,B,0^FS\n^XZ\n", string(data))
+	})
+	t.Run("invalid canvas", func(t *testing.T) {
+		data, err := ImageBuilder{Canvas: render.ImageCanvas{}}.WriteToZPL()
+		assert.Nil(t, data)
+		assert.EqualError(t, err, "invalid width and height")
+	})
+}
+
+func TestWriteToESCPOS(t *testing.T) {
+	t.Run("invalid dot width", func(t *testing.T) {
+		canvas, _ := render.NewCanvas(16, 3)
+		data, err := ImageBuilder{Canvas: canvas}.WriteToESCPOS(380)
+		assert.Nil(t, data)
+		assert.EqualError(t, err, "invalid dot width 380, must be a positive multiple of 8")
+	})
+	t.Run("empty image", func(t *testing.T) {
+		data, err := ImageBuilder{Canvas: render.ImageCanvas{}}.WriteToESCPOS(384)
+		assert.Nil(t, data)
+		assert.EqualError(t, err, "no image to print")
+	})
+	t.Run("fixture", func(t *testing.T) {
+		canvas, _ := render.NewCanvas(16, 3)
+		drawnCanvas, _ := canvas.Rectangle(image.ZP, 16, 1, color.Black)
+		drawnCanvas, _ = drawnCanvas.Rectangle(image.Pt(0, 1), 4, 1, color.NRGBA{R: 10, G: 10, B: 10, A: 255})
+		drawnCanvas, _ = drawnCanvas.Rectangle(image.Pt(12, 1), 4, 1, color.Black)
+		drawnCanvas, _ = drawnCanvas.Rectangle(image.Pt(0, 2), 16, 1, color.White)
+		data, err := ImageBuilder{Canvas: drawnCanvas}.WriteToESCPOS(16)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{
+			0x1b, 0x40,
+			0x1d, 0x76, 0x30, 0x00, 0x02, 0x00, 0x03, 0x00,
+			0xff, 0xff,
+			0xf0, 0x0f,
+			0x00, 0x00,
+		}, data)
+	})
+	t.Run("bands", func(t *testing.T) {
+		canvas, _ := render.NewCanvas(8, 300)
+		drawnCanvas, _ := canvas.Rectangle(image.ZP, 8, 300, color.Black)
+		data, err := ImageBuilder{Canvas: drawnCanvas}.WriteToESCPOS(8)
+		assert.NoError(t, err)
+		assert.Len(t, data, 2+8+255+8+45)
+		assert.Equal(t, []byte{0x1d, 0x76, 0x30, 0x00, 0x01, 0x00, 0xff, 0x00}, data[2:10])
+		assert.Equal(t, []byte{0x1d, 0x76, 0x30, 0x00, 0x01, 0x00, 0x2d, 0x00}, data[265:273])
+		for _, row := range append(data[10:265], data[273:]...) {
+			assert.Equal(t, byte(0xff), row)
+		}
+	})
+	t.Run("padded to dot width", func(t *testing.T) {
+		canvas, _ := render.NewCanvas(12, 2)
+		drawnCanvas, _ := canvas.Rectangle(image.ZP, 12, 1, color.Black)
+		drawnCanvas, _ = drawnCanvas.Rectangle(image.Pt(0, 1), 12, 1, color.White)
+		drawnCanvas, _ = drawnCanvas.Rectangle(image.Pt(4, 1), 4, 1, color.Black)
+		data, err := ImageBuilder{Canvas: drawnCanvas}.WriteToESCPOS(24)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{
+			0x1b, 0x40,
+			0x1d, 0x76, 0x30, 0x00, 0x03, 0x00, 0x02, 0x00,
+			0xff, 0xf0, 0x00,
+			0x0f, 0x00, 0x00,
+		}, data)
+	})
+	t.Run("scaled to dot width", func(t *testing.T) {
+		canvas, _ := render.NewCanvas(768, 100)
+		drawnCanvas, _ := canvas.Rectangle(image.ZP, 768, 100, color.White)
+		data, err := ImageBuilder{Canvas: drawnCanvas}.WriteToESCPOS(384)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0x1d, 0x76, 0x30, 0x00, 0x30, 0x00, 0x32, 0x00}, data[2:10])
+		assert.Len(t, data, 2+8+48*50)
+	})
+}
@@ -0,0 +1,43 @@
+package scaffold
+
+import (
+	"bytes"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
+)
+
+// WriteToPDF outputs the contents of the builder to a PDF byte array. Vector operations are preserved if the canvas is a render.PDFCanvas, any other canvas is embedded in the page as an image.
+func (builder ImageBuilder) WriteToPDF() ([]byte, error) {
+	canvas, isPDF := builder.GetCanvas().(render.PDFCanvas)
+	if !isPDF {
+		var err error
+		canvas, err = render.NewPDFCanvasFrom(builder.GetCanvas())
+		if err != nil {
+			return nil, err
+		}
+	}
+	var buf bytes.Buffer
+	err := canvas.WritePDF(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteToSVG outputs the contents of the builder to an SVG byte array. Elements are preserved if the canvas is a render.SVGCanvas, any other canvas is embedded in the document as an image.
+func (builder ImageBuilder) WriteToSVG() ([]byte, error) {
+	canvas, isSVG := builder.GetCanvas().(render.SVGCanvas)
+	if !isSVG {
+		var err error
+		canvas, err = render.NewSVGCanvasFrom(builder.GetCanvas())
+		if err != nil {
+			return nil, err
+		}
+	}
+	var buf bytes.Buffer
+	err := canvas.WriteSVG(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
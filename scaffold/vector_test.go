@@ -0,0 +1,65 @@
+package scaffold
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/LLKennedy/imagetemplate/v3/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteToPDF(t *testing.T) {
+	t.Run("raster canvas", func(t *testing.T) {
+		canvas, _ := render.NewCanvas(20, 10)
+		drawnCanvas, _ := canvas.Rectangle(image.ZP, 5, 5, color.Black)
+		data, err := ImageBuilder{Canvas: drawnCanvas.SetPPI(144)}.WriteToPDF()
+		assert.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4\n")))
+		assert.Contains(t, string(data), "/MediaBox [0 0 10 5]")
+		assert.Contains(t, string(data), "/Im1")
+	})
+	t.Run("pdf canvas", func(t *testing.T) {
+		canvas, _ := render.NewPDFCanvas(20, 10)
+		drawnCanvas, _ := canvas.Rectangle(image.ZP, 5, 5, color.Black)
+		data, err := ImageBuilder{Canvas: drawnCanvas}.WriteToPDF()
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "/MediaBox [0 0 20 10]")
+		assert.NotContains(t, string(data), "/Im1")
+		var expected bytes.Buffer
+		err = drawnCanvas.(render.PDFCanvas).WritePDF(&expected)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.Bytes(), data)
+	})
+	t.Run("invalid canvas", func(t *testing.T) {
+		data, err := ImageBuilder{Canvas: render.ImageCanvas{}}.WriteToPDF()
+		assert.Nil(t, data)
+		assert.EqualError(t, err, "invalid width and height")
+	})
+}
+
+func TestWriteToSVG(t *testing.T) {
+	t.Run("raster canvas", func(t *testing.T) {
+		canvas, _ := render.NewCanvas(20, 10)
+		drawnCanvas, _ := canvas.Rectangle(image.ZP, 5, 5, color.Black)
+		data, err := ImageBuilder{Canvas: drawnCanvas}.WriteToSVG()
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `viewBox="0 0 20 10"`)
+		assert.Contains(t, string(data), "<image")
+		assert.NotContains(t, string(data), "<rect")
+	})
+	t.Run("svg canvas", func(t *testing.T) {
+		canvas, _ := render.NewSVGCanvas(20, 10)
+		drawnCanvas, _ := canvas.Rectangle(image.ZP, 5, 5, color.Black)
+		data, err := ImageBuilder{Canvas: drawnCanvas}.WriteToSVG()
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `<rect x="0" y="0" width="5" height="5" fill="#000000"/>`)
+		assert.NotContains(t, string(data), "<image")
+	})
+	t.Run("invalid canvas", func(t *testing.T) {
+		data, err := ImageBuilder{Canvas: render.ImageCanvas{}}.WriteToSVG()
+		assert.Nil(t, data)
+		assert.EqualError(t, err, "invalid width and height")
+	})
+}